@@ -2,18 +2,36 @@ package main
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"maps"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
 	"syscall"
 	"time"
 
 	"charm.land/log/v2"
 	"github.com/LarsArtmann/template-arch-lint/internal/application/handlers"
+	"github.com/LarsArtmann/template-arch-lint/internal/application/middleware"
+	"github.com/LarsArtmann/template-arch-lint/internal/application/routes"
+	"github.com/LarsArtmann/template-arch-lint/internal/application/wellknown"
+	"github.com/LarsArtmann/template-arch-lint/internal/config"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/clock"
 	"github.com/LarsArtmann/template-arch-lint/internal/domain/repositories"
 	"github.com/LarsArtmann/template-arch-lint/internal/domain/services"
-	"github.com/larsartmann/httputil"
+	"github.com/LarsArtmann/template-arch-lint/internal/infrastructure"
+	"github.com/LarsArtmann/template-arch-lint/internal/infrastructure/health"
+	"github.com/LarsArtmann/template-arch-lint/internal/infrastructure/persistence"
+	"github.com/LarsArtmann/template-arch-lint/internal/infrastructure/persistence/migrations"
+	_ "github.com/mattn/go-sqlite3"
 )
 
 const (
@@ -22,14 +40,74 @@ const (
 	defaultServerPort = 8080
 )
 
+// Health probe paths for container orchestrators.
 const (
-	defaultServerReadTimeout  = 15 * time.Second
-	defaultServerWriteTimeout = 15 * time.Second
-	defaultServerIdleTimeout  = 60 * time.Second
-	defaultGracefulTimeout    = 30 * time.Second
+	healthLivePath  = "/health/live"
+	healthReadyPath = "/health/ready"
+)
+
+// adminRole is the access token role that unlocks the admin endpoints.
+const adminRole = "admin"
+
+const (
+	// defaultDatabaseCloseTimeout bounds closing the database after draining.
+	defaultDatabaseCloseTimeout = 5 * time.Second
+	// defaultMaxShutdownWait caps a whole shutdown; past it the process exits anyway.
+	defaultMaxShutdownWait = 45 * time.Second
+	// healthCheckTimeout bounds the -health-check probe.
+	healthCheckTimeout = 5 * time.Second
 )
 
 func main() {
+	configPath := flag.String("config", "", "path to a config file")
+	envOnly := flag.Bool("env-only", false, "load configuration from APP_* environment variables only")
+	envDocs := flag.Bool("env-docs", false, "print the environment variable reference and exit")
+	migrate := flag.Bool("migrate", false, "apply pending database migrations and exit")
+	healthCheck := flag.Bool("health-check", false, "probe the readiness of the local server and exit")
+	configInit := flag.String("config-init", "", "print a complete config file for `environment` ("+
+		strings.Join(config.ScaffoldEnvironments(), ", ")+") and exit")
+	configCheck := flag.String("config-check", "", "validate the config `file` without loading it and exit")
+	flag.Parse()
+
+	if *envDocs {
+		fmt.Print(config.EnvDocs())
+		os.Exit(exitCodeSuccess)
+	}
+
+	if *configInit != "" {
+		scaffold, err := config.Scaffold(*configInit)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(exitCodeFailure)
+		}
+
+		_, _ = os.Stdout.Write(scaffold)
+		os.Exit(exitCodeSuccess)
+	}
+
+	if *configCheck != "" {
+		valid, err := checkConfigFile(*configCheck, os.Stdout)
+		if err != nil || !valid {
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+
+			os.Exit(exitCodeFailure)
+		}
+
+		os.Exit(exitCodeSuccess)
+	}
+
+	if *healthCheck {
+		err := probeReadiness(context.Background(), fmt.Sprintf("http://localhost:%d", defaultServerPort))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "not ready:", err)
+			os.Exit(exitCodeFailure)
+		}
+
+		os.Exit(exitCodeSuccess)
+	}
+
 	logger := log.NewWithOptions(os.Stdout, log.Options{
 		ReportCaller:    false,
 		ReportTimestamp: true,
@@ -40,51 +118,621 @@ func main() {
 	logger.Info("🔥 Template-Arch-Lint - Pure Linting Template")
 	logger.Info("✅ This demonstrates enterprise-grade Go architecture enforcement")
 
-	userRepo := repositories.NewInMemoryUserRepository()
-	userService := services.NewUserService(userRepo)
-	userHandler := handlers.NewUserHandler(userService)
+	cfg, err := loadConfiguration(*configPath, *envOnly)
+	if err != nil {
+		logger.Error("❌ Failed to load configuration", "error", err)
+		os.Exit(exitCodeFailure)
+	}
+
+	db, err := persistence.NewDB(context.Background(), persistence.PoolConfig{
+		Driver:          cfg.Database.Driver,
+		DSN:             cfg.Database.DSN,
+		MaxOpenConns:    cfg.Database.MaxOpenConns,
+		MaxIdleConns:    cfg.Database.MaxIdleConns,
+		ConnMaxLifetime: cfg.Database.ConnMaxLifetime,
+		ConnMaxIdleTime: cfg.Database.ConnMaxIdleTime,
+		PingTimeout:     cfg.Database.PingTimeout,
+	})
+	if err != nil {
+		logger.Error("❌ Failed to connect to the database", "driver", cfg.Database.Driver, "error", err)
+		os.Exit(exitCodeFailure)
+	}
+
+	err = stampEnvironment(context.Background(), db, cfg.App.Environment, logger)
+	if err != nil {
+		_ = db.Close()
+
+		logger.Error("❌ Failed to stamp the database environment", "error", err)
+		os.Exit(exitCodeFailure)
+	}
+
+	if *migrate {
+		err = runMigrations(context.Background(), db, logger)
+		_ = db.Close()
+
+		if err != nil {
+			logger.Error("❌ Migration failed", "error", err)
+			os.Exit(exitCodeFailure)
+		}
+
+		os.Exit(exitCodeSuccess)
+	}
+
+	wellKnownSettings := wellknown.Settings{
+		SecurityContacts:   cfg.Server.WellKnown.SecurityContacts,
+		SecurityExpiresIn:  cfg.Server.WellKnown.SecurityExpiresIn,
+		SecurityPolicyURL:  cfg.Server.WellKnown.SecurityPolicyURL,
+		PreferredLanguages: cfg.Server.WellKnown.PreferredLanguages,
+		RobotsDisallow:     cfg.Server.WellKnown.RobotsDisallow,
+	}
+	for _, warning := range wellknown.Validate(wellKnownSettings) {
+		logger.Warn("⚠️ " + warning)
+	}
+
+	// Everything that reads the time shares one clock, so tests can swap it.
+	systemClock := clock.System{}
+
+	var userRepo repositories.UserRepository = repositories.NewInMemoryUserRepositoryWithClock(systemClock)
+	if cfg.Cache.Enabled {
+		userRepo = persistence.NewCachingUserRepository(userRepo, cfg.Cache.TTL).
+			WithJitter(cfg.Cache.TTLJitter).
+			WithNegativeTTL(cfg.Cache.NegativeTTL)
+	}
+
+	userService := services.NewUserService(userRepo).WithLogger(logger).WithClock(systemClock)
+	userHandler := handlers.NewUserHandler(userService).
+		WithPutCreate(cfg.API.AllowPutCreate).
+		WithClock(systemClock)
+
+	if cfg.API.Idempotency.Enabled {
+		userHandler.WithIdempotency(newIdempotencyStore(cfg.API.Idempotency, db, systemClock, logger),
+			idempotencyOptions(cfg.API.Idempotency))
+	}
 
 	mux := http.NewServeMux()
-	mux.HandleFunc("GET /health", httputil.HealthHandler())
-	userHandler.RegisterRoutes(mux)
+	healthChecks := health.NewRegistry(cfg.Database.PingTimeout)
+	healthChecks.Register(persistence.NewDatabaseChecker(db))
+
+	mux.HandleFunc("GET /health", persistence.HealthHandler(db, cfg.Database.PingTimeout))
+	mux.HandleFunc("GET "+healthLivePath, healthChecks.LiveHandler())
+	mux.HandleFunc("GET "+healthReadyPath, healthChecks.ReadyHandler())
+	// User management needs a valid access token, config admin the admin role.
+	authenticator := newAuthenticator(cfg.JWT)
+	requireAdmin := middleware.RequireRole(adminRole)
+
+	mux.Handle(routes.Pattern(http.MethodPost, routes.ConfigValidatePath),
+		authenticator.Middleware(requireAdmin(config.ValidateHandler(config.DefaultValidateMaxBytes))))
+
+	var concurrencyLimiter *middleware.ConcurrencyLimiter
+
+	if cfg.Server.Concurrency.Enabled {
+		concurrencyLimiter = newConcurrencyLimiter(cfg.Server.Concurrency, logger)
+		mux.Handle(routes.Pattern(http.MethodGet, routes.OpsConcurrencyPath),
+			authenticator.Middleware(requireAdmin(concurrencyLimiter.StatsHandler())))
+	}
+
+	for _, route := range userHandler.Routes() {
+		mux.Handle(route.Pattern, authenticator.Middleware(route.Handler))
+	}
+
+	wellknown.NewHandler(wellKnownSettings, routes.All()).RegisterRoutes(mux)
+
+	accessLog := middleware.NewAccessLog(nil).WithClock(systemClock)
+	routed := accessLog.Routes(mux)
+
+	// Outside the route hook, so a 504 still names its route in the log.
+	handler := newRequestTimeouts(cfg.Server, logger).Middleware(routed)
+
+	var recorder *middleware.Recorder
 
-	serverCfg := httputil.ServerConfig{
-		Addr:         fmt.Sprintf(":%d", defaultServerPort),
-		ReadTimeout:  defaultServerReadTimeout,
-		WriteTimeout: defaultServerWriteTimeout,
-		IdleTimeout:  defaultServerIdleTimeout,
+	if cfg.App.Recording.Enabled {
+		recorder = middleware.NewRecorder(middleware.RecordingOptions{
+			Dir:        cfg.App.Recording.Dir,
+			SampleRate: cfg.App.Recording.SampleRate,
+			Routes:     cfg.App.Recording.Routes,
+		})
+		handler = recorder.Middleware(handler)
+
+		logger.Warn("⚠️ Recording request fixtures", "dir", cfg.App.Recording.Dir)
+	}
+
+	// Outside the timeouts, so waiting for a slot does not use up a route's
+	// time limit.
+	if concurrencyLimiter != nil {
+		handler = concurrencyLimiter.Middleware(handler)
+	}
+
+	handler = middleware.NewBodyLimits(middleware.BodyLimitOptions{
+		MaxBytes: cfg.Server.MaxRequestBodyBytes,
+		// Both check their own media types and bounds.
+		RawBodyRoutes: []string{
+			routes.Pattern(http.MethodPost, routes.UsersImportPath),
+			routes.Pattern(http.MethodPost, routes.ConfigValidatePath),
+		},
+	}).Middleware(handler)
+
+	headerLimiter := middleware.NewHeaderLimiter(middleware.HeaderLimitOptions{
+		MaxTotalBytes:  cfg.Server.Headers.SoftLimitBytes,
+		MaxFieldBytes:  cfg.Server.Headers.FieldLimitBytes,
+		MaxCookieBytes: cfg.Server.Headers.CookieLimitBytes,
+	})
+	handler = headerLimiter.Middleware(handler)
+
+	if cfg.Security.RateLimitEnabled {
+		handler = newRateLimiter(cfg.Security, logger).Middleware(handler)
+	}
+
+	// Shedding runs before rate limiting, so an overloaded server does the
+	// least work per rejected request.
+	if cfg.Server.LoadShedding.Enabled {
+		shedder := newLoadShedder(cfg.Server.LoadShedding, mux)
+		handler = shedder.Middleware(handler)
+
+		// The sampler lives as long as the process.
+		go shedder.Run(context.Background())
 	}
 
-	server, err := httputil.NewServer(serverCfg, mux)
+	// CORS sits outside the rate limiter, so preflights do not use up a
+	// client's allowance and 429s still carry the CORS headers.
+	handler = middleware.NewCORS(middleware.CORSOptions{
+		AllowedOrigins:   cfg.Security.CORS.AllowedOrigins,
+		AllowedMethods:   cfg.Security.CORS.AllowedMethods,
+		AllowedHeaders:   cfg.Security.CORS.AllowedHeaders,
+		ExposedHeaders:   cfg.Security.CORS.ExposedHeaders,
+		AllowCredentials: cfg.Security.CORS.AllowCredentials,
+		MaxAge:           cfg.Security.CORS.MaxAge,
+	}).Middleware(handler)
+	handler = middleware.NewSecurityHeaders(securityHeaderOptions(cfg.Security)).Middleware(handler)
+	// Inside RequestIDs, so every access log line carries the request ID.
+	handler = accessLog.Middleware(handler)
+	handler = middleware.NewRequestIDs(logger).WithClock(systemClock).Middleware(handler)
+
+	serverConfig := cfg.Server.WithDefaultTimeouts()
+
+	// httputil.ServerConfig has no MaxHeaderBytes, so the server is built here.
+	server := &http.Server{ //nolint:exhaustruct // remaining fields keep net/http defaults
+		Addr:           fmt.Sprintf(":%d", defaultServerPort),
+		Handler:        handler,
+		ReadTimeout:    serverConfig.ReadTimeout,
+		WriteTimeout:   serverConfig.WriteTimeout,
+		IdleTimeout:    serverConfig.IdleTimeout,
+		MaxHeaderBytes: cfg.Server.Headers.MaxBytes,
+	}
+	// Readiness fails as soon as Shutdown begins, so load balancers drain.
+	server.RegisterOnShutdown(healthChecks.SetShuttingDown)
+
+	listener, err := net.Listen("tcp", server.Addr)
 	if err != nil {
-		logger.Error("❌ Failed to create HTTP server", "error", err)
+		logger.Error("❌ Failed to listen", "addr", server.Addr, "error", err)
 		os.Exit(exitCodeFailure)
 	}
 
 	logger.Info("🚀 Starting HTTP server", "port", defaultServerPort)
 
-	errChan := server.Start()
+	// Fixtures still being written need the drained requests, not the database,
+	// but they must land before the process exits.
+	release := func() error {
+		if recorder != nil {
+			recorder.Wait()
+		}
+
+		return db.Close()
+	}
+
+	timeouts := newShutdownTimeouts(serverConfig)
+
+	err = runServer(context.Background(), server, listener, logger, timeouts, release)
+	if errors.Is(err, errShutdownTimeout) {
+		logger.Error("❌ Shutdown did not finish in time, forcing exit", "limit", timeouts.Max)
+		os.Exit(exitCodeFailure)
+	}
+
+	if err != nil {
+		logger.Error("❌ Server failed", "error", err)
+		os.Exit(exitCodeFailure)
+	}
+
+	logger.Info("✅ Server shutdown complete")
+	os.Exit(exitCodeSuccess)
+}
+
+// shutdownTimeouts bound the phases of a graceful shutdown.
+type shutdownTimeouts struct {
+	// Drain is how long in-flight requests get to finish.
+	Drain time.Duration
+	// DatabaseClose is how long releasing the database may take.
+	DatabaseClose time.Duration
+	// Max caps the whole shutdown, however the phases fare.
+	Max time.Duration
+}
+
+// newShutdownTimeouts drains for server.graceful_shutdown_timeout, or its
+// default when unset. The cap grows with a long drain, so it never ends a
+// shutdown that is still within its phases.
+func newShutdownTimeouts(server config.ServerConfig) shutdownTimeouts {
+	drain := server.WithDefaultTimeouts().GracefulShutdownTimeout
+
+	return shutdownTimeouts{
+		Drain:         drain,
+		DatabaseClose: defaultDatabaseCloseTimeout,
+		Max:           max(defaultMaxShutdownWait, drain+defaultDatabaseCloseTimeout),
+	}
+}
+
+// errShutdownTimeout reports a shutdown that exceeded shutdownTimeouts.Max.
+var errShutdownTimeout = errors.New("shutdown exceeded its time limit")
+
+// runServer serves on listener until ctx is done or SIGINT or SIGTERM
+// arrives, then shuts down in order: stop accepting connections and drain
+// in-flight requests, then call release to close the database. It returns
+// errShutdownTimeout when the shutdown takes longer than timeouts.Max.
+func runServer(
+	ctx context.Context, server *http.Server, listener net.Listener, logger *log.Logger,
+	timeouts shutdownTimeouts, release func() error,
+) error {
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	errChan := make(chan error, 1)
+
+	go func() {
+		err := server.Serve(listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errChan <- err
+		}
+	}()
 
 	select {
-	case <-quit:
+	case <-ctx.Done():
 		logger.Info("🛑 Shutting down server...")
 	case err := <-errChan:
-		logger.Error("❌ Server failed", "error", err)
-		os.Exit(exitCodeFailure)
+		_ = release()
+
+		return fmt.Errorf("serve: %w", err)
+	}
+
+	// A second signal now terminates the process the default way.
+	stop()
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- shutdown(server, timeouts, release)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(timeouts.Max):
+		return errShutdownTimeout
+	}
+}
+
+// shutdown drains server and then releases its resources, which are
+// released even when draining runs out of time.
+func shutdown(server *http.Server, timeouts shutdownTimeouts, release func() error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeouts.Drain)
+	defer cancel()
+
+	drainErr := server.Shutdown(ctx)
+	if drainErr != nil {
+		drainErr = fmt.Errorf("drain requests: %w", drainErr)
+	}
+
+	released := make(chan error, 1)
+
+	go func() {
+		released <- release()
+	}()
+
+	var releaseErr error
+
+	select {
+	case err := <-released:
+		if err != nil {
+			releaseErr = fmt.Errorf("close database: %w", err)
+		}
+	case <-time.After(timeouts.DatabaseClose):
+		releaseErr = fmt.Errorf("close database: timed out after %s", timeouts.DatabaseClose)
+	}
+
+	return errors.Join(drainErr, releaseErr)
+}
+
+// securityHeaderOptions maps the security settings to response headers.
+// The CSP report URI, when set, is appended to the policy.
+func securityHeaderOptions(security config.SecurityConfig) middleware.SecurityHeaderOptions {
+	csp := ""
+	if security.EnableCSP {
+		csp = security.CSPPolicy
+		if security.CSPReportURI != "" {
+			csp += "; report-uri " + security.CSPReportURI
+		}
+	}
+
+	return middleware.SecurityHeaderOptions{
+		HSTS:                  security.EnableHSTS,
+		ContentSecurityPolicy: csp,
+		ReferrerPolicy:        security.ReferrerPolicy,
+	}
+}
+
+// newRateLimiter builds the rate limiter from the security settings. Route
+// groups that validation let through always parse, so a skipped one is a bug
+// worth a warning rather than a startup failure.
+func newRateLimiter(security config.SecurityConfig, logger *log.Logger) *middleware.RateLimiter {
+	rules := make([]middleware.RateLimitRule, 0, len(security.RateLimitRoutes))
+
+	for _, route := range slices.Sorted(maps.Keys(security.RateLimitRoutes)) {
+		rule, ok := middleware.ParseRateLimitRule(route, security.RateLimitRoutes[route])
+		if !ok {
+			logger.Warn("⚠️ Ignoring invalid rate limit route", "route", route)
+
+			continue
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return middleware.NewRateLimiter(middleware.RateLimitOptions{ //nolint:exhaustruct // default idle timeout and clock
+		Requests:       security.RateLimitRequests,
+		Window:         security.RateLimitWindow,
+		Rules:          rules,
+		TrustedProxies: security.TrustedProxies,
+	})
+}
+
+// newRequestTimeouts builds the timeout middleware from the server settings,
+// skipping invalid route groups as newRateLimiter does.
+func newRequestTimeouts(server config.ServerConfig, logger *log.Logger) *middleware.RequestTimeouts {
+	rules := make([]middleware.TimeoutRule, 0, len(server.RequestTimeoutRoutes))
+
+	for _, route := range slices.Sorted(maps.Keys(server.RequestTimeoutRoutes)) {
+		rule, ok := middleware.ParseTimeoutRule(route, server.RequestTimeoutRoutes[route])
+		if !ok {
+			logger.Warn("⚠️ Ignoring invalid request timeout route", "route", route)
+
+			continue
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return middleware.NewRequestTimeouts(middleware.TimeoutOptions{
+		Timeout: server.RequestTimeout,
+		Rules:   rules,
+	})
+}
+
+// newConcurrencyLimiter builds the per-group concurrency limits from the
+// server settings. Group names are matched case-insensitively, as viper
+// lowercases the keys it reads from files; invalid routes and policies are
+// skipped as newRateLimiter skips invalid routes, so those groups queue.
+func newConcurrencyLimiter(concurrency config.ConcurrencyConfig, logger *log.Logger) *middleware.ConcurrencyLimiter {
+	policies := make(map[string]middleware.LimitPolicy, len(concurrency.Policies))
+
+	for _, name := range slices.Sorted(maps.Keys(concurrency.Policies)) {
+		policy, ok := middleware.ParseLimitPolicy(strings.ToLower(concurrency.Policies[name]))
+		if !ok {
+			logger.Warn("⚠️ Ignoring invalid concurrency policy",
+				"group", name, "policy", concurrency.Policies[name])
+
+			continue
+		}
+
+		policies[strings.ToLower(name)] = policy
+	}
+
+	groups := make(map[string]middleware.LimitGroupConfig, len(concurrency.Groups))
+	for name, limit := range concurrency.Groups {
+		groups[strings.ToLower(name)] = middleware.LimitGroupConfig{
+			Limit:   limit,
+			Policy:  policies[strings.ToLower(name)],
+			MaxWait: concurrency.MaxWait,
+		}
+	}
+
+	rules := make([]middleware.ConcurrencyRule, 0, len(concurrency.Routes))
+
+	for _, route := range slices.Sorted(maps.Keys(concurrency.Routes)) {
+		rule, ok := middleware.ParseConcurrencyRule(route, strings.ToLower(concurrency.Routes[route]))
+		if !ok {
+			logger.Warn("⚠️ Ignoring invalid concurrency route", "route", route)
+
+			continue
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return middleware.NewConcurrencyLimiter(groups).WithRules(rules)
+}
+
+// newLoadShedder sheds requests over the configured soft limits. The
+// health endpoints are always exempt, so probes see the server's real state.
+func newLoadShedder(shedding config.LoadSheddingConfig, mux *http.ServeMux) *middleware.LoadShedder {
+	exempt := append([]string{"/health"}, shedding.ExemptPrefixes...)
+
+	return middleware.NewLoadShedder(middleware.LoadSheddingOptions{
+		MaxInFlight:    shedding.MaxInFlight,
+		MaxGoroutines:  shedding.MaxGoroutines,
+		MaxHeapBytes:   shedding.MaxHeapBytes,
+		ExemptPrefixes: exempt,
+		RetryAfter:     shedding.RetryAfter,
+		SampleInterval: shedding.SampleInterval,
+	}, mux, nil)
+}
+
+// newIdempotencyStore returns the store of Idempotency-Key records. The SQL
+// store gets a janitor that deletes expired records once per TTL; the
+// in-memory store sweeps them itself.
+func newIdempotencyStore(
+	idempotency config.IdempotencyConfig, db *sql.DB, clock clock.Clock, logger *log.Logger,
+) repositories.IdempotencyStore {
+	if idempotency.Store != "sql" {
+		return repositories.NewInMemoryIdempotencyStore()
+	}
+
+	store := persistence.NewSQLIdempotencyStore(db)
+
+	// The janitor lives as long as the process.
+	go func() {
+		ticker := time.NewTicker(idempotency.TTL)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			_, err := store.DeleteExpired(context.Background(), clock.Now())
+			if err != nil {
+				logger.Warn("⚠️ Failed to delete expired idempotency keys", "error", err)
+			}
+		}
+	}()
+
+	return store
+}
+
+// idempotencyOptions maps the idempotency settings to handler options.
+// Keys are scoped to the token subject, so callers cannot replay each
+// other's responses.
+func idempotencyOptions(idempotency config.IdempotencyConfig) handlers.IdempotencyOptions {
+	return handlers.IdempotencyOptions{ //nolint:exhaustruct // default poll interval and Retry-After
+		TTL:         idempotency.TTL,
+		Wait:        idempotency.InFlight == "wait",
+		WaitTimeout: idempotency.WaitTimeout,
+		Scope: func(r *http.Request) string {
+			claims, _ := middleware.CurrentUser(r.Context())
+
+			return claims.Subject
+		},
+	}
+}
+
+// newAuthenticator verifies access tokens as the JWT settings describe:
+// HS* tokens with the shared secret, RS256 tokens with the JWKS keys.
+func newAuthenticator(jwt config.JWTConfig) *middleware.Authenticator {
+	options := middleware.AuthOptions{ //nolint:exhaustruct // the key source depends on the algorithm
+		Algorithm: jwt.Algorithm,
+		Issuer:    jwt.Issuer,
+		Audience:  jwt.Audience,
+		ClockSkew: jwt.ClockSkew,
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), defaultGracefulTimeout)
+	if jwt.Algorithm == "RS256" {
+		options.JWKS = middleware.NewJWKS(middleware.JWKSOptions{ //nolint:exhaustruct // default client and clock
+			URL:      jwt.JWKSURL,
+			CacheTTL: jwt.JWKSCacheTTL,
+		})
+	} else {
+		secret := []byte(jwt.SecretKey)
+		options.HMACKey = func() []byte { return secret }
+	}
+
+	return middleware.NewAuthenticator(options)
+}
+
+// probeReadiness asks the server at baseURL whether it is ready, for
+// container health checks that cannot run curl.
+func probeReadiness(ctx context.Context, baseURL string) error {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
 	defer cancel()
 
-	err = server.Shutdown(ctx)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+healthReadyPath, nil)
 	if err != nil {
-		logger.Error("❌ Server forced to shutdown", "error", err)
-		os.Exit(exitCodeFailure)
+		return fmt.Errorf("build readiness request: %w", err)
 	}
 
-	logger.Info("✅ Server shutdown complete")
-	os.Exit(exitCodeSuccess)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("probe readiness: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("readiness probe answered %s", resp.Status)
+	}
+
+	return nil
+}
+
+// loadConfiguration loads from configPath, or from the environment alone in
+// env-only mode, where a config file is a startup error.
+func loadConfiguration(configPath string, envOnly bool) (*config.Config, error) {
+	if !envOnly {
+		return config.LoadConfig(configPath)
+	}
+
+	if configPath != "" {
+		return nil, errors.New("-config cannot be combined with -env-only")
+	}
+
+	return config.LoadConfigFromEnv()
+}
+
+// checkConfigFile validates the config file at path with
+// config.ValidateBytes, writes its warnings and violations to w and reports
+// whether it is valid. A .json file is read as JSON, anything else as YAML.
+func checkConfigFile(path string, w io.Writer) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("read config file: %w", err)
+	}
+
+	format := "yaml"
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		format = "json"
+	}
+
+	result, err := config.ValidateBytes(data, format)
+	if err != nil {
+		return false, fmt.Errorf("check %s: %w", path, err)
+	}
+
+	for _, warning := range result.Warnings {
+		fmt.Fprintf(w, "%s: warning: %s\n", path, warning)
+	}
+
+	for _, violation := range result.Violations {
+		fmt.Fprintf(w, "%s: %s\n", path, violation.Message)
+	}
+
+	if result.Valid {
+		fmt.Fprintf(w, "%s: valid\n", path)
+	}
+
+	return result.Valid, nil
+}
+
+// runMigrations applies the pending embedded migrations to db, logging each
+// one as it is applied.
+func runMigrations(ctx context.Context, db *sql.DB, logger *log.Logger) error {
+	applied, err := migrations.Up(ctx, db)
+	for _, migration := range applied {
+		logger.Info("📦 Applied migration", "name", migration.Name, "duration", migration.Duration)
+	}
+
+	if err != nil {
+		return fmt.Errorf("apply migrations: %w", err)
+	}
+
+	if len(applied) == 0 {
+		logger.Info("✅ Database schema is up to date")
+	}
+
+	return nil
+}
+
+// stampEnvironment marks db as belonging to env on first boot, so
+// destructive operations can later tell production data apart. A database
+// that was stamped before keeps its marker; a mismatch is logged.
+func stampEnvironment(ctx context.Context, db *sql.DB, env string, logger *log.Logger) error {
+	stamped, err := infrastructure.NewEnvironmentMarker(infrastructure.NewDatabaseFromDB(db)).Stamp(ctx, env)
+	if err != nil {
+		return err
+	}
+
+	if stamped != env {
+		logger.Warn("⚠️ Database belongs to another environment", "configured", env, "stamped", stamped)
+	}
+
+	return nil
 }
@@ -0,0 +1,481 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"charm.land/log/v2"
+	"github.com/LarsArtmann/template-arch-lint/internal/application/handlers"
+	"github.com/LarsArtmann/template-arch-lint/internal/application/routes"
+	"github.com/LarsArtmann/template-arch-lint/internal/config"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/repositories"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/services"
+	"github.com/LarsArtmann/template-arch-lint/internal/infrastructure"
+	"github.com/LarsArtmann/template-arch-lint/internal/infrastructure/health"
+	"github.com/LarsArtmann/template-arch-lint/internal/testhelpers/base"
+)
+
+// shutdownRecorder notes the order in which shutdown events happen.
+type shutdownRecorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (s *shutdownRecorder) add(event string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.events = append(s.events, event)
+}
+
+func (s *shutdownRecorder) list() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.events...)
+}
+
+// unsetShutdownTimeouts are the shutdown timeouts of a config that sets none.
+var unsetShutdownTimeouts = newShutdownTimeouts(config.ServerConfig{}) //nolint:exhaustruct // nothing set
+
+// startServer runs runServer on a loopback listener with handler and
+// returns the server's address and its eventual result.
+func startServer(
+	ctx context.Context, t *testing.T, handler http.Handler, timeouts shutdownTimeouts, release func() error,
+) (string, <-chan error) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+
+	server := &http.Server{Handler: handler} //nolint:exhaustruct // test server
+	result := make(chan error, 1)
+
+	go func() {
+		result <- runServer(ctx, server, listener, log.New(&bytes.Buffer{}), timeouts, release)
+	}()
+
+	return "http://" + listener.Addr().String(), result
+}
+
+func TestRunServerDrainsInFlightRequestsBeforeClosingDatabase(t *testing.T) {
+	var events shutdownRecorder
+
+	started := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		events.add("request finished")
+		_, _ = io.WriteString(w, "done")
+	})
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	addr, result := startServer(ctx, t, handler, unsetShutdownTimeouts, func() error {
+		events.add("database closed")
+
+		return nil
+	})
+
+	type response struct {
+		body string
+		err  error
+	}
+
+	responses := make(chan response, 1)
+
+	go func() {
+		resp, err := http.Get(addr + "/slow") //nolint:noctx // test request
+		if err != nil {
+			responses <- response{"", err}
+
+			return
+		}
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		responses <- response{string(body), err}
+	}()
+
+	<-started
+	cancel()
+
+	got := <-responses
+	if got.err != nil || got.body != "done" {
+		t.Fatalf("slow request = %q, %v; want it to complete during shutdown", got.body, got.err)
+	}
+
+	err := <-result
+	if err != nil {
+		t.Fatalf("runServer() = %v, want nil", err)
+	}
+
+	want := []string{"request finished", "database closed"}
+	if got := events.list(); len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("shutdown events = %v, want %v", got, want)
+	}
+
+	_, err = http.Get(addr + "/slow") //nolint:noctx,bodyclose // the dial must fail
+	if err == nil {
+		t.Error("server still accepts connections after shutdown")
+	}
+}
+
+func TestRunServerForcesExitPastMaxShutdownWait(t *testing.T) {
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	defer close(unblock)
+
+	handler := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		close(started)
+		<-unblock
+	})
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	timeouts := shutdownTimeouts{Drain: time.Minute, DatabaseClose: time.Minute, Max: 100 * time.Millisecond}
+	addr, result := startServer(ctx, t, handler, timeouts, func() error { return nil })
+
+	go func() {
+		resp, err := http.Get(addr + "/stuck") //nolint:noctx // test request
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+	}()
+
+	<-started
+	cancel()
+
+	err := <-result
+	if !errors.Is(err, errShutdownTimeout) {
+		t.Fatalf("runServer() = %v, want errShutdownTimeout", err)
+	}
+}
+
+func TestRunServerFailsReadinessWhenShutdownBegins(t *testing.T) {
+	registry := health.NewRegistry(time.Second)
+
+	ctx, cancel := context.WithCancel(t.Context())
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+
+	server := &http.Server{Handler: registry.ReadyHandler()} //nolint:exhaustruct // test server
+	server.RegisterOnShutdown(registry.SetShuttingDown)
+
+	result := make(chan error, 1)
+
+	go func() {
+		result <- runServer(ctx, server, listener, log.New(&bytes.Buffer{}), unsetShutdownTimeouts,
+			func() error { return nil })
+	}()
+
+	err = probeReadiness(t.Context(), "http://"+listener.Addr().String())
+	if err != nil {
+		t.Fatalf("probeReadiness() before shutdown = %v, want nil", err)
+	}
+
+	cancel()
+
+	err = <-result
+	if err != nil {
+		t.Fatalf("runServer() = %v, want nil", err)
+	}
+
+	// net/http runs shutdown hooks in their own goroutines.
+	deadline := time.Now().Add(time.Second)
+	for ready, _ := registry.Ready(t.Context()); ready; ready, _ = registry.Ready(t.Context()) {
+		if time.Now().After(deadline) {
+			t.Fatal("registry still ready after shutdown began")
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	err = probeReadiness(t.Context(), "http://"+listener.Addr().String())
+	if err == nil {
+		t.Error("probeReadiness() succeeded against a stopped server")
+	}
+}
+
+func TestCheckConfigFile(t *testing.T) {
+	scaffold, err := config.Scaffold("production")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "production.yaml")
+	invalid := filepath.Join(dir, "invalid.json")
+
+	if err := os.WriteFile(valid, scaffold, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(invalid, []byte(`{"logging": {"format": "xml"}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+
+	ok, err := checkConfigFile(valid, &out)
+	if err != nil || !ok {
+		t.Fatalf("checkConfigFile(scaffold) = %v, %v, want valid; output:\n%s", ok, err, &out)
+	}
+
+	out.Reset()
+
+	ok, err = checkConfigFile(invalid, &out)
+	if err != nil || ok {
+		t.Fatalf("checkConfigFile(invalid) = %v, %v, want invalid", ok, err)
+	}
+
+	if !strings.Contains(out.String(), "logging.format must be one of: json text") {
+		t.Errorf("output = %q, want the format violation", out.String())
+	}
+
+	_, err = checkConfigFile(filepath.Join(dir, "missing.yaml"), &out)
+	if err == nil {
+		t.Error("checkConfigFile(missing) succeeded")
+	}
+}
+
+func TestStampEnvironmentKeepsFirstMarker(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "app.db")
+	base.RequireSafeTestDSN(t, dsn)
+
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = db.Close() })
+
+	var logs bytes.Buffer
+
+	logger := log.New(&logs)
+
+	err = stampEnvironment(t.Context(), db, infrastructure.EnvironmentProduction, logger)
+	if err != nil {
+		t.Fatalf("stampEnvironment(production) error = %v", err)
+	}
+
+	err = stampEnvironment(t.Context(), db, infrastructure.EnvironmentDevelopment, logger)
+	if err != nil {
+		t.Fatalf("stampEnvironment(development) error = %v", err)
+	}
+
+	env, err := infrastructure.NewEnvironmentMarker(infrastructure.NewDatabaseFromDB(db)).Environment(t.Context())
+	if err != nil || env != infrastructure.EnvironmentProduction {
+		t.Errorf("Environment() = %q, %v, want the first stamp kept", env, err)
+	}
+
+	if !strings.Contains(logs.String(), "another environment") {
+		t.Errorf("logs = %q, want a warning about the mismatch", logs.String())
+	}
+}
+
+// stalledRepository blocks FindPage until its context ends and reports the
+// context's error, as a SQL driver cancelling the query does.
+type stalledRepository struct {
+	repositories.UserRepository
+
+	cancelled chan error
+}
+
+func (r *stalledRepository) FindPage(ctx context.Context, _ repositories.UserPageQuery) (repositories.UserPage, error) {
+	<-ctx.Done()
+	r.cancelled <- ctx.Err()
+
+	return repositories.UserPage{}, ctx.Err() //nolint:exhaustruct // no page
+}
+
+func TestRequestTimeoutCancelsRepositoryQuery(t *testing.T) {
+	repo := &stalledRepository{
+		UserRepository: repositories.NewInMemoryUserRepository(),
+		cancelled:      make(chan error, 1),
+	}
+
+	mux := http.NewServeMux()
+	handlers.NewUserHandler(services.NewUserService(repo)).RegisterRoutes(mux)
+
+	handler := newRequestTimeouts(config.ServerConfig{ //nolint:exhaustruct // only timeouts matter
+		RequestTimeout:       time.Hour,
+		RequestTimeoutRoutes: map[string]time.Duration{"GET " + routes.UsersPath: 20 * time.Millisecond},
+	}, log.New(io.Discard)).Middleware(mux)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, routes.UsersPath, nil))
+
+	if w.Code != http.StatusGatewayTimeout {
+		t.Fatalf("status = %d, want 504: %s", w.Code, w.Body.String())
+	}
+
+	select {
+	case err := <-repo.cancelled:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("repository saw %v, want context.DeadlineExceeded", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("repository query was not cancelled")
+	}
+}
+
+func TestConcurrencyLimiterFromConfig(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	export := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusOK)
+	})
+
+	// Keys as viper reads them from a file, lowercased.
+	handler := newConcurrencyLimiter(config.ConcurrencyConfig{
+		Enabled: true,
+		MaxWait: 0,
+		Groups:  map[string]int{"exports": 1},
+		Routes:  map[string]string{"get /api/v1/users/export": "Exports", "users": "exports"},
+	}, log.New(io.Discard)).Middleware(export)
+
+	first := make(chan int, 1)
+
+	go func() {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, routes.UsersExportPath, nil))
+		first <- w.Code
+	}()
+
+	<-started
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, routes.UsersExportPath, nil))
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("second export status = %d, want 503", w.Code)
+	}
+
+	close(release)
+
+	if code := <-first; code != http.StatusOK {
+		t.Errorf("first export status = %d, want 200", code)
+	}
+}
+
+func TestConcurrencyLimiterAppliesGroupPolicy(t *testing.T) {
+	limiter := newConcurrencyLimiter(config.ConcurrencyConfig{
+		Enabled:  true,
+		MaxWait:  time.Minute,
+		Groups:   map[string]int{"exports": 1, "imports": 1},
+		Policies: map[string]string{"Exports": "reject", "imports": "drop"},
+		Routes:   map[string]string{"GET /api/v1/users/export": "exports"},
+	}, log.New(io.Discard))
+
+	started, release := make(chan struct{}), make(chan struct{})
+	handler := limiter.Middleware(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	go handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, routes.UsersExportPath, nil))
+
+	<-started
+
+	rejected := make(chan int, 1)
+
+	go func() {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, routes.UsersExportPath, nil))
+		rejected <- w.Code
+	}()
+
+	select {
+	case code := <-rejected:
+		if code != http.StatusServiceUnavailable {
+			t.Errorf("second export status = %d, want 503", code)
+		}
+	case <-time.After(time.Second):
+		t.Error("second export queued, want the reject policy to answer at once")
+	}
+
+	close(release)
+
+	stats := limiter.Stats()
+	if stats["imports"].Limit != 1 {
+		t.Errorf("stats = %+v, want the imports group kept despite its invalid policy", stats)
+	}
+}
+
+func TestImportOutlastsServerTimeouts(t *testing.T) {
+	const records = 5
+
+	mux := http.NewServeMux()
+	handlers.NewUserHandler(services.NewUserService(repositories.NewInMemoryUserRepository())).RegisterRoutes(mux)
+
+	server := httptest.NewUnstartedServer(newRequestTimeouts(config.ServerConfig{ //nolint:exhaustruct // timeouts only
+		RequestTimeout: time.Minute,
+	}, log.New(io.Discard)).Middleware(mux))
+	server.Config.ReadTimeout = 100 * time.Millisecond
+	server.Config.WriteTimeout = 100 * time.Millisecond
+	server.Start()
+	t.Cleanup(server.Close)
+
+	// The body trickles in over three times the server's timeouts.
+	body, upload := io.Pipe()
+
+	go func() {
+		for i := range records {
+			time.Sleep(60 * time.Millisecond)
+			_, _ = fmt.Fprintf(upload, "{\"email\":\"slow%d@example.com\",\"name\":\"Slow User\"}\n", i)
+		}
+
+		_ = upload.Close()
+	}()
+
+	resp, err := server.Client().Post(server.URL+routes.UsersImportPath, handlers.ContentTypeNDJSON, body)
+	if err != nil {
+		t.Fatalf("import request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	summary, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read summary: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(summary), fmt.Sprintf(`"succeeded":%d`, records)) {
+		t.Errorf("import = %d %s, want all %d records imported", resp.StatusCode, summary, records)
+	}
+}
+
+func TestShutdownTimeoutsFollowConfig(t *testing.T) {
+	if unsetShutdownTimeouts.Drain <= 0 || unsetShutdownTimeouts.Max < unsetShutdownTimeouts.Drain {
+		t.Errorf("timeouts of an empty config = %+v, want the defaults", unsetShutdownTimeouts)
+	}
+
+	timeouts := newShutdownTimeouts(config.ServerConfig{ //nolint:exhaustruct // only the drain matters
+		GracefulShutdownTimeout: 2 * time.Minute,
+	})
+	if timeouts.Drain != 2*time.Minute || timeouts.Max < timeouts.Drain+timeouts.DatabaseClose {
+		t.Errorf("timeouts = %+v, want a 2m drain within the cap", timeouts)
+	}
+}
@@ -1,12 +1,52 @@
 package handlers
 
 import (
+	"cmp"
+	"crypto/rand"
 	"encoding/json/v2"
 	"net/http"
+
+	"charm.land/log/v2"
+	pkgerrors "github.com/LarsArtmann/template-arch-lint/pkg/errors"
 )
 
+// CorrelationIDHeader echoes the correlation ID of a 5xx response.
+const CorrelationIDHeader = "X-Correlation-ID"
+
+// requestIDHeader is set on the response by the request ID middleware
+// before a handler runs; error responses repeat it in the body.
+const requestIDHeader = "X-Request-ID"
+
 func sendErrorResponse(w http.ResponseWriter, httpStatus int, message string) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(httpStatus)
 	_ = json.MarshalWrite(w, map[string]string{"error": message})
 }
+
+// RespondError writes err as an APIError with the status pkgerrors.ToAPIError
+// picks. A server-side failure is logged in full under a correlation ID,
+// which the response carries in place of the error's text. The correlation
+// ID is the request ID when there is one, and a new random ID otherwise.
+func RespondError(w http.ResponseWriter, r *http.Request, err error) {
+	status, apiErr := pkgerrors.ToAPIError(err)
+	apiErr.RequestID = w.Header().Get(requestIDHeader)
+
+	if status >= http.StatusInternalServerError {
+		apiErr.CorrelationID = cmp.Or(apiErr.RequestID, rand.Text())
+		w.Header().Set(CorrelationIDHeader, apiErr.CorrelationID)
+		log.FromContext(r.Context()).Error("Request failed", "method", r.Method, "path", r.URL.Path,
+			"correlation_id", apiErr.CorrelationID, "error", err)
+	}
+
+	writeJSON(w, status, apiErr)
+}
+
+// errorResponse writes a client error that has no domain error behind it,
+// such as an undecodable body.
+func errorResponse(w http.ResponseWriter, status int, code pkgerrors.APICode, message string) {
+	writeJSON(w, status, pkgerrors.APIError{ //nolint:exhaustruct // client errors name no field
+		Code:      code,
+		Message:   message,
+		RequestID: w.Header().Get(requestIDHeader),
+	})
+}
@@ -0,0 +1,16 @@
+package handlers
+
+import (
+	"context"
+	"io"
+)
+
+// StreamNDJSON exposes streamNDJSON to the external test package.
+func StreamNDJSON[T any](
+	ctx context.Context,
+	r io.Reader,
+	limits StreamLimits,
+	sink func(context.Context, []T) []error,
+) (StreamSummary, error) {
+	return streamNDJSON(ctx, r, limits, sink)
+}
@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"charm.land/log/v2"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/repositories"
+	domainerrors "github.com/LarsArtmann/template-arch-lint/pkg/errors"
+)
+
+// IdempotencyKeyHeader carries the key that makes a POST safe to retry.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader is "true" on a response replayed from the first
+// request made with its Idempotency-Key.
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+const (
+	maxIdempotencyKeyLength        = 255
+	defaultIdempotencyPollInterval = 50 * time.Millisecond
+	defaultIdempotencyRetryAfter   = time.Second
+)
+
+// IdempotencyOptions configure how requests with an Idempotency-Key header
+// are deduplicated.
+type IdempotencyOptions struct {
+	// TTL is how long a key and the response made under it are kept.
+	TTL time.Duration
+	// Wait makes a request whose key is held by a request still running
+	// wait up to WaitTimeout for that response. Without it, or when the
+	// wait times out, the request is answered 409 with Retry-After.
+	Wait        bool
+	WaitTimeout time.Duration
+	// PollInterval is how often a waiting request checks the store; zero
+	// means 50ms.
+	PollInterval time.Duration
+	// RetryAfter is sent with a 409; zero means one second.
+	RetryAfter time.Duration
+	// Scope returns the caller a key belongs to, such as the token subject,
+	// so callers cannot replay each other's responses. Nil makes keys global.
+	Scope func(r *http.Request) string
+}
+
+// idempotency deduplicates the requests of one route by Idempotency-Key.
+type idempotency struct {
+	store   repositories.IdempotencyStore
+	options IdempotencyOptions
+}
+
+// WithIdempotency makes POST /api/v1/users honor Idempotency-Key: the
+// response to the first request with a key is kept in store and replayed
+// to every later request with the key and the same body, and a later
+// request with another body is answered 422. Server errors are not kept,
+// so a retry after one runs again.
+func (h *UserHandler) WithIdempotency(store repositories.IdempotencyStore, options IdempotencyOptions) *UserHandler {
+	if options.PollInterval <= 0 {
+		options.PollInterval = defaultIdempotencyPollInterval
+	}
+
+	if options.RetryAfter <= 0 {
+		options.RetryAfter = defaultIdempotencyRetryAfter
+	}
+
+	h.idempotency = &idempotency{store: store, options: options}
+
+	return h
+}
+
+// idempotent wraps next in the Idempotency-Key handling, when it is on.
+// Requests without the header pass straight through.
+func (h *UserHandler) idempotent(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if h.idempotency == nil || key == "" {
+			next(w, r)
+
+			return
+		}
+
+		if !validIdempotencyKey(key) {
+			RespondError(w, r, domainerrors.NewValidationError(IdempotencyKeyHeader,
+				"Idempotency-Key must be 1 to 255 printable ASCII characters"))
+
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			if _, tooLarge := errors.AsType[*http.MaxBytesError](err); tooLarge {
+				errorResponse(w, http.StatusRequestEntityTooLarge, domainerrors.APICodeRequestTooLarge,
+					"Request body is too large")
+
+				return
+			}
+
+			errorResponse(w, http.StatusBadRequest, domainerrors.APICodeInvalidRequestBody,
+				"Failed to read request body")
+
+			return
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		hash := sha256.Sum256(body)
+		pending := repositories.IdempotencyRecord{ //nolint:exhaustruct // no response yet
+			Key:         h.idempotency.scopedKey(r, key),
+			RequestHash: hex.EncodeToString(hash[:]),
+			ExpiresAt:   h.clock.Now().Add(h.idempotency.options.TTL),
+		}
+
+		held, reserved, err := h.idempotency.reserve(r.Context(), pending, h.clock.Now)
+		switch {
+		case err != nil:
+			RespondError(w, r, err)
+		case reserved:
+			h.idempotency.run(w, r, pending, next)
+		case held.RequestHash != pending.RequestHash:
+			errorResponse(w, http.StatusUnprocessableEntity, domainerrors.APICodeIdempotencyKeyReused,
+				"Idempotency-Key was already used with a different request body")
+		case held.Completed():
+			replay(w, held)
+		default:
+			retryAfter := max(1, int(math.Ceil(h.idempotency.options.RetryAfter.Seconds())))
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			errorResponse(w, http.StatusConflict, domainerrors.APICodeIdempotencyKeyInUse,
+				"A request with this Idempotency-Key is still in progress, retry later")
+		}
+	}
+}
+
+func validIdempotencyKey(key string) bool {
+	if len(key) > maxIdempotencyKeyLength {
+		return false
+	}
+
+	for i := range len(key) {
+		if key[i] < ' ' || key[i] > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// scopedKey is the store key of key for the caller of r. The header value
+// holds no control characters, so the newline cannot be forged.
+func (i *idempotency) scopedKey(r *http.Request, key string) string {
+	if i.options.Scope == nil {
+		return key
+	}
+
+	return i.options.Scope(r) + "\n" + key
+}
+
+// reserve reserves pending, or returns the record that holds its key. A
+// record still running for the same body is polled until it completes,
+// is released or the wait runs out, when waiting is on.
+func (i *idempotency) reserve(
+	ctx context.Context, pending repositories.IdempotencyRecord, now func() time.Time,
+) (repositories.IdempotencyRecord, bool, error) {
+	held, reserved, err := i.store.Reserve(ctx, pending, now())
+	if err != nil || reserved || !i.options.Wait || held.Completed() || held.RequestHash != pending.RequestHash {
+		return held, reserved, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, i.options.WaitTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(i.options.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return held, false, nil
+		case <-ticker.C:
+		}
+
+		held, reserved, err = i.store.Reserve(ctx, pending, now())
+		if err != nil || reserved || held.Completed() || held.RequestHash != pending.RequestHash {
+			return held, reserved, err
+		}
+	}
+}
+
+// run serves the request that reserved pending and keeps its response. A
+// server error or a panic releases the key instead.
+func (i *idempotency) run(
+	w http.ResponseWriter, r *http.Request, pending repositories.IdempotencyRecord, next http.HandlerFunc,
+) {
+	// The outcome is kept even when the client is gone, since that is
+	// exactly when it retries.
+	ctx := context.WithoutCancel(r.Context())
+	logger := log.FromContext(r.Context())
+	capture := &capturingWriter{ResponseWriter: w, status: http.StatusOK, body: bytes.Buffer{}}
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			_ = i.store.Release(ctx, pending.Key)
+
+			panic(recovered)
+		}
+	}()
+
+	next(capture, r)
+
+	if capture.status >= http.StatusInternalServerError {
+		err := i.store.Release(ctx, pending.Key)
+		if err != nil {
+			logger.Error("Failed to release idempotency key", "error", err)
+		}
+
+		return
+	}
+
+	pending.Status = capture.status
+	pending.ContentType = capture.Header().Get("Content-Type")
+	pending.Location = capture.Header().Get("Location")
+	pending.Body = capture.body.Bytes()
+
+	err := i.store.Complete(ctx, pending)
+	if err != nil {
+		logger.Error("Failed to store idempotent response", "error", err)
+	}
+}
+
+// replay writes the response kept in record.
+func replay(w http.ResponseWriter, record repositories.IdempotencyRecord) {
+	if record.ContentType != "" {
+		w.Header().Set("Content-Type", record.ContentType)
+	}
+
+	if record.Location != "" {
+		w.Header().Set("Location", record.Location)
+	}
+
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(record.Status)
+	_, _ = w.Write(record.Body)
+}
+
+// capturingWriter passes a response through while keeping its status and
+// body.
+type capturingWriter struct {
+	http.ResponseWriter
+
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (c *capturingWriter) WriteHeader(status int) {
+	if !c.wroteHeader {
+		c.status = status
+		c.wroteHeader = true
+	}
+
+	c.ResponseWriter.WriteHeader(status)
+}
+
+func (c *capturingWriter) Write(data []byte) (int, error) {
+	c.wroteHeader = true
+	c.body.Write(data)
+
+	return c.ResponseWriter.Write(data)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (c *capturingWriter) Unwrap() http.ResponseWriter {
+	return c.ResponseWriter
+}
@@ -0,0 +1,210 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json/v2"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/application/handlers"
+	"github.com/LarsArtmann/template-arch-lint/internal/application/routes"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/clock"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/entities"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/repositories"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/services"
+	pkgerrors "github.com/LarsArtmann/template-arch-lint/pkg/errors"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// blockingRepository holds every Save until release is closed, announcing
+// it on saving first, so a request can be caught while it runs.
+type blockingRepository struct {
+	repositories.UserRepository
+
+	saving  chan struct{}
+	release chan struct{}
+}
+
+func (r *blockingRepository) Save(ctx context.Context, user *entities.User) error {
+	r.saving <- struct{}{}
+	<-r.release
+
+	return r.UserRepository.Save(ctx, user)
+}
+
+var _ = Describe("POST /api/v1/users with Idempotency-Key", func() {
+	const body = `{"email":"retry@example.com","name":"Retry User"}`
+
+	var (
+		userRepo repositories.UserRepository
+		fake     *clock.Fake
+		mux      *http.ServeMux
+	)
+
+	serve := func(userRepo repositories.UserRepository, options handlers.IdempotencyOptions) {
+		fake = clock.NewFake(time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC))
+		userHandler := handlers.NewUserHandler(services.NewUserService(userRepo).WithClock(fake)).
+			WithClock(fake).
+			WithIdempotency(repositories.NewInMemoryIdempotencyStore(), options)
+		mux = http.NewServeMux()
+		userHandler.RegisterRoutes(mux)
+	}
+
+	post := func(key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, routes.UsersPath, strings.NewReader(body))
+		if key != "" {
+			req.Header.Set(handlers.IdempotencyKeyHeader, key)
+		}
+
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		return w
+	}
+
+	apiError := func(w *httptest.ResponseRecorder) pkgerrors.APIError {
+		var out pkgerrors.APIError
+		Expect(json.Unmarshal(w.Body.Bytes(), &out)).To(Succeed())
+
+		return out
+	}
+
+	countUsers := func() int {
+		count, err := userRepo.Count(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+
+		return count
+	}
+
+	Context("with completed requests", func() {
+		BeforeEach(func() {
+			userRepo = repositories.NewInMemoryUserRepository()
+			serve(userRepo, handlers.IdempotencyOptions{TTL: time.Hour}) //nolint:exhaustruct // no waiting
+		})
+
+		It("should replay the first response to a retry", func() {
+			first := post("key-1", body)
+			Expect(first.Code).To(Equal(http.StatusCreated))
+			Expect(first.Header().Get(handlers.IdempotentReplayedHeader)).To(BeEmpty())
+
+			retry := post("key-1", body)
+			Expect(retry.Code).To(Equal(http.StatusCreated))
+			Expect(retry.Header().Get(handlers.IdempotentReplayedHeader)).To(Equal("true"))
+			Expect(retry.Header().Get("Content-Type")).To(Equal("application/json"))
+			Expect(first.Header().Get("Location")).To(HavePrefix(routes.UsersPath + "/"))
+			Expect(retry.Header().Get("Location")).To(Equal(first.Header().Get("Location")))
+			Expect(retry.Body.String()).To(Equal(first.Body.String()))
+			Expect(countUsers()).To(Equal(1))
+		})
+
+		It("should answer 422 when the key comes with another body", func() {
+			Expect(post("key-1", body).Code).To(Equal(http.StatusCreated))
+
+			w := post("key-1", `{"email":"other@example.com","name":"Other User"}`)
+			Expect(w.Code).To(Equal(http.StatusUnprocessableEntity))
+			Expect(apiError(w).Code).To(Equal(pkgerrors.APICodeIdempotencyKeyReused))
+			Expect(countUsers()).To(Equal(1))
+		})
+
+		It("should run the request again once the key expired", func() {
+			Expect(post("key-1", body).Code).To(Equal(http.StatusCreated))
+
+			fake.Advance(time.Hour - time.Second)
+			Expect(post("key-1", body).Header().Get(handlers.IdempotentReplayedHeader)).To(Equal("true"))
+
+			fake.Advance(time.Second)
+
+			w := post("key-1", body)
+			Expect(w.Header().Get(handlers.IdempotentReplayedHeader)).To(BeEmpty())
+			Expect(w.Code).To(Equal(http.StatusConflict))
+			Expect(apiError(w).Code).To(Equal(pkgerrors.APICodeEmailAlreadyExists))
+		})
+
+		It("should replay client errors and leave requests without a key alone", func() {
+			invalid := post("key-2", `{"email":"not-an-email","name":"Retry User"}`)
+			Expect(invalid.Code).To(Equal(http.StatusBadRequest))
+			Expect(post("key-2", `{"email":"not-an-email","name":"Retry User"}`).Header().
+				Get(handlers.IdempotentReplayedHeader)).To(Equal("true"))
+
+			Expect(post("", body).Code).To(Equal(http.StatusCreated))
+			Expect(post("", body).Code).To(Equal(http.StatusConflict))
+		})
+
+		It("should reject a key with control characters", func() {
+			w := post("key\t1", body)
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+			Expect(apiError(w).Field).To(Equal(handlers.IdempotencyKeyHeader))
+		})
+	})
+
+	Context("with a duplicate submitted while the first runs", func() {
+		var blocking *blockingRepository
+
+		BeforeEach(func() {
+			userRepo = repositories.NewInMemoryUserRepository()
+			blocking = &blockingRepository{
+				UserRepository: userRepo,
+				saving:         make(chan struct{}, 2),
+				release:        make(chan struct{}),
+			}
+		})
+
+		// startFirst sends the first request and returns once it is saving.
+		startFirst := func() <-chan *httptest.ResponseRecorder {
+			done := make(chan *httptest.ResponseRecorder, 1)
+			go func() { done <- post("key-1", body) }()
+
+			Eventually(blocking.saving).Should(Receive())
+
+			return done
+		}
+
+		It("should make the duplicate wait for the first response when waiting", func() {
+			serve(blocking, handlers.IdempotencyOptions{ //nolint:exhaustruct // default Retry-After
+				TTL:          time.Hour,
+				Wait:         true,
+				WaitTimeout:  5 * time.Second,
+				PollInterval: time.Millisecond,
+			})
+
+			first := startFirst()
+			duplicate := make(chan *httptest.ResponseRecorder, 1)
+			go func() { duplicate <- post("key-1", body) }()
+
+			Consistently(duplicate, 50*time.Millisecond).ShouldNot(Receive())
+			close(blocking.release)
+
+			var firstResponse, duplicateResponse *httptest.ResponseRecorder
+			Eventually(first).Should(Receive(&firstResponse))
+			Eventually(duplicate).Should(Receive(&duplicateResponse))
+
+			Expect(firstResponse.Code).To(Equal(http.StatusCreated))
+			Expect(duplicateResponse.Code).To(Equal(http.StatusCreated))
+			Expect(duplicateResponse.Header().Get(handlers.IdempotentReplayedHeader)).To(Equal("true"))
+			Expect(duplicateResponse.Body.String()).To(Equal(firstResponse.Body.String()))
+			Expect(blocking.saving).ToNot(Receive(), "the duplicate must not save")
+			Expect(countUsers()).To(Equal(1))
+		})
+
+		It("should answer the duplicate 409 with Retry-After when rejecting", func() {
+			serve(blocking, handlers.IdempotencyOptions{ //nolint:exhaustruct // no waiting
+				TTL:        time.Hour,
+				RetryAfter: 2 * time.Second,
+			})
+
+			first := startFirst()
+
+			w := post("key-1", body)
+			Expect(w.Code).To(Equal(http.StatusConflict))
+			Expect(w.Header().Get("Retry-After")).To(Equal("2"))
+			Expect(apiError(w).Code).To(Equal(pkgerrors.APICodeIdempotencyKeyInUse))
+
+			close(blocking.release)
+			Eventually(first).Should(Receive(HaveField("Code", http.StatusCreated)))
+			Expect(countUsers()).To(Equal(1))
+		})
+	})
+})
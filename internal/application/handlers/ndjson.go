@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json/v2"
+	stderrors "errors"
+	"fmt"
+	"io"
+)
+
+// ContentTypeNDJSON is the media type of newline-delimited JSON bodies.
+const ContentTypeNDJSON = "application/x-ndjson"
+
+// StreamLimits bound a streamed import by record instead of by raw bytes.
+type StreamLimits struct {
+	// MaxRecordSize is the largest accepted line in bytes.
+	MaxRecordSize int
+	// MaxRecords is the largest number of records read from one stream.
+	MaxRecords int
+	// ChunkSize is how many records are handed to the sink at once.
+	ChunkSize int
+	// MaxReportedErrors caps the per-record errors kept in the summary.
+	MaxReportedErrors int
+}
+
+// DefaultStreamLimits returns the limits used by the import endpoint.
+func DefaultStreamLimits() StreamLimits {
+	return StreamLimits{
+		MaxRecordSize:     64 << 10,
+		MaxRecords:        100_000,
+		ChunkSize:         100,
+		MaxReportedErrors: 100,
+	}
+}
+
+// RecordError reports a failed record by its 1-based line number.
+type RecordError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// StreamSummary is the final result of a streamed import.
+type StreamSummary struct {
+	Received        int           `json:"received"`
+	Succeeded       int           `json:"succeeded"`
+	Failed          int           `json:"failed"`
+	Errors          []RecordError `json:"errors"`
+	ErrorsTruncated bool          `json:"errors_truncated"`
+	LimitExceeded   bool          `json:"limit_exceeded"`
+}
+
+// ErrRecordLimitExceeded is returned when a stream has more than MaxRecords records.
+var ErrRecordLimitExceeded = stderrors.New("record limit exceeded")
+
+// numberedRecord is a decoded record with the line it came from.
+type numberedRecord[T any] struct {
+	Line  int
+	Value T
+}
+
+// streamNDJSON decodes r one line at a time and passes full chunks to sink,
+// which returns one error per record. Malformed and oversized lines are
+// reported with their line number and the stream continues. Only one chunk
+// is held in memory. A cancelled ctx aborts between records.
+func streamNDJSON[T any](
+	ctx context.Context,
+	r io.Reader,
+	limits StreamLimits,
+	sink func(context.Context, []T) []error,
+) (StreamSummary, error) {
+	summary := StreamSummary{Errors: []RecordError{}} //nolint:exhaustruct // counters start at zero
+	reader := bufio.NewReaderSize(r, limits.MaxRecordSize)
+	chunk := make([]numberedRecord[T], 0, limits.ChunkSize)
+	values := make([]T, 0, limits.ChunkSize)
+
+	flush := func() {
+		values = values[:0]
+		for _, record := range chunk {
+			values = append(values, record.Value)
+		}
+
+		for i, err := range sink(ctx, values) {
+			summary.record(limits, chunk[i].Line, err)
+		}
+
+		chunk = chunk[:0]
+	}
+
+	for line := 1; ; line++ {
+		if err := ctx.Err(); err != nil {
+			return summary, err
+		}
+
+		raw, readErr := readLine(reader)
+		if len(bytes.TrimSpace(raw)) > 0 || stderrors.Is(readErr, bufio.ErrBufferFull) {
+			if summary.Received == limits.MaxRecords {
+				summary.LimitExceeded = true
+
+				break
+			}
+
+			summary.Received++
+
+			var value T
+
+			switch {
+			case stderrors.Is(readErr, bufio.ErrBufferFull):
+				summary.record(limits, line, fmt.Errorf("record exceeds %d bytes", limits.MaxRecordSize))
+			case json.Unmarshal(raw, &value) != nil:
+				summary.record(limits, line, stderrors.New("malformed JSON record"))
+			default:
+				chunk = append(chunk, numberedRecord[T]{Line: line, Value: value})
+			}
+		}
+
+		if len(chunk) == limits.ChunkSize {
+			flush()
+		}
+
+		if readErr != nil && !stderrors.Is(readErr, bufio.ErrBufferFull) {
+			if !stderrors.Is(readErr, io.EOF) {
+				return summary, fmt.Errorf("read line %d: %w", line, readErr)
+			}
+
+			break
+		}
+	}
+
+	if len(chunk) > 0 {
+		flush()
+	}
+
+	if summary.LimitExceeded {
+		return summary, ErrRecordLimitExceeded
+	}
+
+	return summary, nil
+}
+
+// readLine returns the next line without its newline. A line longer than the
+// reader buffer is discarded up to its newline and reported as
+// bufio.ErrBufferFull.
+func readLine(reader *bufio.Reader) ([]byte, error) {
+	raw, err := reader.ReadSlice('\n')
+	if !stderrors.Is(err, bufio.ErrBufferFull) {
+		return bytes.TrimSuffix(raw, []byte("\n")), err
+	}
+
+	for stderrors.Is(err, bufio.ErrBufferFull) {
+		_, err = reader.ReadSlice('\n')
+	}
+
+	if err != nil && !stderrors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	return nil, bufio.ErrBufferFull
+}
+
+func (s *StreamSummary) record(limits StreamLimits, line int, err error) {
+	if err == nil {
+		s.Succeeded++
+
+		return
+	}
+
+	s.Failed++
+
+	if len(s.Errors) == limits.MaxReportedErrors {
+		s.ErrorsTruncated = true
+
+		return
+	}
+
+	s.Errors = append(s.Errors, RecordError{Line: line, Error: err.Error()})
+}
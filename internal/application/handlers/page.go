@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	pkgerrors "github.com/LarsArtmann/template-arch-lint/pkg/errors"
+)
+
+const (
+	defaultPageLimit = 10
+	maxPageLimit     = 100
+)
+
+// PageMeta describes where a Page sits in the full result set.
+// Offset pages set Offset, cursor pages set Cursor/NextCursor, and pages
+// requested by number also set Page, PageSize and TotalPages.
+// Total is only present when it was cheap to compute.
+type PageMeta struct {
+	Limit      int    `json:"limit"`
+	Offset     *int   `json:"offset,omitzero"`
+	Page       int    `json:"page,omitzero"`
+	PageSize   int    `json:"page_size,omitzero"`
+	TotalPages *int   `json:"total_pages,omitzero"`
+	Cursor     string `json:"cursor,omitzero"`
+	NextCursor string `json:"next_cursor,omitzero"`
+	Total      *int   `json:"total,omitzero"`
+	HasMore    bool   `json:"has_more"`
+}
+
+// Page is the envelope returned by list-style endpoints paged by offset,
+// page number or cursor.
+type Page[T any] struct {
+	Data       []T               `json:"data"`
+	Pagination PageMeta          `json:"pagination"`
+	Filters    map[string]string `json:"filters"`
+}
+
+// NewNumberedPage wraps items, page number page of pageSize items out of
+// total, numbered from 1. The items were already paged by the repository.
+func NewNumberedPage[T any](items []T, page, pageSize, total int, filters map[string]string) Page[T] {
+	offset := (page - 1) * pageSize
+	totalPages := (total + pageSize - 1) / pageSize
+
+	return Page[T]{
+		Data: emptyIfNil(items),
+		Pagination: PageMeta{ //nolint:exhaustruct // cursor fields are unused for numbered pages
+			Limit:      pageSize,
+			Offset:     &offset,
+			Page:       page,
+			PageSize:   pageSize,
+			TotalPages: &totalPages,
+			Total:      &total,
+			HasMore:    offset < total-len(items),
+		},
+		Filters: emptyFiltersIfNil(filters),
+	}
+}
+
+// PageRequest holds the offset and limit parsed from a list request.
+type PageRequest struct {
+	Offset int
+	Limit  int
+}
+
+// parsePageRequest reads limit, offset and page query parameters.
+// page is accepted for backwards compatibility and converted to an offset;
+// a page whose offset would overflow is a validation error.
+func parsePageRequest(r *http.Request) (PageRequest, error) {
+	query := r.URL.Query()
+
+	limit, err := strconv.Atoi(query.Get("limit"))
+	if err != nil || limit < 1 || limit > maxPageLimit {
+		limit = defaultPageLimit
+	}
+
+	offset, err := strconv.Atoi(query.Get("offset"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	page, err := strconv.Atoi(query.Get("page"))
+	if err == nil && page > 1 && query.Get("offset") == "" {
+		if page-1 > math.MaxInt/limit {
+			return PageRequest{}, pkgerrors.NewValidationError("page", "page is out of range")
+		}
+
+		offset = (page - 1) * limit
+	}
+
+	return PageRequest{Offset: offset, Limit: limit}, nil
+}
+
+// NewOffsetPage slices a complete result set into a page.
+// The total is known because the full set is in hand.
+func NewOffsetPage[T any](items []T, req PageRequest, filters map[string]string) Page[T] {
+	total := len(items)
+	start := min(max(req.Offset, 0), total)
+	end := min(start+req.Limit, total)
+	offset := req.Offset
+
+	return Page[T]{
+		Data: emptyIfNil(items[start:end]),
+		Pagination: PageMeta{ //nolint:exhaustruct // cursor fields are unused for offset pages
+			Limit:   req.Limit,
+			Offset:  &offset,
+			Total:   &total,
+			HasMore: end < total,
+		},
+		Filters: emptyFiltersIfNil(filters),
+	}
+}
+
+// NewCursorPage builds a page from items fetched with limit+1 rows.
+// The extra row only signals HasMore and is dropped from the page.
+func NewCursorPage[T any](
+	items []T,
+	limit int,
+	cursor string,
+	cursorOf func(T) string,
+	filters map[string]string,
+) Page[T] {
+	hasMore := len(items) > limit
+	if hasMore {
+		items = items[:limit]
+	}
+
+	nextCursor := ""
+	if hasMore && len(items) > 0 {
+		nextCursor = cursorOf(items[len(items)-1])
+	}
+
+	return Page[T]{
+		Data: emptyIfNil(items),
+		Pagination: PageMeta{ //nolint:exhaustruct // offset and total are unused for cursor pages
+			Limit:      limit,
+			Cursor:     cursor,
+			NextCursor: nextCursor,
+			HasMore:    hasMore,
+		},
+		Filters: emptyFiltersIfNil(filters),
+	}
+}
+
+// Count returns the number of items on this page, for count badges.
+func (p Page[T]) Count() int {
+	return len(p.Data)
+}
+
+func emptyIfNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+
+	return items
+}
+
+func emptyFiltersIfNil(filters map[string]string) map[string]string {
+	if filters == nil {
+		return map[string]string{}
+	}
+
+	return filters
+}
@@ -0,0 +1,232 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json/v2"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/application/handlers"
+	"github.com/LarsArtmann/template-arch-lint/internal/application/routes"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/clock"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/repositories"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/services"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/values"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Page", func() {
+	marshal := func(v any) map[string]any {
+		raw, err := json.Marshal(v)
+		Expect(err).ToNot(HaveOccurred())
+
+		var out map[string]any
+		Expect(json.Unmarshal(raw, &out)).To(Succeed())
+
+		return out
+	}
+
+	Describe("offset pages", func() {
+		It("should include offset, total and has_more", func() {
+			page := handlers.NewOffsetPage(
+				[]int{1, 2, 3, 4, 5},
+				handlers.PageRequest{Offset: 2, Limit: 2},
+				nil,
+			)
+
+			out := marshal(page)
+			pagination := out["pagination"].(map[string]any)
+
+			Expect(out["data"]).To(Equal([]any{float64(3), float64(4)}))
+			Expect(pagination["offset"]).To(Equal(float64(2)))
+			Expect(pagination["limit"]).To(Equal(float64(2)))
+			Expect(pagination["total"]).To(Equal(float64(5)))
+			Expect(pagination["has_more"]).To(BeTrue())
+			Expect(pagination).ToNot(HaveKey("cursor"))
+			Expect(pagination).ToNot(HaveKey("next_cursor"))
+		})
+
+		It("should return an empty data array past the end", func() {
+			page := handlers.NewOffsetPage([]int{1}, handlers.PageRequest{Offset: 10, Limit: 5}, nil)
+
+			out := marshal(page)
+			Expect(out["data"]).To(Equal([]any{}))
+			Expect(out["pagination"].(map[string]any)["has_more"]).To(BeFalse())
+		})
+
+		It("should start a negative offset at the first item", func() {
+			page := handlers.NewOffsetPage([]int{1, 2, 3}, handlers.PageRequest{Offset: -20, Limit: 2}, nil)
+
+			Expect(marshal(page)["data"]).To(Equal([]any{float64(1), float64(2)}))
+		})
+	})
+
+	Describe("cursor pages", func() {
+		cursorOf := func(v int) string { return strconv.Itoa(v) }
+
+		It("should omit total and offset and set next_cursor when more rows exist", func() {
+			page := handlers.NewCursorPage([]int{4, 5, 6}, 2, "3", cursorOf, nil)
+
+			out := marshal(page)
+			pagination := out["pagination"].(map[string]any)
+
+			Expect(out["data"]).To(Equal([]any{float64(4), float64(5)}))
+			Expect(pagination["cursor"]).To(Equal("3"))
+			Expect(pagination["next_cursor"]).To(Equal("5"))
+			Expect(pagination["has_more"]).To(BeTrue())
+			Expect(pagination).ToNot(HaveKey("total"))
+			Expect(pagination).ToNot(HaveKey("offset"))
+		})
+
+		It("should not set next_cursor on the last page", func() {
+			page := handlers.NewCursorPage([]int{4}, 2, "3", cursorOf, nil)
+
+			pagination := marshal(page)["pagination"].(map[string]any)
+			Expect(pagination).ToNot(HaveKey("next_cursor"))
+			Expect(pagination["has_more"]).To(BeFalse())
+		})
+	})
+
+	Describe("numbered pages", func() {
+		It("should include page, page_size and total_pages next to offset and total", func() {
+			page := handlers.NewNumberedPage([]int{4, 5}, 2, 3, 5, nil)
+
+			out := marshal(page)
+			pagination := out["pagination"].(map[string]any)
+
+			Expect(out["data"]).To(Equal([]any{float64(4), float64(5)}))
+			Expect(pagination).To(Equal(map[string]any{
+				"limit": float64(3), "offset": float64(3), "page": float64(2), "page_size": float64(3),
+				"total_pages": float64(2), "total": float64(5), "has_more": false,
+			}))
+		})
+
+		It("should report more pages before the last one", func() {
+			page := handlers.NewNumberedPage([]int{1, 2, 3}, 1, 3, 5, nil)
+
+			Expect(marshal(page)["pagination"].(map[string]any)["has_more"]).To(BeTrue())
+		})
+	})
+
+	Describe("filter echo", func() {
+		It("should echo applied filters", func() {
+			page := handlers.NewOffsetPage(
+				[]string{},
+				handlers.PageRequest{Offset: 0, Limit: 10},
+				map[string]string{"domain": "example.com"},
+			)
+
+			Expect(marshal(page)["filters"]).To(Equal(map[string]any{"domain": "example.com"}))
+		})
+
+		It("should emit an empty object when no filters apply", func() {
+			page := handlers.NewOffsetPage([]string{}, handlers.PageRequest{Offset: 0, Limit: 10}, nil)
+
+			Expect(marshal(page)["filters"]).To(Equal(map[string]any{}))
+		})
+	})
+
+	Describe("list route contract", func() {
+		var (
+			mux          *http.ServeMux
+			userHandler  *handlers.UserHandler
+			queryHandler *handlers.UserQueryHandler
+		)
+
+		// listRoutes returns the list routes of both handlers as request
+		// paths, with query appended and the search route's email filled in.
+		listRoutes := func(query string) map[string]string {
+			paths := map[string]string{}
+
+			for _, route := range append(userHandler.Routes(), queryHandler.Routes()...) {
+				if !route.List {
+					continue
+				}
+
+				_, path, _ := strings.Cut(route.Pattern, " ")
+				path = strings.ReplaceAll(path, "{domain}", "example.com")
+
+				params := query
+				if path == routes.UsersSearchPath {
+					params = strings.TrimPrefix(query+"&email=page@example.com", "&")
+				}
+
+				paths[route.Pattern] = path + "?" + params
+			}
+
+			return paths
+		}
+
+		BeforeEach(func() {
+			userRepo := repositories.NewInMemoryUserRepository()
+			userService := services.NewUserService(userRepo)
+			userHandler = handlers.NewUserHandler(userService)
+			queryHandler = handlers.NewUserQueryHandler(services.NewUserQueryService(userRepo, clock.System{}))
+			mux = http.NewServeMux()
+			userHandler.RegisterRoutes(mux)
+			queryHandler.RegisterRoutes(mux)
+
+			userID, err := values.GenerateUserID()
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = userService.CreateUser(context.Background(), userID, "page@example.com", "Page User")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should return the page envelope from every list route", func() {
+			paths := listRoutes("")
+			Expect(paths).To(HaveKey(routes.Pattern(http.MethodGet, routes.UsersPath)))
+
+			for pattern, path := range paths {
+				w := httptest.NewRecorder()
+				mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+				Expect(w.Code).To(Equal(http.StatusOK), pattern)
+
+				var response map[string]any
+				Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed(), pattern)
+				Expect(response).To(HaveKey("data"), pattern)
+				Expect(response).To(HaveKey("filters"), pattern)
+				Expect(response).To(HaveKey("pagination"), pattern)
+				Expect(response["pagination"]).To(HaveKey("has_more"), pattern)
+			}
+		})
+
+		It("should register exactly the routes defined in the routes package", func() {
+			registered := map[string]bool{}
+			for _, route := range append(userHandler.Routes(), queryHandler.Routes()...) {
+				_, path, _ := strings.Cut(route.Pattern, " ")
+				registered[path] = true
+			}
+
+			for _, path := range routes.All() {
+				Expect(registered).To(HaveKey(path), "orphaned route constant "+path)
+			}
+
+			for path := range registered {
+				Expect(routes.All()).To(ContainElement(path), "route registered without a constant: "+path)
+			}
+		})
+
+		It("should reject a page whose offset overflows on every list route", func() {
+			for pattern, path := range listRoutes("page=9223372036854775807") {
+				w := httptest.NewRecorder()
+				mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+				Expect(w.Code).To(Equal(http.StatusBadRequest), pattern)
+			}
+		})
+
+		It("should convert page numbers into offsets", func() {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, routes.UsersPaginatedPath+"?page=3&limit=5", nil))
+
+			var response map[string]any
+			Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+			Expect(response["pagination"].(map[string]any)["offset"]).To(Equal(float64(10)))
+		})
+	})
+})
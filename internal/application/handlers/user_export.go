@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json/v2"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"charm.land/log/v2"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/clock"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/entities"
+	pkgerrors "github.com/LarsArtmann/template-arch-lint/pkg/errors"
+)
+
+// Export formats of GET /api/v1/users/export.
+const (
+	exportFormatCSV    = "csv"
+	exportFormatNDJSON = "ndjson"
+)
+
+const (
+	// exportFlushEvery is how many records are written between flushes, so
+	// clients see progress on a long export.
+	exportFlushEvery = 500
+	// exportWriteTimeout is how long writing the records up to the next
+	// flush may take. Each flush extends the server's write deadline by it,
+	// so a large export is not cut off by WriteTimeout.
+	exportWriteTimeout = 30 * time.Second
+)
+
+// exportColumns is the CSV header; NDJSON records use the same names.
+var exportColumns = []string{"id", "email", "name", "created", "modified"}
+
+// exportUser is one NDJSON export record.
+type exportUser struct {
+	ID       string    `json:"id"`
+	Email    string    `json:"email"`
+	Name     string    `json:"name"`
+	Created  time.Time `json:"created"`
+	Modified time.Time `json:"modified"`
+}
+
+// ExportUsers serves GET /api/v1/users/export?format=csv|ndjson, default
+// ndjson. It streams every active user in ID order straight from the
+// repository, so memory use does not grow with the table, and flushes
+// every exportFlushEvery records. A client that goes away stops the
+// iteration. A failure after the first record cannot change the status
+// any more, so it aborts the response to leave it visibly truncated.
+func (h *UserHandler) ExportUsers(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = exportFormatNDJSON
+	}
+
+	if format != exportFormatCSV && format != exportFormatNDJSON {
+		RespondError(w, r, pkgerrors.NewValidationError("format", "format must be csv or ndjson"))
+
+		return
+	}
+
+	exporter := newUserExporter(w, format, h.clock.Now())
+
+	err := h.userService.ExportUsers(r.Context(), exporter.write)
+	if err == nil {
+		err = exporter.finish()
+	}
+
+	switch {
+	case err == nil:
+		log.FromContext(r.Context()).Info("Users exported", "format", format, "records", exporter.records)
+	case r.Context().Err() != nil:
+		log.FromContext(r.Context()).Warn("User export aborted", "records", exporter.records, "error", err)
+	case !exporter.started:
+		RespondError(w, r, err)
+	default:
+		log.FromContext(r.Context()).Error("User export failed", "records", exporter.records, "error", err)
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// userExporter writes export records and sends the headers with the first.
+type userExporter struct {
+	w          http.ResponseWriter
+	controller *http.ResponseController
+	format     string
+	filename   string
+	csv        *csv.Writer
+	started    bool
+	records    int
+}
+
+func newUserExporter(w http.ResponseWriter, format string, now time.Time) *userExporter {
+	return &userExporter{
+		w:          w,
+		controller: http.NewResponseController(w),
+		format:     format,
+		filename:   fmt.Sprintf("users-%s.%s", now.UTC().Format("20060102T150405Z"), format),
+		csv:        csv.NewWriter(w),
+		started:    false,
+		records:    0,
+	}
+}
+
+func (e *userExporter) start() error {
+	e.started = true
+
+	contentType := ContentTypeNDJSON
+	if e.format == exportFormatCSV {
+		contentType = "text/csv; charset=utf-8"
+	}
+
+	e.w.Header().Set("Content-Type", contentType)
+	e.w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", e.filename))
+	e.w.WriteHeader(http.StatusOK)
+	e.extendDeadline()
+
+	if e.format == exportFormatCSV {
+		return e.csv.Write(exportColumns)
+	}
+
+	return nil
+}
+
+func (e *userExporter) write(user *entities.User) error {
+	if !e.started {
+		err := e.start()
+		if err != nil {
+			return err
+		}
+	}
+
+	err := e.writeRecord(user)
+	if err != nil {
+		return err
+	}
+
+	e.records++
+	if e.records%exportFlushEvery == 0 {
+		return e.flush()
+	}
+
+	return nil
+}
+
+func (e *userExporter) writeRecord(user *entities.User) error {
+	if e.format == exportFormatCSV {
+		return e.csv.Write([]string{
+			user.ID.String(),
+			user.GetEmail().String(),
+			user.GetUserName().String(),
+			user.Created.UTC().Format(time.RFC3339Nano),
+			user.Modified.UTC().Format(time.RFC3339Nano),
+		})
+	}
+
+	err := json.MarshalWrite(e.w, exportUser{
+		ID:       user.ID.String(),
+		Email:    user.GetEmail().String(),
+		Name:     user.GetUserName().String(),
+		Created:  user.Created.UTC(),
+		Modified: user.Modified.UTC(),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = e.w.Write([]byte("\n"))
+
+	return err
+}
+
+// finish sends the headers of an empty export and flushes the rest.
+func (e *userExporter) finish() error {
+	if !e.started {
+		err := e.start()
+		if err != nil {
+			return err
+		}
+	}
+
+	return e.flush()
+}
+
+func (e *userExporter) flush() error {
+	e.csv.Flush()
+
+	err := e.csv.Error()
+	if err != nil {
+		return err
+	}
+
+	err = e.controller.Flush()
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+
+	e.extendDeadline()
+
+	return nil
+}
+
+// extendDeadline gives the records up to the next flush exportWriteTimeout.
+// Writers without deadlines, such as test recorders, are left alone. The
+// connection counts in wall time, so the deadline ignores the handler clock.
+func (e *userExporter) extendDeadline() {
+	_ = e.controller.SetWriteDeadline(clock.System{}.Now().Add(exportWriteTimeout))
+}
@@ -0,0 +1,194 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json/v2"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/application/handlers"
+	"github.com/LarsArtmann/template-arch-lint/internal/application/routes"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/entities"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/ids"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/repositories"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/services"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/values"
+	brandedid "github.com/larsartmann/go-branded-id"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// countingExportRepository counts the users FindAllIter hands out and runs
+// onVisit after each, before the handler sees it.
+type countingExportRepository struct {
+	repositories.UserRepository
+
+	visited int
+	onVisit func(visited int)
+}
+
+func (r *countingExportRepository) FindAllIter(ctx context.Context, fn func(*entities.User) error) error {
+	return r.UserRepository.FindAllIter(ctx, func(user *entities.User) error {
+		r.visited++
+		if r.onVisit != nil {
+			r.onVisit(r.visited)
+		}
+
+		return fn(user)
+	})
+}
+
+// fixedExportRepository hands out the given users, which need not pass the
+// validation Save applies.
+type fixedExportRepository struct {
+	repositories.UserRepository
+
+	users []*entities.User
+}
+
+func (r *fixedExportRepository) FindAllIter(_ context.Context, fn func(*entities.User) error) error {
+	for _, user := range r.users {
+		err := fn(user)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+var _ = Describe("GET /api/v1/users/export", func() {
+	var (
+		repo *countingExportRepository
+		mux  *http.ServeMux
+	)
+
+	BeforeEach(func() {
+		repo = &countingExportRepository{
+			UserRepository: repositories.NewInMemoryUserRepository(),
+			visited:        0,
+			onVisit:        nil,
+		}
+		mux = http.NewServeMux()
+		handlers.NewUserHandler(services.NewUserService(repo)).RegisterRoutes(mux)
+	})
+
+	saveUsers := func(n int) {
+		for i := range n {
+			user, err := entities.NewUser(ids.MustGenerateUserID(),
+				fmt.Sprintf("user%03d@example.com", i), fmt.Sprintf("user%03d", i), time.Now())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(repo.Save(context.Background(), user)).To(Succeed())
+		}
+	}
+
+	get := func(ctx context.Context, query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequestWithContext(ctx, http.MethodGet, routes.UsersExportPath+query, nil)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		return w
+	}
+
+	It("should stream CSV with a header row and an attachment filename", func() {
+		saveUsers(3)
+
+		w := get(context.Background(), "?format=csv")
+
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Header().Get("Content-Type")).To(Equal("text/csv; charset=utf-8"))
+		Expect(w.Header().Get("Content-Disposition")).To(
+			MatchRegexp(`^attachment; filename="users-\d{8}T\d{6}Z\.csv"$`))
+
+		rows, err := csv.NewReader(w.Body).ReadAll()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rows).To(HaveLen(4))
+		Expect(rows[0]).To(Equal([]string{"id", "email", "name", "created", "modified"}))
+		Expect(rows[1][0] < rows[2][0] && rows[2][0] < rows[3][0]).To(BeTrue(), "rows in ID order")
+		Expect(rows[1][2]).To(MatchRegexp(`^user\d{3}$`))
+	})
+
+	It("should stream one JSON object per line by default", func() {
+		saveUsers(2)
+
+		w := get(context.Background(), "")
+
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Header().Get("Content-Type")).To(Equal(handlers.ContentTypeNDJSON))
+		Expect(w.Header().Get("Content-Disposition")).To(HaveSuffix(`.ndjson"`))
+
+		lines := strings.Split(strings.TrimSuffix(w.Body.String(), "\n"), "\n")
+		Expect(lines).To(HaveLen(2))
+
+		for _, line := range lines {
+			var record map[string]any
+			Expect(json.Unmarshal([]byte(line), &record)).To(Succeed())
+			Expect(record).To(HaveKey("id"))
+			Expect(record["email"]).To(MatchRegexp(`^user\d{3}@example\.com$`))
+			Expect(record).To(HaveKey("created"))
+			Expect(record).To(HaveKey("modified"))
+		}
+	})
+
+	It("should send the CSV header for an empty table", func() {
+		w := get(context.Background(), "?format=csv")
+
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Body.String()).To(Equal("id,email,name,created,modified\n"))
+	})
+
+	It("should reject an unknown format", func() {
+		w := get(context.Background(), "?format=xml")
+
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+		Expect(w.Body.String()).To(ContainSubstring(`"field":"format"`))
+	})
+
+	It("should stop iterating when the client goes away", func() {
+		saveUsers(100)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		repo.onVisit = func(visited int) {
+			if visited == 10 {
+				cancel()
+			}
+		}
+
+		w := get(ctx, "?format=ndjson")
+
+		Expect(repo.visited).To(Equal(10))
+		Expect(strings.Count(w.Body.String(), "\n")).To(Equal(10))
+	})
+
+	It("should quote CSV fields that contain commas and quotes", func() {
+		email, err := values.NewEmail("quoted@example.com")
+		Expect(err).ToNot(HaveOccurred())
+		name, err := values.NewUserName("Doe, Jane")
+		Expect(err).ToNot(HaveOccurred())
+
+		// Names may hold commas but not quotes, so the ID carries a quote.
+		user, err := entities.NewUserFromValues(brandedid.NewID[ids.UserBrand](`user"1`), email, name, time.Now())
+		Expect(err).ToNot(HaveOccurred())
+
+		fixed := &fixedExportRepository{
+			UserRepository: repositories.NewInMemoryUserRepository(),
+			users:          []*entities.User{user},
+		}
+		mux = http.NewServeMux()
+		handlers.NewUserHandler(services.NewUserService(fixed)).RegisterRoutes(mux)
+
+		w := get(context.Background(), "?format=csv")
+
+		Expect(w.Body.String()).To(ContainSubstring("\n" + `"user""1",quoted@example.com,"Doe, Jane",`))
+
+		rows, err := csv.NewReader(w.Body).ReadAll()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rows[1][:3]).To(Equal([]string{`user"1`, "quoted@example.com", "Doe, Jane"}))
+	})
+})
@@ -4,26 +4,63 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json/v2"
+	"errors"
+	"fmt"
 	"net/http"
 
 	"charm.land/log/v2"
+	"github.com/LarsArtmann/template-arch-lint/internal/application/routes"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/clock"
 	"github.com/LarsArtmann/template-arch-lint/internal/domain/entities"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/repositories"
 	"github.com/LarsArtmann/template-arch-lint/internal/domain/services"
 	"github.com/LarsArtmann/template-arch-lint/internal/domain/values"
+	domainerrors "github.com/LarsArtmann/template-arch-lint/pkg/errors"
 )
 
 const userIDByteLength = 8
 
 type UserHandler struct {
-	userService *services.UserService
+	userService    *services.UserService
+	allowPutCreate bool
+	importLimits   StreamLimits
+	clock          clock.Clock
+	idempotency    *idempotency
 }
 
 func NewUserHandler(userService *services.UserService) *UserHandler {
 	return &UserHandler{
-		userService: userService,
+		userService:    userService,
+		allowPutCreate: false,
+		importLimits:   DefaultStreamLimits(),
+		clock:          clock.System{},
+		idempotency:    nil,
 	}
 }
 
+// WithClock replaces the system clock that list filters and export file
+// names are based on.
+func (h *UserHandler) WithClock(clock clock.Clock) *UserHandler {
+	h.clock = clock
+
+	return h
+}
+
+// WithPutCreate lets PUT create a user that does not exist yet. Without it,
+// PUT creates only when the request sends If-None-Match: *.
+func (h *UserHandler) WithPutCreate(enabled bool) *UserHandler {
+	h.allowPutCreate = enabled
+
+	return h
+}
+
+// WithImportLimits replaces the record limits of the NDJSON import endpoint.
+func (h *UserHandler) WithImportLimits(limits StreamLimits) *UserHandler {
+	h.importLimits = limits
+
+	return h
+}
+
 func generateUserID() string {
 	bytes := make([]byte, userIDByteLength)
 	_, _ = rand.Read(bytes)
@@ -37,39 +74,52 @@ func writeJSON(w http.ResponseWriter, status int, data any) {
 	_ = json.MarshalWrite(w, data)
 }
 
-func errorResponse(w http.ResponseWriter, status int, errCode, message string) {
-	writeJSON(w, status, map[string]string{
-		"error":   errCode,
-		"message": message,
-	})
-}
-
-func bindRequest[T any](r *http.Request, req *T) bool {
+// bindRequest decodes the JSON body into req. When it cannot, it answers
+// 413 for a body over the limit the body limits middleware set, and 400
+// for anything else, and returns false.
+func bindRequest[T any](w http.ResponseWriter, r *http.Request, req *T) bool {
 	err := json.UnmarshalRead(r.Body, req)
-	if err != nil {
-		log.Error("Invalid request format", "error", err)
+	if err == nil {
+		return true
+	}
+
+	if _, tooLarge := errors.AsType[*http.MaxBytesError](err); tooLarge {
+		errorResponse(w, http.StatusRequestEntityTooLarge, domainerrors.APICodeRequestTooLarge,
+			"Request body is too large")
 
 		return false
 	}
 
-	return true
+	log.FromContext(r.Context()).Error("Invalid request format", "error", err)
+	errorResponse(w, http.StatusBadRequest, domainerrors.APICodeInvalidRequestBody, "Invalid request body")
+
+	return false
 }
 
-func userToMap(user *entities.User) map[string]any {
-	return map[string]any{
-		"id":        user.ID.String(),
-		"email":     user.GetEmail().String(),
-		"name":      user.GetUserName().String(),
-		"createdAt": user.GetCreatedAt(),
-		"updatedAt": user.GetUpdatedAt(),
+// userETag derives the entity tag from the stored version, which every
+// save bumps. The modification time would not do: a fake or coarse clock
+// can give two revisions the same one.
+func userETag(user *entities.User) string {
+	return fmt.Sprintf(`"%d"`, user.Version)
+}
+
+// Routes returns the route table served by this handler.
+func (h *UserHandler) Routes() []Route {
+	return []Route{
+		{Pattern: routes.Pattern(http.MethodPost, routes.UsersPath), Handler: h.idempotent(h.CreateUser), List: false},
+		{Pattern: routes.Pattern(http.MethodGet, routes.UsersPath), Handler: h.ListUsers, List: true},
+		{Pattern: routes.Pattern(http.MethodPost, routes.UsersImportPath), Handler: h.ImportUsers, List: false},
+		{Pattern: routes.Pattern(http.MethodGet, routes.UsersExportPath), Handler: h.ExportUsers, List: false},
+		{Pattern: routes.Pattern(http.MethodGet, routes.UserPath), Handler: h.GetUser, List: false},
+		{Pattern: routes.Pattern(http.MethodPut, routes.UserPath), Handler: h.UpdateUser, List: false},
+		{Pattern: routes.Pattern(http.MethodDelete, routes.UserPath), Handler: h.DeleteUser, List: false},
 	}
 }
 
 func (h *UserHandler) RegisterRoutes(mux *http.ServeMux) {
-	mux.HandleFunc("POST /api/v1/users", h.CreateUser)
-	mux.HandleFunc("GET /api/v1/users/{id}", h.GetUser)
-	mux.HandleFunc("PUT /api/v1/users/{id}", h.UpdateUser)
-	mux.HandleFunc("DELETE /api/v1/users/{id}", h.DeleteUser)
+	for _, route := range h.Routes() {
+		mux.HandleFunc(route.Pattern, route.Handler)
+	}
 }
 
 func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
@@ -77,47 +127,51 @@ func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 		Email string `json:"email"`
 		Name  string `json:"name"`
 	}
-	if !bindRequest(r, &req) {
-		errorResponse(w, http.StatusBadRequest, "invalid_request_format", "Invalid request body")
-
+	if !bindRequest(w, r, &req) {
 		return
 	}
 
 	userID, err := values.NewUserID(generateUserID())
 	if err != nil {
-		log.Error("Failed to generate user ID", "error", err)
-		errorResponse(
-			w,
-			http.StatusInternalServerError,
-			"user_id_generation_failed",
-			"Failed to generate user ID",
-		)
+		RespondError(w, r, fmt.Errorf("generate user ID: %w", err))
+
+		return
+	}
+
+	email, err := values.NewEmail(req.Email)
+	if err != nil {
+		RespondError(w, r, domainerrors.NewValidationError("email", "Invalid email address"))
 
 		return
 	}
 
-	user, err := h.userService.CreateUser(r.Context(), userID, req.Email, req.Name)
+	name, err := values.NewUserName(req.Name)
 	if err != nil {
-		log.Error("Failed to create user", "error", err)
-		errorResponse(
-			w,
-			http.StatusInternalServerError,
-			"user_creation_failed",
-			"Failed to create user",
-		)
+		RespondError(w, r, domainerrors.NewValidationError("name", "Invalid name"))
 
 		return
 	}
 
-	writeJSON(w, http.StatusCreated, userToMap(user))
+	user, err := h.userService.CreateUserV2(r.Context(), userID, email, name)
+	if err != nil {
+		RespondError(w, r, err)
+
+		return
+	}
+
+	w.Header().Set("Location", routes.UserByID(user.ID))
+	writeJSON(w, http.StatusCreated, ToUserResponse(user))
 }
 
+// errInvalidUserID rejects a malformed {id} path segment.
+var errInvalidUserID = domainerrors.NewValidationError("id", "Invalid user ID format")
+
 func parseUserID(r *http.Request) (values.UserID, bool) {
 	idStr := r.PathValue("id")
 
 	userID, err := values.NewUserID(idStr)
 	if err != nil {
-		log.Error("Invalid user ID format", "error", err)
+		log.FromContext(r.Context()).Error("Invalid user ID format", "error", err)
 
 		return values.UserID{}, false
 	}
@@ -128,26 +182,29 @@ func parseUserID(r *http.Request) (values.UserID, bool) {
 func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 	userID, ok := parseUserID(r)
 	if !ok {
-		errorResponse(w, http.StatusBadRequest, "invalid_user_id", "Invalid user ID format")
+		RespondError(w, r, errInvalidUserID)
 
 		return
 	}
 
 	user, err := h.userService.GetUser(r.Context(), userID)
 	if err != nil {
-		log.Error("Failed to get user", "error", err)
-		errorResponse(w, http.StatusNotFound, "user_not_found", "User not found")
+		RespondError(w, r, err)
 
 		return
 	}
 
-	writeJSON(w, http.StatusOK, userToMap(user))
+	w.Header().Set("ETag", userETag(user))
+	writeJSON(w, http.StatusOK, ToUserResponse(user))
 }
 
+// UpdateUser replaces the user with the request body. Absent users are
+// created when PUT-create is enabled or the request sends If-None-Match: *.
+// If-Match and If-None-Match are evaluated against the stored user.
 func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	userID, ok := parseUserID(r)
 	if !ok {
-		errorResponse(w, http.StatusBadRequest, "invalid_user_id", "Invalid user ID format")
+		RespondError(w, r, errInvalidUserID)
 
 		return
 	}
@@ -156,45 +213,99 @@ func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 		Email string `json:"email"`
 		Name  string `json:"name"`
 	}
-	if !bindRequest(r, &req) {
-		errorResponse(w, http.StatusBadRequest, "invalid_request_format", "Invalid request body")
+	if !bindRequest(w, r, &req) {
+		return
+	}
+
+	current, err := h.userService.GetUser(r.Context(), userID)
+	if err != nil && !errors.Is(err, repositories.ErrUserNotFound) { //nolint:legacyerrors // value sentinel
+		RespondError(w, r, err)
+
+		return
+	}
+
+	if !preconditionsHold(r, current) {
+		errorResponse(w, http.StatusPreconditionFailed, domainerrors.APICodePreconditionFailed,
+			"User does not match the precondition")
+
+		return
+	}
+
+	if current == nil && !h.allowPutCreate && r.Header.Get("If-None-Match") != "*" {
+		RespondError(w, r, repositories.ErrUserNotFound)
+
+		return
+	}
+
+	// The check above reads outside the transaction; passing the version
+	// If-Match named makes the save itself enforce it, so of two requests
+	// with the same If-Match only one succeeds.
+	expectedVersion := 0
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" && ifMatch != "*" {
+		expectedVersion = current.Version
+	}
+
+	user, created, err := h.userService.ReplaceUser(r.Context(), userID, services.UserFields{
+		Email: req.Email,
+		Name:  req.Name,
+	}, expectedVersion)
+	stale := errors.Is(err, repositories.ErrConcurrentModification) //nolint:legacyerrors // value sentinel
+	if expectedVersion != 0 && stale {
+		errorResponse(w, http.StatusPreconditionFailed, domainerrors.APICodePreconditionFailed,
+			"User does not match the precondition")
 
 		return
 	}
 
-	user, err := h.userService.UpdateUser(r.Context(), userID, req.Email, req.Name)
 	if err != nil {
-		log.Error("Failed to update user", "error", err)
-		errorResponse(
-			w,
-			http.StatusInternalServerError,
-			"user_update_failed",
-			"Failed to update user",
-		)
+		RespondError(w, r, err)
 
 		return
 	}
 
-	writeJSON(w, http.StatusOK, userToMap(user))
+	log.FromContext(r.Context()).Info("User replaced via PUT", "id", user.ID.String(), "created", created)
+	w.Header().Set("ETag", userETag(user))
+
+	if created {
+		w.Header().Set("Location", routes.UserByID(user.ID))
+		writeJSON(w, http.StatusCreated, ToUserResponse(user))
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ToUserResponse(user))
+}
+
+// preconditionsHold evaluates If-Match and If-None-Match. current is nil
+// when the user does not exist.
+func preconditionsHold(r *http.Request, current *entities.User) bool {
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch == "*" && current != nil {
+		return false
+	}
+
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" {
+		return true
+	}
+
+	if current == nil {
+		return false
+	}
+
+	return ifMatch == "*" || ifMatch == userETag(current)
 }
 
 func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	userID, ok := parseUserID(r)
 	if !ok {
-		errorResponse(w, http.StatusBadRequest, "invalid_user_id", "Invalid user ID format")
+		RespondError(w, r, errInvalidUserID)
 
 		return
 	}
 
 	err := h.userService.DeleteUser(r.Context(), userID)
 	if err != nil {
-		log.Error("Failed to delete user", "error", err)
-		errorResponse(
-			w,
-			http.StatusInternalServerError,
-			"user_deletion_failed",
-			"Failed to delete user",
-		)
+		RespondError(w, r, err)
 
 		return
 	}
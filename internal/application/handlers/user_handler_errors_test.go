@@ -0,0 +1,245 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	stderrors "errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+
+	"charm.land/log/v2"
+	"github.com/LarsArtmann/template-arch-lint/internal/application/handlers"
+	"github.com/LarsArtmann/template-arch-lint/internal/application/middleware"
+	"github.com/LarsArtmann/template-arch-lint/internal/application/routes"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/entities"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/repositories"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/services"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/values"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// brokenLookupRepository fails every FindByID with an error whose text
+// must never reach a client.
+type brokenLookupRepository struct {
+	repositories.UserRepository
+}
+
+func (brokenLookupRepository) FindByID(context.Context, values.UserID) (*entities.User, error) {
+	return nil, stderrors.New("dial tcp 10.0.0.7:5432: password authentication failed for user app")
+}
+
+var _ = Describe("UserHandler error responses", func() {
+	var mux *http.ServeMux
+
+	serve := func(method, path, body string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		return w
+	}
+
+	Context("with a working repository", func() {
+		var existing *entities.User
+
+		BeforeEach(func() {
+			userService := services.NewUserService(repositories.NewInMemoryUserRepository())
+			mux = http.NewServeMux()
+			handlers.NewUserHandler(userService).RegisterRoutes(mux)
+
+			var err error
+
+			existing, err = userService.CreateUser(context.Background(), values.MustGenerateUserID(),
+				"taken@example.com", "Taken User")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		DescribeTable("exact bodies",
+			func(method, path, body string, headers map[string]string, wantStatus int, wantBody string) {
+				w := serve(method, path, body, headers)
+
+				Expect(w.Code).To(Equal(wantStatus))
+				Expect(w.Header().Get("Content-Type")).To(Equal("application/json"))
+				Expect(w.Body.String()).To(MatchJSON(wantBody))
+			},
+			Entry("invalid email", http.MethodPost, routes.UsersPath, `{"email":"not-an-email","name":"New User"}`,
+				nil, http.StatusBadRequest,
+				`{"code":"VALIDATION_FAILED","message":"Invalid email address","field":"email"}`),
+			Entry("invalid name", http.MethodPost, routes.UsersPath, `{"email":"new@example.com","name":""}`,
+				nil, http.StatusBadRequest,
+				`{"code":"VALIDATION_FAILED","message":"Invalid name","field":"name"}`),
+			Entry("invalid user ID", http.MethodGet, routes.UsersPath+"/x", "",
+				nil, http.StatusBadRequest,
+				`{"code":"VALIDATION_FAILED","message":"Invalid user ID format","field":"id"}`),
+			Entry("undecodable body", http.MethodPost, routes.UsersPath, `{"email":`,
+				nil, http.StatusBadRequest,
+				`{"code":"INVALID_REQUEST_BODY","message":"Invalid request body"}`),
+			Entry("unknown user on GET", http.MethodGet, routes.UsersPath+"/user_missing", "",
+				nil, http.StatusNotFound,
+				`{"code":"USER_NOT_FOUND","message":"user not found"}`),
+			Entry("unknown user on DELETE", http.MethodDelete, routes.UsersPath+"/user_missing", "",
+				nil, http.StatusNotFound,
+				`{"code":"USER_NOT_FOUND","message":"user not found"}`),
+			Entry("unknown user on PUT", http.MethodPut, routes.UsersPath+"/user_missing",
+				`{"email":"new@example.com","name":"New User"}`,
+				nil, http.StatusNotFound,
+				`{"code":"USER_NOT_FOUND","message":"user not found"}`),
+			Entry("email taken on create", http.MethodPost, routes.UsersPath,
+				`{"email":"taken@example.com","name":"Other User"}`,
+				nil, http.StatusConflict,
+				`{"code":"EMAIL_ALREADY_EXISTS","message":"user already exists","field":"email"}`),
+			Entry("unsupported import media type", http.MethodPost, routes.UsersImportPath, `{}`,
+				map[string]string{"Content-Type": "application/json"}, http.StatusUnsupportedMediaType,
+				`{"code":"UNSUPPORTED_MEDIA_TYPE","message":"Import expects application/x-ndjson"}`),
+		)
+
+		It("should repeat the request ID in a client error body", func() {
+			req := httptest.NewRequest(http.MethodGet, routes.UsersPath+"/x", nil)
+			w := httptest.NewRecorder()
+			w.Header().Set("X-Request-ID", "req-2")
+			mux.ServeHTTP(w, req)
+
+			Expect(w.Body.String()).To(MatchJSON(
+				`{"code":"VALIDATION_FAILED","message":"Invalid user ID format","field":"id","request_id":"req-2"}`))
+		})
+
+		It("should report a stale If-Match as PRECONDITION_FAILED", func() {
+			w := serve(http.MethodPut, routes.UserByID(existing.ID), `{"email":"taken@example.com","name":"Renamed"}`,
+				map[string]string{"If-Match": `"0"`})
+
+			Expect(w.Code).To(Equal(http.StatusPreconditionFailed))
+			Expect(w.Body.String()).To(MatchJSON(
+				`{"code":"PRECONDITION_FAILED","message":"User does not match the precondition"}`))
+		})
+
+		It("should report an email taken by another user on PUT as EMAIL_ALREADY_EXISTS", func() {
+			w := serve(http.MethodPost, routes.UsersPath, `{"email":"other@example.com","name":"Other User"}`, nil)
+			Expect(w.Code).To(Equal(http.StatusCreated))
+
+			w = serve(http.MethodPut, routes.UserByID(existing.ID), `{"email":"other@example.com","name":"Taken"}`, nil)
+
+			Expect(w.Code).To(Equal(http.StatusConflict))
+			Expect(w.Body.String()).To(MatchJSON(
+				`{"code":"EMAIL_ALREADY_EXISTS","message":"user already exists","field":"email"}`))
+		})
+	})
+
+	Context("behind the body limits middleware", func() {
+		var handler http.Handler
+
+		BeforeEach(func() {
+			userHandler := handlers.NewUserHandler(services.NewUserService(repositories.NewInMemoryUserRepository()))
+			mux = http.NewServeMux()
+			userHandler.RegisterRoutes(mux)
+			handler = middleware.NewBodyLimits(middleware.BodyLimitOptions{
+				MaxBytes:      64,
+				RawBodyRoutes: []string{routes.Pattern(http.MethodPost, routes.UsersImportPath)},
+			}).Middleware(mux)
+		})
+
+		send := func(method, path, contentType string, body io.Reader) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(method, path, body)
+			if contentType != "" {
+				req.Header.Set("Content-Type", contentType)
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			return w
+		}
+
+		It("should reject a missing or wrong content type on every JSON route", func() {
+			for _, route := range handlers.NewUserHandler(nil).Routes() {
+				method, path, _ := strings.Cut(route.Pattern, " ")
+				if method == http.MethodGet || method == http.MethodDelete || path == routes.UsersImportPath {
+					continue
+				}
+
+				path = strings.Replace(path, "{id}", "user_any", 1)
+
+				for _, contentType := range []string{"", "text/plain"} {
+					w := send(method, path, contentType, strings.NewReader(`{}`))
+
+					Expect(w.Code).To(Equal(http.StatusUnsupportedMediaType), route.Pattern+" with "+contentType)
+					Expect(w.Body.String()).To(MatchJSON(
+						`{"code":"UNSUPPORTED_MEDIA_TYPE","message":"Request body must be application/json"}`))
+				}
+			}
+		})
+
+		It("should tell an oversized body from malformed JSON", func() {
+			// A reader hides the length, so the limit trips while decoding.
+			oversized := io.MultiReader(strings.NewReader(`{"email":"` + strings.Repeat("a", 100) + `"}`))
+			w := send(http.MethodPost, routes.UsersPath, "application/json", oversized)
+
+			Expect(w.Code).To(Equal(http.StatusRequestEntityTooLarge))
+			Expect(w.Body.String()).To(MatchJSON(`{"code":"REQUEST_TOO_LARGE","message":"Request body is too large"}`))
+
+			w = send(http.MethodPost, routes.UsersPath, "application/json", strings.NewReader(`{"email":`))
+
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+			Expect(w.Body.String()).To(MatchJSON(`{"code":"INVALID_REQUEST_BODY","message":"Invalid request body"}`))
+		})
+	})
+
+	Context("with a failing repository", func() {
+		var logs bytes.Buffer
+
+		BeforeEach(func() {
+			userService := services.NewUserService(brokenLookupRepository{
+				UserRepository: repositories.NewInMemoryUserRepository(),
+			})
+			mux = http.NewServeMux()
+			handlers.NewUserHandler(userService).RegisterRoutes(mux)
+
+			logs.Reset()
+			log.SetOutput(&logs)
+			DeferCleanup(log.SetOutput, os.Stderr)
+		})
+
+		It("should hide the cause and log it under the echoed correlation ID", func() {
+			w := serve(http.MethodGet, routes.UsersPath+"/user_any", "", nil)
+
+			Expect(w.Code).To(Equal(http.StatusInternalServerError))
+
+			correlationID := w.Header().Get(handlers.CorrelationIDHeader)
+			Expect(correlationID).ToNot(BeEmpty())
+			Expect(w.Body.String()).To(MatchJSON(
+				`{"code":"INTERNAL_ERROR","message":"Internal server error","correlation_id":"` + correlationID + `"}`))
+			Expect(w.Body.String()).ToNot(ContainSubstring("password"))
+
+			Expect(logs.String()).To(ContainSubstring(correlationID))
+			Expect(logs.String()).To(ContainSubstring("password authentication failed"))
+		})
+
+		It("should give every failure its own correlation ID", func() {
+			first := serve(http.MethodGet, routes.UsersPath+"/user_any", "", nil)
+			second := serve(http.MethodGet, routes.UsersPath+"/user_any", "", nil)
+
+			Expect(first.Header().Get(handlers.CorrelationIDHeader)).
+				ToNot(Equal(second.Header().Get(handlers.CorrelationIDHeader)))
+		})
+
+		It("should use the request ID as the correlation ID", func() {
+			req := httptest.NewRequest(http.MethodGet, routes.UsersPath+"/user_any", nil)
+			w := httptest.NewRecorder()
+			// The request ID middleware sets the header before the handler runs.
+			w.Header().Set("X-Request-ID", "req-1")
+			mux.ServeHTTP(w, req)
+
+			Expect(w.Header().Get(handlers.CorrelationIDHeader)).To(Equal("req-1"))
+			Expect(w.Body.String()).To(MatchJSON(
+				`{"code":"INTERNAL_ERROR","message":"Internal server error",` +
+					`"correlation_id":"req-1","request_id":"req-1"}`))
+		})
+	})
+})
@@ -0,0 +1,229 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json/v2"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/application/handlers"
+	"github.com/LarsArtmann/template-arch-lint/internal/application/routes"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/clock"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/entities"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/repositories"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/services"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/values"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// readBarrierRepository holds the first FindByID calls after arm(n) until
+// n of them have arrived, so concurrent requests all read the same user.
+type readBarrierRepository struct {
+	repositories.UserRepository
+
+	held    atomic.Int64
+	arrived sync.WaitGroup
+}
+
+func (r *readBarrierRepository) arm(n int) {
+	r.arrived.Add(n)
+	r.held.Store(int64(n))
+}
+
+func (r *readBarrierRepository) FindByID(ctx context.Context, id values.UserID) (*entities.User, error) {
+	user, err := r.UserRepository.FindByID(ctx, id)
+
+	if r.held.Add(-1) >= 0 {
+		r.arrived.Done()
+		r.arrived.Wait()
+	}
+
+	return user, err
+}
+
+var _ = Describe("PUT /api/v1/users/{id}", func() {
+	var (
+		userService *services.UserService
+		userHandler *handlers.UserHandler
+		mux         *http.ServeMux
+		userID      values.UserID
+	)
+
+	BeforeEach(func() {
+		userService = services.NewUserService(repositories.NewInMemoryUserRepository())
+		userHandler = handlers.NewUserHandler(userService)
+		mux = http.NewServeMux()
+		userHandler.RegisterRoutes(mux)
+		userID = values.MustGenerateUserID()
+	})
+
+	put := func(id values.UserID, body string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, routes.UserByID(id), strings.NewReader(body))
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		return w
+	}
+
+	decode := func(w *httptest.ResponseRecorder) map[string]any {
+		var out map[string]any
+		Expect(json.Unmarshal(w.Body.Bytes(), &out)).To(Succeed())
+
+		return out
+	}
+
+	Context("with PUT-create disabled", func() {
+		It("should keep returning 404 for unknown users", func() {
+			w := put(userID, `{"email":"new@example.com","name":"New User"}`, nil)
+
+			Expect(w.Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("should create when the request sends If-None-Match: *", func() {
+			headers := map[string]string{"If-None-Match": "*"}
+
+			w := put(userID, `{"email":"new@example.com","name":"New User"}`, headers)
+			Expect(w.Code).To(Equal(http.StatusCreated))
+
+			w = put(userID, `{"email":"new@example.com","name":"New User"}`, headers)
+			Expect(w.Code).To(Equal(http.StatusPreconditionFailed))
+		})
+	})
+
+	Context("with PUT-create enabled", func() {
+		BeforeEach(func() {
+			userHandler.WithPutCreate(true)
+		})
+
+		It("should create an absent user with 201 and Location", func() {
+			w := put(userID, `{"email":"new@example.com","name":"New User"}`, nil)
+
+			Expect(w.Code).To(Equal(http.StatusCreated))
+			Expect(w.Header().Get("Location")).To(Equal(routes.UserByID(userID)))
+			Expect(w.Header().Get("ETag")).ToNot(BeEmpty())
+			Expect(decode(w)["id"]).To(Equal(userID.String()))
+
+			stored, err := userService.GetUser(context.Background(), userID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stored.GetEmail().String()).To(Equal("new@example.com"))
+		})
+
+		It("should replace an existing user with 200", func() {
+			Expect(put(userID, `{"email":"old@example.com","name":"Old Name"}`, nil).Code).
+				To(Equal(http.StatusCreated))
+
+			w := put(userID, `{"email":"replaced@example.com","name":"Replaced Name"}`, nil)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Header().Get("Location")).To(BeEmpty())
+			Expect(decode(w)).To(HaveKeyWithValue("email", "replaced@example.com"))
+			Expect(decode(w)).To(HaveKeyWithValue("name", "Replaced Name"))
+		})
+
+		It("should reject a replacement that leaves out a field", func() {
+			Expect(put(userID, `{"email":"old@example.com","name":"Old Name"}`, nil).Code).
+				To(Equal(http.StatusCreated))
+
+			w := put(userID, `{"email":"old@example.com"}`, nil)
+
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("should apply the email uniqueness policy on create", func() {
+			Expect(put(userID, `{"email":"taken@example.com","name":"First User"}`, nil).Code).
+				To(Equal(http.StatusCreated))
+
+			w := put(values.MustGenerateUserID(), `{"email":"taken@example.com","name":"Second User"}`, nil)
+
+			Expect(w.Code).To(Equal(http.StatusConflict))
+		})
+
+		It("should guard replacement with If-Match", func() {
+			created := put(userID, `{"email":"old@example.com","name":"Old Name"}`, nil)
+			etag := created.Header().Get("ETag")
+
+			stale := put(userID, `{"email":"a@example.com","name":"Name A"}`, map[string]string{"If-Match": `"0"`})
+			Expect(stale.Code).To(Equal(http.StatusPreconditionFailed))
+
+			fresh := put(userID, `{"email":"a@example.com","name":"Name A"}`, map[string]string{"If-Match": etag})
+			Expect(fresh.Code).To(Equal(http.StatusOK))
+
+			reused := put(userID, `{"email":"b@example.com","name":"Name B"}`, map[string]string{"If-Match": etag})
+			Expect(reused.Code).To(Equal(http.StatusPreconditionFailed))
+		})
+
+		It("should give every revision its own ETag under a frozen clock", func() {
+			frozen := clock.NewFake(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+			userService = services.NewUserService(repositories.NewInMemoryUserRepositoryWithClock(frozen)).
+				WithClock(frozen)
+			mux = http.NewServeMux()
+			handlers.NewUserHandler(userService).WithPutCreate(true).RegisterRoutes(mux)
+
+			created := put(userID, `{"email":"old@example.com","name":"Old Name"}`, nil)
+			first := created.Header().Get("ETag")
+
+			replaced := put(userID, `{"email":"a@example.com","name":"Name A"}`, map[string]string{"If-Match": first})
+			Expect(replaced.Code).To(Equal(http.StatusOK))
+			Expect(replaced.Header().Get("ETag")).ToNot(Equal(first))
+
+			stale := put(userID, `{"email":"b@example.com","name":"Name B"}`, map[string]string{"If-Match": first})
+			Expect(stale.Code).To(Equal(http.StatusPreconditionFailed))
+		})
+
+		It("should let only one of two concurrent PUTs with the same If-Match through", func() {
+			barrier := &readBarrierRepository{ //nolint:exhaustruct // unarmed until arm
+				UserRepository: repositories.NewInMemoryUserRepository(),
+			}
+			mux = http.NewServeMux()
+			handlers.NewUserHandler(services.NewUserService(barrier)).WithPutCreate(true).RegisterRoutes(mux)
+
+			etag := put(userID, `{"email":"old@example.com","name":"Old Name"}`, nil).Header().Get("ETag")
+
+			// Both requests pass the If-Match check before either saves.
+			barrier.arm(2)
+
+			var (
+				wg    sync.WaitGroup
+				start = make(chan struct{})
+				codes = make(chan int, 2)
+			)
+
+			for _, name := range []string{"Writer One", "Writer Two"} {
+				wg.Go(func() {
+					defer GinkgoRecover()
+
+					<-start
+
+					body := `{"email":"old@example.com","name":"` + name + `"}`
+					codes <- put(userID, body, map[string]string{"If-Match": etag}).Code
+				})
+			}
+
+			close(start)
+			wg.Wait()
+			close(codes)
+
+			var got []int
+			for code := range codes {
+				got = append(got, code)
+			}
+
+			Expect(got).To(ConsistOf(http.StatusOK, http.StatusPreconditionFailed))
+		})
+
+		It("should not create through If-Match", func() {
+			w := put(userID, `{"email":"new@example.com","name":"New User"}`, map[string]string{"If-Match": "*"})
+
+			Expect(w.Code).To(Equal(http.StatusPreconditionFailed))
+		})
+	})
+})
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"charm.land/log/v2"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/clock"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/services"
+	pkgerrors "github.com/LarsArtmann/template-arch-lint/pkg/errors"
+)
+
+const (
+	// importReadTimeout is how long the client may take to send the next
+	// part of an import body. Reads extend the server's read deadline by
+	// it, so a large import is not cut off by ReadTimeout.
+	importReadTimeout = 30 * time.Second
+	// importExtendEvery throttles the deadline updates of a fast upload.
+	importExtendEvery = time.Second
+)
+
+// importUserRecord is one line of an NDJSON user import.
+type importUserRecord struct {
+	Email string `json:"email"`
+	Name  string `json:"name"`
+}
+
+// ImportUsers streams an NDJSON body of {"email","name"} records into the
+// user service in fixed-size chunks, so memory use does not grow with the
+// payload. Bad records are reported by line number and skipped. The summary
+// is written once the stream ends, with 413 when the record cap was hit.
+// The read and write deadlines move along with the upload, so neither
+// server timeout ends an import that keeps making progress.
+func (h *UserHandler) ImportUsers(w http.ResponseWriter, r *http.Request) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != ContentTypeNDJSON {
+		errorResponse(w, http.StatusUnsupportedMediaType, pkgerrors.APICodeUnsupportedMediaType,
+			"Import expects "+ContentTypeNDJSON)
+
+		return
+	}
+
+	controller := http.NewResponseController(w)
+	body := &deadlineReader{Reader: r.Body, controller: controller, extended: time.Time{}}
+	summary, err := streamNDJSON(r.Context(), body, h.importLimits, h.importChunk)
+
+	// The write deadline still counts from the start of the request.
+	_ = controller.SetWriteDeadline(clock.System{}.Now().Add(importReadTimeout))
+
+	switch {
+	case err == nil:
+		writeJSON(w, http.StatusOK, summary)
+	case errors.Is(err, ErrRecordLimitExceeded):
+		writeJSON(w, http.StatusRequestEntityTooLarge, summary)
+	case r.Context().Err() != nil:
+		log.FromContext(r.Context()).Warn("User import aborted", "received", summary.Received, "error", err)
+	default:
+		log.FromContext(r.Context()).Error("User import failed", "received", summary.Received, "error", err)
+		errorResponse(w, http.StatusBadRequest, pkgerrors.APICodeInvalidRequestBody, "Failed to read import body")
+	}
+}
+
+func (h *UserHandler) importChunk(ctx context.Context, records []importUserRecord) []error {
+	batch := make([]services.UserFields, len(records))
+	for i, record := range records {
+		batch[i] = services.UserFields{Email: record.Email, Name: record.Name}
+	}
+
+	return h.userService.ImportUsers(ctx, batch)
+}
+
+// deadlineReader extends the read deadline of the connection by
+// importReadTimeout as the body is read. Writers without deadlines, such as
+// test recorders, are left alone. The connection counts in wall time, so
+// the deadline ignores the handler clock.
+type deadlineReader struct {
+	io.Reader
+
+	controller *http.ResponseController
+	extended   time.Time
+}
+
+func (d *deadlineReader) Read(p []byte) (int, error) {
+	now := clock.System{}.Now()
+	if now.Sub(d.extended) >= importExtendEvery {
+		_ = d.controller.SetReadDeadline(now.Add(importReadTimeout))
+		d.extended = now
+	}
+
+	return d.Reader.Read(p)
+}
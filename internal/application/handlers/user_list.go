@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/repositories"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/services"
+	pkgerrors "github.com/LarsArtmann/template-arch-lint/pkg/errors"
+)
+
+// Defaults of GET /api/v1/users when a query parameter is absent; the
+// default sort is repositories.DefaultUserSort.
+const (
+	defaultUserListPage     = 1
+	defaultUserListPageSize = 20
+)
+
+// userListParams are the query parameters GET /api/v1/users accepts.
+var userListParams = []string{"page", "page_size", "domain", "active", "sort"}
+
+// userListQuery is a validated GET /api/v1/users request.
+type userListQuery struct {
+	page     int
+	pageSize int
+	filters  services.UserFilters
+	sort     repositories.UserSort
+}
+
+// ListUsers serves GET /api/v1/users. The query parameters are:
+//
+//   - page: page number, from 1; default 1
+//   - page_size: users per page, 1 to 100; default 20
+//   - domain: only users whose email domain is exactly this
+//   - active: true or false; absent lists both
+//   - sort: created, modified, email or name, optionally followed by
+//     :asc or :desc; default created:desc
+//
+// Any other parameter, a repeated parameter or an invalid value is
+// rejected with 400 VALIDATION_FAILED naming the parameter as the field.
+// Filtering, sorting and paging run in the repository.
+func (h *UserHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	query, err := parseUserListQuery(r)
+	if err != nil {
+		RespondError(w, r, err)
+
+		return
+	}
+
+	page, err := h.userService.ListUsersPage(r.Context(), repositories.UserPageQuery{
+		Spec:   query.filters.Specification(h.clock.Now()),
+		Sort:   query.sort,
+		Offset: (query.page - 1) * query.pageSize,
+		Limit:  query.pageSize,
+	})
+	if err != nil {
+		RespondError(w, r, err)
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK,
+		NewNumberedPage(ToUserResponses(page.Users), query.page, query.pageSize, page.Total, query.echoedFilters()))
+}
+
+// echoedFilters returns the filters the request applied, as the page
+// envelope echoes them.
+func (q userListQuery) echoedFilters() map[string]string {
+	filters := map[string]string{}
+
+	if q.filters.Domain != nil {
+		filters["domain"] = *q.filters.Domain
+	}
+
+	if q.filters.Active != nil {
+		filters["active"] = strconv.FormatBool(*q.filters.Active)
+	}
+
+	return filters
+}
+
+func parseUserListQuery(r *http.Request) (userListQuery, error) {
+	params := r.URL.Query()
+
+	for name, given := range params {
+		if !slices.Contains(userListParams, name) {
+			return userListQuery{}, pkgerrors.NewValidationError(name, "unknown query parameter")
+		}
+
+		if len(given) > 1 {
+			return userListQuery{}, pkgerrors.NewValidationError(name, "query parameter given more than once")
+		}
+	}
+
+	query := userListQuery{
+		page:     defaultUserListPage,
+		pageSize: defaultUserListPageSize,
+		filters:  services.UserFilters{Domain: nil, Active: nil},
+		sort:     repositories.DefaultUserSort,
+	}
+
+	if params.Has("page_size") {
+		pageSize, err := strconv.Atoi(params.Get("page_size"))
+		if err != nil || pageSize < 1 || pageSize > maxPageLimit {
+			return userListQuery{}, pkgerrors.NewValidationError("page_size",
+				"page_size must be an integer from 1 to "+strconv.Itoa(maxPageLimit))
+		}
+
+		query.pageSize = pageSize
+	}
+
+	if params.Has("page") {
+		page, err := strconv.Atoi(params.Get("page"))
+		if err != nil || page < 1 || page-1 > math.MaxInt/query.pageSize {
+			return userListQuery{}, pkgerrors.NewValidationError("page", "page must be a positive integer")
+		}
+
+		query.page = page
+	}
+
+	if params.Has("domain") {
+		domain := params.Get("domain")
+		if domain == "" || strings.Contains(domain, "@") {
+			return userListQuery{}, pkgerrors.NewValidationError("domain", "domain must be an email domain")
+		}
+
+		query.filters.Domain = &domain
+	}
+
+	if params.Has("active") {
+		value := params.Get("active")
+		if value != "true" && value != "false" {
+			return userListQuery{}, pkgerrors.NewValidationError("active", "active must be true or false")
+		}
+
+		active := value == "true"
+		query.filters.Active = &active
+	}
+
+	if params.Has("sort") {
+		sort, err := repositories.ParseUserSort(params.Get("sort"))
+		if err != nil {
+			return userListQuery{}, err
+		}
+
+		query.sort = sort
+	}
+
+	return query, nil
+}
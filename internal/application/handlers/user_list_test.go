@@ -0,0 +1,203 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json/v2"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/application/handlers"
+	"github.com/LarsArtmann/template-arch-lint/internal/application/routes"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/entities"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/repositories"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/services"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/values"
+	pkgerrors "github.com/LarsArtmann/template-arch-lint/pkg/errors"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type listResponse struct {
+	Data []struct {
+		Email string `json:"email"`
+	} `json:"data"`
+	Pagination handlers.PageMeta `json:"pagination"`
+	Filters    map[string]string `json:"filters"`
+}
+
+// numberedMeta is the pagination of page number page out of total users
+// in pages of pageSize.
+func numberedMeta(page, pageSize, total, totalPages int) handlers.PageMeta {
+	offset := (page - 1) * pageSize
+
+	return handlers.PageMeta{
+		Limit:      pageSize,
+		Offset:     &offset,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: &totalPages,
+		Cursor:     "",
+		NextCursor: "",
+		Total:      &total,
+		HasMore:    page < totalPages,
+	}
+}
+
+var _ = Describe("GET /api/v1/users", func() {
+	var mux *http.ServeMux
+
+	BeforeEach(func() {
+		ctx := context.Background()
+		now := time.Now()
+		userRepo := repositories.NewInMemoryUserRepository()
+
+		for _, seed := range []struct {
+			email, name       string
+			created, modified time.Duration
+		}{
+			{email: "alice@example.com", name: "Alice", created: 24 * time.Hour, modified: 24 * time.Hour},
+			{email: "bob@example.com", name: "Bob", created: 40 * 24 * time.Hour, modified: 2 * time.Hour},
+			{email: "carol@corp.com", name: "Carol", created: 48 * time.Hour, modified: 72 * time.Hour},
+			{email: "dave@example.com", name: "Dave", created: 60 * 24 * time.Hour, modified: 240 * time.Hour},
+		} {
+			user, err := entities.NewUser(values.MustGenerateUserID(), seed.email, seed.name, time.Now())
+			Expect(err).ToNot(HaveOccurred())
+
+			user.Created = now.Add(-seed.created)
+			user.Modified = now.Add(-seed.modified)
+			Expect(userRepo.Save(ctx, user)).To(Succeed())
+		}
+
+		mux = http.NewServeMux()
+		handlers.NewUserHandler(services.NewUserService(userRepo)).RegisterRoutes(mux)
+	})
+
+	list := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, routes.UsersPath+"?"+query, nil))
+
+		return w
+	}
+
+	decode := func(w *httptest.ResponseRecorder) listResponse {
+		var out listResponse
+		Expect(json.Unmarshal(w.Body.Bytes(), &out)).To(Succeed())
+
+		return out
+	}
+
+	emails := func(response listResponse) []string {
+		result := make([]string, 0, len(response.Data))
+		for _, user := range response.Data {
+			result = append(result, user.Email)
+		}
+
+		return result
+	}
+
+	DescribeTable("query parameters",
+		func(query string, wantEmails []string, wantMeta handlers.PageMeta) {
+			w := list(query)
+			Expect(w.Code).To(Equal(http.StatusOK))
+
+			response := decode(w)
+			Expect(emails(response)).To(Equal(wantEmails))
+			Expect(response.Pagination).To(Equal(wantMeta))
+		},
+		Entry("defaults to newest first", "",
+			[]string{"alice@example.com", "carol@corp.com", "bob@example.com", "dave@example.com"},
+			numberedMeta(1, 20, 4, 1)),
+		Entry("sort=created", "sort=created",
+			[]string{"dave@example.com", "bob@example.com", "carol@corp.com", "alice@example.com"},
+			numberedMeta(1, 20, 4, 1)),
+		Entry("sort=modified:desc", "sort=modified:desc",
+			[]string{"bob@example.com", "alice@example.com", "carol@corp.com", "dave@example.com"},
+			numberedMeta(1, 20, 4, 1)),
+		Entry("sort=modified:asc", "sort=modified:asc",
+			[]string{"dave@example.com", "carol@corp.com", "alice@example.com", "bob@example.com"},
+			numberedMeta(1, 20, 4, 1)),
+		Entry("sort=email:asc", "sort=email:asc",
+			[]string{"alice@example.com", "bob@example.com", "carol@corp.com", "dave@example.com"},
+			numberedMeta(1, 20, 4, 1)),
+		Entry("sort=email:desc", "sort=email:desc",
+			[]string{"dave@example.com", "carol@corp.com", "bob@example.com", "alice@example.com"},
+			numberedMeta(1, 20, 4, 1)),
+		Entry("sort=name", "sort=name",
+			[]string{"alice@example.com", "bob@example.com", "carol@corp.com", "dave@example.com"},
+			numberedMeta(1, 20, 4, 1)),
+		Entry("sort=name:desc", "sort=name:desc",
+			[]string{"dave@example.com", "carol@corp.com", "bob@example.com", "alice@example.com"},
+			numberedMeta(1, 20, 4, 1)),
+		Entry("domain", "domain=example.com",
+			[]string{"alice@example.com", "bob@example.com", "dave@example.com"},
+			numberedMeta(1, 20, 3, 1)),
+		Entry("active=true", "active=true",
+			[]string{"alice@example.com", "carol@corp.com"},
+			numberedMeta(1, 20, 2, 1)),
+		Entry("active=false", "active=false",
+			[]string{"bob@example.com", "dave@example.com"},
+			numberedMeta(1, 20, 2, 1)),
+		Entry("page and page_size", "page=2&page_size=3",
+			[]string{"dave@example.com"},
+			numberedMeta(2, 3, 4, 2)),
+		Entry("page_size with sort", "page_size=2&sort=email",
+			[]string{"alice@example.com", "bob@example.com"},
+			numberedMeta(1, 2, 4, 2)),
+		Entry("every parameter", "page=1&page_size=20&domain=example.com&active=true&sort=created:desc",
+			[]string{"alice@example.com"},
+			numberedMeta(1, 20, 1, 1)),
+		Entry("domain and active=false sorted by name", "domain=example.com&active=false&sort=name:desc",
+			[]string{"dave@example.com", "bob@example.com"},
+			numberedMeta(1, 20, 2, 1)),
+		Entry("page past the end", "page=3&page_size=2",
+			[]string{},
+			numberedMeta(3, 2, 4, 2)),
+	)
+
+	It("should return an empty data array when nothing matches", func() {
+		w := list("domain=nowhere.org")
+		Expect(w.Code).To(Equal(http.StatusOK))
+
+		var raw map[string]any
+		Expect(json.Unmarshal(w.Body.Bytes(), &raw)).To(Succeed())
+		Expect(raw["data"]).To(Equal([]any{}))
+		Expect(raw["pagination"]).To(Equal(map[string]any{
+			"limit": float64(20), "offset": float64(0), "page": float64(1), "page_size": float64(20),
+			"total": float64(0), "total_pages": float64(0), "has_more": false,
+		}))
+	})
+
+	It("should echo the applied filters", func() {
+		response := decode(list("domain=example.com&active=false&sort=name"))
+
+		Expect(response.Filters).To(Equal(map[string]string{"domain": "example.com", "active": "false"}))
+		Expect(decode(list("")).Filters).To(BeEmpty())
+	})
+
+	DescribeTable("rejected query parameters",
+		func(query, wantField string) {
+			w := list(query)
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+
+			var body pkgerrors.APIError
+			Expect(json.Unmarshal(w.Body.Bytes(), &body)).To(Succeed())
+			Expect(body.Code).To(Equal(pkgerrors.APICodeValidationFailed))
+			Expect(body.Field).To(Equal(wantField))
+			Expect(body.Message).ToNot(BeEmpty())
+		},
+		Entry("unknown sort field", "sort=id", "sort"),
+		Entry("unknown sort direction", "sort=created:up", "sort"),
+		Entry("empty sort", "sort=", "sort"),
+		Entry("page zero", "page=0", "page"),
+		Entry("page not a number", "page=two", "page"),
+		Entry("page overflowing the offset", "page=9223372036854775807&page_size=100", "page"),
+		Entry("page_size zero", "page_size=0", "page_size"),
+		Entry("page_size above the maximum", "page_size=101", "page_size"),
+		Entry("active not a boolean", "active=yes", "active"),
+		Entry("empty domain", "domain=", "domain"),
+		Entry("domain with an @", "domain=a@example.com", "domain"),
+		Entry("unknown parameter", "limit=10", "limit"),
+		Entry("repeated parameter", "page=1&page=2", "page"),
+	)
+})
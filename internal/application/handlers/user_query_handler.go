@@ -1,10 +1,13 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 
+	"github.com/LarsArtmann/template-arch-lint/internal/application/routes"
 	"github.com/LarsArtmann/template-arch-lint/internal/domain/entities"
 	"github.com/LarsArtmann/template-arch-lint/internal/domain/services"
 	"github.com/LarsArtmann/template-arch-lint/internal/domain/values"
@@ -12,6 +15,12 @@ import (
 	"github.com/samber/lo"
 )
 
+// Limits of the q search of GET /api/v1/users/search.
+const (
+	searchMinQueryLength = 2
+	searchDefaultLimit   = 20
+)
+
 type UserQueryHandler struct {
 	userQueryService services.UserQueryService
 }
@@ -22,14 +31,35 @@ func NewUserQueryHandler(userQueryService services.UserQueryService) *UserQueryH
 	}
 }
 
+// Route is one entry of a handler's route table.
+// List marks routes that respond with a Page envelope.
+type Route struct {
+	Pattern string
+	Handler http.HandlerFunc
+	List    bool
+}
+
+// Routes returns the route table served by this handler.
+func (h *UserQueryHandler) Routes() []Route {
+	return []Route{
+		{Pattern: routes.Pattern(http.MethodGet, routes.UserQueryPath), Handler: h.GetUser, List: false},
+		{Pattern: routes.Pattern(http.MethodGet, routes.UsersQueryPath), Handler: h.ListUsers, List: true},
+		{Pattern: routes.Pattern(http.MethodGet, routes.UsersSearchPath), Handler: h.SearchUsers, List: true},
+		{Pattern: routes.Pattern(http.MethodGet, routes.UsersByDomainPath), Handler: h.GetUsersByDomain, List: true},
+		{Pattern: routes.Pattern(http.MethodGet, routes.UsersStatsPath), Handler: h.GetUserStats, List: false},
+		{Pattern: routes.Pattern(http.MethodGet, routes.UsersActivePath), Handler: h.GetActiveUsers, List: true},
+		{
+			Pattern: routes.Pattern(http.MethodGet, routes.UsersPaginatedPath),
+			Handler: h.GetUsersWithPagination,
+			List:    true,
+		},
+	}
+}
+
 func (h *UserQueryHandler) RegisterRoutes(mux *http.ServeMux) {
-	mux.HandleFunc("GET /api/v1/users/query/{id}", h.GetUser)
-	mux.HandleFunc("GET /api/v1/users/query", h.ListUsers)
-	mux.HandleFunc("GET /api/v1/users/search", h.SearchUsers)
-	mux.HandleFunc("GET /api/v1/users/domain/{domain}", h.GetUsersByDomain)
-	mux.HandleFunc("GET /api/v1/users/stats", h.GetUserStats)
-	mux.HandleFunc("GET /api/v1/users/active", h.GetActiveUsers)
-	mux.HandleFunc("GET /api/v1/users/paginated", h.GetUsersWithPagination)
+	for _, route := range h.Routes() {
+		mux.HandleFunc(route.Pattern, route.Handler)
+	}
 }
 
 func (h *UserQueryHandler) GetUser(w http.ResponseWriter, r *http.Request) {
@@ -56,10 +86,17 @@ func (h *UserQueryHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, http.StatusOK, map[string]any{"data": user})
+	writeJSON(w, http.StatusOK, map[string]any{"data": ToUserResponse(user)})
 }
 
 func (h *UserQueryHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	req, err := parsePageRequest(r)
+	if err != nil {
+		RespondError(w, r, err)
+
+		return
+	}
+
 	users, err := h.userQueryService.ListUsers(r.Context())
 	if err != nil {
 		sendErrorResponse(w, http.StatusInternalServerError, "Failed to retrieve users")
@@ -67,10 +104,19 @@ func (h *UserQueryHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, http.StatusOK, map[string]any{"data": users})
+	writeJSON(w, http.StatusOK, NewOffsetPage(ToUserResponses(users), req, nil))
 }
 
+// SearchUsers serves GET /api/v1/users/search. With q it returns the users
+// whose email local part starts with q or whose name contains it, ignoring
+// case, in email order; with email it looks up that exact address.
 func (h *UserQueryHandler) SearchUsers(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Has("q") {
+		h.searchUsersByQuery(w, r)
+
+		return
+	}
+
 	email := r.URL.Query().Get("email")
 	if email == "" {
 		sendErrorResponse(w, http.StatusBadRequest, "Email query parameter is required")
@@ -78,21 +124,80 @@ func (h *UserQueryHandler) SearchUsers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	filters := map[string]string{"email": email}
+
+	req, err := parsePageRequest(r)
+	if err != nil {
+		RespondError(w, r, err)
+
+		return
+	}
+
 	user, err := h.userQueryService.GetUserByEmail(r.Context(), email)
 	if err != nil {
 		_, isNotFound := pkgerrors.AsNotFoundError(err)
 		if isNotFound {
-			writeJSON(w, http.StatusOK, map[string]any{"data": []any{}})
+			writeJSON(w, http.StatusOK, NewOffsetPage([]UserResponse{}, req, filters))
+
+			return
+		}
+
+		if _, isInvalid := pkgerrors.AsValidationError(err); isInvalid {
+			sendErrorResponse(w, http.StatusBadRequest, "Invalid email query parameter")
+
+			return
+		}
+
+		sendErrorResponse(w, http.StatusInternalServerError, "Failed to search users")
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, NewOffsetPage([]UserResponse{ToUserResponse(user)}, req, filters))
+}
+
+// searchUsersByQuery answers ?q=...&limit=... with at most limit users,
+// 20 by default and 100 at most. q must have at least two characters, so
+// that a search never scans for almost every user.
+func (h *UserQueryHandler) searchUsersByQuery(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if utf8.RuneCountInString(query) < searchMinQueryLength {
+		sendErrorResponse(w, http.StatusBadRequest,
+			fmt.Sprintf("Query parameter q must have at least %d characters", searchMinQueryLength))
+
+		return
+	}
+
+	limit := searchDefaultLimit
+
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > maxPageLimit {
+			sendErrorResponse(w, http.StatusBadRequest,
+				fmt.Sprintf("Query parameter limit must be between 1 and %d", maxPageLimit))
 
 			return
 		}
 
+		limit = parsed
+	}
+
+	// The extra user only tells whether there are more matches.
+	users, err := h.userQueryService.SearchUsers(r.Context(), query, limit+1)
+	if err != nil {
 		sendErrorResponse(w, http.StatusInternalServerError, "Failed to search users")
 
 		return
 	}
 
-	writeJSON(w, http.StatusOK, map[string]any{"data": []*entities.User{user}})
+	writeJSON(w, http.StatusOK, Page[UserResponse]{
+		Data: ToUserResponses(users[:min(limit, len(users))]),
+		Pagination: PageMeta{ //nolint:exhaustruct // a search page has no offset, cursor or total
+			Limit:   limit,
+			HasMore: len(users) > limit,
+		},
+		Filters: map[string]string{"q": query},
+	})
 }
 
 func (h *UserQueryHandler) GetUsersByDomain(w http.ResponseWriter, r *http.Request) {
@@ -103,6 +208,13 @@ func (h *UserQueryHandler) GetUsersByDomain(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	req, err := parsePageRequest(r)
+	if err != nil {
+		RespondError(w, r, err)
+
+		return
+	}
+
 	users, err := h.userQueryService.ListUsers(r.Context())
 	if err != nil {
 		sendErrorResponse(w, http.StatusInternalServerError, "Failed to retrieve users")
@@ -121,7 +233,8 @@ func (h *UserQueryHandler) GetUsersByDomain(w http.ResponseWriter, r *http.Reque
 		return false
 	})
 
-	writeJSON(w, http.StatusOK, map[string]any{"data": filteredUsers})
+	writeJSON(w, http.StatusOK,
+		NewOffsetPage(ToUserResponses(filteredUsers), req, map[string]string{"domain": domain}))
 }
 
 func (h *UserQueryHandler) GetUserStats(w http.ResponseWriter, r *http.Request) {
@@ -136,6 +249,13 @@ func (h *UserQueryHandler) GetUserStats(w http.ResponseWriter, r *http.Request)
 }
 
 func (h *UserQueryHandler) GetActiveUsers(w http.ResponseWriter, r *http.Request) {
+	req, err := parsePageRequest(r)
+	if err != nil {
+		RespondError(w, r, err)
+
+		return
+	}
+
 	users, err := h.userQueryService.ListUsers(r.Context())
 	if err != nil {
 		sendErrorResponse(w, http.StatusInternalServerError, "Failed to retrieve active users")
@@ -147,18 +267,16 @@ func (h *UserQueryHandler) GetActiveUsers(w http.ResponseWriter, r *http.Request
 		return true
 	})
 
-	writeJSON(w, http.StatusOK, map[string]any{"data": activeUsers})
+	writeJSON(w, http.StatusOK,
+		NewOffsetPage(ToUserResponses(activeUsers), req, map[string]string{"active": "true"}))
 }
 
 func (h *UserQueryHandler) GetUsersWithPagination(w http.ResponseWriter, r *http.Request) {
-	page, err := strconv.Atoi(r.URL.Query().Get("page"))
-	if err != nil || page < 1 {
-		page = 1
-	}
+	req, err := parsePageRequest(r)
+	if err != nil {
+		RespondError(w, r, err)
 
-	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
-	if err != nil || limit < 1 || limit > 100 {
-		limit = 10
+		return
 	}
 
 	users, err := h.userQueryService.ListUsers(r.Context())
@@ -168,35 +286,5 @@ func (h *UserQueryHandler) GetUsersWithPagination(w http.ResponseWriter, r *http
 		return
 	}
 
-	total := len(users)
-	start := (page - 1) * limit
-	end := start + limit
-
-	if start >= total {
-		writeJSON(w, http.StatusOK, map[string]any{
-			"data": []*entities.User{},
-			"pagination": map[string]any{
-				"page":  page,
-				"limit": limit,
-				"total": total,
-			},
-		})
-
-		return
-	}
-
-	if end > total {
-		end = total
-	}
-
-	paginatedUsers := users[start:end]
-
-	writeJSON(w, http.StatusOK, map[string]any{
-		"data": paginatedUsers,
-		"pagination": map[string]any{
-			"page":  page,
-			"limit": limit,
-			"total": total,
-		},
-	})
+	writeJSON(w, http.StatusOK, NewOffsetPage(ToUserResponses(users), req, nil))
 }
@@ -5,10 +5,13 @@ import (
 	"encoding/json/v2"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strconv"
 	"testing"
 
 	"github.com/LarsArtmann/template-arch-lint/internal/application/handlers"
+	"github.com/LarsArtmann/template-arch-lint/internal/application/routes"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/clock"
 	"github.com/LarsArtmann/template-arch-lint/internal/domain/repositories"
 	"github.com/LarsArtmann/template-arch-lint/internal/domain/services"
 	"github.com/LarsArtmann/template-arch-lint/internal/domain/values"
@@ -34,7 +37,7 @@ var _ = Describe("UserQueryHandler", func() {
 		mux = http.NewServeMux()
 
 		userRepo = repositories.NewInMemoryUserRepository()
-		userQueryService = services.NewUserQueryService(userRepo)
+		userQueryService = services.NewUserQueryService(userRepo, clock.System{})
 		userService = services.NewUserService(userRepo)
 		userQueryHandler = handlers.NewUserQueryHandler(userQueryService)
 
@@ -106,7 +109,7 @@ var _ = Describe("UserQueryHandler", func() {
 			It("should return user with 200 status", func() {
 				userID := createTestUser("test@example.com", "Test User")
 
-				req := httptest.NewRequest(http.MethodGet, "/api/v1/users/query/"+userID, nil)
+				req := httptest.NewRequest(http.MethodGet, routes.UsersQueryPath+"/"+userID, nil)
 				w := httptest.NewRecorder()
 				mux.ServeHTTP(w, req)
 
@@ -124,7 +127,7 @@ var _ = Describe("UserQueryHandler", func() {
 			It("should return 404 status", func() {
 				req := httptest.NewRequest(
 					http.MethodGet,
-					"/api/v1/users/query/non-existent-id",
+					routes.UsersQueryPath+"/non-existent-id",
 					nil,
 				)
 				w := httptest.NewRecorder()
@@ -142,7 +145,7 @@ var _ = Describe("UserQueryHandler", func() {
 
 		Context("when user ID is invalid", func() {
 			It("should return 400 status for invalid characters", func() {
-				expectBadRequestResponse("/api/v1/users/query/invalid@id")
+				expectBadRequestResponse(routes.UsersQueryPath + "/invalid@id")
 			})
 		})
 	})
@@ -153,7 +156,7 @@ var _ = Describe("UserQueryHandler", func() {
 				createTestUser("test1@example.com", "User 1")
 				createTestUser("test2@example.com", "User 2")
 
-				req := httptest.NewRequest(http.MethodGet, "/api/v1/users/query", nil)
+				req := httptest.NewRequest(http.MethodGet, routes.UsersQueryPath, nil)
 				w := httptest.NewRecorder()
 				mux.ServeHTTP(w, req)
 
@@ -172,7 +175,7 @@ var _ = Describe("UserQueryHandler", func() {
 
 		Context("when no users exist", func() {
 			It("should return empty array with 200 status", func() {
-				expectEmptyArrayResponse("/api/v1/users/query")
+				expectEmptyArrayResponse(routes.UsersQueryPath)
 			})
 		})
 	})
@@ -184,7 +187,7 @@ var _ = Describe("UserQueryHandler", func() {
 
 				req := httptest.NewRequest(
 					http.MethodGet,
-					"/api/v1/users/search?email=search@example.com",
+					routes.SearchUsersByEmail("search@example.com"),
 					nil,
 				)
 				w := httptest.NewRecorder()
@@ -205,13 +208,110 @@ var _ = Describe("UserQueryHandler", func() {
 
 		Context("when email parameter is missing", func() {
 			It("should return 400 status", func() {
-				expectBadRequestResponse("/api/v1/users/search")
+				expectBadRequestResponse(routes.UsersSearchPath)
 			})
 		})
 
 		Context("when user does not exist with email", func() {
 			It("should return empty array with 200 status", func() {
-				expectEmptyArrayResponse("/api/v1/users/search?email=nonexistent@example.com")
+				expectEmptyArrayResponse(routes.SearchUsersByEmail("nonexistent@example.com"))
+			})
+		})
+
+		Context("when email parameter is not a valid email", func() {
+			It("should return 400 status", func() {
+				expectBadRequestResponse(routes.SearchUsersByEmail("not-an-email"))
+			})
+		})
+
+		Context("when searching with q", func() {
+			search := func(query url.Values) (int, map[string]any) {
+				req := httptest.NewRequest(http.MethodGet, routes.UsersSearchPath+"?"+query.Encode(), nil)
+				w := httptest.NewRecorder()
+				mux.ServeHTTP(w, req)
+
+				var response map[string]any
+				if w.Code == http.StatusOK {
+					Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+				}
+
+				return w.Code, response
+			}
+
+			emailsOf := func(response map[string]any) []string {
+				var emails []string
+				for _, item := range response["data"].([]any) {
+					emails = append(emails, item.(map[string]any)["email"].(string))
+				}
+
+				return emails
+			}
+
+			It("should match email prefixes and names ignoring case", func() {
+				createTestUser("alice.smith@example.com", "Alice Smith")
+				createTestUser("bob@example.com", "Bob Alison")
+				createTestUser("carol@example.com", "Carol")
+				createTestUser("dave@example.com", "Dave Mali")
+
+				code, response := search(url.Values{"q": {"ALI"}})
+
+				Expect(code).To(Equal(http.StatusOK))
+				Expect(emailsOf(response)).To(Equal([]string{
+					"alice.smith@example.com", "bob@example.com", "dave@example.com",
+				}))
+				Expect(response["filters"]).To(Equal(map[string]any{"q": "ALI"}))
+			})
+
+			It("should treat wildcards literally", func() {
+				createTestUser("a_b@example.com", "First")
+				createTestUser("axb@example.com", "Second")
+
+				code, response := search(url.Values{"q": {"a_b"}})
+
+				Expect(code).To(Equal(http.StatusOK))
+				Expect(emailsOf(response)).To(Equal([]string{"a_b@example.com"}))
+
+				code, response = search(url.Values{"q": {"%b"}})
+
+				Expect(code).To(Equal(http.StatusOK))
+				Expect(response["data"]).To(BeEmpty())
+			})
+
+			It("should return at most limit users and report more", func() {
+				for i := 1; i <= 5; i++ {
+					createTestUser("match"+strconv.Itoa(i)+"@example.com", "Match "+strconv.Itoa(i))
+				}
+
+				code, response := search(url.Values{"q": {"match"}, "limit": {"2"}})
+
+				Expect(code).To(Equal(http.StatusOK))
+				Expect(emailsOf(response)).To(Equal([]string{"match1@example.com", "match2@example.com"}))
+				Expect(response["pagination"]).To(HaveKeyWithValue("limit", BeNumerically("==", 2)))
+				Expect(response["pagination"]).To(HaveKeyWithValue("has_more", BeTrue()))
+
+				code, response = search(url.Values{"q": {"match"}})
+
+				Expect(code).To(Equal(http.StatusOK))
+				Expect(response["data"]).To(HaveLen(5))
+				Expect(response["pagination"]).To(HaveKeyWithValue("limit", BeNumerically("==", 20)))
+				Expect(response["pagination"]).To(HaveKeyWithValue("has_more", BeFalse()))
+			})
+
+			It("should return an empty array when nothing matches", func() {
+				createTestUser("search@example.com", "Search User")
+
+				expectEmptyArrayResponse(routes.UsersSearchPath + "?q=nobody")
+			})
+
+			It("should reject queries shorter than two characters", func() {
+				expectBadRequestResponse(routes.UsersSearchPath + "?q=a")
+				expectBadRequestResponse(routes.UsersSearchPath + "?q=" + url.QueryEscape(" a "))
+			})
+
+			It("should reject a limit outside 1 to 100", func() {
+				expectBadRequestResponse(routes.UsersSearchPath + "?q=ab&limit=101")
+				expectBadRequestResponse(routes.UsersSearchPath + "?q=ab&limit=0")
+				expectBadRequestResponse(routes.UsersSearchPath + "?q=ab&limit=many")
 			})
 		})
 	})
@@ -225,7 +325,7 @@ var _ = Describe("UserQueryHandler", func() {
 
 				req := httptest.NewRequest(
 					http.MethodGet,
-					"/api/v1/users/paginated?page=1&limit=3",
+					routes.UsersPaginatedPath+"?page=1&limit=3",
 					nil,
 				)
 				w := httptest.NewRecorder()
@@ -244,15 +344,16 @@ var _ = Describe("UserQueryHandler", func() {
 				pagination := response["pagination"].(map[string]any)
 
 				Expect(data).To(HaveLen(3))
-				Expect(pagination["page"]).To(Equal(float64(1)))
+				Expect(pagination["offset"]).To(Equal(float64(0)))
 				Expect(pagination["limit"]).To(Equal(float64(3)))
 				Expect(pagination["total"]).To(BeNumerically(">=", 5))
+				Expect(pagination["has_more"]).To(BeTrue())
 			})
 		})
 
 		Context("with default pagination parameters", func() {
 			It("should use default values", func() {
-				req := httptest.NewRequest(http.MethodGet, "/api/v1/users/paginated", nil)
+				req := httptest.NewRequest(http.MethodGet, routes.UsersPaginatedPath, nil)
 				w := httptest.NewRecorder()
 				mux.ServeHTTP(w, req)
 
@@ -265,7 +366,7 @@ var _ = Describe("UserQueryHandler", func() {
 				Expect(response).To(HaveKey("pagination"))
 
 				pagination := response["pagination"].(map[string]any)
-				Expect(pagination["page"]).To(Equal(float64(1)))
+				Expect(pagination["offset"]).To(Equal(float64(0)))
 				Expect(pagination["limit"]).To(Equal(float64(10)))
 			})
 		})
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/entities"
+)
+
+// UserResponse is how every user endpoint represents a user. Its JSON
+// fields are the API contract, so the entity can change without clients
+// noticing; handlers never write an entities.User directly.
+type UserResponse struct {
+	ID         string    `json:"id"`
+	Email      string    `json:"email"`
+	Name       string    `json:"name"`
+	CreatedAt  time.Time `json:"created_at"`
+	ModifiedAt time.Time `json:"modified_at"`
+}
+
+// ToUserResponse maps user to its response. Times are in UTC and cut to
+// whole seconds, so they encode as plain RFC 3339 without fractions.
+func ToUserResponse(user *entities.User) UserResponse {
+	return UserResponse{
+		ID:         user.ID.String(),
+		Email:      user.GetEmail().String(),
+		Name:       user.GetUserName().String(),
+		CreatedAt:  user.Created.UTC().Truncate(time.Second),
+		ModifiedAt: user.Modified.UTC().Truncate(time.Second),
+	}
+}
+
+// ToUserResponses maps users in order; nil maps to an empty slice, so
+// lists encode as [] rather than null.
+func ToUserResponses(users []*entities.User) []UserResponse {
+	responses := make([]UserResponse, 0, len(users))
+	for _, user := range users {
+		responses = append(responses, ToUserResponse(user))
+	}
+
+	return responses
+}
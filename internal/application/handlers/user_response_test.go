@@ -0,0 +1,149 @@
+package handlers_test
+
+import (
+	"encoding/json/v2"
+	"go/ast"
+	"go/types"
+	"time"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/application/handlers"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/entities"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/ids"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"golang.org/x/tools/go/packages"
+)
+
+const entitiesPath = "github.com/LarsArtmann/template-arch-lint/internal/domain/entities"
+
+var _ = Describe("UserResponse", func() {
+	It("should encode a user as the documented JSON", func() {
+		id, err := ids.NewUserID("user-42")
+		Expect(err).ToNot(HaveOccurred())
+		created := time.Date(2026, time.March, 1, 9, 30, 0, 123, time.FixedZone("CET", 3600))
+		user, err := entities.NewUser(id, "Jane.Doe@example.com", "Jane Doe", created)
+		Expect(err).ToNot(HaveOccurred())
+
+		user.Modified = time.Date(2026, time.April, 2, 10, 0, 0, 0, time.UTC)
+		user.Version = 3
+
+		data, err := json.Marshal(handlers.ToUserResponse(user))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(Equal(`{"id":"user-42","email":"Jane.Doe@example.com","name":"Jane Doe",` +
+			`"created_at":"2026-03-01T08:30:00Z","modified_at":"2026-04-02T10:00:00Z"}`))
+	})
+
+	It("should encode no users as an empty list", func() {
+		data, err := json.Marshal(handlers.ToUserResponses(nil))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(Equal("[]"))
+	})
+
+	It("should be the only user representation handlers write", func() {
+		pkgs, err := packages.Load(&packages.Config{ //nolint:exhaustruct // defaults load the package in this directory
+			Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax | packages.NeedTypes |
+				packages.NeedTypesInfo,
+		}, ".")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pkgs).To(HaveLen(1))
+		Expect(pkgs[0].Errors).To(BeEmpty())
+
+		pkg := pkgs[0]
+		calls := 0
+
+		for _, file := range pkg.Syntax {
+			ast.Inspect(file, func(node ast.Node) bool {
+				call, ok := node.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+
+				if fun, ok := call.Fun.(*ast.Ident); !ok || fun.Name != "writeJSON" {
+					return true
+				}
+
+				calls++
+				body := call.Args[len(call.Args)-1]
+
+				Expect(writesEntity(pkg.TypesInfo, pkg.Types, body)).To(BeFalse(),
+					"writeJSON at %s writes an entities.User", pkg.Fset.Position(call.Pos()))
+
+				return true
+			})
+		}
+
+		Expect(calls).To(BeNumerically(">", 10), "writeJSON calls found")
+	})
+})
+
+// writesEntity reports whether encoding expr would write an entities.User:
+// its type holds one, or a composite literal it builds, such as a map[string]any
+// envelope, has an element that does. Calls are judged by their result only,
+// so mapping a user to a UserResponse passes.
+func writesEntity(info *types.Info, pkg *types.Package, expr ast.Expr) bool {
+	if holdsEntity(info.TypeOf(expr), pkg, map[types.Type]bool{}) {
+		return true
+	}
+
+	literal, ok := ast.Unparen(expr).(*ast.CompositeLit)
+	if !ok {
+		return false
+	}
+
+	for _, elt := range literal.Elts {
+		if pair, ok := elt.(*ast.KeyValueExpr); ok {
+			elt = pair.Value
+		}
+
+		if writesEntity(info, pkg, elt) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// holdsEntity reports whether a value of typ can contain an entities.User.
+// Named types of other packages are opaque apart from their type arguments;
+// the handler package's own types are followed into their fields.
+func holdsEntity(typ types.Type, pkg *types.Package, seen map[types.Type]bool) bool {
+	if typ == nil || seen[typ] {
+		return false
+	}
+
+	seen[typ] = true
+
+	switch typ := typ.(type) {
+	case *types.Named:
+		obj := typ.Obj()
+		if obj.Pkg() != nil && obj.Pkg().Path() == entitiesPath && obj.Name() == "User" {
+			return true
+		}
+
+		for arg := range typ.TypeArgs().Types() {
+			if holdsEntity(arg, pkg, seen) {
+				return true
+			}
+		}
+
+		return obj.Pkg() == pkg && holdsEntity(typ.Underlying(), pkg, seen)
+	case *types.Pointer:
+		return holdsEntity(typ.Elem(), pkg, seen)
+	case *types.Slice:
+		return holdsEntity(typ.Elem(), pkg, seen)
+	case *types.Array:
+		return holdsEntity(typ.Elem(), pkg, seen)
+	case *types.Map:
+		return holdsEntity(typ.Key(), pkg, seen) || holdsEntity(typ.Elem(), pkg, seen)
+	case *types.Struct:
+		for field := range typ.Fields() {
+			if holdsEntity(field.Type(), pkg, seen) {
+				return true
+			}
+		}
+
+		return false
+	default:
+		return false
+	}
+}
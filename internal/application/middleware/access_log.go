@@ -0,0 +1,227 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json/v2"
+	"errors"
+	"io"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"charm.land/log/v2"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/clock"
+	pkgerrors "github.com/LarsArtmann/template-arch-lint/pkg/errors"
+)
+
+// unmatchedRoute names requests that no route pattern matched, such as
+// those rejected by middleware before routing. Raw paths are never logged
+// as the route, so the route field keeps a bounded set of values.
+const unmatchedRoute = "unmatched"
+
+// LatencyObserver receives the latency of every request by route template
+// and method, for histograms.
+type LatencyObserver interface {
+	ObserveLatency(method, route string, latency time.Duration)
+}
+
+type accessRecordKey struct{}
+
+// accessRecord collects what the inner route hook learns for the outer
+// middleware. The hook may still run after a timed-out request was logged,
+// so the route is stored atomically.
+type accessRecord struct {
+	route atomic.Pointer[string]
+}
+
+// AccessLog writes one structured log line per request and reports its
+// latency to an optional LatencyObserver. It also recovers panics, so a
+// panicking handler is logged and answered with 500 like any other failure.
+type AccessLog struct {
+	latency LatencyObserver
+	clock   clock.Clock
+}
+
+// NewAccessLog creates the middleware; latency may be nil.
+func NewAccessLog(latency LatencyObserver) *AccessLog {
+	return &AccessLog{latency: latency, clock: clock.System{}}
+}
+
+// WithClock replaces the system clock that latencies are measured on.
+func (a *AccessLog) WithClock(clock clock.Clock) *AccessLog {
+	a.clock = clock
+
+	return a
+}
+
+// Middleware wraps next. It logs through the request's context logger, so
+// inside RequestIDs every line carries request_id. The level follows the
+// status: Info below 400, Warn for 4xx and Error for 5xx.
+func (a *AccessLog) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := a.clock.Now()
+		record := &accessRecord{}                 //nolint:exhaustruct // no route until the mux matched one
+		body := &countingBody{ReadCloser: r.Body} //nolint:exhaustruct // counts from zero
+		r.Body = body
+
+		writer := &accessLogWriter{ResponseWriter: w, status: 0, bytes: 0}
+
+		defer func() {
+			recovered := recover()
+			if recovered != nil {
+				if errors.Is(asError(recovered), http.ErrAbortHandler) {
+					panic(recovered)
+				}
+
+				log.FromContext(r.Context()).Error("Handler panicked",
+					"panic", recovered, "stack", string(debug.Stack()))
+				writer.failInternally()
+			}
+
+			route := unmatchedRoute
+			if matched := record.route.Load(); matched != nil {
+				route = *matched
+			}
+
+			a.finish(r, writer, route, body.n.Load(), a.clock.Now().Sub(start))
+		}()
+
+		next.ServeHTTP(writer, r.WithContext(context.WithValue(r.Context(), accessRecordKey{}, record)))
+	})
+}
+
+// Routes wraps the ServeMux whose route pattern names requests in the log.
+// ServeMux sets Request.Pattern only on the request it receives, so the
+// outer middleware cannot see it without this hook.
+func (a *AccessLog) Routes(mux http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux.ServeHTTP(w, r)
+
+		if record, ok := r.Context().Value(accessRecordKey{}).(*accessRecord); ok && r.Pattern != "" {
+			route := patternPath(r.Pattern)
+			record.route.Store(&route)
+		}
+	})
+}
+
+func (a *AccessLog) finish(
+	r *http.Request, w *accessLogWriter, route string, requestBytes int64, latency time.Duration,
+) {
+	status := w.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	if a.latency != nil {
+		a.latency.ObserveLatency(r.Method, route, latency)
+	}
+
+	logger := log.FromContext(r.Context())
+	fields := []any{
+		"method", r.Method,
+		"route", route,
+		"status", status,
+		"latency_ms", float64(latency) / float64(time.Millisecond),
+		"request_bytes", requestBytes,
+		"response_bytes", w.bytes,
+		"client_ip", clientIP(r),
+		"user_agent", r.UserAgent(),
+	}
+
+	switch {
+	case status >= http.StatusInternalServerError:
+		logger.Error("HTTP request", fields...)
+	case status >= http.StatusBadRequest:
+		logger.Warn("HTTP request", fields...)
+	default:
+		logger.Info("HTTP request", fields...)
+	}
+}
+
+// patternPath drops the method and host from a ServeMux pattern.
+func patternPath(pattern string) string {
+	_, path, found := strings.Cut(pattern, " ")
+	if !found {
+		path = pattern
+	}
+
+	if i := strings.Index(path, "/"); i > 0 {
+		path = path[i:]
+	}
+
+	return path
+}
+
+func asError(recovered any) error {
+	err, _ := recovered.(error)
+
+	return err
+}
+
+// countingBody counts the request body bytes the handler reads, which a
+// timed-out handler may still do while the request is logged.
+type countingBody struct {
+	io.ReadCloser
+
+	n atomic.Int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n.Add(int64(n))
+
+	return n, err
+}
+
+// accessLogWriter records the status and the response size.
+type accessLogWriter struct {
+	http.ResponseWriter
+
+	status int
+	bytes  int64
+}
+
+func (w *accessLogWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *accessLogWriter) Write(data []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	n, err := w.ResponseWriter.Write(data)
+	w.bytes += int64(n)
+
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *accessLogWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// failInternally answers a panicked request with 500. When the handler had
+// already started its response, only the logged status changes.
+func (w *accessLogWriter) failInternally() {
+	if w.status != 0 {
+		w.status = http.StatusInternalServerError
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusInternalServerError)
+	_ = json.MarshalWrite(w, pkgerrors.APIError{ //nolint:exhaustruct // the request ID is the correlation ID
+		Code:          pkgerrors.APICodeInternal,
+		Message:       "Internal server error",
+		CorrelationID: w.Header().Get(RequestIDHeader),
+		RequestID:     w.Header().Get(RequestIDHeader),
+	})
+}
@@ -0,0 +1,159 @@
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json/v2"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"charm.land/log/v2"
+	"github.com/LarsArtmann/template-arch-lint/internal/application/middleware"
+)
+
+type latencyObservation struct {
+	method, route string
+}
+
+type fakeLatencyObserver struct {
+	observed []latencyObservation
+}
+
+func (o *fakeLatencyObserver) ObserveLatency(method, route string, _ time.Duration) {
+	o.observed = append(o.observed, latencyObservation{method: method, route: route})
+}
+
+// serveAccessLogged runs one request through RequestIDs, AccessLog and a
+// mux with a single route, and returns the response and the decoded log
+// record of the access log line.
+func serveAccessLogged(
+	t *testing.T, observer middleware.LatencyObserver, route string, handler http.HandlerFunc, req *http.Request,
+) (*httptest.ResponseRecorder, map[string]any) {
+	t.Helper()
+
+	var logs bytes.Buffer
+
+	logger := log.New(&logs)
+	logger.SetFormatter(log.JSONFormatter)
+	logger.SetLevel(log.DebugLevel)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(route, handler)
+
+	accessLog := middleware.NewAccessLog(observer)
+	chain := middleware.NewRequestIDs(logger).Middleware(accessLog.Middleware(accessLog.Routes(mux)))
+
+	w := httptest.NewRecorder()
+	chain.ServeHTTP(w, req)
+
+	for line := range strings.Lines(logs.String()) {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("log line %q is not JSON: %v", line, err)
+		}
+
+		if record["msg"] == "HTTP request" {
+			return w, record
+		}
+	}
+
+	t.Fatalf("no access log record in %q", logs.String())
+
+	return nil, nil
+}
+
+func TestAccessLogRecordsRequestFields(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/users/42", strings.NewReader(`{"name":"x"}`))
+	req.Header.Set("User-Agent", "access-test/1.0")
+
+	w, record := serveAccessLogged(t, nil, "POST /api/v1/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("created"))
+	}, req)
+
+	want := map[string]any{
+		"level":          "info",
+		"method":         http.MethodPost,
+		"route":          "/api/v1/users/{id}",
+		"status":         float64(http.StatusCreated),
+		"request_bytes":  float64(len(`{"name":"x"}`)),
+		"response_bytes": float64(len("created")),
+		"client_ip":      "192.0.2.1",
+		"user_agent":     "access-test/1.0",
+		"request_id":     w.Header().Get(middleware.RequestIDHeader),
+	}
+	for field, value := range want {
+		if record[field] != value {
+			t.Errorf("%s = %v, want %v", field, record[field], value)
+		}
+	}
+
+	if _, ok := record["latency_ms"].(float64); !ok {
+		t.Errorf("latency_ms = %v, want a number", record["latency_ms"])
+	}
+}
+
+func TestAccessLogLevelFollowsStatus(t *testing.T) {
+	tests := []struct {
+		status int
+		level  string
+	}{
+		{http.StatusOK, "info"},
+		{http.StatusFound, "info"},
+		{http.StatusNotFound, "warn"},
+		{http.StatusServiceUnavailable, "error"},
+	}
+
+	for _, tt := range tests {
+		_, record := serveAccessLogged(t, nil, "GET /status", func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(tt.status)
+		}, httptest.NewRequest(http.MethodGet, "/status", nil))
+
+		if record["level"] != tt.level {
+			t.Errorf("level for %d = %v, want %s", tt.status, record["level"], tt.level)
+		}
+	}
+}
+
+func TestAccessLogNamesUnmatchedRoutes(t *testing.T) {
+	_, record := serveAccessLogged(t, nil, "GET /status", func(http.ResponseWriter, *http.Request) {},
+		httptest.NewRequest(http.MethodGet, "/no/such/path", nil))
+
+	if record["route"] != "unmatched" || record["status"] != float64(http.StatusNotFound) {
+		t.Errorf("route, status = %v, %v; want unmatched, 404", record["route"], record["status"])
+	}
+}
+
+func TestAccessLogRecoversPanics(t *testing.T) {
+	w, record := serveAccessLogged(t, nil, "GET /panic", func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	}, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", w.Code)
+	}
+
+	if !strings.Contains(w.Body.String(), `"code":"INTERNAL_ERROR"`) {
+		t.Errorf("body = %s, want an INTERNAL_ERROR APIError", w.Body.String())
+	}
+
+	if record["level"] != "error" || record["status"] != float64(http.StatusInternalServerError) {
+		t.Errorf("level, status = %v, %v; want error, 500", record["level"], record["status"])
+	}
+}
+
+func TestAccessLogObservesLatencyByRoute(t *testing.T) {
+	observer := &fakeLatencyObserver{observed: nil}
+
+	serveAccessLogged(t, observer, "GET /api/v1/users/{id}", func(http.ResponseWriter, *http.Request) {},
+		httptest.NewRequest(http.MethodGet, "/api/v1/users/7", nil))
+
+	want := []latencyObservation{{method: http.MethodGet, route: "/api/v1/users/{id}"}}
+	if len(observer.observed) != 1 || observer.observed[0] != want[0] {
+		t.Errorf("observed %v, want %v", observer.observed, want)
+	}
+}
@@ -0,0 +1,344 @@
+package middleware
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json/v2"
+	"errors"
+	"fmt"
+	"hash"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"charm.land/log/v2"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/clock"
+	pkgerrors "github.com/LarsArtmann/template-arch-lint/pkg/errors"
+)
+
+// Token verification failures. Clients only ever see a generic 401; these
+// name the cause in the log.
+var (
+	errMissingToken     = errors.New("missing bearer token")
+	errMalformedToken   = errors.New("malformed token")
+	errUnexpectedAlg    = errors.New("unexpected signing algorithm")
+	errInvalidSignature = errors.New("invalid signature")
+	errTokenExpired     = errors.New("token expired")
+	errTokenNotYetValid = errors.New("token not yet valid")
+	errWrongIssuer      = errors.New("wrong issuer")
+	errWrongAudience    = errors.New("wrong audience")
+	errMissingSubject   = errors.New("token has no subject")
+)
+
+// Claims are the verified claims of an access token.
+type Claims struct {
+	Subject   string
+	Roles     []string
+	Issuer    string
+	Audience  []string
+	ExpiresAt time.Time
+}
+
+// HasRole reports whether the claims grant role.
+func (c Claims) HasRole(role string) bool {
+	return slices.Contains(c.Roles, role)
+}
+
+type claimsKey struct{}
+
+// CurrentUser returns the claims Authenticator verified for the request.
+func CurrentUser(ctx context.Context) (Claims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(Claims)
+
+	return claims, ok
+}
+
+// AuthOptions configure an Authenticator.
+type AuthOptions struct {
+	// Algorithm is the only accepted signing algorithm: HS256, HS384,
+	// HS512 or RS256. Tokens naming any other, "none" included, fail.
+	Algorithm string
+	// HMACKey returns the HS* verification key. It is called per token, so
+	// a rotated secret takes effect without a restart.
+	HMACKey func() []byte
+	// JWKS supplies the RS256 verification keys.
+	JWKS *JWKS
+	// Issuer, when set, must equal the iss claim.
+	Issuer string
+	// Audience, when set, must be among the aud claim.
+	Audience string
+	// ClockSkew tolerates clocks that disagree on exp and nbf.
+	ClockSkew time.Duration
+	// Clock returns the current time; nil means the system clock.
+	Clock func() time.Time
+}
+
+// Authenticator verifies bearer access tokens.
+type Authenticator struct {
+	options AuthOptions
+}
+
+// NewAuthenticator creates an Authenticator enforcing options.
+func NewAuthenticator(options AuthOptions) *Authenticator {
+	if options.Clock == nil {
+		options.Clock = clock.System{}.Now
+	}
+
+	return &Authenticator{options: options}
+}
+
+// Middleware rejects requests without a valid bearer token with 401 and
+// makes the verified claims available through CurrentUser.
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+		var (
+			claims Claims
+			err    = errMissingToken
+		)
+
+		if found && token != "" {
+			claims, err = a.Verify(r.Context(), token)
+		}
+
+		if err != nil {
+			log.FromContext(r.Context()).Info("Request not authenticated", "path", r.URL.Path, "reason", err)
+			writeUnauthorized(w)
+
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), claimsKey{}, claims)))
+	})
+}
+
+// RequireRole returns middleware that answers 403 unless the authenticated
+// caller has role. It belongs inside Authenticator.Middleware; a request
+// that reaches it unauthenticated gets 401.
+func RequireRole(role string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := CurrentUser(r.Context())
+			if !ok {
+				writeUnauthorized(w)
+
+				return
+			}
+
+			if !claims.HasRole(role) {
+				log.FromContext(r.Context()).Info("Request lacks role",
+					"path", r.URL.Path, "subject", claims.Subject, "role", role)
+				writeAuthError(w, http.StatusForbidden, pkgerrors.APICodeForbidden, "The "+role+" role is required")
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+type tokenHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+type tokenClaims struct {
+	Subject   string   `json:"sub"`
+	Roles     []string `json:"roles"`
+	Issuer    string   `json:"iss"`
+	Audience  audience `json:"aud"`
+	ExpiresAt float64  `json:"exp"`
+	NotBefore float64  `json:"nbf"`
+}
+
+// Verify checks the signature and the registered claims of a compact JWT.
+// The exp claim is required.
+func (a *Authenticator) Verify(ctx context.Context, token string) (Claims, error) {
+	claims, err := a.verify(ctx, token)
+	if err != nil {
+		return Claims{}, err //nolint:exhaustruct // no claims on failure
+	}
+
+	return Claims{
+		Subject:   claims.Subject,
+		Roles:     claims.Roles,
+		Issuer:    claims.Issuer,
+		Audience:  claims.Audience,
+		ExpiresAt: unixTime(claims.ExpiresAt),
+	}, nil
+}
+
+func (a *Authenticator) verify(ctx context.Context, token string) (*tokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errMalformedToken
+	}
+
+	var header tokenHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+
+	if header.Algorithm != a.options.Algorithm {
+		return nil, fmt.Errorf("%w %q", errUnexpectedAlg, header.Algorithm)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errMalformedToken
+	}
+
+	err = a.verifySignature(ctx, header, parts[0]+"."+parts[1], signature)
+	if err != nil {
+		return nil, err
+	}
+
+	var claims tokenClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+
+	err = a.checkClaims(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return &claims, nil
+}
+
+func (a *Authenticator) verifySignature(ctx context.Context, header tokenHeader, input string, signature []byte) error {
+	switch header.Algorithm {
+	case "HS256", "HS384", "HS512":
+		if a.options.HMACKey == nil {
+			return errUnexpectedAlg
+		}
+
+		mac := hmac.New(hmacHash(header.Algorithm), a.options.HMACKey())
+		mac.Write([]byte(input))
+
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return errInvalidSignature
+		}
+
+		return nil
+	case "RS256":
+		if a.options.JWKS == nil {
+			return errUnexpectedAlg
+		}
+
+		key, err := a.options.JWKS.Key(ctx, header.KeyID)
+		if err != nil {
+			return err
+		}
+
+		digest := sha256.Sum256([]byte(input))
+		if rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) != nil {
+			return errInvalidSignature
+		}
+
+		return nil
+	default:
+		return errUnexpectedAlg
+	}
+}
+
+func (a *Authenticator) checkClaims(claims tokenClaims) error {
+	now := a.options.Clock()
+
+	if claims.ExpiresAt == 0 || !now.Before(unixTime(claims.ExpiresAt).Add(a.options.ClockSkew)) {
+		return errTokenExpired
+	}
+
+	if claims.NotBefore != 0 && now.Add(a.options.ClockSkew).Before(unixTime(claims.NotBefore)) {
+		return errTokenNotYetValid
+	}
+
+	if a.options.Issuer != "" && claims.Issuer != a.options.Issuer {
+		return errWrongIssuer
+	}
+
+	if a.options.Audience != "" && !slices.Contains(claims.Audience, a.options.Audience) {
+		return errWrongAudience
+	}
+
+	if claims.Subject == "" {
+		return errMissingSubject
+	}
+
+	return nil
+}
+
+// audience decodes the aud claim, which is a string or an array of strings.
+type audience []string
+
+func (aud *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if json.Unmarshal(data, &single) == nil {
+		*aud = audience{single}
+
+		return nil
+	}
+
+	var many []string
+
+	err := json.Unmarshal(data, &many)
+	if err != nil {
+		return errMalformedToken
+	}
+
+	*aud = many
+
+	return nil
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return errMalformedToken
+	}
+
+	if json.Unmarshal(data, v) != nil {
+		return errMalformedToken
+	}
+
+	return nil
+}
+
+func hmacHash(algorithm string) func() hash.Hash {
+	switch algorithm {
+	case "HS384":
+		return sha512.New384
+	case "HS512":
+		return sha512.New
+	default:
+		return sha256.New
+	}
+}
+
+func unixTime(seconds float64) time.Time {
+	return time.Unix(0, int64(seconds*float64(time.Second)))
+}
+
+// writeUnauthorized answers 401 with the Bearer challenge of RFC 6750.
+func writeUnauthorized(w http.ResponseWriter) {
+	w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+	writeAuthError(w, http.StatusUnauthorized, pkgerrors.APICodeUnauthorized, "Missing or invalid access token")
+}
+
+func writeAuthError(w http.ResponseWriter, status int, code pkgerrors.APICode, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.MarshalWrite(w, pkgerrors.APIError{ //nolint:exhaustruct // an auth failure names no field
+		Code:      code,
+		Message:   message,
+		RequestID: w.Header().Get(RequestIDHeader),
+	})
+}
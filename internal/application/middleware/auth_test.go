@@ -0,0 +1,253 @@
+package middleware_test
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json/v2"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/application/middleware"
+)
+
+const (
+	testIssuer   = "test-issuer"
+	testAudience = "test-api"
+)
+
+var (
+	testSecret = []byte("a-test-secret-that-is-at-least-32-bytes")
+	testNow    = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+)
+
+func validClaims() map[string]any {
+	return map[string]any{
+		"sub":   "user-1",
+		"roles": []string{"reader"},
+		"iss":   testIssuer,
+		"aud":   testAudience,
+		"exp":   testNow.Add(time.Hour).Unix(),
+	}
+}
+
+func encodeSegment(t *testing.T, v any) string {
+	t.Helper()
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func signHS256(t *testing.T, claims map[string]any) string {
+	t.Helper()
+
+	input := encodeSegment(t, map[string]string{"alg": "HS256", "typ": "JWT"}) + "." + encodeSegment(t, claims)
+	mac := hmac.New(sha256.New, testSecret)
+	mac.Write([]byte(input))
+
+	return input + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]any) string {
+	t.Helper()
+
+	input := encodeSegment(t, map[string]string{"alg": "RS256", "kid": kid}) + "." + encodeSegment(t, claims)
+	digest := sha256.Sum256([]byte(input))
+
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func newHMACAuthenticator() *middleware.Authenticator {
+	return middleware.NewAuthenticator(middleware.AuthOptions{ //nolint:exhaustruct // HMAC only
+		Algorithm: "HS256",
+		HMACKey:   func() []byte { return testSecret },
+		Issuer:    testIssuer,
+		Audience:  testAudience,
+		ClockSkew: 30 * time.Second,
+		Clock:     func() time.Time { return testNow },
+	})
+}
+
+// serveAuthenticated runs a request with token through auth and the extra
+// middleware and returns the response and the subject the handler saw.
+func serveAuthenticated(
+	auth *middleware.Authenticator, token string, wrap ...func(http.Handler) http.Handler,
+) (*httptest.ResponseRecorder, string) {
+	var subject string
+
+	var handler http.Handler = http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		claims, _ := middleware.CurrentUser(r.Context())
+		subject = claims.Subject
+	})
+	for _, m := range wrap {
+		handler = m(handler)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users", nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	w := httptest.NewRecorder()
+	auth.Middleware(handler).ServeHTTP(w, req)
+
+	return w, subject
+}
+
+func TestAuthenticatorAcceptsValidToken(t *testing.T) {
+	w, subject := serveAuthenticated(newHMACAuthenticator(), signHS256(t, validClaims()))
+
+	if w.Code != http.StatusOK || subject != "user-1" {
+		t.Errorf("status, subject = %d, %q; want 200, user-1", w.Code, subject)
+	}
+}
+
+func TestAuthenticatorRejectsInvalidTokens(t *testing.T) {
+	expired := validClaims()
+	expired["exp"] = testNow.Add(-time.Minute).Unix()
+
+	wrongAudience := validClaims()
+	wrongAudience["aud"] = []string{"other-api"}
+
+	wrongIssuer := validClaims()
+	wrongIssuer["iss"] = "someone-else"
+
+	valid := signHS256(t, validClaims())
+	tampered := valid[:strings.LastIndex(valid, ".")] + "." + encodeSegment(t, "forged")
+	unsigned := encodeSegment(t, map[string]string{"alg": "none"}) + "." + encodeSegment(t, validClaims()) + "."
+
+	tests := map[string]string{
+		"missing":            "",
+		"malformed":          "not-a-jwt",
+		"expired":            signHS256(t, expired),
+		"wrong audience":     signHS256(t, wrongAudience),
+		"wrong issuer":       signHS256(t, wrongIssuer),
+		"tampered signature": tampered,
+		"alg none":           unsigned,
+	}
+
+	for name, token := range tests {
+		w, _ := serveAuthenticated(newHMACAuthenticator(), token)
+
+		if w.Code != http.StatusUnauthorized || !strings.Contains(w.Body.String(), `"code":"UNAUTHORIZED"`) {
+			t.Errorf("%s: got %d %s, want a 401 UNAUTHORIZED APIError", name, w.Code, w.Body.String())
+		}
+
+		if w.Header().Get("WWW-Authenticate") == "" {
+			t.Errorf("%s: no WWW-Authenticate header", name)
+		}
+	}
+}
+
+func TestAuthenticatorToleratesClockSkew(t *testing.T) {
+	claims := validClaims()
+	claims["exp"] = testNow.Add(-10 * time.Second).Unix()
+
+	w, _ := serveAuthenticated(newHMACAuthenticator(), signHS256(t, claims))
+
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200 within the clock skew", w.Code)
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	admin := validClaims()
+	admin["roles"] = []string{"reader", "admin"}
+
+	w, _ := serveAuthenticated(newHMACAuthenticator(), signHS256(t, admin), middleware.RequireRole("admin"))
+	if w.Code != http.StatusOK {
+		t.Errorf("admin: status = %d, want 200", w.Code)
+	}
+
+	w, _ = serveAuthenticated(newHMACAuthenticator(), signHS256(t, validClaims()), middleware.RequireRole("admin"))
+	if w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), `"code":"FORBIDDEN"`) {
+		t.Errorf("reader: got %d %s, want a 403 FORBIDDEN APIError", w.Code, w.Body.String())
+	}
+}
+
+// jwksServer publishes key under kid and counts the fetches.
+func jwksServer(t *testing.T, key *rsa.PublicKey, kid string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var fetches atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fetches.Add(1)
+
+		_ = json.MarshalWrite(w, map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": kid,
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &fetches
+}
+
+func TestAuthenticatorVerifiesRS256WithCachedJWKS(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server, fetches := jwksServer(t, &key.PublicKey, "key-1")
+	now := testNow
+	clock := func() time.Time { return now }
+
+	auth := middleware.NewAuthenticator(middleware.AuthOptions{ //nolint:exhaustruct // RS256 only
+		Algorithm: "RS256",
+		JWKS: middleware.NewJWKS(middleware.JWKSOptions{
+			URL: server.URL, CacheTTL: 10 * time.Minute, Client: server.Client(), Clock: clock,
+		}),
+		Issuer:   testIssuer,
+		Audience: testAudience,
+		Clock:    clock,
+	})
+
+	for range 3 {
+		w, subject := serveAuthenticated(auth, signRS256(t, key, "key-1", validClaims()))
+		if w.Code != http.StatusOK || subject != "user-1" {
+			t.Fatalf("status, subject = %d, %q; want 200, user-1", w.Code, subject)
+		}
+	}
+
+	if got := fetches.Load(); got != 1 {
+		t.Errorf("fetched the JWKS %d times for three tokens, want once", got)
+	}
+
+	w, _ := serveAuthenticated(auth, signRS256(t, key, "unknown", validClaims()))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("unknown kid: status = %d, want 401", w.Code)
+	}
+
+	if got := fetches.Load(); got != 1 {
+		t.Errorf("an unknown kid right after a fetch refetched the JWKS (%d fetches)", got)
+	}
+
+	now = now.Add(11 * time.Minute)
+	serveAuthenticated(auth, signRS256(t, key, "key-1", validClaims()))
+
+	if got := fetches.Load(); got != 2 {
+		t.Errorf("fetches after the cache TTL = %d, want 2", got)
+	}
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"encoding/json/v2"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+
+	pkgerrors "github.com/LarsArtmann/template-arch-lint/pkg/errors"
+)
+
+// BodyLimitOptions configure BodyLimits.
+type BodyLimitOptions struct {
+	// MaxBytes bounds the body of a POST, PUT or PATCH request.
+	MaxBytes int64
+	// RawBodyRoutes are "METHOD /path" patterns, matched exactly, whose
+	// handlers check the content type and size of their own bodies, such
+	// as streaming imports.
+	RawBodyRoutes []string
+}
+
+// BodyLimits requires POST, PUT and PATCH requests to send JSON of at most
+// MaxBytes. A declared Content-Length over the limit is rejected before the
+// handler runs; a longer body than declared fails the handler's read with
+// an *http.MaxBytesError, which handlers answer with 413 themselves.
+type BodyLimits struct {
+	options BodyLimitOptions
+}
+
+// NewBodyLimits creates the middleware for options.
+func NewBodyLimits(options BodyLimitOptions) *BodyLimits {
+	return &BodyLimits{options: options}
+}
+
+// Middleware wraps next with the content-type and size checks.
+func (b *BodyLimits) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !hasJSONBody(r.Method) || slices.Contains(b.options.RawBodyRoutes, r.Method+" "+r.URL.Path) {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil || mediaType != "application/json" {
+			rejectBody(w, http.StatusUnsupportedMediaType, pkgerrors.APICodeUnsupportedMediaType,
+				"Request body must be application/json")
+
+			return
+		}
+
+		if r.ContentLength > b.options.MaxBytes {
+			rejectBody(w, http.StatusRequestEntityTooLarge, pkgerrors.APICodeRequestTooLarge,
+				"Request body exceeds "+strconv.FormatInt(b.options.MaxBytes, 10)+" bytes")
+
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, b.options.MaxBytes)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func hasJSONBody(method string) bool {
+	return method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch
+}
+
+func rejectBody(w http.ResponseWriter, status int, code pkgerrors.APICode, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.MarshalWrite(w, pkgerrors.APIError{ //nolint:exhaustruct // body errors name no field
+		Code:      code,
+		Message:   message,
+		RequestID: w.Header().Get(RequestIDHeader),
+	})
+}
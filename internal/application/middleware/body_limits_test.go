@@ -0,0 +1,108 @@
+package middleware_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/application/middleware"
+)
+
+const (
+	testBodyLimit = 64
+	testUserPath  = "/api/v1/users/u1"
+)
+
+// serveBody sends body through BodyLimits to a handler that reads it whole.
+// A negative contentLength leaves the length unknown, as with chunked bodies.
+func serveBody(t *testing.T, method, path, contentType, body string, contentLength int64) (int, error) {
+	t.Helper()
+
+	var readErr error
+
+	limits := middleware.NewBodyLimits(middleware.BodyLimitOptions{
+		MaxBytes:      testBodyLimit,
+		RawBodyRoutes: []string{"POST /api/v1/users/import"},
+	})
+	handler := limits.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, readErr = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.ContentLength = contentLength
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	return w.Code, readErr
+}
+
+func TestBodyLimitsSize(t *testing.T) {
+	atLimit := strings.Repeat("a", testBodyLimit)
+	overLimit := atLimit + "a"
+
+	tests := []struct {
+		name          string
+		body          string
+		contentLength int64
+		wantStatus    int
+		wantTooLarge  bool
+	}{
+		{"exactly at the limit", atLimit, testBodyLimit, http.StatusNoContent, false},
+		{"one byte over, declared", overLimit, testBodyLimit + 1, http.StatusRequestEntityTooLarge, false},
+		{"exactly at the limit, chunked", atLimit, -1, http.StatusNoContent, false},
+		{"one byte over, chunked", overLimit, -1, http.StatusNoContent, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, readErr := serveBody(t, http.MethodPost, "/api/v1/users", "application/json",
+				tt.body, tt.contentLength)
+			if status != tt.wantStatus {
+				t.Errorf("status = %d, want %d", status, tt.wantStatus)
+			}
+
+			var tooLarge *http.MaxBytesError
+			if errors.As(readErr, &tooLarge) != tt.wantTooLarge {
+				t.Errorf("handler read error = %v, want MaxBytesError = %v", readErr, tt.wantTooLarge)
+			}
+		})
+	}
+}
+
+func TestBodyLimitsContentType(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		contentType string
+		want        int
+	}{
+		{"JSON", http.MethodPost, "/api/v1/users", "application/json", http.StatusNoContent},
+		{"JSON with charset", http.MethodPut, testUserPath, "application/json; charset=utf-8", http.StatusNoContent},
+		{"missing on POST", http.MethodPost, "/api/v1/users", "", http.StatusUnsupportedMediaType},
+		{"missing on PATCH", http.MethodPatch, testUserPath, "", http.StatusUnsupportedMediaType},
+		{"form on PUT", http.MethodPut, testUserPath, "multipart/form-data", http.StatusUnsupportedMediaType},
+		{"text on POST", http.MethodPost, "/api/v1/users", "text/plain", http.StatusUnsupportedMediaType},
+		{"raw body route", http.MethodPost, "/api/v1/users/import", "application/x-ndjson", http.StatusNoContent},
+		{"GET without body", http.MethodGet, "/api/v1/users", "", http.StatusNoContent},
+		{"DELETE without body", http.MethodDelete, testUserPath, "", http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, _ := serveBody(t, tt.method, tt.path, tt.contentType, "{}", 2)
+			if status != tt.want {
+				t.Errorf("status = %d, want %d", status, tt.want)
+			}
+		})
+	}
+}
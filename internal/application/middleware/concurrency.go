@@ -0,0 +1,268 @@
+// Package middleware provides HTTP middleware shared by all handlers.
+package middleware
+
+import (
+	"context"
+	"encoding/json/v2"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// LimitPolicy decides what happens to a request when its group is full.
+type LimitPolicy int
+
+const (
+	// PolicyQueue waits up to MaxWait for a free slot; a zero MaxWait rejects.
+	PolicyQueue LimitPolicy = iota
+	// PolicyReject fails immediately.
+	PolicyReject
+)
+
+// ParseLimitPolicy parses a policy name as used in the configuration:
+// "queue" or "reject". An empty name is PolicyQueue.
+func ParseLimitPolicy(name string) (LimitPolicy, bool) {
+	switch name {
+	case "", "queue":
+		return PolicyQueue, true
+	case "reject":
+		return PolicyReject, true
+	default:
+		return PolicyQueue, false
+	}
+}
+
+// LimitGroupConfig configures one named concurrency group.
+type LimitGroupConfig struct {
+	Limit   int
+	Policy  LimitPolicy
+	MaxWait time.Duration
+}
+
+// GroupStats is the current occupancy of a concurrency group.
+type GroupStats struct {
+	Limit   int `json:"limit"`
+	Active  int `json:"active"`
+	Waiting int `json:"waiting"`
+}
+
+// ConcurrencyRule assigns one route group to a named concurrency group.
+type ConcurrencyRule struct {
+	// Method restricts the rule to one HTTP method; empty matches any.
+	Method string
+	// PathPrefix selects the paths the rule covers.
+	PathPrefix string
+	// Group names the concurrency group whose slots the routes share.
+	Group string
+}
+
+// ParseConcurrencyRule parses a route group of the form "[METHOD ]/prefix",
+// as ParseRateLimitRule does, with the group it belongs to.
+func ParseConcurrencyRule(route, group string) (ConcurrencyRule, bool) {
+	method, prefix, ok := parseRouteGroup(route)
+	if !ok || group == "" {
+		return ConcurrencyRule{}, false //nolint:exhaustruct // invalid rule
+	}
+
+	return ConcurrencyRule{Method: method, PathPrefix: prefix, Group: group}, true
+}
+
+// ConcurrencyLimiter bounds in-flight requests per named group.
+// Routes assigned to the same group share its slots.
+type ConcurrencyLimiter struct {
+	mu     sync.Mutex
+	groups map[string]*limitGroup
+	rules  []ConcurrencyRule
+}
+
+type limitGroup struct {
+	config  LimitGroupConfig
+	active  int
+	waiters []chan struct{}
+}
+
+// NewConcurrencyLimiter creates a limiter with the given groups.
+func NewConcurrencyLimiter(groups map[string]LimitGroupConfig) *ConcurrencyLimiter {
+	limiter := &ConcurrencyLimiter{ //nolint:exhaustruct // mu has a valid zero value, rules come from WithRules
+		groups: make(map[string]*limitGroup, len(groups)),
+	}
+	for name, config := range groups {
+		limiter.groups[name] = newLimitGroup(config)
+	}
+
+	return limiter
+}
+
+func newLimitGroup(config LimitGroupConfig) *limitGroup {
+	return &limitGroup{config: config, active: 0, waiters: nil}
+}
+
+// WithRules assigns route groups to concurrency groups for Middleware,
+// matched as RateLimitOptions rules are.
+func (l *ConcurrencyLimiter) WithRules(rules []ConcurrencyRule) *ConcurrencyLimiter {
+	l.rules = rules
+
+	return l
+}
+
+// Limit wraps next so that it runs inside the named group.
+// Unknown groups are not limited.
+func (l *ConcurrencyLimiter) Limit(group string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		l.serve(group, next, w, r)
+	})
+}
+
+// Middleware runs each request inside the group its route is assigned to
+// by the rules. Requests on other routes are not limited.
+func (l *ConcurrencyLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		best := matchRouteGroup(r, len(l.rules), func(i int) (string, string) {
+			return l.rules[i].Method, l.rules[i].PathPrefix
+		})
+		if best < 0 {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		l.serve(l.rules[best].Group, next, w, r)
+	})
+}
+
+func (l *ConcurrencyLimiter) serve(group string, next http.Handler, w http.ResponseWriter, r *http.Request) {
+	acquired, known := l.acquire(r.Context(), group)
+	if !known {
+		next.ServeHTTP(w, r)
+
+		return
+	}
+
+	if !acquired {
+		l.reject(w, group)
+
+		return
+	}
+
+	// Release in a defer so a panicking handler cannot leak the slot.
+	defer l.release(group)
+
+	next.ServeHTTP(w, r)
+}
+
+// Stats returns the occupancy of every group.
+func (l *ConcurrencyLimiter) Stats() map[string]GroupStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	stats := make(map[string]GroupStats, len(l.groups))
+	for name, g := range l.groups {
+		stats[name] = GroupStats{Limit: g.config.Limit, Active: g.active, Waiting: len(g.waiters)}
+	}
+
+	return stats
+}
+
+// StatsHandler serves Stats as JSON, keyed by group.
+func (l *ConcurrencyLimiter) StatsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.MarshalWrite(w, l.Stats())
+	}
+}
+
+// acquire takes a slot in group. known is false when the group is not configured.
+func (l *ConcurrencyLimiter) acquire(ctx context.Context, group string) (bool, bool) {
+	l.mu.Lock()
+
+	g, ok := l.groups[group]
+	if !ok {
+		l.mu.Unlock()
+
+		return false, false
+	}
+
+	if g.active < g.config.Limit {
+		g.active++
+		l.mu.Unlock()
+
+		return true, true
+	}
+
+	if g.config.Policy == PolicyReject || g.config.MaxWait <= 0 {
+		l.mu.Unlock()
+
+		return false, true
+	}
+
+	ready := make(chan struct{})
+	g.waiters = append(g.waiters, ready)
+	maxWait := g.config.MaxWait
+	l.mu.Unlock()
+
+	timer := time.NewTimer(maxWait)
+	defer timer.Stop()
+
+	select {
+	case <-ready:
+		return true, true
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	return l.abandon(g, ready), true
+}
+
+// abandon removes a waiter that gave up. If the slot was granted in the
+// meantime the waiter keeps it and true is returned.
+func (l *ConcurrencyLimiter) abandon(g *limitGroup, ready chan struct{}) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	index := slices.Index(g.waiters, ready)
+	if index < 0 {
+		return true
+	}
+
+	g.waiters = slices.Delete(g.waiters, index, index+1)
+
+	return false
+}
+
+func (l *ConcurrencyLimiter) release(group string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	g := l.groups[group]
+	g.active--
+	g.admitWaiters()
+}
+
+// admitWaiters hands free slots to queued requests in arrival order.
+// The caller must hold the limiter lock.
+func (g *limitGroup) admitWaiters() {
+	for g.active < g.config.Limit && len(g.waiters) > 0 {
+		g.active++
+		close(g.waiters[0])
+		g.waiters = g.waiters[1:]
+	}
+}
+
+func (l *ConcurrencyLimiter) reject(w http.ResponseWriter, group string) {
+	l.mu.Lock()
+	maxWait := l.groups[group].config.MaxWait
+	l.mu.Unlock()
+
+	retryAfter := max(1, int(math.Ceil(maxWait.Seconds())))
+
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusServiceUnavailable)
+	_ = json.MarshalWrite(w, map[string]string{
+		"error":   "concurrency_limit_exceeded",
+		"message": "Too many concurrent requests for " + group + ", retry later",
+	})
+}
@@ -0,0 +1,273 @@
+package middleware_test
+
+import (
+	"encoding/json/v2"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/application/middleware"
+)
+
+// blockingHandler tracks peak concurrency and blocks until release is closed.
+type blockingHandler struct {
+	current atomic.Int32
+	peak    atomic.Int32
+	started chan struct{}
+	release chan struct{}
+}
+
+func newBlockingHandler() *blockingHandler {
+	return &blockingHandler{started: make(chan struct{}, 100), release: make(chan struct{})}
+}
+
+func (h *blockingHandler) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	now := h.current.Add(1)
+	for {
+		peak := h.peak.Load()
+		if now <= peak || h.peak.CompareAndSwap(peak, now) {
+			break
+		}
+	}
+
+	h.started <- struct{}{}
+	<-h.release
+	h.current.Add(-1)
+	w.WriteHeader(http.StatusOK)
+}
+
+func serve(handler http.Handler) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/users/export", nil))
+
+	return w
+}
+
+func TestConcurrencyLimitEnforced(t *testing.T) {
+	limiter := middleware.NewConcurrencyLimiter(map[string]middleware.LimitGroupConfig{
+		"exports": {Limit: 2, Policy: middleware.PolicyQueue, MaxWait: 5 * time.Second},
+	})
+	inner := newBlockingHandler()
+	handler := limiter.Limit("exports", inner)
+
+	var wg sync.WaitGroup
+
+	codes := make(chan int, 6)
+
+	for range 6 {
+		wg.Go(func() { codes <- serve(handler).Code })
+	}
+
+	<-inner.started
+	<-inner.started
+
+	waitFor(t, func() bool { return limiter.Stats()["exports"].Waiting == 4 })
+
+	close(inner.release)
+	wg.Wait()
+	close(codes)
+
+	for code := range codes {
+		if code != http.StatusOK {
+			t.Errorf("queued request finished with %d", code)
+		}
+	}
+
+	if peak := inner.peak.Load(); peak != 2 {
+		t.Errorf("peak concurrency = %d, want 2", peak)
+	}
+
+	if stats := limiter.Stats()["exports"]; stats.Active != 0 || stats.Waiting != 0 {
+		t.Errorf("slots leaked: %+v", stats)
+	}
+}
+
+func TestConcurrencyLimitQueueTimeout(t *testing.T) {
+	limiter := middleware.NewConcurrencyLimiter(map[string]middleware.LimitGroupConfig{
+		"imports": {Limit: 1, Policy: middleware.PolicyQueue, MaxWait: 20 * time.Millisecond},
+	})
+	inner := newBlockingHandler()
+	handler := limiter.Limit("imports", inner)
+
+	go serve(handler)
+	<-inner.started
+
+	w := serve(handler)
+	close(inner.release)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", w.Code)
+	}
+
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("expected Retry-After header")
+	}
+
+	waitFor(t, func() bool { return limiter.Stats()["imports"].Waiting == 0 })
+}
+
+func TestConcurrencyLimitImmediateReject(t *testing.T) {
+	limiter := middleware.NewConcurrencyLimiter(map[string]middleware.LimitGroupConfig{
+		"admin-heavy": {Limit: 1, Policy: middleware.PolicyReject, MaxWait: time.Minute},
+	})
+	inner := newBlockingHandler()
+	handler := limiter.Limit("admin-heavy", inner)
+
+	go serve(handler)
+	<-inner.started
+
+	start := time.Now()
+	w := serve(handler)
+
+	close(inner.release)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", w.Code)
+	}
+
+	if time.Since(start) > time.Second {
+		t.Error("reject policy must not wait for a slot")
+	}
+}
+
+func TestConcurrencyStatsHandler(t *testing.T) {
+	limiter := middleware.NewConcurrencyLimiter(map[string]middleware.LimitGroupConfig{
+		"exports": {Limit: 1, Policy: middleware.PolicyQueue, MaxWait: 5 * time.Second},
+	})
+	inner := newBlockingHandler()
+	handler := limiter.Limit("exports", inner)
+
+	var wg sync.WaitGroup
+
+	for range 2 {
+		wg.Go(func() { serve(handler) })
+	}
+
+	<-inner.started
+	waitFor(t, func() bool { return limiter.Stats()["exports"].Waiting == 1 })
+
+	w := httptest.NewRecorder()
+	limiter.StatsHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/ops/concurrency", nil))
+
+	var stats map[string]middleware.GroupStats
+	if err := json.Unmarshal(w.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("decode stats: %v", err)
+	}
+
+	if want := (middleware.GroupStats{Limit: 1, Active: 1, Waiting: 1}); stats["exports"] != want {
+		t.Errorf("stats = %+v, want exports %+v", stats, want)
+	}
+
+	close(inner.release)
+	wg.Wait()
+}
+
+func TestParseLimitPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		want   middleware.LimitPolicy
+		wantOK bool
+	}{
+		{name: "", want: middleware.PolicyQueue, wantOK: true},
+		{name: "queue", want: middleware.PolicyQueue, wantOK: true},
+		{name: "reject", want: middleware.PolicyReject, wantOK: true},
+		{name: "drop", want: middleware.PolicyQueue, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		if got, ok := middleware.ParseLimitPolicy(tt.name); got != tt.want || ok != tt.wantOK {
+			t.Errorf("ParseLimitPolicy(%q) = %v, %v, want %v, %v", tt.name, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestConcurrencyLimitReleasedOnPanic(t *testing.T) {
+	limiter := middleware.NewConcurrencyLimiter(map[string]middleware.LimitGroupConfig{
+		"exports": {Limit: 1, Policy: middleware.PolicyReject, MaxWait: 0},
+	})
+	handler := limiter.Limit("exports", http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("export failed")
+	}))
+
+	func() {
+		defer func() { _ = recover() }()
+
+		serve(handler)
+	}()
+
+	if active := limiter.Stats()["exports"].Active; active != 0 {
+		t.Errorf("slot leaked after panic: active = %d", active)
+	}
+}
+
+func TestConcurrencyLimitUnknownGroupPassesThrough(t *testing.T) {
+	limiter := middleware.NewConcurrencyLimiter(nil)
+
+	w := serve(limiter.Limit("unconfigured", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})))
+
+	if w.Code != http.StatusNoContent {
+		t.Errorf("status = %d, want handler response", w.Code)
+	}
+}
+
+func TestConcurrencyLimitMiddlewareByRoute(t *testing.T) {
+	rule, ok := middleware.ParseConcurrencyRule("get /api/v1/users/export", "exports")
+	if !ok {
+		t.Fatal("ParseConcurrencyRule() rejected a valid route")
+	}
+
+	limiter := middleware.NewConcurrencyLimiter(map[string]middleware.LimitGroupConfig{
+		"exports": {Limit: 1, Policy: middleware.PolicyReject, MaxWait: 0},
+	}).WithRules([]middleware.ConcurrencyRule{rule})
+
+	inner := newBlockingHandler()
+	handler := limiter.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/users/export" {
+			inner.ServeHTTP(w, r)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	first := make(chan int, 1)
+	go func() { first <- serve(handler).Code }()
+
+	<-inner.started
+
+	if w := serve(handler); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("second export status = %d, want 503", w.Code)
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/users", nil))
+
+	if w.Code != http.StatusNoContent {
+		t.Errorf("unassigned route status = %d, want 204", w.Code)
+	}
+
+	close(inner.release)
+
+	if code := <-first; code != http.StatusOK {
+		t.Errorf("first export status = %d, want 200", code)
+	}
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met within 2s")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
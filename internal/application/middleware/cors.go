@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"encoding/json/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	pkgerrors "github.com/LarsArtmann/template-arch-lint/pkg/errors"
+)
+
+// CORSOptions configure CORS. Config validation guarantees that the "*"
+// origin is never combined with AllowCredentials.
+type CORSOptions struct {
+	// AllowedOrigins are matched exactly; "*" allows any origin.
+	AllowedOrigins []string
+	// AllowedMethods are the methods a preflight may ask for.
+	AllowedMethods []string
+	// AllowedHeaders are the request headers a preflight may ask for.
+	AllowedHeaders []string
+	// ExposedHeaders are the response headers browser scripts may read.
+	ExposedHeaders []string
+	// AllowCredentials lets browsers send cookies and Authorization.
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache a preflight answer.
+	MaxAge time.Duration
+}
+
+// CORS answers preflight requests and marks responses to allowed origins.
+type CORS struct {
+	options CORSOptions
+}
+
+// NewCORS creates the middleware for options.
+func NewCORS(options CORSOptions) *CORS {
+	return &CORS{options: options}
+}
+
+// Middleware wraps next. Requests without an Origin pass through untouched.
+// A preflight, an OPTIONS request with Access-Control-Request-Method, is
+// answered here with 204, or with 403 when the origin or the method is not
+// allowed; it never reaches next.
+func (c *CORS) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+		if origin == "" {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		w.Header().Add("Vary", "Origin")
+
+		if !c.allowsOrigin(origin) {
+			if preflight {
+				forbidOrigin(w)
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		c.allow(w, origin)
+
+		if !preflight {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		if !slices.Contains(c.options.AllowedMethods, r.Header.Get("Access-Control-Request-Method")) {
+			forbidOrigin(w)
+
+			return
+		}
+
+		header := w.Header()
+		header.Add("Vary", "Access-Control-Request-Method")
+		header.Add("Vary", "Access-Control-Request-Headers")
+		header.Set("Access-Control-Allow-Methods", strings.Join(c.options.AllowedMethods, ", "))
+
+		if len(c.options.AllowedHeaders) > 0 {
+			header.Set("Access-Control-Allow-Headers", strings.Join(c.options.AllowedHeaders, ", "))
+		}
+
+		if c.options.MaxAge > 0 {
+			header.Set("Access-Control-Max-Age", strconv.Itoa(int(c.options.MaxAge.Seconds())))
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func (c *CORS) allowsOrigin(origin string) bool {
+	return slices.Contains(c.options.AllowedOrigins, "*") || slices.Contains(c.options.AllowedOrigins, origin)
+}
+
+// allow sets the headers every response to an allowed origin carries. A
+// wildcard configuration answers "*" unless credentials are allowed.
+func (c *CORS) allow(w http.ResponseWriter, origin string) {
+	header := w.Header()
+
+	allowed := origin
+	if slices.Contains(c.options.AllowedOrigins, "*") && !c.options.AllowCredentials {
+		allowed = "*"
+	}
+
+	header.Set("Access-Control-Allow-Origin", allowed)
+
+	if c.options.AllowCredentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+
+	if len(c.options.ExposedHeaders) > 0 {
+		header.Set("Access-Control-Expose-Headers", strings.Join(c.options.ExposedHeaders, ", "))
+	}
+}
+
+// forbidOrigin rejects a preflight without CORS headers, so the browser
+// blocks the actual request.
+func forbidOrigin(w http.ResponseWriter) {
+	w.Header().Del("Access-Control-Allow-Origin")
+	w.Header().Del("Access-Control-Allow-Credentials")
+	w.Header().Del("Access-Control-Expose-Headers")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusForbidden)
+	_ = json.MarshalWrite(w, pkgerrors.APIError{ //nolint:exhaustruct // a CORS rejection names no field
+		Code:      pkgerrors.APICodeCORSRejected,
+		Message:   "Cross-origin request not allowed",
+		RequestID: w.Header().Get(RequestIDHeader),
+	})
+}
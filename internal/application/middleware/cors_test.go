@@ -0,0 +1,128 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/application/middleware"
+)
+
+var testCORSOptions = middleware.CORSOptions{
+	AllowedOrigins:   []string{"https://app.example.com"},
+	AllowedMethods:   []string{http.MethodGet, http.MethodPost},
+	AllowedHeaders:   []string{"Content-Type", "Authorization"},
+	ExposedHeaders:   []string{"ETag"},
+	AllowCredentials: true,
+	MaxAge:           10 * time.Minute,
+}
+
+// serveCORS runs a request through CORS and reports whether it reached the handler.
+func serveCORS(
+	options middleware.CORSOptions, method string, headers map[string]string,
+) (*httptest.ResponseRecorder, bool) {
+	reached := false
+	handler := middleware.NewCORS(options).Middleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		reached = true
+	}))
+
+	req := httptest.NewRequest(method, "/api/v1/users", nil)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	return w, reached
+}
+
+func TestCORSPreflight(t *testing.T) {
+	w, reached := serveCORS(testCORSOptions, http.MethodOptions, map[string]string{
+		"Origin":                         "https://app.example.com",
+		"Access-Control-Request-Method":  http.MethodPost,
+		"Access-Control-Request-Headers": "content-type",
+	})
+
+	if reached || w.Code != http.StatusNoContent {
+		t.Fatalf("preflight status = %d, reached handler = %v; want 204 answered by the middleware", w.Code, reached)
+	}
+
+	assertHeaders(t, w, map[string]string{
+		"Access-Control-Allow-Origin":      "https://app.example.com",
+		"Access-Control-Allow-Credentials": "true",
+		"Access-Control-Allow-Methods":     "GET, POST",
+		"Access-Control-Allow-Headers":     "Content-Type, Authorization",
+		"Access-Control-Max-Age":           "600",
+	})
+}
+
+func TestCORSRejectsPreflight(t *testing.T) {
+	tests := []struct {
+		name   string
+		origin string
+		method string
+	}{
+		{"unknown origin", "https://evil.example.com", http.MethodPost},
+		{"disallowed method", "https://app.example.com", http.MethodDelete},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, reached := serveCORS(testCORSOptions, http.MethodOptions, map[string]string{
+				"Origin":                        tt.origin,
+				"Access-Control-Request-Method": tt.method,
+			})
+
+			if reached || w.Code != http.StatusForbidden {
+				t.Fatalf("preflight status = %d, reached handler = %v; want 403", w.Code, reached)
+			}
+
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+				t.Errorf("Access-Control-Allow-Origin = %q, want none", got)
+			}
+		})
+	}
+}
+
+func TestCORSActualRequests(t *testing.T) {
+	wildcard := testCORSOptions
+	wildcard.AllowedOrigins = []string{"*"}
+	wildcard.AllowCredentials = false
+
+	tests := []struct {
+		name       string
+		options    middleware.CORSOptions
+		origin     string
+		wantOrigin string
+	}{
+		{"allowed origin", testCORSOptions, "https://app.example.com", "https://app.example.com"},
+		{"unknown origin", testCORSOptions, "https://evil.example.com", ""},
+		{"no origin", testCORSOptions, "", ""},
+		{"wildcard", wildcard, "https://any.example.com", "*"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := map[string]string{}
+			if tt.origin != "" {
+				headers["Origin"] = tt.origin
+			}
+
+			w, reached := serveCORS(tt.options, http.MethodGet, headers)
+			if !reached {
+				t.Fatal("request did not reach the handler")
+			}
+
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+
+			exposed := w.Header().Get("Access-Control-Expose-Headers")
+			if tt.wantOrigin != "" && exposed != "ETag" {
+				t.Errorf("Access-Control-Expose-Headers = %q, want ETag", exposed)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"encoding/json/v2"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"charm.land/log/v2"
+)
+
+// headerNearLimitRatio is the share of the total soft limit at which a
+// request is logged as approaching it.
+const headerNearLimitRatio = 0.8
+
+// HeaderLimitOptions are soft limits checked below the server's hard
+// MaxHeaderBytes, so oversized requests get a 431 with a JSON body instead
+// of the bare response net/http sends. Zero disables a limit.
+type HeaderLimitOptions struct {
+	// MaxTotalBytes bounds the combined size of all header lines.
+	MaxTotalBytes int
+	// MaxFieldBytes bounds a single header line.
+	MaxFieldBytes int
+	// MaxCookieBytes bounds a single cookie's name=value pair.
+	MaxCookieBytes int
+}
+
+// HeaderLimitStats counts requests seen by a HeaderLimiter.
+type HeaderLimitStats struct {
+	Rejected  int64 `json:"rejected"`
+	NearLimit int64 `json:"near_limit"`
+}
+
+// HeaderLimiter rejects requests whose headers or cookies exceed soft limits.
+type HeaderLimiter struct {
+	options   HeaderLimitOptions
+	rejected  atomic.Int64
+	nearLimit atomic.Int64
+}
+
+// NewHeaderLimiter creates a limiter enforcing options.
+func NewHeaderLimiter(options HeaderLimitOptions) *HeaderLimiter {
+	return &HeaderLimiter{options: options} //nolint:exhaustruct // counters start at zero
+}
+
+// Middleware wraps next with the header and cookie checks. Offending header
+// and cookie names are logged with the client IP; values never are.
+func (l *HeaderLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		total, offending := l.measure(r)
+
+		if len(offending) > 0 || (l.options.MaxTotalBytes > 0 && total > l.options.MaxTotalBytes) {
+			l.rejected.Add(1)
+			log.FromContext(r.Context()).Warn("Request headers over soft limit",
+				"client_ip", clientIP(r), "total_bytes", total, "headers", offending)
+			l.reject(w, offending)
+
+			return
+		}
+
+		if l.options.MaxTotalBytes > 0 && float64(total) >= headerNearLimitRatio*float64(l.options.MaxTotalBytes) {
+			l.nearLimit.Add(1)
+			log.FromContext(r.Context()).Warn("Request headers near soft limit",
+				"client_ip", clientIP(r), "total_bytes", total, "limit", l.options.MaxTotalBytes)
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Stats returns the rejection and near-limit counters.
+func (l *HeaderLimiter) Stats() HeaderLimitStats {
+	return HeaderLimitStats{Rejected: l.rejected.Load(), NearLimit: l.nearLimit.Load()}
+}
+
+// measure returns the total header size in wire format and the names of
+// headers and cookies that exceed their own limits.
+func (l *HeaderLimiter) measure(r *http.Request) (int, []string) {
+	var offending []string
+
+	total := 0
+
+	for name, values := range r.Header {
+		for _, value := range values {
+			// "Name: value\r\n"
+			size := len(name) + len(": ") + len(value) + len("\r\n")
+			total += size
+
+			if l.options.MaxFieldBytes > 0 && size > l.options.MaxFieldBytes && !slices.Contains(offending, name) {
+				offending = append(offending, name)
+			}
+		}
+	}
+
+	if l.options.MaxCookieBytes > 0 {
+		for _, cookie := range r.Cookies() {
+			if len(cookie.Name)+len("=")+len(cookie.Value) > l.options.MaxCookieBytes {
+				offending = append(offending, "Cookie "+cookie.Name)
+			}
+		}
+	}
+
+	slices.Sort(offending)
+
+	return total, offending
+}
+
+func (l *HeaderLimiter) reject(w http.ResponseWriter, offending []string) {
+	message := "Request headers are too large"
+	if len(offending) > 0 {
+		message += ": " + strings.Join(offending, ", ")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Connection", "close")
+	w.WriteHeader(http.StatusRequestHeaderFieldsTooLarge)
+	_ = json.MarshalWrite(w, map[string]string{
+		"error":   "request_header_fields_too_large",
+		"message": message,
+	})
+}
+
+// clientIP returns the peer address without its port.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
@@ -0,0 +1,113 @@
+package middleware_test
+
+import (
+	"encoding/json/v2"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/application/middleware"
+)
+
+var testHeaderLimits = middleware.HeaderLimitOptions{
+	MaxTotalBytes:  4096,
+	MaxFieldBytes:  1024,
+	MaxCookieBytes: 512,
+}
+
+func TestHeaderLimiterRejectsOversizedHeaders(t *testing.T) {
+	tests := []struct {
+		name        string
+		setup       func(r *http.Request)
+		wantMessage string
+	}{
+		{
+			name:        "single oversized header",
+			setup:       func(r *http.Request) { r.Header.Set("X-Trace-Blob", strings.Repeat("a", 2000)) },
+			wantMessage: "Request headers are too large: X-Trace-Blob",
+		},
+		{
+			name: "oversized cookie",
+			setup: func(r *http.Request) {
+				r.AddCookie(&http.Cookie{Name: "prefs", Value: strings.Repeat("b", 600)}) //nolint:exhaustruct // test
+				r.AddCookie(&http.Cookie{Name: "session", Value: "abc"})                  //nolint:exhaustruct // test
+			},
+			wantMessage: "Request headers are too large: Cookie prefs",
+		},
+		{
+			name: "many headers over the total",
+			setup: func(r *http.Request) {
+				for i := range 100 {
+					r.Header.Set(fmt.Sprintf("X-Custom-%03d", i), strings.Repeat("c", 40))
+				}
+			},
+			wantMessage: "Request headers are too large",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := middleware.NewHeaderLimiter(testHeaderLimits)
+			called := false
+			handler := limiter.Middleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+				called = true
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/users", nil)
+			tt.setup(req)
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if called || w.Code != http.StatusRequestHeaderFieldsTooLarge {
+				t.Fatalf("status = %d, handler called = %v; want 431 and no call", w.Code, called)
+			}
+
+			var envelope map[string]string
+			if err := json.Unmarshal(w.Body.Bytes(), &envelope); err != nil {
+				t.Fatalf("body is not a JSON envelope: %v", err)
+			}
+
+			want := map[string]string{"error": "request_header_fields_too_large", "message": tt.wantMessage}
+			if !maps.Equal(envelope, want) {
+				t.Errorf("envelope = %v, want %v", envelope, want)
+			}
+
+			if got := limiter.Stats().Rejected; got != 1 {
+				t.Errorf("Rejected = %d, want 1", got)
+			}
+		})
+	}
+}
+
+func TestHeaderLimiterPassesAndCountsNearLimit(t *testing.T) {
+	limiter := middleware.NewHeaderLimiter(testHeaderLimits)
+	handler := limiter.Middleware(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	small := httptest.NewRequest(http.MethodGet, "/", nil)
+	small.Header.Set("X-Request-Id", "abc")
+
+	near := httptest.NewRequest(http.MethodGet, "/", nil)
+	for i := range 5 {
+		near.Header.Set(fmt.Sprintf("X-Custom-%d", i), strings.Repeat("d", 700))
+	}
+
+	for _, req := range []*http.Request{small, near} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNoContent {
+			t.Errorf("status = %d, want 204", w.Code)
+		}
+	}
+
+	want := middleware.HeaderLimitStats{Rejected: 0, NearLimit: 1}
+	if got := limiter.Stats(); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,171 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json/v2"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/clock"
+)
+
+// jwksRefetchInterval spaces out refetches for unknown key IDs, so tokens
+// with made-up kids cannot make every request hit the key endpoint.
+const jwksRefetchInterval = 30 * time.Second
+
+var errUnknownKey = errors.New("unknown signing key")
+
+// JWKSOptions configure a JWKS.
+type JWKSOptions struct {
+	// URL serves the JSON Web Key Set.
+	URL string
+	// CacheTTL is how long fetched keys are used before fetching again.
+	CacheTTL time.Duration
+	// Client fetches the key set; nil means http.DefaultClient.
+	Client *http.Client
+	// Clock returns the current time; nil means the system clock.
+	Clock func() time.Time
+}
+
+// JWKS caches the RSA keys of a JSON Web Key Set by key ID. Keys are
+// fetched on first use and again when the cache expires, or when a token
+// names a key the cache lacks, which is how a rotated key is picked up.
+type JWKS struct {
+	options JWKSOptions
+
+	mu        sync.Mutex
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+}
+
+// NewJWKS creates a key cache for options.URL.
+func NewJWKS(options JWKSOptions) *JWKS {
+	if options.Client == nil {
+		options.Client = http.DefaultClient
+	}
+
+	if options.Clock == nil {
+		options.Clock = clock.System{}.Now
+	}
+
+	return &JWKS{options: options} //nolint:exhaustruct // keys are fetched on first use
+}
+
+// Key returns the RSA key with kid. An empty kid matches the only key of a
+// single-key set.
+func (j *JWKS) Key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := j.options.Clock()
+	age := now.Sub(j.fetchedAt)
+
+	key, found := j.lookup(kid)
+	if j.keys == nil || age >= j.options.CacheTTL || !found && age >= jwksRefetchInterval {
+		keys, err := j.fetch(ctx)
+		if err != nil {
+			// A failed refresh keeps serving the keys already known.
+			if found {
+				return key, nil
+			}
+
+			return nil, err
+		}
+
+		j.keys, j.fetchedAt = keys, now
+		key, found = j.lookup(kid)
+	}
+
+	if !found {
+		return nil, fmt.Errorf("%w %q", errUnknownKey, kid)
+	}
+
+	return key, nil
+}
+
+func (j *JWKS) lookup(kid string) (*rsa.PublicKey, bool) {
+	if kid == "" && len(j.keys) == 1 {
+		for _, key := range j.keys {
+			return key, true
+		}
+	}
+
+	key, ok := j.keys[kid]
+
+	return key, ok
+}
+
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+}
+
+// fetch downloads the key set and keeps its RSA signing keys.
+func (j *JWKS) fetch(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.options.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("build JWKS request: %w", err)
+	}
+
+	resp, err := j.options.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch JWKS: %s", resp.Status)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+
+	err = json.UnmarshalRead(resp.Body, &set)
+	if err != nil {
+		return nil, fmt.Errorf("decode JWKS: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+
+	for _, jwk := range set.Keys {
+		if jwk.KeyType != "RSA" || jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		key, err := rsaPublicKey(jwk)
+		if err == nil {
+			keys[jwk.KeyID] = key
+		}
+	}
+
+	return keys, nil
+}
+
+func rsaPublicKey(jwk jsonWebKey) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil {
+		return nil, fmt.Errorf("decode modulus: %w", err)
+	}
+
+	e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	if err != nil {
+		return nil, fmt.Errorf("decode exponent: %w", err)
+	}
+
+	exponent := new(big.Int).SetBytes(e)
+	if !exponent.IsInt64() || exponent.Int64() > 1<<31-1 {
+		return nil, errors.New("exponent out of range")
+	}
+
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+}
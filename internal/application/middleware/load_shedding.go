@@ -0,0 +1,221 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json/v2"
+	"maps"
+	"net/http"
+	"runtime"
+	"runtime/metrics"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"charm.land/log/v2"
+	pkgerrors "github.com/LarsArtmann/template-arch-lint/pkg/errors"
+)
+
+// heapBytesMetric is the runtime/metrics name of the live heap size.
+const heapBytesMetric = "/memory/classes/heap/objects:bytes"
+
+// ShedObserver is told about every shed request by route template, for a
+// counter metric.
+type ShedObserver interface {
+	ObserveShed(route string)
+}
+
+// LoadSheddingOptions configure a LoadShedder. A zero limit disables that
+// check.
+type LoadSheddingOptions struct {
+	// MaxInFlight is the number of requests served at once beyond which
+	// new requests are shed.
+	MaxInFlight int64
+	// MaxGoroutines is the goroutine count beyond which requests are shed.
+	MaxGoroutines int64
+	// MaxHeapBytes is the live heap size beyond which requests are shed.
+	MaxHeapBytes int64
+	// ExemptPrefixes are path prefixes that are never shed, such as the
+	// health endpoints.
+	ExemptPrefixes []string
+	// RetryAfter is advertised to shed clients; it is at least one second.
+	RetryAfter time.Duration
+	// SampleInterval is how often Run samples goroutines and heap.
+	SampleInterval time.Duration
+}
+
+// LoadSheddingStats is the current load and the shed requests by route.
+type LoadSheddingStats struct {
+	InFlight   int64            `json:"in_flight"`
+	Goroutines int64            `json:"goroutines"`
+	HeapBytes  int64            `json:"heap_bytes"`
+	Shed       map[string]int64 `json:"shed"`
+}
+
+// LoadShedder rejects non-exempt requests with 503 while the server is over
+// a soft limit. The admission decision reads only atomics: goroutines and
+// heap are sampled by Run, not per request, and options are swapped whole
+// by SetOptions.
+type LoadShedder struct {
+	options    atomic.Pointer[LoadSheddingOptions]
+	inFlight   atomic.Int64
+	goroutines atomic.Int64
+	heapBytes  atomic.Int64
+	mux        *http.ServeMux
+	observer   ShedObserver
+
+	mu   sync.Mutex
+	shed map[string]int64
+}
+
+// NewLoadShedder creates a shedder enforcing options and takes a first
+// sample. mux names the route of shed requests, which are rejected before
+// routing; observer may be nil.
+func NewLoadShedder(options LoadSheddingOptions, mux *http.ServeMux, observer ShedObserver) *LoadShedder {
+	shedder := &LoadShedder{ //nolint:exhaustruct // atomics and mu have valid zero values
+		mux:      mux,
+		observer: observer,
+		shed:     make(map[string]int64),
+	}
+	shedder.SetOptions(options)
+	shedder.Sample()
+
+	return shedder
+}
+
+// SetOptions replaces the limits at runtime; requests in flight are not
+// affected.
+func (s *LoadShedder) SetOptions(options LoadSheddingOptions) {
+	options.ExemptPrefixes = append([]string(nil), options.ExemptPrefixes...)
+	s.options.Store(&options)
+}
+
+// Sample records the current goroutine count and live heap size.
+func (s *LoadShedder) Sample() {
+	sample := []metrics.Sample{{Name: heapBytesMetric}} //nolint:exhaustruct // Read fills Value
+	metrics.Read(sample)
+
+	if sample[0].Value.Kind() == metrics.KindUint64 {
+		s.heapBytes.Store(int64(min(sample[0].Value.Uint64(), uint64(1<<63-1)))) //nolint:gosec // clamped
+	}
+
+	s.goroutines.Store(int64(runtime.NumGoroutine()))
+}
+
+// Run samples every SampleInterval until ctx is done. Without Run the
+// goroutine and heap limits see only the sample NewLoadShedder took.
+func (s *LoadShedder) Run(ctx context.Context) {
+	interval := s.options.Load().SampleInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Sample()
+		}
+	}
+}
+
+// Middleware wraps next with load shedding. Every admitted request counts
+// towards the in-flight gauge, exempt ones included.
+func (s *LoadShedder) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		options := s.options.Load()
+		inFlight := s.inFlight.Add(1)
+
+		// Release in a defer so a panicking handler cannot leak the count.
+		defer s.inFlight.Add(-1)
+
+		if reason := s.overloaded(options, inFlight); reason != "" && !isExempt(options, r.URL.Path) {
+			s.reject(w, r, options, reason)
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Stats returns the current load and the shed counts by route.
+func (s *LoadShedder) Stats() LoadSheddingStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return LoadSheddingStats{
+		InFlight:   s.inFlight.Load(),
+		Goroutines: s.goroutines.Load(),
+		HeapBytes:  s.heapBytes.Load(),
+		Shed:       maps.Clone(s.shed),
+	}
+}
+
+// overloaded names the first soft limit exceeded, or returns "". inFlight
+// includes the request being decided.
+func (s *LoadShedder) overloaded(options *LoadSheddingOptions, inFlight int64) string {
+	switch {
+	case options.MaxInFlight > 0 && inFlight > options.MaxInFlight:
+		return "in_flight"
+	case options.MaxGoroutines > 0 && s.goroutines.Load() > options.MaxGoroutines:
+		return "goroutines"
+	case options.MaxHeapBytes > 0 && s.heapBytes.Load() > options.MaxHeapBytes:
+		return "heap_bytes"
+	default:
+		return ""
+	}
+}
+
+func isExempt(options *LoadSheddingOptions, path string) bool {
+	for _, prefix := range options.ExemptPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *LoadShedder) reject(w http.ResponseWriter, r *http.Request, options *LoadSheddingOptions, reason string) {
+	route := s.route(r)
+
+	s.mu.Lock()
+	s.shed[route]++
+	s.mu.Unlock()
+
+	if s.observer != nil {
+		s.observer.ObserveShed(route)
+	}
+
+	log.FromContext(r.Context()).Warn("Request shed",
+		"method", r.Method, "route", route, "reason", reason)
+
+	w.Header().Set("Retry-After", strconv.Itoa(max(1, ceilSeconds(options.RetryAfter))))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusServiceUnavailable)
+	_ = json.MarshalWrite(w, pkgerrors.APIError{ //nolint:exhaustruct // an overload names no field
+		Code:      pkgerrors.APICodeOverloaded,
+		Message:   "Server is overloaded, retry later",
+		RequestID: w.Header().Get(RequestIDHeader),
+	})
+}
+
+// route names r by the pattern mux would route it to, so the shed counts
+// stay bounded by the routes rather than the paths.
+func (s *LoadShedder) route(r *http.Request) string {
+	if s.mux == nil {
+		return unmatchedRoute
+	}
+
+	if _, pattern := s.mux.Handler(r); pattern != "" {
+		return patternPath(pattern)
+	}
+
+	return unmatchedRoute
+}
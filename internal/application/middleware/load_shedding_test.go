@@ -0,0 +1,139 @@
+package middleware_test
+
+import (
+	"encoding/json/v2"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/application/middleware"
+	pkgerrors "github.com/LarsArtmann/template-arch-lint/pkg/errors"
+)
+
+type fakeShedObserver struct {
+	mu     sync.Mutex
+	routes []string
+}
+
+func (o *fakeShedObserver) ObserveShed(route string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.routes = append(o.routes, route)
+}
+
+func sheddingMux(users http.Handler) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.Handle("GET /api/v1/users", users)
+	mux.HandleFunc("GET /health/live", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	return mux
+}
+
+func get(handler http.Handler, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+	return w
+}
+
+func TestLoadSheddingInFlightLimit(t *testing.T) {
+	users := newBlockingHandler()
+	mux := sheddingMux(users)
+	observer := &fakeShedObserver{mu: sync.Mutex{}, routes: nil}
+	shedder := middleware.NewLoadShedder(middleware.LoadSheddingOptions{ //nolint:exhaustruct // no other limits
+		MaxInFlight:    2,
+		ExemptPrefixes: []string{"/health"},
+		RetryAfter:     3 * time.Second,
+	}, mux, observer)
+	handler := shedder.Middleware(mux)
+
+	var wg sync.WaitGroup
+
+	for range 2 {
+		wg.Go(func() { get(handler, "/api/v1/users") })
+		<-users.started
+	}
+
+	w := get(handler, "/api/v1/users")
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503 while 2 slow requests are in flight", w.Code)
+	}
+
+	if got := w.Header().Get("Retry-After"); got != "3" {
+		t.Errorf("Retry-After = %q, want 3", got)
+	}
+
+	var body pkgerrors.APIError
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Code != pkgerrors.APICodeOverloaded {
+		t.Errorf("body = %s, want code %s", w.Body.String(), pkgerrors.APICodeOverloaded)
+	}
+
+	if got := get(handler, "/health/live").Code; got != http.StatusOK {
+		t.Errorf("health status = %d, want 200 while shedding", got)
+	}
+
+	if stats := shedder.Stats(); stats.InFlight != 2 || stats.Shed["/api/v1/users"] != 1 {
+		t.Errorf("stats = %+v, want 2 in flight and 1 shed on /api/v1/users", stats)
+	}
+
+	if len(observer.routes) != 1 || observer.routes[0] != "/api/v1/users" {
+		t.Errorf("observed %v, want [/api/v1/users]", observer.routes)
+	}
+
+	close(users.release)
+	wg.Wait()
+
+	if got := get(handler, "/api/v1/users").Code; got != http.StatusOK {
+		t.Errorf("status = %d, want 200 once the slow requests finished", got)
+	}
+}
+
+func TestLoadSheddingSetOptions(t *testing.T) {
+	mux := sheddingMux(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	// Any running test has more than one goroutine.
+	shedder := middleware.NewLoadShedder(middleware.LoadSheddingOptions{ //nolint:exhaustruct // goroutines only
+		MaxGoroutines: 1,
+	}, mux, nil)
+	handler := shedder.Middleware(mux)
+
+	w := get(handler, "/api/v1/users")
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503 over the goroutine limit", w.Code)
+	}
+
+	if got := w.Header().Get("Retry-After"); got != "1" {
+		t.Errorf("Retry-After = %q, want the 1 second minimum", got)
+	}
+
+	shedder.SetOptions(middleware.LoadSheddingOptions{MaxGoroutines: 1 << 20}) //nolint:exhaustruct // goroutines only
+
+	if got := get(handler, "/api/v1/users").Code; got != http.StatusOK {
+		t.Errorf("status = %d, want 200 after raising the limit", got)
+	}
+
+	if got := shedder.Stats().Shed; got["/api/v1/users"] != 1 {
+		t.Errorf("shed = %v, want 1 on /api/v1/users", got)
+	}
+}
+
+func TestLoadSheddingUnmatchedRoute(t *testing.T) {
+	shedder := middleware.NewLoadShedder(middleware.LoadSheddingOptions{ //nolint:exhaustruct // goroutines only
+		MaxGoroutines: 1,
+	}, nil, nil)
+
+	w := get(shedder.Middleware(http.NotFoundHandler()), "/no/such/path")
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503", w.Code)
+	}
+
+	if got := shedder.Stats().Shed; got["unmatched"] != 1 || len(got) != 1 {
+		t.Errorf("shed = %v, want only unmatched", got)
+	}
+}
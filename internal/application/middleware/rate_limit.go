@@ -0,0 +1,294 @@
+package middleware
+
+import (
+	"encoding/json/v2"
+	"math"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"charm.land/log/v2"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/clock"
+	pkgerrors "github.com/LarsArtmann/template-arch-lint/pkg/errors"
+)
+
+// RateLimitRule limits the requests to one route group.
+type RateLimitRule struct {
+	// Method restricts the rule to one HTTP method; empty matches any.
+	Method string
+	// PathPrefix selects the paths the rule covers.
+	PathPrefix string
+	// Requests is the number of requests allowed per window and client.
+	Requests int
+}
+
+// ParseRateLimitRule parses a route group of the form "[METHOD ]/prefix",
+// such as "POST /api/v1/users" or "/api/v1". The method is case-insensitive.
+func ParseRateLimitRule(route string, requests int) (RateLimitRule, bool) {
+	method, prefix, ok := parseRouteGroup(route)
+	if !ok || requests <= 0 {
+		return RateLimitRule{}, false //nolint:exhaustruct // invalid rule
+	}
+
+	return RateLimitRule{Method: method, PathPrefix: prefix, Requests: requests}, true
+}
+
+// RateLimitOptions configure a RateLimiter.
+type RateLimitOptions struct {
+	// Requests is allowed per window and client on routes that match no
+	// rule. Zero leaves those routes unlimited.
+	Requests int
+	// Window is the period over which a bucket refills completely.
+	Window time.Duration
+	// Rules override Requests for route groups; the longest matching
+	// PathPrefix wins, and a rule with a Method beats one without.
+	Rules []RateLimitRule
+	// TrustedProxies lists the IPs and CIDR ranges whose X-Forwarded-For
+	// is believed. Requests from anywhere else are keyed by the peer address.
+	TrustedProxies []string
+	// IdleTimeout evicts a client's bucket after this long without a
+	// request. Zero means twice the window.
+	IdleTimeout time.Duration
+	// Clock returns the current time; nil means the system clock.
+	Clock func() time.Time
+}
+
+// RateLimitStats counts the work of a RateLimiter.
+type RateLimitStats struct {
+	Limited int64 `json:"limited"`
+	Buckets int   `json:"buckets"`
+}
+
+// bucketKey separates the buckets of one client per rule, so a busy route
+// group does not use up another group's allowance.
+type bucketKey struct {
+	rule int
+	ip   string
+}
+
+type bucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+// RateLimiter throttles clients with a token bucket per client IP and route
+// group. A bucket holds the group's request limit and refills at that rate
+// per window, so a client may burst up to the limit and then continues at
+// the average rate.
+type RateLimiter struct {
+	options RateLimitOptions
+	trusted []netip.Prefix
+	limited atomic.Int64
+
+	mu        sync.Mutex
+	buckets   map[bucketKey]*bucket
+	lastSweep time.Time
+}
+
+// NewRateLimiter creates a limiter enforcing options. Trusted proxies that
+// are neither an IP nor a CIDR range are ignored; config validation
+// rejects them before they get here.
+func NewRateLimiter(options RateLimitOptions) *RateLimiter {
+	if options.Clock == nil {
+		options.Clock = clock.System{}.Now
+	}
+
+	if options.IdleTimeout <= 0 {
+		options.IdleTimeout = 2 * options.Window
+	}
+
+	limiter := &RateLimiter{ //nolint:exhaustruct // counters and buckets start empty
+		options:   options,
+		buckets:   make(map[bucketKey]*bucket),
+		lastSweep: options.Clock(),
+	}
+
+	for _, proxy := range options.TrustedProxies {
+		prefix, err := parseIPOrPrefix(proxy)
+		if err == nil {
+			limiter.trusted = append(limiter.trusted, prefix)
+		}
+	}
+
+	return limiter
+}
+
+// Middleware wraps next with the rate limit. Every limited response carries
+// X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset, the
+// seconds until the bucket is full again; a rejected request also gets
+// Retry-After and a 429 APIError.
+func (l *RateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rule, limit := l.match(r)
+		if limit <= 0 {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		ip := l.clientIP(r)
+		allowed, remaining, reset, retryAfter := l.take(bucketKey{rule: rule, ip: ip}, limit)
+
+		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(limit))
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		w.Header().Set("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(reset)))
+
+		if !allowed {
+			l.limited.Add(1)
+			log.FromContext(r.Context()).Warn("Request rate limited",
+				"client_ip", ip, "method", r.Method, "path", r.URL.Path, "limit", limit)
+			l.reject(w, retryAfter)
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Stats returns the rejection counter and the number of live buckets.
+func (l *RateLimiter) Stats() RateLimitStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return RateLimitStats{Limited: l.limited.Load(), Buckets: len(l.buckets)}
+}
+
+// match returns the index of the rule covering r, or -1 for the default
+// limit, together with the limit that applies.
+func (l *RateLimiter) match(r *http.Request) (int, int) {
+	best := matchRouteGroup(r, len(l.options.Rules), func(i int) (string, string) {
+		return l.options.Rules[i].Method, l.options.Rules[i].PathPrefix
+	})
+	if best < 0 {
+		return -1, l.options.Requests
+	}
+
+	return best, l.options.Rules[best].Requests
+}
+
+// take removes a token from the bucket at key if it has one. It returns
+// whether it did, the whole tokens left, the time until the bucket is full
+// and the time until the next token.
+func (l *RateLimiter) take(key bucketKey, limit int) (bool, int, time.Duration, time.Duration) {
+	now := l.options.Clock()
+	perToken := l.options.Window / time.Duration(limit)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit), lastSeen: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = min(float64(limit), b.tokens+float64(now.Sub(b.lastSeen))/float64(perToken))
+	b.lastSeen = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+
+	reset := time.Duration((float64(limit) - b.tokens) * float64(perToken))
+	retryAfter := time.Duration((1 - b.tokens) * float64(perToken))
+
+	return allowed, int(b.tokens), reset, retryAfter
+}
+
+// sweep evicts the buckets idle for longer than IdleTimeout, at most once
+// per IdleTimeout, so memory stays bounded by the clients of recent windows.
+// An evicted bucket was full anyway, so eviction changes no decision.
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.options.IdleTimeout {
+		return
+	}
+
+	l.lastSweep = now
+
+	for key, b := range l.buckets {
+		if now.Sub(b.lastSeen) >= l.options.IdleTimeout {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// clientIP returns the peer address, or when the peer is a trusted proxy,
+// the rightmost X-Forwarded-For address that is not itself a trusted proxy.
+func (l *RateLimiter) clientIP(r *http.Request) string {
+	peer := clientIP(r)
+	if !l.isTrusted(peer) {
+		return peer
+	}
+
+	forwarded := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(forwarded[i])
+		if hop == "" {
+			continue
+		}
+
+		if !l.isTrusted(hop) {
+			return hop
+		}
+
+		peer = hop
+	}
+
+	return peer
+}
+
+func (l *RateLimiter) isTrusted(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+
+	addr = addr.Unmap()
+
+	for _, prefix := range l.trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (l *RateLimiter) reject(w http.ResponseWriter, retryAfter time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(max(1, ceilSeconds(retryAfter))))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusTooManyRequests)
+	_ = json.MarshalWrite(w, pkgerrors.APIError{ //nolint:exhaustruct // a rate limit names no field
+		Code:      pkgerrors.APICodeRateLimited,
+		Message:   "Too many requests",
+		RequestID: w.Header().Get(RequestIDHeader),
+	})
+}
+
+// parseIPOrPrefix accepts "10.0.0.1" as well as "10.0.0.0/8".
+func parseIPOrPrefix(s string) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		return netip.ParsePrefix(s)
+	}
+
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+
+	addr = addr.Unmap()
+
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
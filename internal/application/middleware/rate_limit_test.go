@@ -0,0 +1,222 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/application/middleware"
+)
+
+// fakeClock is a settable time source for RateLimitOptions.Clock.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestRateLimiter(clock *fakeClock, options middleware.RateLimitOptions) http.Handler {
+	options.Clock = clock.Now
+
+	noContent := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	return middleware.NewRateLimiter(options).Middleware(noContent)
+}
+
+func rateLimitedRequest(
+	handler http.Handler, method, path, remoteAddr, forwardedFor string,
+) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.RemoteAddr = remoteAddr
+
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	return w
+}
+
+// getUsers lists users as one fixed client.
+func getUsers(handler http.Handler) *httptest.ResponseRecorder {
+	return rateLimitedRequest(handler, http.MethodGet, "/api/v1/users", "192.0.2.1:1234", "")
+}
+
+func TestRateLimiterBurstThenRefill(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	handler := newTestRateLimiter(clock, middleware.RateLimitOptions{ //nolint:exhaustruct // test
+		Requests: 3,
+		Window:   3 * time.Second,
+	})
+
+	for i := range 3 {
+		if w := getUsers(handler); w.Code != http.StatusNoContent {
+			t.Fatalf("burst request %d status = %d, want 204", i, w.Code)
+		}
+	}
+
+	if w := getUsers(handler); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("request over the burst status = %d, want 429", w.Code)
+	}
+
+	clock.Advance(time.Second)
+
+	if w := getUsers(handler); w.Code != http.StatusNoContent {
+		t.Fatalf("request after one token refilled status = %d, want 204", w.Code)
+	}
+
+	if w := getUsers(handler); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("second request after one refill status = %d, want 429", w.Code)
+	}
+}
+
+func TestRateLimiterHeaders(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	handler := newTestRateLimiter(clock, middleware.RateLimitOptions{ //nolint:exhaustruct // test
+		Requests: 2,
+		Window:   time.Minute,
+	})
+
+	first := getUsers(handler)
+	assertHeaders(t, first, map[string]string{
+		"X-RateLimit-Limit": "2", "X-RateLimit-Remaining": "1", "X-RateLimit-Reset": "30", "Retry-After": "",
+	})
+
+	getUsers(handler)
+
+	limited := getUsers(handler)
+	if limited.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429", limited.Code)
+	}
+
+	assertHeaders(t, limited, map[string]string{
+		"X-RateLimit-Limit": "2", "X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "60", "Retry-After": "30",
+	})
+
+	if body := limited.Body.String(); body != `{"code":"RATE_LIMITED","message":"Too many requests"}` {
+		t.Errorf("body = %s, want a RATE_LIMITED APIError", body)
+	}
+}
+
+func assertHeaders(t *testing.T, w *httptest.ResponseRecorder, want map[string]string) {
+	t.Helper()
+
+	for name, value := range want {
+		if got := w.Header().Get(name); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+}
+
+func TestRateLimiterKeepsBucketsPerClientAndRouteGroup(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	rule, ok := middleware.ParseRateLimitRule("post /api/v1/users", 1)
+	if !ok {
+		t.Fatal("ParseRateLimitRule rejected a valid route")
+	}
+
+	handler := newTestRateLimiter(clock, middleware.RateLimitOptions{ //nolint:exhaustruct // test
+		Requests:       1,
+		Window:         time.Minute,
+		Rules:          []middleware.RateLimitRule{rule},
+		TrustedProxies: []string{"10.0.0.0/8"},
+	})
+
+	tests := []struct {
+		name         string
+		method       string
+		remoteAddr   string
+		forwardedFor string
+		want         int
+	}{
+		{"first client", http.MethodGet, "192.0.2.1:1", "", http.StatusNoContent},
+		{"first client again", http.MethodGet, "192.0.2.1:2", "", http.StatusTooManyRequests},
+		{"second client", http.MethodGet, "192.0.2.2:1", "", http.StatusNoContent},
+		{"first client in another group", http.MethodPost, "192.0.2.1:3", "", http.StatusNoContent},
+		{"client behind proxies", http.MethodGet, "10.0.0.5:1", "198.51.100.7, 10.0.0.9", http.StatusNoContent},
+		{"same client via another proxy", http.MethodGet, "10.1.2.3:1", "198.51.100.7", http.StatusTooManyRequests},
+		{"spoofed untrusted header", http.MethodGet, "192.0.2.2:9", "198.51.100.8", http.StatusTooManyRequests},
+	}
+
+	for _, tt := range tests {
+		w := rateLimitedRequest(handler, tt.method, "/api/v1/users", tt.remoteAddr, tt.forwardedFor)
+		if w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.want)
+		}
+	}
+}
+
+func TestRateLimiterEvictsIdleBuckets(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	limiter := middleware.NewRateLimiter(middleware.RateLimitOptions{ //nolint:exhaustruct // test
+		Requests: 5,
+		Window:   time.Minute,
+		Clock:    clock.Now,
+	})
+	handler := limiter.Middleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+
+	for i := range 50 {
+		rateLimitedRequest(handler, http.MethodGet, "/", "192.0.2."+strconv.Itoa(i)+":1", "")
+	}
+
+	if got := limiter.Stats().Buckets; got != 50 {
+		t.Fatalf("buckets = %d, want 50", got)
+	}
+
+	clock.Advance(2 * time.Minute)
+	rateLimitedRequest(handler, http.MethodGet, "/", "192.0.2.200:1", "")
+
+	if got := limiter.Stats().Buckets; got != 1 {
+		t.Errorf("buckets after the idle timeout = %d, want only the new client's", got)
+	}
+}
+
+func TestRateLimiterLeavesUnlimitedRoutesAlone(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	rule, _ := middleware.ParseRateLimitRule("/api/v1", 1)
+	handler := newTestRateLimiter(clock, middleware.RateLimitOptions{ //nolint:exhaustruct // test
+		Window: time.Minute,
+		Rules:  []middleware.RateLimitRule{rule},
+	})
+
+	for range 3 {
+		w := rateLimitedRequest(handler, http.MethodGet, "/health/live", "192.0.2.1:1", "")
+		if w.Code != http.StatusNoContent || w.Header().Get("X-RateLimit-Limit") != "" {
+			t.Fatalf("unlimited route status = %d, headers = %v", w.Code, w.Header())
+		}
+	}
+}
+
+func TestRateLimiterMatchesWholePathSegments(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	rule, _ := middleware.ParseRateLimitRule("/api/v1/users", 1)
+	handler := newTestRateLimiter(clock, middleware.RateLimitOptions{ //nolint:exhaustruct // test
+		Window: time.Minute,
+		Rules:  []middleware.RateLimitRule{rule},
+	})
+
+	tests := []struct {
+		path    string
+		limited bool
+	}{
+		{"/api/v1/users", true},
+		{"/api/v1/users/42", true},
+		{"/api/v1/users-archive", false},
+		{"/api/v1/usersettings", false},
+	}
+
+	for _, tt := range tests {
+		w := rateLimitedRequest(handler, http.MethodGet, tt.path, "192.0.2."+strconv.Itoa(len(tt.path))+":1", "")
+		if limited := w.Header().Get("X-RateLimit-Limit") != ""; limited != tt.limited {
+			t.Errorf("%s: limited = %v, want %v", tt.path, limited, tt.limited)
+		}
+	}
+}
@@ -0,0 +1,357 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"charm.land/log/v2"
+)
+
+const (
+	// maxFixtureBody is the largest request or response body that is recorded.
+	maxFixtureBody = 1 << 20
+
+	redactedValue = "[REDACTED]"
+)
+
+// strippedHeaders never appear in fixtures.
+var strippedHeaders = []string{"Authorization", "Cookie", "Proxy-Authorization", "Set-Cookie", "X-Api-Key"}
+
+// redactedFieldMarkers mark JSON fields whose values are replaced in fixtures.
+var redactedFieldMarkers = []string{"password", "secret", "token", "authorization", "api_key"}
+
+// Fixture is one recorded request/response pair.
+type Fixture struct {
+	Request  FixtureRequest  `json:"request"`
+	Response FixtureResponse `json:"response"`
+}
+
+// FixtureRequest is the recorded request.
+type FixtureRequest struct {
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Query   string            `json:"query,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    any               `json:"body,omitempty"`
+}
+
+// FixtureResponse is the recorded response.
+type FixtureResponse struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    any               `json:"body,omitempty"`
+}
+
+// RecordingOptions configures a Recorder.
+type RecordingOptions struct {
+	// Dir is the fixture root; files go to Dir/route/method/status/NNNN.json.
+	Dir string
+	// SampleRate is the fraction of matching requests recorded, 0 to 1.
+	SampleRate float64
+	// Routes are path prefixes to record. Empty records every route.
+	Routes []string
+}
+
+// Recorder captures sanitized request/response pairs as fixture files.
+// It is meant for development and test environments only.
+type Recorder struct {
+	options RecordingOptions
+	sample  func() float64
+
+	mu        sync.Mutex
+	sequences map[string]int
+	writes    sync.WaitGroup
+}
+
+// NewRecorder creates a Recorder.
+func NewRecorder(options RecordingOptions) *Recorder {
+	return &Recorder{ //nolint:exhaustruct // mu and writes have valid zero values
+		options:   options,
+		sample:    rand.Float64,
+		sequences: make(map[string]int),
+	}
+}
+
+// Middleware records matching requests. Fixtures are written in the
+// background; a failed write is logged and never affects the response.
+func (rec *Recorder) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !rec.shouldRecord(r) {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		requestBody, complete := peekBody(r)
+		capture := &captureWriter{ResponseWriter: w, status: http.StatusOK, body: bytes.Buffer{}, overflow: false}
+
+		next.ServeHTTP(capture, r)
+
+		if !complete || capture.overflow {
+			return
+		}
+
+		fixture := Fixture{
+			Request: FixtureRequest{
+				Method:  r.Method,
+				Path:    r.URL.Path,
+				Query:   r.URL.RawQuery,
+				Headers: sanitizeHeaders(r.Header),
+				Body:    sanitizeBody(requestBody),
+			},
+			Response: FixtureResponse{
+				Status:  capture.status,
+				Headers: sanitizeHeaders(capture.Header()),
+				Body:    sanitizeBody(capture.body.Bytes()),
+			},
+		}
+		route := routeOf(r)
+
+		rec.writes.Go(func() {
+			err := rec.write(route, fixture)
+			if err != nil {
+				log.FromContext(r.Context()).Warn("Failed to write request fixture", "route", route, "error", err)
+			}
+		})
+	})
+}
+
+// Wait blocks until all pending fixture writes have finished.
+func (rec *Recorder) Wait() {
+	rec.writes.Wait()
+}
+
+func (rec *Recorder) shouldRecord(r *http.Request) bool {
+	if len(rec.options.Routes) > 0 && !slices.ContainsFunc(rec.options.Routes, func(prefix string) bool {
+		return strings.HasPrefix(r.URL.Path, prefix)
+	}) {
+		return false
+	}
+
+	return rec.sample() < rec.options.SampleRate
+}
+
+func (rec *Recorder) write(route string, fixture Fixture) error {
+	dir := filepath.Join(rec.options.Dir, route, fixture.Request.Method, strconv.Itoa(fixture.Response.Status))
+
+	err := os.MkdirAll(dir, 0o750)
+	if err != nil {
+		return fmt.Errorf("create fixture directory: %w", err)
+	}
+
+	return WriteFixture(filepath.Join(dir, rec.nextSequence(dir)), fixture)
+}
+
+// nextSequence numbers fixtures per directory, continuing after files
+// recorded by earlier runs.
+func (rec *Recorder) nextSequence(dir string) string {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	sequence, seen := rec.sequences[dir]
+	if !seen {
+		entries, _ := os.ReadDir(dir)
+		sequence = len(entries)
+	}
+
+	sequence++
+	rec.sequences[dir] = sequence
+
+	return fmt.Sprintf("%04d.json", sequence)
+}
+
+// EncodeFixture renders a fixture with sorted keys and indentation so
+// re-recorded fixtures produce clean diffs.
+func EncodeFixture(fixture Fixture) ([]byte, error) {
+	data, err := json.Marshal(fixture, json.Deterministic(true), jsontext.WithIndent("  "))
+	if err != nil {
+		return nil, fmt.Errorf("encode fixture: %w", err)
+	}
+
+	return append(data, '\n'), nil
+}
+
+// WriteFixture writes fixture to path in EncodeFixture format.
+func WriteFixture(path string, fixture Fixture) error {
+	data, err := EncodeFixture(fixture)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(path, data, 0o600)
+	if err != nil {
+		return fmt.Errorf("write fixture %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// ReadFixture loads a fixture written by WriteFixture.
+func ReadFixture(path string) (Fixture, error) {
+	var fixture Fixture
+
+	data, err := os.ReadFile(path) //nolint:gosec // fixture paths come from the test tree
+	if err != nil {
+		return fixture, fmt.Errorf("read fixture %s: %w", path, err)
+	}
+
+	err = json.Unmarshal(data, &fixture)
+	if err != nil {
+		return fixture, fmt.Errorf("decode fixture %s: %w", path, err)
+	}
+
+	return fixture, nil
+}
+
+// RedactBody applies the fixture redaction rules to a decoded JSON value.
+func RedactBody(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		redacted := make(map[string]any, len(v))
+		for key, field := range v {
+			if isRedactedField(key) {
+				redacted[key] = redactedValue
+			} else {
+				redacted[key] = RedactBody(field)
+			}
+		}
+
+		return redacted
+	case []any:
+		redacted := make([]any, len(v))
+		for i, item := range v {
+			redacted[i] = RedactBody(item)
+		}
+
+		return redacted
+	default:
+		return value
+	}
+}
+
+// DecodeBody decodes a JSON body for comparison, falling back to the raw
+// text for non-JSON bodies.
+func DecodeBody(body []byte) any {
+	if len(body) == 0 {
+		return nil
+	}
+
+	var value any
+
+	err := json.Unmarshal(body, &value)
+	if err != nil {
+		return string(body)
+	}
+
+	return value
+}
+
+func sanitizeBody(body []byte) any {
+	return RedactBody(DecodeBody(body))
+}
+
+func isRedactedField(key string) bool {
+	lower := strings.ToLower(key)
+
+	return slices.ContainsFunc(redactedFieldMarkers, func(marker string) bool {
+		return strings.Contains(lower, marker)
+	})
+}
+
+func sanitizeHeaders(header http.Header) map[string]string {
+	headers := make(map[string]string, len(header))
+
+	for name, values := range header {
+		if len(values) == 0 || slices.Contains(strippedHeaders, http.CanonicalHeaderKey(name)) {
+			continue
+		}
+
+		headers[http.CanonicalHeaderKey(name)] = values[0]
+	}
+
+	if len(headers) == 0 {
+		return nil
+	}
+
+	return headers
+}
+
+// routeOf names the fixture directory after the matched route pattern when
+// the request went through a ServeMux, otherwise after the path.
+func routeOf(r *http.Request) string {
+	route := r.URL.Path
+	if r.Pattern != "" {
+		_, path, found := strings.Cut(r.Pattern, " ")
+		if !found {
+			path = r.Pattern
+		}
+
+		route = path
+	}
+
+	route = strings.NewReplacer("{", "_", "}", "_", "...", "").Replace(strings.Trim(route, "/"))
+	if route == "" {
+		return "root"
+	}
+
+	return filepath.FromSlash(route)
+}
+
+// peekBody reads the request body for recording and restores it for the
+// handler. complete is false when the body is too large to record.
+func peekBody(r *http.Request) ([]byte, bool) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, true
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxFixtureBody+1))
+	r.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(body), r.Body), Closer: r.Body}
+
+	return body, err == nil && len(body) <= maxFixtureBody
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// captureWriter copies the response while passing it through.
+type captureWriter struct {
+	http.ResponseWriter
+
+	status   int
+	body     bytes.Buffer
+	overflow bool
+}
+
+func (w *captureWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *captureWriter) Write(data []byte) (int, error) {
+	if w.body.Len()+len(data) > maxFixtureBody {
+		w.overflow = true
+	} else {
+		w.body.Write(data)
+	}
+
+	return w.ResponseWriter.Write(data)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, so a
+// recorded export can still flush and extend its deadlines.
+func (w *captureWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
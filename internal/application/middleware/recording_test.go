@@ -0,0 +1,145 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/application/middleware"
+)
+
+const recordedFixture = `{
+  "request": {
+    "method": "POST",
+    "path": "/api/v1/users",
+    "headers": {
+      "Content-Type": "application/json"
+    },
+    "body": {
+      "email": "jane@example.com",
+      "password": "[REDACTED]"
+    }
+  },
+  "response": {
+    "status": 201,
+    "headers": {
+      "Content-Type": "application/json"
+    },
+    "body": {
+      "email": "jane@example.com",
+      "id": "user_1"
+    }
+  }
+}
+`
+
+func createUserHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/users", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc"}) //nolint:exhaustruct // test cookie
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":"user_1","email":"jane@example.com"}`))
+	})
+
+	return mux
+}
+
+func postUser(handler http.Handler) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/users",
+		strings.NewReader(`{"password":"hunter2","email":"jane@example.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer secret-token")
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	return w
+}
+
+func TestRecorderWritesStableRedactedFixture(t *testing.T) {
+	dir := t.TempDir()
+	recorder := middleware.NewRecorder(middleware.RecordingOptions{Dir: dir, SampleRate: 1, Routes: nil})
+	handler := recorder.Middleware(createUserHandler())
+
+	postUser(handler)
+	postUser(handler)
+	recorder.Wait()
+
+	for _, name := range []string{"0001.json", "0002.json"} {
+		data, err := os.ReadFile(filepath.Join(dir, "api", "v1", "users", "POST", "201", name))
+		if err != nil {
+			t.Fatalf("fixture not written: %v", err)
+		}
+
+		if string(data) != recordedFixture {
+			t.Errorf("%s =\n%s\nwant\n%s", name, data, recordedFixture)
+		}
+	}
+}
+
+func TestRecorderHonorsAllowlistAndSampling(t *testing.T) {
+	tests := []struct {
+		name    string
+		options middleware.RecordingOptions
+	}{
+		{
+			name:    "route not allowlisted",
+			options: middleware.RecordingOptions{SampleRate: 1, Routes: []string{"/api/v1/orders"}},
+		},
+		{name: "sampled out", options: middleware.RecordingOptions{SampleRate: 0, Routes: nil}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.options.Dir = t.TempDir()
+			recorder := middleware.NewRecorder(tt.options)
+
+			postUser(recorder.Middleware(createUserHandler()))
+			recorder.Wait()
+
+			entries, err := os.ReadDir(tt.options.Dir)
+			if err != nil || len(entries) != 0 {
+				t.Errorf("expected no fixtures, got %v (%v)", entries, err)
+			}
+		})
+	}
+}
+
+func TestRecorderWriteFailureDoesNotAffectResponse(t *testing.T) {
+	blocked := filepath.Join(t.TempDir(), "not-a-directory")
+	if err := os.WriteFile(blocked, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := middleware.NewRecorder(middleware.RecordingOptions{Dir: blocked, SampleRate: 1, Routes: nil})
+
+	w := postUser(recorder.Middleware(createUserHandler()))
+	recorder.Wait()
+
+	if w.Code != http.StatusCreated || !strings.Contains(w.Body.String(), "user_1") {
+		t.Errorf("response changed by failed recording: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestRecorderPassesFlushThrough(t *testing.T) {
+	recorder := middleware.NewRecorder(middleware.RecordingOptions{Dir: t.TempDir(), SampleRate: 1, Routes: nil})
+	handler := recorder.Middleware(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("first chunk"))
+
+		err := http.NewResponseController(w).Flush()
+		if err != nil {
+			t.Errorf("Flush() through the recorder error = %v", err)
+		}
+	}))
+
+	w := postUser(handler)
+	recorder.Wait()
+
+	if !w.Flushed {
+		t.Error("response was not flushed")
+	}
+}
@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"charm.land/log/v2"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/clock"
+)
+
+// RequestIDHeader carries the request ID in both directions.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds a client-supplied request ID.
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+// RequestIDs tags every request with an ID. The ID is echoed in the
+// X-Request-ID response header, and the request's context carries it
+// together with a logger that adds it to every line as request_id.
+type RequestIDs struct {
+	logger *log.Logger
+	clock  clock.Clock
+}
+
+// NewRequestIDs creates the middleware; request-scoped loggers derive from logger.
+func NewRequestIDs(logger *log.Logger) *RequestIDs {
+	return &RequestIDs{logger: logger, clock: clock.System{}}
+}
+
+// WithClock replaces the system clock that generated IDs are timestamped by.
+func (m *RequestIDs) WithClock(clock clock.Clock) *RequestIDs {
+	m.clock = clock
+
+	return m
+}
+
+// Middleware wraps next. A client's X-Request-ID is kept when it is at most
+// 128 characters of letters, digits and -._: so that it is safe to log;
+// otherwise a UUIDv7 is generated. The response header is set before next
+// runs, so handlers can read the ID back from it.
+func (m *RequestIDs) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newUUIDv7(m.clock.Now())
+		}
+
+		w.Header().Set(RequestIDHeader, id)
+
+		ctx := context.WithValue(r.Context(), requestIDKey{}, id)
+		ctx = log.WithContext(ctx, m.logger.With("request_id", id))
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// RequestIDFromContext returns the ID RequestIDs stored in ctx, or "".
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+
+	return id
+}
+
+// LoggerFromContext returns the request-scoped logger RequestIDs stored in
+// ctx, or the default logger outside a request. It is log.FromContext,
+// named here so callers need not know where the logger comes from.
+func LoggerFromContext(ctx context.Context) *log.Logger {
+	return log.FromContext(ctx)
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for _, c := range []byte(id) {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '.', c == '_', c == ':':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// newUUIDv7 returns a random RFC 9562 version 7 UUID created at now, which
+// sorts by creation time to the millisecond.
+func newUUIDv7(now time.Time) string {
+	var uuid [16]byte
+
+	_, _ = rand.Read(uuid[6:])
+
+	var millis [8]byte
+	binary.BigEndian.PutUint64(millis[:], uint64(now.UnixMilli())) //nolint:gosec // positive after 1970
+	copy(uuid[:6], millis[2:])
+
+	uuid[6] = uuid[6]&0x0f | 0x70 // version 7
+	uuid[8] = uuid[8]&0x3f | 0x80 // RFC 9562 variant
+
+	buf := make([]byte, 0, 36)
+	for i, part := range [][]byte{uuid[:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:]} {
+		if i > 0 {
+			buf = append(buf, '-')
+		}
+
+		buf = hex.AppendEncode(buf, part)
+	}
+
+	return string(buf)
+}
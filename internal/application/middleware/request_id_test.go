@@ -0,0 +1,120 @@
+package middleware_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"charm.land/log/v2"
+	"github.com/LarsArtmann/template-arch-lint/internal/application/middleware"
+)
+
+var uuidV7Pattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+// serveWithRequestID runs one request through RequestIDs and returns the
+// response together with the ID the handler saw in its context.
+func serveWithRequestID(
+	t *testing.T, ids *middleware.RequestIDs, requestID string,
+) (*httptest.ResponseRecorder, string) {
+	t.Helper()
+
+	var seen string
+
+	handler := ids.Middleware(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		seen = middleware.RequestIDFromContext(r.Context())
+		middleware.LoggerFromContext(r.Context()).Info("Handling request")
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users", nil)
+	if requestID != "" {
+		req.Header.Set(middleware.RequestIDHeader, requestID)
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	return w, seen
+}
+
+func TestRequestIDsGeneratesMissingID(t *testing.T) {
+	w, seen := serveWithRequestID(t, middleware.NewRequestIDs(log.New(&bytes.Buffer{})), "")
+
+	got := w.Header().Get(middleware.RequestIDHeader)
+	if !uuidV7Pattern.MatchString(got) {
+		t.Errorf("generated request ID = %q, want a UUIDv7", got)
+	}
+
+	if seen != got {
+		t.Errorf("context request ID = %q, want %q", seen, got)
+	}
+}
+
+func TestRequestIDsPreservesValidID(t *testing.T) {
+	const clientID = "client-42.retry_1:a"
+
+	w, seen := serveWithRequestID(t, middleware.NewRequestIDs(log.New(&bytes.Buffer{})), clientID)
+
+	if got := w.Header().Get(middleware.RequestIDHeader); got != clientID {
+		t.Errorf("response request ID = %q, want %q", got, clientID)
+	}
+
+	if seen != clientID {
+		t.Errorf("context request ID = %q, want %q", seen, clientID)
+	}
+}
+
+func TestRequestIDsReplacesUnsafeID(t *testing.T) {
+	for _, clientID := range []string{"id with spaces", "id\"quoted", strings.Repeat("a", 129)} {
+		w, _ := serveWithRequestID(t, middleware.NewRequestIDs(log.New(&bytes.Buffer{})), clientID)
+
+		if got := w.Header().Get(middleware.RequestIDHeader); !uuidV7Pattern.MatchString(got) {
+			t.Errorf("request ID for %q = %q, want a generated UUIDv7", clientID, got)
+		}
+	}
+}
+
+func TestRequestIDsTagsLogLines(t *testing.T) {
+	var logs bytes.Buffer
+
+	w, _ := serveWithRequestID(t, middleware.NewRequestIDs(log.New(&logs)), "")
+
+	want := "request_id=" + w.Header().Get(middleware.RequestIDHeader)
+	if !strings.Contains(logs.String(), want) {
+		t.Errorf("log output %q does not contain %q", logs.String(), want)
+	}
+}
+
+func TestRequestIDsAreDistinctAcrossConcurrentRequests(t *testing.T) {
+	const requests = 200
+
+	ids := middleware.NewRequestIDs(log.New(&bytes.Buffer{}))
+	handler := ids.Middleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+
+	var (
+		mu   sync.Mutex
+		seen = make(map[string]bool, requests)
+		wg   sync.WaitGroup
+	)
+
+	for range requests {
+		wg.Go(func() {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/users", nil))
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			seen[w.Header().Get(middleware.RequestIDHeader)] = true
+		})
+	}
+
+	wg.Wait()
+
+	if len(seen) != requests {
+		t.Errorf("got %d distinct request IDs for %d requests", len(seen), requests)
+	}
+}
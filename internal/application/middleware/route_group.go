@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+)
+
+// parseRouteGroup parses a route group of the form "[METHOD ]/prefix",
+// such as "POST /api/v1/users" or "/api/v1". The method is
+// case-insensitive and returned in upper case.
+func parseRouteGroup(route string) (string, string, bool) {
+	method, prefix, found := strings.Cut(strings.TrimSpace(route), " ")
+	if !found {
+		method, prefix = "", method
+	}
+
+	prefix = strings.TrimSpace(prefix)
+	if !strings.HasPrefix(prefix, "/") {
+		return "", "", false
+	}
+
+	return strings.ToUpper(method), prefix, true
+}
+
+// matchRouteGroup returns the index of the route group among count that
+// covers r, or -1 when none does. group returns the method and path prefix
+// of the i-th group. A prefix covers whole path segments only, so
+// "/api/v1/users" covers "/api/v1/users/42" but not "/api/v1/users-archive".
+// The longest matching prefix wins, and a group with a method beats one
+// without.
+func matchRouteGroup(r *http.Request, count int, group func(i int) (string, string)) int {
+	best, bestLength := -1, -1
+
+	for i := range count {
+		method, prefix := group(i)
+		if method != "" && method != r.Method || !coversPath(prefix, r.URL.Path) {
+			continue
+		}
+
+		// A method-specific group outranks an any-method group of the same prefix.
+		length := 2 * len(prefix)
+		if method != "" {
+			length++
+		}
+
+		if length > bestLength {
+			best, bestLength = i, length
+		}
+	}
+
+	return best
+}
+
+// coversPath reports whether prefix covers path on a segment boundary.
+func coversPath(prefix, path string) bool {
+	if !strings.HasPrefix(path, prefix) {
+		return false
+	}
+
+	return len(path) == len(prefix) || strings.HasSuffix(prefix, "/") || path[len(prefix)] == '/'
+}
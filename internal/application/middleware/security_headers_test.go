@@ -0,0 +1,73 @@
+package middleware_test
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/application/middleware"
+)
+
+func TestSecurityHeaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		options middleware.SecurityHeaderOptions
+		tls     bool
+		want    map[string]string
+	}{
+		{
+			name: "defaults over plain HTTP",
+			options: middleware.SecurityHeaderOptions{
+				HSTS: false, ContentSecurityPolicy: "default-src 'none'", ReferrerPolicy: "no-referrer",
+			},
+			want: map[string]string{
+				"X-Content-Type-Options":    "nosniff",
+				"X-Frame-Options":           "DENY",
+				"Content-Security-Policy":   "default-src 'none'",
+				"Referrer-Policy":           "no-referrer",
+				"Strict-Transport-Security": "",
+			},
+		},
+		{
+			name:    "TLS request gets HSTS",
+			options: middleware.SecurityHeaderOptions{HSTS: false, ContentSecurityPolicy: "", ReferrerPolicy: ""},
+			tls:     true,
+			want: map[string]string{
+				"Strict-Transport-Security": "max-age=31536000; includeSubDomains",
+				"Content-Security-Policy":   "",
+				"Referrer-Policy":           "",
+			},
+		},
+		{
+			name:    "HSTS forced by config behind a proxy",
+			options: middleware.SecurityHeaderOptions{HSTS: true, ContentSecurityPolicy: "", ReferrerPolicy: ""},
+			want: map[string]string{
+				"Strict-Transport-Security": "max-age=31536000; includeSubDomains",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := middleware.NewSecurityHeaders(tt.options).Middleware(
+				http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					w.WriteHeader(http.StatusNotFound)
+				}))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/users", nil)
+			if tt.tls {
+				req.TLS = &tls.ConnectionState{} //nolint:exhaustruct // only presence matters
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			for name, value := range tt.want {
+				if got := w.Header().Get(name); got != value {
+					t.Errorf("%s = %q, want %q", name, got, value)
+				}
+			}
+		})
+	}
+}
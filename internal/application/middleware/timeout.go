@@ -0,0 +1,264 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json/v2"
+	"errors"
+	"maps"
+	"net/http"
+	"sync"
+	"time"
+
+	"charm.land/log/v2"
+	pkgerrors "github.com/LarsArtmann/template-arch-lint/pkg/errors"
+)
+
+// TimeoutRule gives one route group its own request timeout.
+type TimeoutRule struct {
+	// Method restricts the rule to one HTTP method; empty matches any.
+	Method string
+	// PathPrefix selects the paths the rule covers.
+	PathPrefix string
+	// Timeout bounds the requests of the group; zero leaves them unbounded.
+	Timeout time.Duration
+}
+
+// ParseTimeoutRule parses a route group of the form "[METHOD ]/prefix",
+// as ParseRateLimitRule does, with its timeout.
+func ParseTimeoutRule(route string, timeout time.Duration) (TimeoutRule, bool) {
+	method, prefix, ok := parseRouteGroup(route)
+	if !ok || timeout < 0 {
+		return TimeoutRule{}, false //nolint:exhaustruct // invalid rule
+	}
+
+	return TimeoutRule{Method: method, PathPrefix: prefix, Timeout: timeout}, true
+}
+
+// TimeoutOptions configure RequestTimeouts.
+type TimeoutOptions struct {
+	// Timeout bounds requests that match no rule; zero leaves them unbounded.
+	Timeout time.Duration
+	// Rules override Timeout for route groups, matched as RateLimitOptions
+	// rules are.
+	Rules []TimeoutRule
+}
+
+// RequestTimeouts bounds how long a handler may take. The request context
+// carries the deadline, so repository calls made with it are cancelled,
+// and a handler that has written nothing when it passes is answered 504.
+type RequestTimeouts struct {
+	options TimeoutOptions
+}
+
+// NewRequestTimeouts creates the timeout middleware for options.
+func NewRequestTimeouts(options TimeoutOptions) *RequestTimeouts {
+	return &RequestTimeouts{options: options}
+}
+
+// Middleware runs next under the timeout of its route group. next runs in
+// its own goroutine, so the 504 goes out when the deadline passes rather
+// than when next gives up; whatever next writes afterwards is discarded.
+// A handler that already started its response when the deadline passes
+// has the rest of it cut off. Panics in next are re-raised here.
+func (t *RequestTimeouts) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		timeout := t.match(r)
+		if timeout <= 0 {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		guard := newTimeoutWriter(w, ctx)
+		done := make(chan struct{})
+		panicked := make(chan any, 1)
+
+		go func() {
+			defer close(done)
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					panicked <- recovered
+				}
+			}()
+
+			next.ServeHTTP(guard, r.WithContext(ctx))
+		}()
+
+		select {
+		case <-done:
+			select {
+			case recovered := <-panicked:
+				panic(recovered)
+			default:
+			}
+		case <-ctx.Done():
+		}
+
+		if deadlineExceeded(ctx) && guard.timeOut() {
+			log.FromContext(r.Context()).Warn("Request timed out",
+				"method", r.Method, "path", r.URL.Path, "timeout", timeout)
+			writeTimeout(w)
+		}
+	})
+}
+
+// match returns the timeout of the route group covering r.
+func (t *RequestTimeouts) match(r *http.Request) time.Duration {
+	best := matchRouteGroup(r, len(t.options.Rules), func(i int) (string, string) {
+		return t.options.Rules[i].Method, t.options.Rules[i].PathPrefix
+	})
+	if best < 0 {
+		return t.options.Timeout
+	}
+
+	return t.options.Rules[best].Timeout
+}
+
+func writeTimeout(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusGatewayTimeout)
+	_ = json.MarshalWrite(w, pkgerrors.APIError{ //nolint:exhaustruct // a timeout names no field
+		Code:      pkgerrors.APICodeTimeout,
+		Message:   "Request timed out",
+		RequestID: w.Header().Get(RequestIDHeader),
+	})
+}
+
+// deadlineExceeded reports whether ctx ended by its own timeout rather
+// than because the client went away.
+func deadlineExceeded(ctx context.Context) bool {
+	return errors.Is(ctx.Err(), context.DeadlineExceeded)
+}
+
+// timeoutWriter lets a handler write to w until the request times out,
+// and makes sure only one of the two writes the response. A handler that
+// wakes up on the expired context and writes an error of its own loses to
+// the 504. The handler writes to a header map of its own, which is copied
+// to w on its first write, so it cannot touch w's headers after the 504
+// went out.
+type timeoutWriter struct {
+	w      http.ResponseWriter
+	ctx    context.Context //nolint:containedctx // the deadline decides who writes
+	header http.Header
+
+	mu          sync.Mutex
+	wroteHeader bool
+	timedOut    bool
+}
+
+func newTimeoutWriter(w http.ResponseWriter, ctx context.Context) *timeoutWriter {
+	return &timeoutWriter{ //nolint:exhaustruct // mu and flags start zero
+		w:      w,
+		ctx:    ctx,
+		header: w.Header().Clone(),
+	}
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+func (tw *timeoutWriter) WriteHeader(status int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.expiredLocked() || tw.wroteHeader {
+		return
+	}
+
+	tw.writeHeaderLocked(status)
+}
+
+func (tw *timeoutWriter) Write(data []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.expiredLocked() {
+		return 0, http.ErrHandlerTimeout
+	}
+
+	if !tw.wroteHeader {
+		tw.writeHeaderLocked(http.StatusOK)
+	}
+
+	return tw.w.Write(data)
+}
+
+// FlushError lets http.ResponseController flush through the guard.
+func (tw *timeoutWriter) FlushError() error {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.expiredLocked() {
+		return http.ErrHandlerTimeout
+	}
+
+	if !tw.wroteHeader {
+		tw.writeHeaderLocked(http.StatusOK)
+	}
+
+	return http.NewResponseController(tw.w).Flush()
+}
+
+// SetWriteDeadline lets http.ResponseController extend the write deadline
+// of a handler that has not timed out.
+func (tw *timeoutWriter) SetWriteDeadline(deadline time.Time) error {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.expiredLocked() {
+		return http.ErrHandlerTimeout
+	}
+
+	return http.NewResponseController(tw.w).SetWriteDeadline(deadline)
+}
+
+// expiredLocked reports whether the handler may no longer write. Once the
+// deadline passed, only a handler that already started its response may
+// go on, until the middleware calls timeOut.
+func (tw *timeoutWriter) expiredLocked() bool {
+	if !tw.wroteHeader && deadlineExceeded(tw.ctx) {
+		tw.timedOut = true
+	}
+
+	return tw.timedOut
+}
+
+// SetReadDeadline lets http.ResponseController extend the read deadline
+// of a handler that streams a large request body.
+func (tw *timeoutWriter) SetReadDeadline(deadline time.Time) error {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.expiredLocked() {
+		return http.ErrHandlerTimeout
+	}
+
+	return http.NewResponseController(tw.w).SetReadDeadline(deadline)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer for the
+// calls the guard does not intercept itself.
+func (tw *timeoutWriter) Unwrap() http.ResponseWriter {
+	return tw.w
+}
+
+func (tw *timeoutWriter) writeHeaderLocked(status int) {
+	tw.wroteHeader = true
+	maps.Copy(tw.w.Header(), tw.header)
+	tw.w.WriteHeader(status)
+}
+
+// timeOut stops all further writes and reports whether the handler had
+// written nothing, so the 504 can still be sent.
+func (tw *timeoutWriter) timeOut() bool {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	tw.timedOut = true
+
+	return !tw.wroteHeader
+}
@@ -0,0 +1,171 @@
+package middleware_test
+
+import (
+	"encoding/json/v2"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/application/middleware"
+	pkgerrors "github.com/LarsArtmann/template-arch-lint/pkg/errors"
+)
+
+func timedRequest(handler http.Handler, method, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+
+	return w
+}
+
+func TestRequestTimeoutsAnswerSlowHandler(t *testing.T) {
+	lateWrite := make(chan error, 1)
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		w.Header().Set("X-Late", "1")
+		w.WriteHeader(http.StatusOK)
+
+		_, err := w.Write([]byte("too late"))
+		lateWrite <- err
+	})
+
+	handler := middleware.NewRequestTimeouts(middleware.TimeoutOptions{ //nolint:exhaustruct // no rules
+		Timeout: 10 * time.Millisecond,
+	}).Middleware(slow)
+
+	w := timedRequest(handler, http.MethodGet, "/api/v1/users")
+	if w.Code != http.StatusGatewayTimeout {
+		t.Fatalf("status = %d, want 504", w.Code)
+	}
+
+	var body pkgerrors.APIError
+
+	err := json.Unmarshal(w.Body.Bytes(), &body)
+	if err != nil {
+		t.Fatalf("response is not an API error: %v", err)
+	}
+
+	if body.Code != pkgerrors.APICodeTimeout {
+		t.Errorf("code = %q, want %q", body.Code, pkgerrors.APICodeTimeout)
+	}
+
+	select {
+	case err := <-lateWrite:
+		if !errors.Is(err, http.ErrHandlerTimeout) {
+			t.Errorf("late Write error = %v, want http.ErrHandlerTimeout", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("handler did not finish after its context was cancelled")
+	}
+
+	if w.Header().Get("X-Late") != "" {
+		t.Error("header set after the timeout reached the response")
+	}
+}
+
+func TestRequestTimeoutsLeaveFastHandlerAlone(t *testing.T) {
+	fast := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Context().Deadline(); !ok {
+			t.Error("request context has no deadline")
+		}
+
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("done"))
+	})
+
+	handler := middleware.NewRequestTimeouts(middleware.TimeoutOptions{ //nolint:exhaustruct // no rules
+		Timeout: time.Second,
+	}).Middleware(fast)
+
+	w := timedRequest(handler, http.MethodPost, "/api/v1/users")
+	if w.Code != http.StatusCreated || w.Body.String() != "done" || w.Header().Get("Content-Type") != "text/plain" {
+		t.Errorf("response = %d %q %v, want the handler's own", w.Code, w.Body.String(), w.Header())
+	}
+}
+
+func TestRequestTimeoutsByRouteGroup(t *testing.T) {
+	exportRule, ok := middleware.ParseTimeoutRule("GET /api/v1/users/export", time.Minute)
+	if !ok {
+		t.Fatal("ParseTimeoutRule() rejected a valid route")
+	}
+
+	unboundedRule, ok := middleware.ParseTimeoutRule("/health", 0)
+	if !ok {
+		t.Fatal("ParseTimeoutRule() rejected a zero timeout")
+	}
+
+	var remaining time.Duration
+
+	var bounded bool
+
+	handler := middleware.NewRequestTimeouts(middleware.TimeoutOptions{
+		Timeout: time.Second,
+		Rules:   []middleware.TimeoutRule{exportRule, unboundedRule},
+	}).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var deadline time.Time
+
+		deadline, bounded = r.Context().Deadline()
+		remaining = time.Until(deadline)
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		wantBounded bool
+		wantOver    time.Duration
+		wantUnder   time.Duration
+	}{
+		{"route rule", http.MethodGet, "/api/v1/users/export", true, 30 * time.Second, time.Minute},
+		{"other method", http.MethodPost, "/api/v1/users/export", true, 0, time.Second},
+		{"sibling path", http.MethodGet, "/api/v1/users/export-archive", true, 0, time.Second},
+		{"default", http.MethodGet, "/api/v1/users", true, 0, time.Second},
+		{"zero rule", http.MethodGet, "/health/live", false, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timedRequest(handler, tt.method, tt.path)
+
+			if bounded != tt.wantBounded {
+				t.Fatalf("deadline set = %v, want %v", bounded, tt.wantBounded)
+			}
+
+			if bounded && (remaining <= tt.wantOver || remaining > tt.wantUnder) {
+				t.Errorf("time left = %v, want in (%v, %v]", remaining, tt.wantOver, tt.wantUnder)
+			}
+		})
+	}
+}
+
+func TestParseTimeoutRuleRejectsInvalid(t *testing.T) {
+	for _, route := range []string{"users", "GET", "GET users", ""} {
+		if _, ok := middleware.ParseTimeoutRule(route, time.Second); ok {
+			t.Errorf("ParseTimeoutRule(%q) accepted an invalid route", route)
+		}
+	}
+
+	if _, ok := middleware.ParseTimeoutRule("/api", -time.Second); ok {
+		t.Error("ParseTimeoutRule() accepted a negative timeout")
+	}
+}
+
+func TestRequestTimeoutsRepanic(t *testing.T) {
+	handler := middleware.NewRequestTimeouts(middleware.TimeoutOptions{ //nolint:exhaustruct // no rules
+		Timeout: time.Second,
+	}).Middleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	}))
+
+	defer func() {
+		if recovered := recover(); recovered != "boom" {
+			t.Errorf("recovered %v, want the handler's panic", recovered)
+		}
+	}()
+
+	timedRequest(handler, http.MethodGet, "/api/v1/users")
+}
@@ -0,0 +1,81 @@
+// Package routes defines every HTTP route path in one place.
+// Handlers register these constants, and templates and tests build URLs
+// with the builder functions, so a renamed route cannot drift.
+package routes
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/values"
+)
+
+// Route path patterns in http.ServeMux syntax.
+const (
+	UsersPath          = "/api/v1/users"
+	UserPath           = "/api/v1/users/{id}"
+	UsersQueryPath     = "/api/v1/users/query"
+	UserQueryPath      = "/api/v1/users/query/{id}"
+	UsersSearchPath    = "/api/v1/users/search"
+	UsersByDomainPath  = "/api/v1/users/domain/{domain}"
+	UsersStatsPath     = "/api/v1/users/stats"
+	UsersActivePath    = "/api/v1/users/active"
+	UsersPaginatedPath = "/api/v1/users/paginated"
+	UsersImportPath    = "/api/v1/users/import"
+	UsersExportPath    = "/api/v1/users/export"
+)
+
+// ConfigValidatePath serves a dry-run validation of a config document. It is
+// mounted by the server directly rather than by the user handlers.
+const ConfigValidatePath = "/api/config/validate"
+
+// OpsConcurrencyPath serves the occupancy of the concurrency groups to
+// admins. The server mounts it when concurrency limits are enabled.
+const OpsConcurrencyPath = "/api/ops/concurrency"
+
+// All returns every route path pattern, for cross-checking registrations.
+func All() []string {
+	return []string{
+		UsersPath,
+		UserPath,
+		UsersQueryPath,
+		UserQueryPath,
+		UsersSearchPath,
+		UsersByDomainPath,
+		UsersStatsPath,
+		UsersActivePath,
+		UsersPaginatedPath,
+		UsersImportPath,
+		UsersExportPath,
+	}
+}
+
+// Pattern combines a method and path into a ServeMux pattern such as "GET /api/v1/users".
+func Pattern(method, path string) string {
+	return method + " " + path
+}
+
+// UserByID returns the URL of a single user.
+func UserByID(id values.UserID) string {
+	return expand(UserPath, "id", id.String())
+}
+
+// UserQueryByID returns the read-model URL of a single user.
+func UserQueryByID(id values.UserID) string {
+	return expand(UserQueryPath, "id", id.String())
+}
+
+// UsersByDomain returns the URL listing users with an email in domain.
+func UsersByDomain(domain string) string {
+	return expand(UsersByDomainPath, "domain", domain)
+}
+
+// SearchUsersByEmail returns the user search URL for email.
+func SearchUsersByEmail(email string) string {
+	return UsersSearchPath + "?" + url.Values{"email": {email}}.Encode()
+}
+
+// expand replaces the {name} wildcard in pattern with the path-escaped value.
+func expand(pattern, name, value string) string {
+	return strings.Replace(pattern, "{"+name+"}", url.PathEscape(value), 1)
+}
@@ -0,0 +1,85 @@
+package routes_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/application/routes"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/ids"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/values"
+	brandedid "github.com/larsartmann/go-branded-id"
+)
+
+func TestBuildersEscapeParameters(t *testing.T) {
+	tests := []struct {
+		name      string
+		pattern   string
+		wildcard  string
+		value     string
+		buildPath func(string) string
+		want      string
+	}{
+		{
+			name:      "plain user id",
+			pattern:   routes.UserPath,
+			wildcard:  "id",
+			value:     "user_123",
+			buildPath: func(v string) string { return routes.UserByID(brandedid.NewID[ids.UserBrand](v)) },
+			want:      "/api/v1/users/user_123",
+		},
+		{
+			name:      "user id with reserved characters",
+			pattern:   routes.UserQueryPath,
+			wildcard:  "id",
+			value:     "a b/c?d#e",
+			buildPath: func(v string) string { return routes.UserQueryByID(brandedid.NewID[ids.UserBrand](v)) },
+			want:      "/api/v1/users/query/a%20b%2Fc%3Fd%23e",
+		},
+		{
+			name:      "domain",
+			pattern:   routes.UsersByDomainPath,
+			wildcard:  "domain",
+			value:     "example.com",
+			buildPath: routes.UsersByDomain,
+			want:      "/api/v1/users/domain/example.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.buildPath(tt.value)
+			if got != tt.want {
+				t.Errorf("built %q, want %q", got, tt.want)
+			}
+
+			// The value must round-trip through the router unchanged.
+			var matched string
+
+			mux := http.NewServeMux()
+			mux.HandleFunc(routes.Pattern(http.MethodGet, tt.pattern), func(_ http.ResponseWriter, r *http.Request) {
+				matched = r.PathValue(tt.wildcard)
+			})
+			mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, got, nil))
+
+			if matched != tt.value {
+				t.Errorf("router extracted %q, want %q", matched, tt.value)
+			}
+		})
+	}
+}
+
+func TestSearchUsersByEmailEncodesQuery(t *testing.T) {
+	got := routes.SearchUsersByEmail("a+b@example.com")
+	if got != "/api/v1/users/search?email=a%2Bb%40example.com" {
+		t.Errorf("SearchUsersByEmail() = %q", got)
+	}
+}
+
+func TestUserByIDWithGeneratedID(t *testing.T) {
+	id := values.MustGenerateUserID()
+
+	if got := routes.UserByID(id); got != routes.UsersPath+"/"+id.String() {
+		t.Errorf("UserByID() = %q", got)
+	}
+}
@@ -0,0 +1,151 @@
+// Package wellknown serves robots.txt and /.well-known/security.txt
+// generated from configuration.
+package wellknown
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/clock"
+)
+
+// Route paths served by this package.
+const (
+	RobotsPath      = "/robots.txt"
+	SecurityTxtPath = "/.well-known/security.txt"
+)
+
+const (
+	contentTypeText = "text/plain; charset=utf-8"
+	cacheControl    = "public, max-age=86400"
+
+	// expirySoonWindow is how early an upcoming security.txt expiry is reported.
+	expirySoonWindow = 30 * 24 * time.Hour
+)
+
+// sensitivePrefixes are route prefixes always disallowed in robots.txt.
+var sensitivePrefixes = []string{"/admin", "/debug", "/metrics", "/api/ops"}
+
+// Settings holds the content of both files.
+type Settings struct {
+	SecurityContacts   []string
+	SecurityExpiresIn  time.Duration
+	SecurityPolicyURL  string
+	PreferredLanguages string
+	RobotsDisallow     []string
+}
+
+// Handler serves the well-known files.
+type Handler struct {
+	settings   Settings
+	routePaths []string
+	now        func() time.Time
+}
+
+// NewHandler creates a Handler. routePaths is the application route table;
+// paths below a sensitive prefix are added to the robots.txt disallow list.
+func NewHandler(settings Settings, routePaths []string) *Handler {
+	return &Handler{settings: settings, routePaths: routePaths, now: clock.System{}.Now}
+}
+
+// RegisterRoutes registers both files on mux.
+func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc(http.MethodGet+" "+RobotsPath, h.Robots)
+	mux.HandleFunc(http.MethodGet+" "+SecurityTxtPath, h.SecurityTxt)
+}
+
+// Robots serves robots.txt.
+func (h *Handler) Robots(w http.ResponseWriter, _ *http.Request) {
+	writeText(w, RenderRobots(h.settings, h.routePaths))
+}
+
+// SecurityTxt serves security.txt, or 404 when no contact is configured.
+func (h *Handler) SecurityTxt(w http.ResponseWriter, r *http.Request) {
+	if len(h.settings.SecurityContacts) == 0 {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	writeText(w, RenderSecurityTxt(h.settings, h.now()))
+}
+
+func writeText(w http.ResponseWriter, body string) {
+	w.Header().Set("Content-Type", contentTypeText)
+	w.Header().Set("Cache-Control", cacheControl)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(body))
+}
+
+// RenderRobots renders robots.txt from the configured rules plus every
+// sensitive prefix that appears in the route table.
+func RenderRobots(settings Settings, routePaths []string) string {
+	disallow := slices.Clone(settings.RobotsDisallow)
+
+	for _, prefix := range sensitivePrefixes {
+		if slices.ContainsFunc(routePaths, func(path string) bool { return hasPathPrefix(path, prefix) }) {
+			disallow = append(disallow, prefix+"/")
+		}
+	}
+
+	slices.Sort(disallow)
+	disallow = slices.Compact(disallow)
+
+	var b strings.Builder
+
+	b.WriteString("User-agent: *\n")
+
+	for _, path := range disallow {
+		fmt.Fprintf(&b, "Disallow: %s\n", path)
+	}
+
+	return b.String()
+}
+
+func hasPathPrefix(path, prefix string) bool {
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// RenderSecurityTxt renders security.txt as defined by RFC 9116.
+// The relative expiry is rendered as an absolute RFC 3339 date.
+func RenderSecurityTxt(settings Settings, now time.Time) string {
+	var b strings.Builder
+
+	for _, contact := range settings.SecurityContacts {
+		fmt.Fprintf(&b, "Contact: %s\n", contact)
+	}
+
+	fmt.Fprintf(&b, "Expires: %s\n", now.Add(settings.SecurityExpiresIn).UTC().Format(time.RFC3339))
+
+	if settings.SecurityPolicyURL != "" {
+		fmt.Fprintf(&b, "Policy: %s\n", settings.SecurityPolicyURL)
+	}
+
+	if settings.PreferredLanguages != "" {
+		fmt.Fprintf(&b, "Preferred-Languages: %s\n", settings.PreferredLanguages)
+	}
+
+	return b.String()
+}
+
+// Validate returns startup warnings for the security.txt settings.
+func Validate(settings Settings) []string {
+	var warnings []string
+
+	if len(settings.SecurityContacts) == 0 {
+		warnings = append(warnings, "security.txt has no contact and will not be served")
+	}
+
+	switch {
+	case settings.SecurityExpiresIn <= 0:
+		warnings = append(warnings, "security.txt expiry is in the past")
+	case settings.SecurityExpiresIn < expirySoonWindow:
+		warnings = append(warnings, fmt.Sprintf(
+			"security.txt expires in %s, less than 30 days", settings.SecurityExpiresIn))
+	}
+
+	return warnings
+}
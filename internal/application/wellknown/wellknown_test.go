@@ -0,0 +1,135 @@
+package wellknown_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/application/wellknown"
+)
+
+func testSettings() wellknown.Settings {
+	return wellknown.Settings{
+		SecurityContacts:   []string{"mailto:security@example.com"},
+		SecurityExpiresIn:  90 * 24 * time.Hour,
+		SecurityPolicyURL:  "https://example.com/security-policy",
+		PreferredLanguages: "en",
+		RobotsDisallow:     []string{"/private/"},
+	}
+}
+
+func TestRenderSecurityTxt(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	got := wellknown.RenderSecurityTxt(testSettings(), now)
+	want := "Contact: mailto:security@example.com\n" +
+		"Expires: 2026-05-30T12:00:00Z\n" +
+		"Policy: https://example.com/security-policy\n" +
+		"Preferred-Languages: en\n"
+
+	if got != want {
+		t.Errorf("RenderSecurityTxt() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRenderRobotsDerivesDisallowFromRoutes(t *testing.T) {
+	routePaths := []string{"/api/v1/users", "/api/v1/users/{id}"}
+
+	before := wellknown.RenderRobots(testSettings(), routePaths)
+	if before != "User-agent: *\nDisallow: /private/\n" {
+		t.Errorf("unexpected robots.txt:\n%s", before)
+	}
+
+	routePaths = append(routePaths, "/admin/users/{id}", "/debug/pprof", "/administrator")
+
+	after := wellknown.RenderRobots(testSettings(), routePaths)
+	for _, line := range []string{"Disallow: /admin/\n", "Disallow: /debug/\n"} {
+		if !strings.Contains(after, line) {
+			t.Errorf("robots.txt missing %q after registering admin routes:\n%s", line, after)
+		}
+	}
+
+	if strings.Contains(after, "/administrator") {
+		t.Errorf("prefix match must respect path segments:\n%s", after)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		mutate   func(*wellknown.Settings)
+		wantWarn string
+	}{
+		{name: "valid", mutate: func(*wellknown.Settings) {}, wantWarn: ""},
+		{
+			name:     "missing contact",
+			mutate:   func(s *wellknown.Settings) { s.SecurityContacts = nil },
+			wantWarn: "no contact",
+		},
+		{
+			name:     "expired",
+			mutate:   func(s *wellknown.Settings) { s.SecurityExpiresIn = -time.Hour },
+			wantWarn: "in the past",
+		},
+		{
+			name:     "expiring soon",
+			mutate:   func(s *wellknown.Settings) { s.SecurityExpiresIn = 10 * 24 * time.Hour },
+			wantWarn: "less than 30 days",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := testSettings()
+			tt.mutate(&settings)
+
+			warnings := strings.Join(wellknown.Validate(settings), "\n")
+			if tt.wantWarn == "" && warnings != "" {
+				t.Errorf("unexpected warnings: %s", warnings)
+			}
+
+			if !strings.Contains(warnings, tt.wantWarn) {
+				t.Errorf("warnings %q do not contain %q", warnings, tt.wantWarn)
+			}
+		})
+	}
+}
+
+func TestHandlerHeaders(t *testing.T) {
+	mux := http.NewServeMux()
+	wellknown.NewHandler(testSettings(), nil).RegisterRoutes(mux)
+
+	for _, path := range []string{wellknown.RobotsPath, wellknown.SecurityTxtPath} {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+		if w.Code != http.StatusOK {
+			t.Errorf("%s status = %d", path, w.Code)
+		}
+
+		if got := w.Header().Get("Content-Type"); got != "text/plain; charset=utf-8" {
+			t.Errorf("%s Content-Type = %q", path, got)
+		}
+
+		if got := w.Header().Get("Cache-Control"); !strings.Contains(got, "max-age=") {
+			t.Errorf("%s Cache-Control = %q", path, got)
+		}
+	}
+}
+
+func TestSecurityTxtNotServedWithoutContact(t *testing.T) {
+	settings := testSettings()
+	settings.SecurityContacts = nil
+
+	mux := http.NewServeMux()
+	wellknown.NewHandler(settings, nil).RegisterRoutes(mux)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, wellknown.SecurityTxtPath, nil))
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+}
@@ -2,10 +2,19 @@
 package config
 
 import (
+	"cmp"
+	stderrors "errors"
 	"fmt"
+	"io/fs"
+	"maps"
+	"net/netip"
+	"os"
+	"reflect"
+	"slices"
 	"strings"
 	"time"
 
+	"charm.land/log/v2"
 	"github.com/LarsArtmann/template-arch-lint/internal/domain/values"
 	"github.com/LarsArtmann/template-arch-lint/pkg/errors"
 	"github.com/go-playground/validator/v10"
@@ -18,17 +27,41 @@ const (
 	defaultServerWriteTimeout        = 10 * time.Second
 	defaultServerIdleTimeout         = 120 * time.Second
 	defaultGracefulShutdownTimeout   = 30 * time.Second
+	defaultRequestTimeout            = 10 * time.Second
+	defaultStreamRequestTimeout      = 10 * time.Minute
 	defaultDatabaseMaxOpenConns      = 25
 	defaultDatabaseMaxIdleConns      = 25
 	defaultDatabaseConnMaxLifetime   = 5 * time.Minute
 	defaultDatabaseConnMaxIdleTime   = 5 * time.Minute
+	defaultDatabasePingTimeout       = 5 * time.Second
+	defaultCacheTTL                  = time.Minute
+	defaultCacheTTLJitter            = 0.1
+	defaultCacheNegativeTTL          = 5 * time.Second
+	defaultIdempotencyTTL            = 24 * time.Hour
+	defaultIdempotencyWaitTimeout    = 10 * time.Second
 	defaultAccessTokenExpiry         = 24 * time.Hour
 	defaultRefreshTokenExpiry        = 7 * 24 * time.Hour
-	defaultSecurityMaxRequestSize    = 10 * 1024 * 1024 // 10MB
+	defaultJWTClockSkew              = 30 * time.Second
+	defaultJWKSCacheTTL              = 10 * time.Minute
+	defaultMaxRequestBodyBytes       = 1 << 20 // 1MB
 	defaultSecurityRateLimitRequests = 100
+	defaultUserCreateRateLimit       = 10
+	defaultCORSMaxAge                = 10 * time.Minute
+	defaultSecurityTxtExpiresIn      = 365 * 24 * time.Hour
+	defaultHeaderMaxBytes            = 64 * 1024
+	defaultHeaderSoftLimitBytes      = 32 * 1024
+	defaultHeaderFieldLimitBytes     = 8 * 1024
+	defaultHeaderCookieLimitBytes    = 4 * 1024
+	defaultLoadSheddingMaxInFlight   = 1000
+	defaultLoadSheddingMaxGoroutines = 10000
+	defaultConcurrencyMaxWait        = 5 * time.Second
+	defaultExportConcurrency         = 2
+	minJWTSecretKeyLength            = 32
 )
 
 // Config represents the application configuration.
+// Every leaf field carries a desc tag; it feeds the generated environment
+// variable reference.
 type Config struct {
 	Server   ServerConfig   `mapstructure:"server"   validate:"required"`
 	Database DatabaseConfig `mapstructure:"database" validate:"required"`
@@ -36,84 +69,282 @@ type Config struct {
 	App      AppConfig      `mapstructure:"app"      validate:"required"`
 	JWT      JWTConfig      `mapstructure:"jwt"      validate:"required"`
 	Security SecurityConfig `mapstructure:"security"`
+	API      APIConfig      `mapstructure:"api"`
+	Cache    CacheConfig    `mapstructure:"cache"`
+
+	warnings []string
+}
+
+// Warnings returns the deprecation and unknown-key notices found while loading.
+func (c *Config) Warnings() []string {
+	return c.warnings
 }
 
 // ServerConfig contains HTTP server configuration.
 type ServerConfig struct {
-	Host                    string        `mapstructure:"host"                      validate:"required"`
-	Port                    values.Port   `mapstructure:"port"                      validate:"required"`
-	ReadTimeout             time.Duration `mapstructure:"read_timeout"`
-	WriteTimeout            time.Duration `mapstructure:"write_timeout"`
-	IdleTimeout             time.Duration `mapstructure:"idle_timeout"`
-	GracefulShutdownTimeout time.Duration `mapstructure:"graceful_shutdown_timeout"`
+	Host                    string             `desc:"HTTP listen host"                   mapstructure:"host"                      validate:"required"`
+	Port                    values.Port        `desc:"HTTP listen port"                   mapstructure:"port"                      validate:"required"`
+	ReadTimeout             time.Duration      `desc:"Maximum time to read a request"     mapstructure:"read_timeout"`
+	WriteTimeout            time.Duration      `desc:"Maximum time to write a reply"      mapstructure:"write_timeout"`
+	IdleTimeout             time.Duration      `desc:"Keep-alive idle timeout"            mapstructure:"idle_timeout"`
+	GracefulShutdownTimeout time.Duration      `desc:"Time allowed to drain on stop"      mapstructure:"graceful_shutdown_timeout"`
+	RequestTimeout          time.Duration      `desc:"Time limit per request, 0 for none" mapstructure:"request_timeout"           validate:"gte=0"`
+	WellKnown               WellKnownConfig    `mapstructure:"well_known"`
+	Headers                 HeadersConfig      `mapstructure:"headers"`
+	LoadShedding            LoadSheddingConfig `mapstructure:"load_shedding"`
+	Concurrency             ConcurrencyConfig  `mapstructure:"concurrency"`
+	MaxRequestBodyBytes     int64              `desc:"Maximum JSON request body in bytes" mapstructure:"max_request_body_bytes"    validate:"gt=0"`
+	// RequestTimeoutRoutes overrides RequestTimeout per route group, keyed
+	// like security.rate_limit_routes, so exports can run longer.
+	RequestTimeoutRoutes map[string]time.Duration `desc:"Request time limit by route group" mapstructure:"request_timeout_routes"`
+}
+
+// WithDefaultTimeouts returns s with every zero server timeout replaced by
+// its default. net/http reads a zero timeout as none at all, which is never
+// what a config that simply left the key out means.
+func (s ServerConfig) WithDefaultTimeouts() ServerConfig {
+	s.ReadTimeout = cmp.Or(s.ReadTimeout, defaultServerReadTimeout)
+	s.WriteTimeout = cmp.Or(s.WriteTimeout, defaultServerWriteTimeout)
+	s.IdleTimeout = cmp.Or(s.IdleTimeout, defaultServerIdleTimeout)
+	s.GracefulShutdownTimeout = cmp.Or(s.GracefulShutdownTimeout, defaultGracefulShutdownTimeout)
+
+	return s
+}
+
+// HeadersConfig bounds request header sizes. MaxBytes is the hard
+// http.Server limit; the soft limits below it produce a JSON 431.
+type HeadersConfig struct {
+	MaxBytes         int `desc:"Hard limit on request header bytes" mapstructure:"max_bytes"`
+	SoftLimitBytes   int `desc:"Soft limit on total header bytes"   mapstructure:"soft_limit_bytes"`
+	FieldLimitBytes  int `desc:"Soft limit on a single header line" mapstructure:"field_limit_bytes"`
+	CookieLimitBytes int `desc:"Soft limit on a single cookie"      mapstructure:"cookie_limit_bytes"`
+}
+
+// LoadSheddingConfig sets the soft limits beyond which requests outside
+// ExemptPrefixes are answered with 503. A zero limit disables that check.
+type LoadSheddingConfig struct {
+	Enabled        bool          `desc:"Shed requests over the soft limits"        mapstructure:"enabled"`
+	MaxInFlight    int64         `desc:"Soft limit on in-flight requests"          mapstructure:"max_in_flight"   validate:"gte=0"`
+	MaxGoroutines  int64         `desc:"Soft limit on goroutines"                  mapstructure:"max_goroutines"  validate:"gte=0"`
+	MaxHeapBytes   int64         `desc:"Soft limit on live heap bytes"             mapstructure:"max_heap_bytes"  validate:"gte=0"`
+	ExemptPrefixes []string      `desc:"Path prefixes that are never shed"         mapstructure:"exempt_prefixes"`
+	RetryAfter     time.Duration `desc:"Retry-After sent with a shed request"      mapstructure:"retry_after"`
+	SampleInterval time.Duration `desc:"How often goroutines and heap are sampled" mapstructure:"sample_interval" validate:"gt=0"`
+}
+
+// ConcurrencyConfig bounds the in-flight requests of expensive routes.
+// Routes assigned to the same group share its slots; a request that finds
+// no free slot waits up to MaxWait and is then answered with 503, unless
+// its group's policy is "reject", which answers at once.
+type ConcurrencyConfig struct {
+	Enabled bool           `desc:"Limit concurrent requests per group"         mapstructure:"enabled"`
+	MaxWait time.Duration  `desc:"Time a request queues for a slot, 0 rejects" mapstructure:"max_wait" validate:"gte=0"`
+	Groups  map[string]int `desc:"Concurrent requests allowed per group"       mapstructure:"groups"`
+	// Policies sets what a full group does, "queue" or "reject", by group
+	// name. Groups without a policy queue.
+	Policies map[string]string `desc:"Full group policy by group: queue or reject" mapstructure:"policies"`
+	// Routes assigns route groups, keyed like security.rate_limit_routes,
+	// to the groups above.
+	Routes map[string]string `desc:"Concurrency group by route group" mapstructure:"routes"`
+}
+
+// WellKnownConfig contains the content of robots.txt and security.txt.
+type WellKnownConfig struct {
+	SecurityContacts   []string      `desc:"security.txt Contact URIs"        mapstructure:"security_contacts"`
+	SecurityExpiresIn  time.Duration `desc:"security.txt Expires offset"      mapstructure:"security_expires_in"`
+	SecurityPolicyURL  string        `desc:"security.txt Policy URL"          mapstructure:"security_policy_url"`
+	PreferredLanguages string        `desc:"security.txt Preferred-Languages" mapstructure:"preferred_languages"`
+	RobotsDisallow     []string      `desc:"robots.txt Disallow paths"        mapstructure:"robots_disallow"`
 }
 
 // DatabaseConfig contains database configuration.
 type DatabaseConfig struct {
-	Driver          string        `mapstructure:"driver"             validate:"required,oneof=sqlite3 postgres mysql"`
-	DSN             string        `mapstructure:"dsn"                validate:"required"`
-	MaxOpenConns    int           `mapstructure:"max_open_conns"`
-	MaxIdleConns    int           `mapstructure:"max_idle_conns"`
-	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime"`
-	ConnMaxIdleTime time.Duration `mapstructure:"conn_max_idle_time"`
+	Driver          string        `desc:"Database driver"                               mapstructure:"driver"             validate:"required,oneof=sqlite3 postgres mysql"`
+	DSN             string        `desc:"Database connection string"                    mapstructure:"dsn"                validate:"required"`
+	MaxOpenConns    int           `desc:"Maximum open connections, 0 for unlimited"     mapstructure:"max_open_conns"     validate:"min=0"`
+	MaxIdleConns    int           `desc:"Maximum idle connections"                      mapstructure:"max_idle_conns"     validate:"min=0"`
+	ConnMaxLifetime time.Duration `desc:"Maximum connection lifetime, 0 for unlimited"  mapstructure:"conn_max_lifetime"  validate:"min=0"`
+	ConnMaxIdleTime time.Duration `desc:"Maximum connection idle time, 0 for unlimited" mapstructure:"conn_max_idle_time" validate:"min=0"`
+	PingTimeout     time.Duration `desc:"Timeout for the startup connectivity check"    mapstructure:"ping_timeout"       validate:"gt=0"`
 }
 
 // LoggingConfig contains logging configuration.
 type LoggingConfig struct {
-	Level  values.LogLevel `mapstructure:"level"  validate:"required"`
-	Format string          `mapstructure:"format" validate:"required,oneof=json text"`
-	Output string          `mapstructure:"output" validate:"required"`
+	Level  values.LogLevel `desc:"Minimum log level"        mapstructure:"level"  validate:"required"`
+	Format string          `desc:"Log format: json or text" mapstructure:"format" validate:"required,oneof=json text"`
+	Output string          `desc:"Log destination"          mapstructure:"output" validate:"required"`
 }
 
 // AppConfig contains application-specific configuration.
 type AppConfig struct {
-	Name        string `mapstructure:"name"        validate:"required"`
-	Version     string `mapstructure:"version"     validate:"required"`
-	Environment string `mapstructure:"environment" validate:"required,valid_environment"`
-	Debug       bool   `mapstructure:"debug"`
+	Name        string          `desc:"Application name"       mapstructure:"name"        validate:"required"`
+	Version     string          `desc:"Application version"    mapstructure:"version"     validate:"required"`
+	Environment string          `desc:"Deployment environment" mapstructure:"environment" validate:"required,valid_environment"`
+	Debug       bool            `desc:"Enable debug behavior"  mapstructure:"debug"`
+	Recording   RecordingConfig `mapstructure:"recording"`
+}
+
+// RecordingConfig enables request/response fixture recording outside production.
+type RecordingConfig struct {
+	Enabled    bool     `desc:"Record request/response fixtures" mapstructure:"enabled"`
+	SampleRate float64  `desc:"Fraction of requests recorded"    mapstructure:"sample_rate" validate:"gte=0,lte=1"`
+	Routes     []string `desc:"Route prefixes to record"         mapstructure:"routes"`
+	Dir        string   `desc:"Fixture output directory"         mapstructure:"dir"`
 }
 
-// JWTConfig contains JWT authentication configuration.
+// JWTConfig contains JWT authentication configuration. HS* tokens are
+// verified with SecretKey, RS256 tokens with the keys published at JWKSURL.
 type JWTConfig struct {
-	SecretKey          string        `mapstructure:"secret_key"           validate:"required,min=32"`
-	AccessTokenExpiry  time.Duration `mapstructure:"access_token_expiry"`
-	RefreshTokenExpiry time.Duration `mapstructure:"refresh_token_expiry"`
-	Issuer             string        `mapstructure:"issuer"               validate:"required"`
-	Algorithm          string        `mapstructure:"algorithm"            validate:"required,oneof=HS256 HS384 HS512"`
+	SecretKey          string        `desc:"JWT signing key, for HS* only"    mapstructure:"secret_key"`
+	AccessTokenExpiry  time.Duration `desc:"Access token lifetime"            mapstructure:"access_token_expiry"`
+	RefreshTokenExpiry time.Duration `desc:"Refresh token lifetime"           mapstructure:"refresh_token_expiry"`
+	Issuer             string        `desc:"JWT issuer claim"                 mapstructure:"issuer"               validate:"required"`
+	Audience           string        `desc:"Required JWT audience, if any"    mapstructure:"audience"`
+	Algorithm          string        `desc:"JWT signing algorithm"            mapstructure:"algorithm"            validate:"required,oneof=HS256 HS384 HS512 RS256"`
+	ClockSkew          time.Duration `desc:"Tolerance for exp and nbf claims" mapstructure:"clock_skew"           validate:"gte=0"`
+	JWKSURL            string        `desc:"JWKS URL of the RS256 keys"       mapstructure:"jwks_url"             validate:"omitempty,url"`
+	JWKSCacheTTL       time.Duration `desc:"How long fetched JWKS keys last"  mapstructure:"jwks_cache_ttl"       validate:"gte=0"`
 }
 
 // SecurityConfig contains security configuration.
 type SecurityConfig struct {
-	AllowedOrigins    []string      `mapstructure:"allowed_origins"`
-	TrustedProxies    []string      `mapstructure:"trusted_proxies"`
-	EnableHSTS        bool          `mapstructure:"enable_hsts"`
-	EnableCSP         bool          `mapstructure:"enable_csp"`
-	CSPReportURI      string        `mapstructure:"csp_report_uri"`
-	MaxRequestSize    int64         `mapstructure:"max_request_size"`
-	RateLimitEnabled  bool          `mapstructure:"rate_limit_enabled"`
-	RateLimitRequests int           `mapstructure:"rate_limit_requests"`
-	RateLimitWindow   time.Duration `mapstructure:"rate_limit_window"`
+	CORS              CORSConfig    `mapstructure:"cors"`
+	TrustedProxies    []string      `desc:"Trusted reverse proxy addresses" mapstructure:"trusted_proxies"`
+	EnableHSTS        bool          `desc:"Send Strict-Transport-Security"  mapstructure:"enable_hsts"`
+	EnableCSP         bool          `desc:"Send Content-Security-Policy"    mapstructure:"enable_csp"`
+	CSPPolicy         string        `desc:"Content-Security-Policy value"   mapstructure:"csp_policy"`
+	CSPReportURI      string        `desc:"CSP report-uri"                  mapstructure:"csp_report_uri"`
+	ReferrerPolicy    string        `desc:"Referrer-Policy value"           mapstructure:"referrer_policy"`
+	RateLimitEnabled  bool          `desc:"Enable request rate limiting"    mapstructure:"rate_limit_enabled"`
+	RateLimitRequests int           `desc:"Requests allowed per window"     mapstructure:"rate_limit_requests"`
+	RateLimitWindow   time.Duration `desc:"Rate limit window"               mapstructure:"rate_limit_window"`
+	// RateLimitRoutes overrides RateLimitRequests per route group, keyed by
+	// "[METHOD ]/prefix", such as "POST /api/v1/users".
+	RateLimitRoutes map[string]int `desc:"Requests per window by route group" mapstructure:"rate_limit_routes"`
+}
+
+// CORSConfig lists what cross-origin browsers may do. An origin of "*"
+// allows any origin, which cannot be combined with credentials.
+type CORSConfig struct {
+	AllowedOrigins   []string      `desc:"CORS allowed origins, * for any"      mapstructure:"allowed_origins"`
+	AllowedMethods   []string      `desc:"CORS allowed methods"                 mapstructure:"allowed_methods"`
+	AllowedHeaders   []string      `desc:"CORS allowed request headers"         mapstructure:"allowed_headers"`
+	ExposedHeaders   []string      `desc:"Response headers exposed to browsers" mapstructure:"exposed_headers"`
+	AllowCredentials bool          `desc:"Allow cookies and auth headers"       mapstructure:"allow_credentials"`
+	MaxAge           time.Duration `desc:"How long browsers cache a preflight"  mapstructure:"max_age"`
+}
+
+// APIConfig contains HTTP API behavior switches.
+type APIConfig struct {
+	// AllowPutCreate lets PUT create a resource that does not exist yet.
+	AllowPutCreate bool              `desc:"Let PUT create missing resources" mapstructure:"allow_put_create"`
+	Idempotency    IdempotencyConfig `mapstructure:"idempotency"`
+}
+
+// IdempotencyConfig controls the Idempotency-Key header on user creation.
+// InFlight decides what a repeat of a request that is still running gets:
+// "wait" waits up to WaitTimeout for its response, "reject" answers 409.
+type IdempotencyConfig struct {
+	Enabled     bool          `desc:"Replay POSTs that repeat an Idempotency-Key" mapstructure:"enabled"`
+	TTL         time.Duration `desc:"How long a key and its response are kept"    mapstructure:"ttl"          validate:"gt=0"`
+	Store       string        `desc:"Where keys are kept: memory or sql"          mapstructure:"store"        validate:"required,oneof=memory sql"`
+	InFlight    string        `desc:"Repeat of a running request: wait or reject" mapstructure:"in_flight"    validate:"required,oneof=wait reject"`
+	WaitTimeout time.Duration `desc:"How long a repeat waits before a 409"        mapstructure:"wait_timeout" validate:"gte=0"`
+}
+
+// CacheConfig contains the read-through cache for user lookups.
+type CacheConfig struct {
+	Enabled     bool          `desc:"Cache user lookups by ID and email"        mapstructure:"enabled"`
+	TTL         time.Duration `desc:"How long a cached user is served"          mapstructure:"ttl"          validate:"gt=0"`
+	TTLJitter   float64       `desc:"Fraction of the TTL randomly cut off"      mapstructure:"ttl_jitter"   validate:"gte=0,lte=1"`
+	NegativeTTL time.Duration `desc:"How long a failed lookup is cached, 0 off" mapstructure:"negative_ttl" validate:"gte=0"`
 }
 
 // LoadConfig loads configuration from various sources.
+// Deprecated, removed and unknown keys are logged as warnings, and so is a
+// config file that does not exist, so environment variables alone suffice.
+// ${NAME} references in any string setting are replaced by the environment
+// variable NAME; those left unset are logged as warnings too.
 func LoadConfig(configPath string) (*Config, error) {
+	return loadConfig(configPath, false)
+}
+
+// LoadConfigStrict loads configuration like LoadConfig but fails on removed
+// or unknown keys, on a missing config file and on unresolved ${NAME}
+// secret references instead of warning about them.
+func LoadConfigStrict(configPath string) (*Config, error) {
+	return loadConfig(configPath, true)
+}
+
+// LoadConfigFromEnv loads configuration from APP_* environment variables
+// only and never reads a file. APP_* variables that match no binding are
+// reported as warnings, since a typo would otherwise be silently ignored.
+func LoadConfigFromEnv() (*Config, error) {
+	config, err := loadConfig("", false)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, problem := range unknownEnvProblems(os.Environ()) {
+		log.Warn(problem)
+		config.warnings = append(config.warnings, problem)
+	}
+
+	return config, nil
+}
+
+func loadConfig(configPath string, strict bool) (*Config, error) {
 	config := &Config{}
+	v := viper.New()
 
 	// Set defaults
-	setDefaults(config)
+	setDefaults(v)
+
+	// A missing file falls back to environment variables and defaults;
+	// validation still fails if a required value ends up unset.
+	missingFile := ""
+	if _, statErr := os.Stat(configPath); configPath != "" && !strict && stderrors.Is(statErr, fs.ErrNotExist) {
+		missingFile, configPath = configPath, ""
+	}
 
 	// Configure viper
-	err := configureViper(configPath)
+	err := configureViper(v, configPath)
 	if err != nil {
 		return nil, errors.NewInternalError("failed to configure viper", err)
 	}
 
+	config.warnings, err = applyDeprecations(v, strict)
+	if err != nil {
+		return nil, err
+	}
+
+	if missingFile != "" {
+		config.warnings = append(config.warnings,
+			fmt.Sprintf("config file %q not found, using environment variables and defaults", missingFile))
+	}
+
+	for _, warning := range config.warnings {
+		log.Warn(warning)
+	}
+
 	// Unmarshal configuration
-	err = viper.Unmarshal(config)
+	err = v.Unmarshal(config, viper.DecodeHook(decodeHooks()))
 	if err != nil {
 		return nil, errors.NewInternalError("failed to unmarshal configuration", err)
 	}
 
+	// ${NAME} references come from the environment; a missing variable
+	// would otherwise leave the literal placeholder in, say, the DSN.
+	problem, err := checkSecretRefs(config, os.LookupEnv, strict)
+	if err != nil {
+		return nil, err
+	}
+
+	if problem != "" {
+		log.Warn(problem)
+		config.warnings = append(config.warnings, problem)
+	}
+
 	// Validate configuration
 	err = validateConfig(config)
 	if err != nil {
@@ -124,68 +355,130 @@ func LoadConfig(configPath string) (*Config, error) {
 }
 
 // setDefaults sets default values for the configuration.
-func setDefaults(_ *Config) {
+func setDefaults(v *viper.Viper) {
 	// App defaults
-	viper.SetDefault("app.name", "template-arch-lint")
-	viper.SetDefault("app.version", "1.0.0")
-	viper.SetDefault("app.environment", "development")
-	viper.SetDefault("app.debug", false)
+	v.SetDefault("app.name", "template-arch-lint")
+	v.SetDefault("app.version", "1.0.0")
+	v.SetDefault("app.environment", "development")
+	v.SetDefault("app.debug", false)
+	v.SetDefault("app.recording.enabled", false)
+	v.SetDefault("app.recording.sample_rate", 1.0)
+	v.SetDefault("app.recording.routes", []string{})
+	v.SetDefault("app.recording.dir", "testdata/fixtures")
 
 	// Server defaults
-	viper.SetDefault("server.host", "localhost")
-	viper.SetDefault("server.port", values.DefaultHTTPPort)
-	viper.SetDefault("server.read_timeout", defaultServerReadTimeout)
-	viper.SetDefault("server.write_timeout", defaultServerWriteTimeout)
-	viper.SetDefault("server.idle_timeout", defaultServerIdleTimeout)
-	viper.SetDefault("server.graceful_shutdown_timeout", defaultGracefulShutdownTimeout)
+	v.SetDefault("server.host", "localhost")
+	v.SetDefault("server.port", values.DefaultHTTPPort)
+	v.SetDefault("server.read_timeout", defaultServerReadTimeout)
+	v.SetDefault("server.write_timeout", defaultServerWriteTimeout)
+	v.SetDefault("server.idle_timeout", defaultServerIdleTimeout)
+	v.SetDefault("server.graceful_shutdown_timeout", defaultGracefulShutdownTimeout)
+	v.SetDefault("server.request_timeout", defaultRequestTimeout)
+	v.SetDefault("server.request_timeout_routes", map[string]time.Duration{
+		"GET /api/v1/users/export":  defaultStreamRequestTimeout,
+		"POST /api/v1/users/import": defaultStreamRequestTimeout,
+	})
+	v.SetDefault("server.well_known.security_contacts", []string{})
+	v.SetDefault("server.well_known.security_expires_in", defaultSecurityTxtExpiresIn)
+	v.SetDefault("server.well_known.security_policy_url", "")
+	v.SetDefault("server.well_known.preferred_languages", "en")
+	v.SetDefault("server.well_known.robots_disallow", []string{"/admin/", "/debug/"})
+	v.SetDefault("server.headers.max_bytes", defaultHeaderMaxBytes)
+	v.SetDefault("server.headers.soft_limit_bytes", defaultHeaderSoftLimitBytes)
+	v.SetDefault("server.headers.field_limit_bytes", defaultHeaderFieldLimitBytes)
+	v.SetDefault("server.headers.cookie_limit_bytes", defaultHeaderCookieLimitBytes)
+	v.SetDefault("server.max_request_body_bytes", defaultMaxRequestBodyBytes)
+	v.SetDefault("server.load_shedding.enabled", true)
+	v.SetDefault("server.load_shedding.max_in_flight", defaultLoadSheddingMaxInFlight)
+	v.SetDefault("server.load_shedding.max_goroutines", defaultLoadSheddingMaxGoroutines)
+	v.SetDefault("server.load_shedding.max_heap_bytes", 0)
+	v.SetDefault("server.load_shedding.exempt_prefixes", []string{"/.well-known/", "/robots.txt"})
+	v.SetDefault("server.load_shedding.retry_after", time.Second)
+	v.SetDefault("server.load_shedding.sample_interval", time.Second)
+	v.SetDefault("server.concurrency.enabled", true)
+	v.SetDefault("server.concurrency.max_wait", defaultConcurrencyMaxWait)
+	v.SetDefault("server.concurrency.groups", map[string]int{"exports": defaultExportConcurrency, "imports": 1})
+	v.SetDefault("server.concurrency.policies", map[string]string{"exports": "queue", "imports": "queue"})
+	v.SetDefault("server.concurrency.routes", map[string]string{
+		"GET /api/v1/users/export":  "exports",
+		"POST /api/v1/users/import": "imports",
+	})
 
 	// Database defaults
-	viper.SetDefault("database.driver", "sqlite3")
-	viper.SetDefault("database.dsn", "./app.db")
-	viper.SetDefault("database.max_open_conns", defaultDatabaseMaxOpenConns)
-	viper.SetDefault("database.max_idle_conns", defaultDatabaseMaxIdleConns)
-	viper.SetDefault("database.conn_max_lifetime", defaultDatabaseConnMaxLifetime)
-	viper.SetDefault("database.conn_max_idle_time", defaultDatabaseConnMaxIdleTime)
+	v.SetDefault("database.driver", "sqlite3")
+	v.SetDefault("database.dsn", "./app.db")
+	v.SetDefault("database.max_open_conns", defaultDatabaseMaxOpenConns)
+	v.SetDefault("database.max_idle_conns", defaultDatabaseMaxIdleConns)
+	v.SetDefault("database.conn_max_lifetime", defaultDatabaseConnMaxLifetime)
+	v.SetDefault("database.conn_max_idle_time", defaultDatabaseConnMaxIdleTime)
+	v.SetDefault("database.ping_timeout", defaultDatabasePingTimeout)
 
 	// Logging defaults
-	viper.SetDefault("logging.level", values.DefaultLogLevel())
-	viper.SetDefault("logging.format", "json")
-	viper.SetDefault("logging.output", "stdout")
+	v.SetDefault("logging.level", values.DefaultLogLevel())
+	v.SetDefault("logging.format", "json")
+	v.SetDefault("logging.output", "stdout")
 
 	// JWT defaults
-	viper.SetDefault(
+	v.SetDefault(
 		"jwt.secret_key",
 		"your-super-secret-jwt-key-minimum-32-characters-long-for-security",
 	)
-	viper.SetDefault("jwt.access_token_expiry", defaultAccessTokenExpiry)
-	viper.SetDefault("jwt.refresh_token_expiry", defaultRefreshTokenExpiry)
-	viper.SetDefault("jwt.issuer", "template-arch-lint")
-	viper.SetDefault("jwt.algorithm", "HS256")
+	v.SetDefault("jwt.access_token_expiry", defaultAccessTokenExpiry)
+	v.SetDefault("jwt.refresh_token_expiry", defaultRefreshTokenExpiry)
+	v.SetDefault("jwt.issuer", "template-arch-lint")
+	v.SetDefault("jwt.audience", "")
+	v.SetDefault("jwt.algorithm", "HS256")
+	v.SetDefault("jwt.clock_skew", defaultJWTClockSkew)
+	v.SetDefault("jwt.jwks_url", "")
+	v.SetDefault("jwt.jwks_cache_ttl", defaultJWKSCacheTTL)
 
 	// Security defaults
-	viper.SetDefault("security.allowed_origins", []string{"http://localhost:8080"})
-	viper.SetDefault("security.trusted_proxies", []string{})
-	viper.SetDefault("security.enable_hsts", false) // Disabled by default for development
-	viper.SetDefault("security.enable_csp", true)
-	viper.SetDefault("security.csp_report_uri", "")
-	viper.SetDefault("security.max_request_size", defaultSecurityMaxRequestSize) // 10MB
-	viper.SetDefault("security.rate_limit_enabled", false)
-	viper.SetDefault("security.rate_limit_requests", defaultSecurityRateLimitRequests)
-	viper.SetDefault("security.rate_limit_window", time.Minute)
+	v.SetDefault("security.cors.allowed_origins", []string{"http://localhost:8080"})
+	v.SetDefault("security.cors.allowed_methods", []string{"GET", "POST", "PUT", "PATCH", "DELETE"})
+	v.SetDefault("security.cors.allowed_headers",
+		[]string{"Content-Type", "Authorization", "If-Match", "If-None-Match", "X-Request-ID", "Idempotency-Key"})
+	v.SetDefault("security.cors.exposed_headers", []string{"ETag", "Location", "X-Request-ID"})
+	v.SetDefault("security.cors.allow_credentials", false)
+	v.SetDefault("security.cors.max_age", defaultCORSMaxAge)
+	v.SetDefault("security.trusted_proxies", []string{})
+	v.SetDefault("security.enable_hsts", false) // Disabled by default for development
+	v.SetDefault("security.enable_csp", true)
+	v.SetDefault("security.csp_policy", "default-src 'none'; frame-ancestors 'none'")
+	v.SetDefault("security.csp_report_uri", "")
+	v.SetDefault("security.referrer_policy", "strict-origin-when-cross-origin")
+	v.SetDefault("security.rate_limit_enabled", false)
+	v.SetDefault("security.rate_limit_requests", defaultSecurityRateLimitRequests)
+	v.SetDefault("security.rate_limit_window", time.Minute)
+	v.SetDefault("security.rate_limit_routes", map[string]int{"POST /api/v1/users": defaultUserCreateRateLimit})
+
+	// API defaults
+	v.SetDefault("api.allow_put_create", false)
+	v.SetDefault("api.idempotency.enabled", true)
+	v.SetDefault("api.idempotency.ttl", defaultIdempotencyTTL)
+	v.SetDefault("api.idempotency.store", "memory")
+	v.SetDefault("api.idempotency.in_flight", "wait")
+	v.SetDefault("api.idempotency.wait_timeout", defaultIdempotencyWaitTimeout)
+
+	// Cache defaults
+	v.SetDefault("cache.enabled", true)
+	v.SetDefault("cache.ttl", defaultCacheTTL)
+	v.SetDefault("cache.ttl_jitter", defaultCacheTTLJitter)
+	v.SetDefault("cache.negative_ttl", defaultCacheNegativeTTL)
 }
 
 // configureViper sets up viper configuration.
-func configureViper(configPath string) error {
+func configureViper(v *viper.Viper, configPath string) error {
 	// Environment variable configuration
-	viper.SetEnvPrefix("APP")
-	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
-	viper.AutomaticEnv()
+	err := bindEnv(v)
+	if err != nil {
+		return err
+	}
 
 	// File configuration (optional)
 	if configPath != "" {
-		viper.SetConfigFile(configPath)
+		v.SetConfigFile(configPath)
 
-		err := viper.ReadInConfig()
+		err := v.ReadInConfig()
 		if err != nil {
 			return errors.NewInternalError("failed to read config file", err)
 		}
@@ -196,32 +489,193 @@ func configureViper(configPath string) error {
 
 // validateConfig validates the configuration.
 func validateConfig(config *Config) error {
+	violations, err := configViolations(config)
+	if err != nil {
+		return err
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+
+	// Every problem is listed at once, each with the variable that sets it.
+	messages := make([]string, 0, len(violations))
+	for _, violation := range violations {
+		messages = append(messages, fmt.Sprintf("%s (%s)", violation.Message, envName(violation.Field)))
+	}
+
+	return errors.NewValidationError(violations[0].Field, strings.Join(messages, "; "))
+}
+
+// configViolations checks the validator tags and the domain rules and
+// returns every failure. Field paths use the dotted config keys.
+func configViolations(config *Config) ([]Violation, error) {
 	validate := validator.New()
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+
+		return name
+	})
 
 	// Register custom validators
 	err := validate.RegisterValidation("valid_environment", validateEnvironment)
 	if err != nil {
-		return errors.NewInternalError("failed to register environment validator", err)
+		return nil, errors.NewInternalError("failed to register environment validator", err)
 	}
 
+	var violations []Violation
+
 	// Validate struct with validator tags
 	err = validate.Struct(config)
-	if err != nil {
-		return err
+	if fieldErrors, ok := stderrors.AsType[validator.ValidationErrors](err); ok {
+		for _, fieldError := range fieldErrors {
+			violations = append(violations, tagViolation(fieldError))
+		}
+	} else if err != nil {
+		return nil, errors.NewInternalError("failed to validate config", err)
 	}
 
 	// Additional domain-specific validation
-	err = config.Server.Port.Validate()
-	if err != nil {
-		return errors.NewValidationError("server_port", fmt.Sprintf("validation failed: %v", err))
+	if err := config.Server.Port.Validate(); err != nil {
+		violations = append(violations, ruleViolation("server.port", "valid", err.Error()))
 	}
 
-	err = config.Logging.Level.Validate()
-	if err != nil {
-		return errors.NewValidationError("logging_level", fmt.Sprintf("validation failed: %v", err))
+	if err := config.Logging.Level.Validate(); err != nil {
+		violations = append(violations, ruleViolation("logging.level", "valid", err.Error()))
 	}
 
-	return nil
+	if config.Server.Headers.SoftLimitBytes > config.Server.Headers.MaxBytes {
+		violations = append(violations, ruleViolation("server.headers.soft_limit_bytes", "lte_max_bytes",
+			"soft limit must not exceed max_bytes"))
+	}
+
+	if strings.HasPrefix(config.JWT.Algorithm, "HS") && len(config.JWT.SecretKey) < minJWTSecretKeyLength {
+		violations = append(violations, ruleViolation("jwt.secret_key", "required_for_hs",
+			fmt.Sprintf("secret_key of at least %d characters is required to verify %s tokens",
+				minJWTSecretKeyLength, config.JWT.Algorithm)))
+	}
+
+	if config.JWT.Algorithm == "RS256" && config.JWT.JWKSURL == "" {
+		violations = append(violations, ruleViolation("jwt.jwks_url", "required_for_rs256",
+			"jwks_url is required to verify RS256 tokens"))
+	}
+
+	if config.Security.CORS.AllowCredentials && slices.Contains(config.Security.CORS.AllowedOrigins, "*") {
+		violations = append(violations, ruleViolation("security.cors.allow_credentials", "no_wildcard_origin",
+			`credentials cannot be allowed for the "*" origin; list the origins instead`))
+	}
+
+	for _, proxy := range config.Security.TrustedProxies {
+		if !validIPOrPrefix(proxy) {
+			violations = append(violations, ruleViolation("security.trusted_proxies", "ip_or_cidr",
+				fmt.Sprintf("%q is neither an IP address nor a CIDR range", proxy)))
+		}
+	}
+
+	for _, route := range slices.Sorted(maps.Keys(config.Server.RequestTimeoutRoutes)) {
+		if timeout := config.Server.RequestTimeoutRoutes[route]; !validRouteGroup(route) || timeout < 0 {
+			violations = append(violations, ruleViolation("server.request_timeout_routes", "route_timeout",
+				fmt.Sprintf("%q=%s must be \"[METHOD ]/prefix\" with a timeout of 0 or more", route, timeout)))
+		}
+	}
+
+	for _, group := range slices.Sorted(maps.Keys(config.Server.Concurrency.Groups)) {
+		if limit := config.Server.Concurrency.Groups[group]; limit <= 0 {
+			violations = append(violations, ruleViolation("server.concurrency.groups", "group_limit",
+				fmt.Sprintf("%q=%d must be a positive limit", group, limit)))
+		}
+	}
+
+	for _, group := range slices.Sorted(maps.Keys(config.Server.Concurrency.Policies)) {
+		policy := config.Server.Concurrency.Policies[group]
+		_, ok := config.Server.Concurrency.Groups[strings.ToLower(group)]
+
+		if !ok || !validConcurrencyPolicy(policy) {
+			violations = append(violations, ruleViolation("server.concurrency.policies", "group_policy",
+				fmt.Sprintf("%q=%q must name a group listed in groups and one of: queue reject", group, policy)))
+		}
+	}
+
+	for _, route := range slices.Sorted(maps.Keys(config.Server.Concurrency.Routes)) {
+		group := config.Server.Concurrency.Routes[route]
+		if _, ok := config.Server.Concurrency.Groups[strings.ToLower(group)]; !validRouteGroup(route) || !ok {
+			violations = append(violations, ruleViolation("server.concurrency.routes", "route_group",
+				fmt.Sprintf("%q=%q must be \"[METHOD ]/prefix\" with a group listed in groups", route, group)))
+		}
+	}
+
+	for _, route := range slices.Sorted(maps.Keys(config.Security.RateLimitRoutes)) {
+		if requests := config.Security.RateLimitRoutes[route]; !validRouteGroup(route) || requests <= 0 {
+			violations = append(violations, ruleViolation("security.rate_limit_routes", "route_limit",
+				fmt.Sprintf("%q=%d must be \"[METHOD ]/prefix\" with a positive limit", route, requests)))
+		}
+	}
+
+	if config.App.Recording.Enabled && config.App.Environment == "production" {
+		violations = append(violations, ruleViolation("app.recording.enabled", "not_in_production",
+			"recording must not be enabled in production"))
+	}
+
+	return violations, nil
+}
+
+func tagViolation(fieldError validator.FieldError) Violation {
+	field := strings.TrimPrefix(fieldError.Namespace(), "Config.")
+	message := fmt.Sprintf("failed the %q rule", fieldError.Tag())
+
+	switch fieldError.Tag() {
+	case "required":
+		message = "is required"
+	case "oneof":
+		message = "must be one of: " + fieldError.Param()
+	case "min":
+		message = "must be at least " + fieldError.Param()
+		if fieldError.Kind() == reflect.String {
+			message += " characters long"
+		}
+	case "gt":
+		message = "must be greater than " + fieldError.Param()
+	}
+
+	return ruleViolation(field, fieldError.Tag(), field+" "+message)
+}
+
+func validIPOrPrefix(s string) bool {
+	if strings.Contains(s, "/") {
+		_, err := netip.ParsePrefix(s)
+
+		return err == nil
+	}
+
+	_, err := netip.ParseAddr(s)
+
+	return err == nil
+}
+
+// validConcurrencyPolicy reports whether policy names what a full
+// concurrency group does.
+func validConcurrencyPolicy(policy string) bool {
+	switch strings.ToLower(policy) {
+	case "queue", "reject":
+		return true
+	default:
+		return false
+	}
+}
+
+// validRouteGroup reports whether route has the "[METHOD ]/prefix" form of
+// the per-route settings.
+func validRouteGroup(route string) bool {
+	_, prefix, found := strings.Cut(strings.TrimSpace(route), " ")
+	if !found {
+		prefix = route
+	}
+
+	return strings.HasPrefix(strings.TrimSpace(prefix), "/")
+}
+
+func ruleViolation(field, rule, message string) Violation {
+	return Violation{Field: field, Rule: rule, Message: message}
 }
 
 // validateEnvironment validates environment values.
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/LarsArtmann/template-arch-lint/internal/domain/values"
@@ -69,6 +71,40 @@ func getLoadConfigTestCases() []struct {
 			},
 			wantErr: true,
 		},
+		{
+			name:       "invalid trusted proxy",
+			configPath: "",
+			envVars: map[string]string{
+				"APP_SECURITY_TRUSTED_PROXIES": "10.0.0.1,proxy.internal",
+			},
+			wantErr: true,
+		},
+		{
+			name:       "rate limit route without a path",
+			configPath: "",
+			envVars: map[string]string{
+				"APP_SECURITY_RATE_LIMIT_ROUTES": "POST users=10",
+			},
+			wantErr: true,
+		},
+		{
+			name:       "wildcard CORS origin with credentials",
+			configPath: "",
+			envVars: map[string]string{
+				"APP_SECURITY_CORS_ALLOWED_ORIGINS":   "*",
+				"APP_SECURITY_CORS_ALLOW_CREDENTIALS": "true",
+			},
+			wantErr: true,
+		},
+		{
+			name:       "recording enabled in production",
+			configPath: "",
+			envVars: map[string]string{
+				"APP_APP_ENVIRONMENT":       "production",
+				"APP_APP_RECORDING_ENABLED": "true",
+			},
+			wantErr: true,
+		},
 	}
 }
 
@@ -127,3 +163,93 @@ func validateLoadConfigResult(t *testing.T, config *Config, expectPort int, expe
 		t.Errorf("LoadConfig() level = %v, want %v", config.Logging.Level, expectedLevel)
 	}
 }
+
+func TestLoadConfigSources(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		envVars  map[string]string
+		wantPort int
+		wantDSN  string
+	}{
+		{
+			name:     "file only",
+			file:     "server:\n  port: 9001\ndatabase:\n  dsn: file.db\n",
+			envVars:  map[string]string{},
+			wantPort: 9001,
+			wantDSN:  "file.db",
+		},
+		{
+			name:     "env only",
+			file:     "",
+			envVars:  map[string]string{"APP_SERVER_PORT": "9002", "APP_DATABASE_DSN": "env.db"},
+			wantPort: 9002,
+			wantDSN:  "env.db",
+		},
+		{
+			name:     "env overrides file",
+			file:     "server:\n  port: 9001\ndatabase:\n  dsn: file.db\n",
+			envVars:  map[string]string{"APP_DATABASE_DSN": "env.db"},
+			wantPort: 9001,
+			wantDSN:  "env.db",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTestEnvironment(t, tt.envVars)
+
+			path := filepath.Join(t.TempDir(), "missing.yaml")
+			if tt.file != "" {
+				path = writeConfigFile(t, tt.file)
+			}
+
+			config, err := LoadConfig(path)
+			if err != nil {
+				t.Fatalf("LoadConfig() error = %v", err)
+			}
+
+			if int(config.Server.Port) != tt.wantPort || config.Database.DSN != tt.wantDSN {
+				t.Errorf("port, dsn = %d, %q, want %d, %q",
+					config.Server.Port, config.Database.DSN, tt.wantPort, tt.wantDSN)
+			}
+		})
+	}
+}
+
+func TestLoadConfigMissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing.yaml")
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	if !containsWarning(config.Warnings(), "not found, using environment variables and defaults") {
+		t.Errorf("expected a missing-file warning, got %v", config.Warnings())
+	}
+
+	_, err = LoadConfigStrict(path)
+	if err == nil {
+		t.Error("LoadConfigStrict() accepted a missing config file")
+	}
+}
+
+func TestLoadConfigListsEveryProblem(t *testing.T) {
+	setupTestEnvironment(t, map[string]string{
+		"APP_DATABASE_DRIVER": "oracle",
+		"APP_APP_ENVIRONMENT": "moon",
+		"APP_LOGGING_FORMAT":  "xml",
+	})
+
+	_, err := LoadConfigFromEnv()
+	if err == nil {
+		t.Fatal("LoadConfigFromEnv() accepted invalid variables")
+	}
+
+	for _, variable := range []string{"APP_DATABASE_DRIVER", "APP_APP_ENVIRONMENT", "APP_LOGGING_FORMAT"} {
+		if !strings.Contains(err.Error(), variable) {
+			t.Errorf("error %q does not name %s", err, variable)
+		}
+	}
+}
@@ -0,0 +1,286 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/LarsArtmann/template-arch-lint/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+// maxSuggestionDistance is the largest edit distance still offered as a did-you-mean.
+const maxSuggestionDistance = 3
+
+// Deprecation describes an old configuration key.
+// A deprecation with NewKey is migrated automatically; one without NewKey
+// was removed and only produces a notice.
+type Deprecation struct {
+	OldKey  string
+	NewKey  string
+	Removal string
+}
+
+// deprecations lists every retired configuration key.
+var deprecations = []Deprecation{
+	{OldKey: "server.shutdown_timeout", NewKey: "server.graceful_shutdown_timeout", Removal: ""},
+	{OldKey: "database.url", NewKey: "database.dsn", Removal: ""},
+	{OldKey: "jwt.secret", NewKey: "jwt.secret_key", Removal: ""},
+	{OldKey: "security.allowed_origins", NewKey: "security.cors.allowed_origins", Removal: ""},
+	{OldKey: "security.max_request_size", NewKey: "server.max_request_body_bytes", Removal: ""},
+	{OldKey: "logging.file", NewKey: "", Removal: "use logging.output instead"},
+}
+
+// applyDeprecations migrates renamed keys and reports removed and unknown keys.
+// In strict mode removed or unknown keys are errors instead of warnings.
+func applyDeprecations(v *viper.Viper, strict bool) ([]string, error) {
+	warnings, removed := migrateDeprecations(v)
+
+	problems := make([]string, 0, len(removed))
+	for _, dep := range removed {
+		problems = append(problems, removedKeyProblem(dep))
+	}
+
+	problems = append(problems, unknownKeyProblems(v, reflect.TypeFor[Config]())...)
+
+	if strict && len(problems) > 0 {
+		return warnings, errors.NewConfigurationError("config", strings.Join(problems, "; "))
+	}
+
+	return append(warnings, problems...), nil
+}
+
+// migrateDeprecations copies renamed keys to their new names and returns the
+// deprecation warnings and the removed keys present in the config file.
+func migrateDeprecations(v *viper.Viper) ([]string, []Deprecation) {
+	var (
+		warnings []string
+		removed  []Deprecation
+	)
+
+	for _, dep := range deprecations {
+		if !v.InConfig(dep.OldKey) {
+			continue
+		}
+
+		if dep.NewKey == "" {
+			removed = append(removed, dep)
+
+			continue
+		}
+
+		if !v.InConfig(dep.NewKey) {
+			v.Set(dep.NewKey, v.Get(dep.OldKey))
+		}
+
+		warnings = append(warnings, fmt.Sprintf("config key %q is deprecated, use %q", dep.OldKey, dep.NewKey))
+	}
+
+	return warnings, removed
+}
+
+func removedKeyProblem(dep Deprecation) string {
+	return fmt.Sprintf("config key %q was removed: %s", dep.OldKey, dep.Removal)
+}
+
+// unknownKey is a config file key that matches no field, with the closest
+// known key when one is near enough.
+type unknownKey struct {
+	Key        string
+	Suggestion string
+}
+
+func (u unknownKey) problem() string {
+	problem := fmt.Sprintf("unknown config key %q", u.Key)
+	if u.Suggestion != "" {
+		problem += fmt.Sprintf(", did you mean %q?", u.Suggestion)
+	}
+
+	return problem
+}
+
+// unknownKeyProblems reports config file keys that match no field of root.
+func unknownKeyProblems(v *viper.Viper, root reflect.Type) []string {
+	unknown := unknownKeys(v, root)
+	problems := make([]string, 0, len(unknown))
+
+	for _, key := range unknown {
+		problems = append(problems, key.problem())
+	}
+
+	return problems
+}
+
+// unknownKeys finds config file keys that match no field of root.
+// Keys below an unknown section are reported once, as the section.
+func unknownKeys(v *viper.Viper, root reflect.Type) []unknownKey {
+	known, mapPrefixes := knownConfigKeys(root)
+	sections := knownSections(known)
+	reported := make(map[string]bool)
+
+	var unknownKeys []unknownKey
+
+	keys := v.AllKeys()
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		if !v.InConfig(key) || isKnownKey(key, known, mapPrefixes) {
+			continue
+		}
+
+		unknown := unknownPrefix(key, sections)
+		if reported[unknown] {
+			continue
+		}
+
+		reported[unknown] = true
+
+		suggestion := suggestKey(key, known)
+		if suggestion == "" && unknown != key {
+			suggestion = suggestKey(unknown, sections)
+		}
+
+		unknownKeys = append(unknownKeys, unknownKey{Key: unknown, Suggestion: suggestion})
+	}
+
+	return unknownKeys
+}
+
+func isKnownKey(key string, known, mapPrefixes []string) bool {
+	if slices.Contains(known, key) {
+		return true
+	}
+
+	if slices.ContainsFunc(deprecations, func(dep Deprecation) bool { return dep.OldKey == key }) {
+		return true
+	}
+
+	// Free-form maps such as headers accept any nested key.
+	return slices.ContainsFunc(mapPrefixes, func(prefix string) bool {
+		return strings.HasPrefix(key, prefix+".")
+	})
+}
+
+// knownSections returns every dotted parent of the known keys.
+func knownSections(known []string) []string {
+	var sections []string
+
+	for _, key := range known {
+		for i, r := range key {
+			if r == '.' && !slices.Contains(sections, key[:i]) {
+				sections = append(sections, key[:i])
+			}
+		}
+	}
+
+	return sections
+}
+
+// unknownPrefix returns the shortest prefix of key that is not a known section.
+func unknownPrefix(key string, sections []string) string {
+	for i, r := range key {
+		if r == '.' && !slices.Contains(sections, key[:i]) {
+			return key[:i]
+		}
+	}
+
+	return key
+}
+
+// knownConfigKeys flattens the mapstructure tags of root into dotted keys.
+// Map-typed fields are returned separately because their children are user-defined.
+func knownConfigKeys(root reflect.Type) ([]string, []string) {
+	var known, mapPrefixes []string
+
+	for _, leaf := range configLeaves(root) {
+		known = append(known, leaf.Key)
+
+		if leaf.Field.Type.Kind() == reflect.Map {
+			mapPrefixes = append(mapPrefixes, leaf.Key)
+		}
+	}
+
+	return known, mapPrefixes
+}
+
+// configLeaf is a settable configuration field and its dotted key.
+type configLeaf struct {
+	Key   string
+	Field reflect.StructField
+	// Index is the field's index sequence from root, for FieldByIndex.
+	Index []int
+}
+
+// configLeaves walks the mapstructure tags of root in declaration order.
+// Only this package's section structs nest; value objects and maps are leaves.
+func configLeaves(root reflect.Type) []configLeaf {
+	var leaves []configLeaf
+
+	configPkgPath := root.PkgPath()
+
+	var walk func(t reflect.Type, prefix string, index []int)
+
+	walk = func(t reflect.Type, prefix string, index []int) {
+		for field := range t.Fields() {
+			tag, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+			if tag == "" || tag == "-" {
+				continue
+			}
+
+			key := strings.TrimPrefix(prefix+"."+tag, ".")
+			fieldIndex := append(slices.Clone(index), field.Index...)
+
+			if field.Type.Kind() == reflect.Struct && field.Type.PkgPath() == configPkgPath {
+				walk(field.Type, key, fieldIndex)
+
+				continue
+			}
+
+			leaves = append(leaves, configLeaf{Key: key, Field: field, Index: fieldIndex})
+		}
+	}
+
+	walk(root, "", nil)
+
+	return leaves
+}
+
+// suggestKey returns the known key closest to key, or "" when nothing is close.
+func suggestKey(key string, known []string) string {
+	best, bestDistance := "", maxSuggestionDistance+1
+
+	for _, candidate := range known {
+		if distance := levenshtein(key, candidate); distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+
+	return best
+}
+
+func levenshtein(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
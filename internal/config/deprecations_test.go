@@ -0,0 +1,170 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// writeConfigFile writes a YAML config file and resets viper once the test ends.
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	err := os.WriteFile(path, []byte(content), 0o600)
+	if err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	return path
+}
+
+func TestDeprecatedKeyIsMigrated(t *testing.T) {
+	path := writeConfigFile(t, "server:\n  shutdown_timeout: 45s\n")
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+
+	if config.Server.GracefulShutdownTimeout != 45*time.Second {
+		t.Errorf("GracefulShutdownTimeout = %v, want 45s from the old key", config.Server.GracefulShutdownTimeout)
+	}
+
+	want := `"server.shutdown_timeout" is deprecated, use "server.graceful_shutdown_timeout"`
+	if !containsWarning(config.Warnings(), want) {
+		t.Errorf("expected deprecation warning naming both keys, got %v", config.Warnings())
+	}
+}
+
+func TestAllowedOriginsMovedToCORS(t *testing.T) {
+	path := writeConfigFile(t, "security:\n  allowed_origins: [\"https://app.example.com\"]\n")
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+
+	if !slices.Equal(config.Security.CORS.AllowedOrigins, []string{"https://app.example.com"}) {
+		t.Errorf("CORS.AllowedOrigins = %v, want the value of the old key", config.Security.CORS.AllowedOrigins)
+	}
+}
+
+func TestRemovedKey(t *testing.T) {
+	path := writeConfigFile(t, "logging:\n  file: /var/log/app.log\n")
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+
+	if !containsWarning(config.Warnings(), `"logging.file" was removed`) {
+		t.Errorf("expected removal warning, got %v", config.Warnings())
+	}
+
+	_, err = LoadConfigStrict(path)
+	if err == nil || !strings.Contains(err.Error(), "logging.file") {
+		t.Errorf("LoadConfigStrict() error = %v, want removal failure", err)
+	}
+}
+
+func TestUnknownKeySuggestion(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want string
+	}{
+		{
+			name: "section typo",
+			yaml: "serverr:\n  port: 9090\n",
+			want: `unknown config key "serverr", did you mean "server.port"?`,
+		},
+		{
+			name: "field typo",
+			yaml: "database:\n  max_open_con: 5\n",
+			want: `unknown config key "database.max_open_con", did you mean "database.max_open_conns"?`,
+		},
+		{
+			name: "unknown section reported once",
+			yaml: "observability:\n  enabled: true\n  tracing:\n    endpoint: otel:4317\n",
+			want: `unknown config key "observability"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfigFile(t, tt.yaml)
+
+			config, err := LoadConfig(path)
+			if err != nil {
+				t.Fatalf("LoadConfig() failed: %v", err)
+			}
+
+			if !containsWarning(config.Warnings(), tt.want) {
+				t.Errorf("expected warning containing %q, got %v", tt.want, config.Warnings())
+			}
+
+			if len(config.Warnings()) != 1 {
+				t.Errorf("expected exactly one warning, got %v", config.Warnings())
+			}
+		})
+	}
+}
+
+func TestValidConfigHasNoWarnings(t *testing.T) {
+	path := writeConfigFile(t, "server:\n  port: 9090\n  read_timeout: 3s\napp:\n  environment: test\n")
+
+	config, err := LoadConfigStrict(path)
+	if err != nil {
+		t.Fatalf("LoadConfigStrict() failed: %v", err)
+	}
+
+	if len(config.Warnings()) != 0 {
+		t.Errorf("valid config produced warnings: %v", config.Warnings())
+	}
+}
+
+func TestUnknownKeysAllowFreeFormMaps(t *testing.T) {
+	type tracingConfig struct {
+		Endpoint string            `mapstructure:"endpoint"`
+		Headers  map[string]string `mapstructure:"headers"`
+	}
+
+	type rootConfig struct {
+		Tracing tracingConfig `mapstructure:"tracing"`
+	}
+
+	v := viper.New()
+	v.SetConfigType("yaml")
+
+	err := v.ReadConfig(strings.NewReader(
+		"tracing:\n  endpoint: otel:4317\n  headers:\n    authorization: token\n    x-tenant: acme\n",
+	))
+	if err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+
+	if problems := unknownKeyProblems(v, reflect.TypeFor[rootConfig]()); len(problems) != 0 {
+		t.Errorf("nested map keys reported as unknown: %v", problems)
+	}
+}
+
+func containsWarning(warnings []string, fragment string) bool {
+	for _, warning := range warnings {
+		if strings.Contains(warning, fragment) {
+			return true
+		}
+	}
+
+	return false
+}
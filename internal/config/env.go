@@ -0,0 +1,228 @@
+package config
+
+import (
+	"encoding/json/v2"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/LarsArtmann/template-arch-lint/pkg/errors"
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/spf13/viper"
+)
+
+// envPrefix is prepended to every environment variable name.
+const envPrefix = "APP_"
+
+// EnvBinding describes the environment variable bound to one config key.
+type EnvBinding struct {
+	Key         string
+	Env         string
+	Type        string
+	Default     string
+	Description string
+}
+
+// EnvBindings returns the environment variable of every config field, in
+// declaration order. Descriptions come from the fields' desc tags.
+func EnvBindings() []EnvBinding {
+	defaults := viper.New()
+	setDefaults(defaults)
+
+	leaves := configLeaves(reflect.TypeFor[Config]())
+	bindings := make([]EnvBinding, 0, len(leaves))
+
+	for _, leaf := range leaves {
+		bindings = append(bindings, EnvBinding{
+			Key:         leaf.Key,
+			Env:         envName(leaf.Key),
+			Type:        envType(leaf.Field.Type),
+			Default:     formatEnvValue(defaults.Get(leaf.Key)),
+			Description: leaf.Field.Tag.Get("desc"),
+		})
+	}
+
+	return bindings
+}
+
+// EnvDocs renders EnvBindings as a Markdown reference.
+func EnvDocs() string {
+	var doc strings.Builder
+
+	doc.WriteString("# Environment variables\n\n")
+	doc.WriteString("<!-- Generated from internal/config; regenerate with " +
+		"`go test ./internal/config -run TestEnvDocs -update`. -->\n\n")
+	doc.WriteString("Every setting can be set through its environment variable, " +
+		"which overrides the config file. Start the server with `-env-only` to skip " +
+		"config files entirely. Lists are comma-separated; maps take a JSON object " +
+		"or comma-separated key=value pairs.\n\n")
+	doc.WriteString("| Variable | Type | Default | Description |\n")
+	doc.WriteString("| --- | --- | --- | --- |\n")
+
+	for _, binding := range EnvBindings() {
+		defaultValue := ""
+		if binding.Default != "" {
+			defaultValue = "`" + binding.Default + "`"
+		}
+
+		fmt.Fprintf(&doc, "| `%s` | %s | %s | %s |\n",
+			binding.Env, binding.Type, defaultValue, binding.Description)
+	}
+
+	return doc.String()
+}
+
+// bindEnv registers every environment variable explicitly, so nested keys
+// resolve even when no default or file value exists for them.
+func bindEnv(v *viper.Viper) error {
+	for _, binding := range EnvBindings() {
+		err := v.BindEnv(binding.Key, binding.Env)
+		if err != nil {
+			return errors.NewInternalError("failed to bind "+binding.Env, err)
+		}
+	}
+
+	return nil
+}
+
+// unknownEnvProblems reports APP_* variables in environ that match no binding.
+func unknownEnvProblems(environ []string) []string {
+	bindings := EnvBindings()
+	names := make([]string, 0, len(bindings))
+
+	for _, binding := range bindings {
+		names = append(names, binding.Env)
+	}
+
+	var problems []string
+
+	for _, entry := range environ {
+		name, _, _ := strings.Cut(entry, "=")
+		if !strings.HasPrefix(name, envPrefix) || slices.Contains(names, name) {
+			continue
+		}
+
+		problem := fmt.Sprintf("unknown environment variable %q", name)
+		if suggestion := suggestKey(name, names); suggestion != "" {
+			problem += fmt.Sprintf(", did you mean %q?", suggestion)
+		}
+
+		problems = append(problems, problem)
+	}
+
+	slices.Sort(problems)
+
+	return problems
+}
+
+func envName(key string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+func envType(t reflect.Type) string {
+	if t == reflect.TypeFor[time.Duration]() {
+		return "duration"
+	}
+
+	switch t.Kind() { //nolint:exhaustive // config fields use a small set of kinds
+	case reflect.Bool:
+		return "bool"
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice:
+		return "list"
+	case reflect.Map:
+		return "map"
+	default:
+		return "string"
+	}
+}
+
+// formatEnvValue renders a viper value the way its environment variable
+// would be written.
+func formatEnvValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []string:
+		return strings.Join(v, ",")
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, fmt.Sprint(item))
+		}
+
+		return strings.Join(items, ",")
+	default:
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Map {
+			return formatEnvMap(rv)
+		}
+
+		return fmt.Sprint(v)
+	}
+}
+
+// formatEnvMap renders a map as sorted key=value pairs, the form
+// stringToMapHook reads back.
+func formatEnvMap(m reflect.Value) string {
+	pairs := make([]string, 0, m.Len())
+
+	for iter := m.MapRange(); iter.Next(); {
+		pairs = append(pairs, fmt.Sprintf("%v=%v", iter.Key(), iter.Value()))
+	}
+
+	slices.Sort(pairs)
+
+	return strings.Join(pairs, ",")
+}
+
+// decodeHooks extends viper's default hooks with string-to-map decoding,
+// so map fields can be set from a single environment variable.
+func decodeHooks() mapstructure.DecodeHookFunc {
+	return mapstructure.ComposeDecodeHookFunc(
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToSliceHookFunc(","),
+		stringToMapHook,
+	)
+}
+
+// stringToMapHook decodes a JSON object or comma-separated key=value pairs
+// into a map field.
+func stringToMapHook(from, to reflect.Type, data any) (any, error) {
+	if from.Kind() != reflect.String || to.Kind() != reflect.Map {
+		return data, nil
+	}
+
+	raw := strings.TrimSpace(reflect.ValueOf(data).String())
+	if raw == "" {
+		return map[string]any{}, nil
+	}
+
+	if strings.HasPrefix(raw, "{") {
+		var object map[string]any
+
+		err := json.Unmarshal([]byte(raw), &object)
+		if err != nil {
+			return nil, errors.NewInternalError("failed to decode JSON map", err)
+		}
+
+		return object, nil
+	}
+
+	pairs := make(map[string]any)
+
+	for pair := range strings.SplitSeq(raw, ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, errors.NewValidationError("map", fmt.Sprintf("%q is not a key=value pair", pair))
+		}
+
+		pairs[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+
+	return pairs, nil
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	envDocsPath      = "../../docs/config-environment.md"
+	fullConfigSample = "testdata/full.yaml"
+)
+
+var update = flag.Bool("update", false, "regenerate "+envDocsPath)
+
+func TestEnvBindingsAreComplete(t *testing.T) {
+	defaults := viper.New()
+	setDefaults(defaults)
+
+	for _, binding := range EnvBindings() {
+		if binding.Description == "" {
+			t.Errorf("%s has no desc tag", binding.Key)
+		}
+
+		if !defaults.IsSet(binding.Key) {
+			t.Errorf("%s has no default in setDefaults", binding.Key)
+		}
+	}
+}
+
+func TestEnvDocs(t *testing.T) {
+	docs := EnvDocs()
+
+	if *update {
+		err := os.WriteFile(envDocsPath, []byte(docs), 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	checkedIn, err := os.ReadFile(envDocsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(checkedIn) != docs {
+		t.Errorf("%s is out of date; run go test ./internal/config -run TestEnvDocs -update", envDocsPath)
+	}
+}
+
+func TestLoadConfigFromEnvMatchesYAML(t *testing.T) {
+	fromFile, err := LoadConfig(fullConfigSample)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file := viper.New()
+	file.SetConfigFile(fullConfigSample)
+
+	err = file.ReadInConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, binding := range EnvBindings() {
+		if !file.InConfig(binding.Key) {
+			t.Errorf("%s sets no value for %s", fullConfigSample, binding.Key)
+		}
+
+		t.Setenv(binding.Env, formatEnvValue(file.Get(binding.Key)))
+	}
+
+	fromEnv, err := LoadConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(fromEnv, fromFile) {
+		t.Errorf("env-only config differs from %s:\n env: %+v\nfile: %+v", fullConfigSample, fromEnv, fromFile)
+	}
+}
+
+func TestUnknownEnvProblems(t *testing.T) {
+	problems := unknownEnvProblems([]string{
+		"APP_SERVER_PORT=8080",
+		"APP_SERVER_PROT=8080",
+		"HOME=/root",
+	})
+
+	want := []string{`unknown environment variable "APP_SERVER_PROT", did you mean "APP_SERVER_PORT"?`}
+	if !slices.Equal(problems, want) {
+		t.Errorf("unknownEnvProblems() = %v, want %v", problems, want)
+	}
+}
+
+func TestStringToMapHook(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "json object", value: `{"Authorization":"Bearer x","X-Team":"core"}`,
+			want: map[string]string{"Authorization": "Bearer x", "X-Team": "core"}},
+		{name: "key=value pairs", value: "Authorization=Bearer x, X-Team=core",
+			want: map[string]string{"Authorization": "Bearer x", "X-Team": "core"}},
+		{name: "empty", value: "", want: map[string]string{}},
+		{name: "missing separator", value: "Authorization", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := viper.New()
+			v.Set("headers", tt.value)
+
+			var out struct {
+				Headers map[string]string `mapstructure:"headers"`
+			}
+
+			err := v.Unmarshal(&out, viper.DecodeHook(decodeHooks()))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && !reflect.DeepEqual(out.Headers, tt.want) {
+				t.Errorf("Headers = %v, want %v", out.Headers, tt.want)
+			}
+		})
+	}
+}
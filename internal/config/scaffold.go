@@ -0,0 +1,193 @@
+package config
+
+import (
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/values"
+	"github.com/LarsArtmann/template-arch-lint/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+// scaffoldPresets are the settings each environment's scaffold changes from
+// the defaults, keyed like the config file.
+var scaffoldPresets = map[string]map[string]any{
+	"development": {
+		"app.environment": "development",
+		"app.debug":       true,
+		"logging.level":   values.DevelopmentLogLevel(),
+		"logging.format":  "text",
+	},
+	"staging": {
+		"app.environment":      "staging",
+		"app.debug":            false,
+		"logging.level":        values.LogLevelInfo,
+		"server.host":          "0.0.0.0",
+		"server.read_timeout":  10 * time.Second,
+		"server.write_timeout": 30 * time.Second,
+		"security.enable_hsts": true,
+	},
+	"production": {
+		"app.environment":                  "production",
+		"app.debug":                        false,
+		"logging.level":                    values.LogLevelWarn,
+		"server.host":                      "0.0.0.0",
+		"server.read_timeout":              10 * time.Second,
+		"server.write_timeout":             30 * time.Second,
+		"server.graceful_shutdown_timeout": time.Minute,
+		"security.enable_hsts":             true,
+		"security.rate_limit_enabled":      true,
+	},
+}
+
+// ScaffoldEnvironments returns the environments Scaffold has presets for.
+func ScaffoldEnvironments() []string {
+	return slices.Sorted(maps.Keys(scaffoldPresets))
+}
+
+// Scaffold renders a config file for environment that sets every key, in
+// declaration order, to its default or to the environment's preset. Each
+// key is preceded by its desc tag and validate rules as a comment. The
+// keys come from the Config struct itself, so a new field shows up without
+// touching this code, and the output is the same on every run.
+func Scaffold(environment string) ([]byte, error) {
+	config, err := scaffoldConfig(environment)
+	if err != nil {
+		return nil, err
+	}
+
+	var out strings.Builder
+
+	fmt.Fprintf(&out, "# %s configuration, generated with -config-init %s.\n", environment, environment)
+	out.WriteString("# Every key is listed with its default for this environment; check edits\n")
+	out.WriteString("# with -config-check. Secrets are better set through APP_* variables.\n")
+
+	root := reflect.ValueOf(config).Elem()
+
+	var section []string
+
+	for _, leaf := range configLeaves(root.Type()) {
+		path := strings.Split(leaf.Key, ".")
+		parents := path[:len(path)-1]
+
+		// Sections are contiguous in declaration order, so a leaf opens
+		// every parent it does not share with the previous leaf.
+		shared := 0
+		for shared < min(len(section), len(parents)) && section[shared] == parents[shared] {
+			shared++
+		}
+
+		for depth := shared; depth < len(parents); depth++ {
+			if depth == 0 {
+				out.WriteString("\n")
+			}
+
+			fmt.Fprintf(&out, "%s%s:\n", scaffoldIndent(depth), parents[depth])
+		}
+
+		section = parents
+
+		value, err := scaffoldValue(root.FieldByIndex(leaf.Index))
+		if err != nil {
+			return nil, errors.NewInternalError("failed to render "+leaf.Key, err)
+		}
+
+		indent := scaffoldIndent(len(parents))
+		fmt.Fprintf(&out, "%s# %s\n", indent, scaffoldComment(leaf.Field))
+		fmt.Fprintf(&out, "%s%s: %s\n", indent, path[len(path)-1], value)
+	}
+
+	return []byte(out.String()), nil
+}
+
+// scaffoldConfig returns the defaults with the preset of environment applied.
+func scaffoldConfig(environment string) (*Config, error) {
+	preset, ok := scaffoldPresets[environment]
+	if !ok {
+		return nil, errors.NewValidationError("environment", fmt.Sprintf("no preset for environment %q, want %s",
+			environment, strings.Join(ScaffoldEnvironments(), ", ")))
+	}
+
+	v := viper.New()
+	setDefaults(v)
+
+	for key, value := range preset {
+		v.Set(key, value)
+	}
+
+	config := &Config{} //nolint:exhaustruct // filled by Unmarshal
+
+	err := v.Unmarshal(config, viper.DecodeHook(decodeHooks()))
+	if err != nil {
+		return nil, errors.NewInternalError("failed to unmarshal scaffold", err)
+	}
+
+	return config, nil
+}
+
+func scaffoldIndent(depth int) string {
+	return strings.Repeat("  ", depth)
+}
+
+// scaffoldComment describes field by its desc tag and validate rules.
+func scaffoldComment(field reflect.StructField) string {
+	comment := field.Tag.Get("desc")
+	if rules := field.Tag.Get("validate"); rules != "" {
+		comment += " [validate: " + rules + "]"
+	}
+
+	return comment
+}
+
+// scaffoldValue renders value as a YAML flow value. Durations become
+// strings such as "5m", also as map values; value objects are written as
+// their underlying kind. Every other value is written as JSON, which YAML
+// reads as is.
+func scaffoldValue(value reflect.Value) (string, error) {
+	var plain any
+
+	switch {
+	case value.Type() == reflect.TypeFor[time.Duration]():
+		plain = formatScaffoldDuration(time.Duration(value.Int()))
+	case value.Kind() == reflect.String:
+		plain = value.String()
+	case value.CanInt():
+		plain = value.Int()
+	default:
+		plain = value.Interface()
+	}
+
+	// Durations inside maps are written like top-level ones.
+	durations := json.MarshalFunc(func(d time.Duration) ([]byte, error) {
+		return json.Marshal(formatScaffoldDuration(d))
+	})
+
+	encoded, err := json.Marshal(plain, json.WithMarshalers(durations),
+		json.Deterministic(true), jsontext.SpaceAfterColon(true), jsontext.SpaceAfterComma(true))
+	if err != nil {
+		return "", err
+	}
+
+	return string(encoded), nil
+}
+
+// formatScaffoldDuration drops the zero units time.Duration.String keeps,
+// writing "1h" rather than "1h0m0s".
+func formatScaffoldDuration(d time.Duration) string {
+	formatted := d.String()
+	if strings.HasSuffix(formatted, "m0s") {
+		formatted = strings.TrimSuffix(formatted, "0s")
+	}
+
+	if strings.HasSuffix(formatted, "h0m") {
+		formatted = strings.TrimSuffix(formatted, "0m")
+	}
+
+	return formatted
+}
@@ -0,0 +1,125 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestScaffoldRoundTripsThroughLoadConfig(t *testing.T) {
+	for _, environment := range ScaffoldEnvironments() {
+		t.Run(environment, func(t *testing.T) {
+			scaffold, err := Scaffold(environment)
+			if err != nil {
+				t.Fatalf("Scaffold() error = %v", err)
+			}
+
+			path := filepath.Join(t.TempDir(), environment+".yaml")
+
+			err = os.WriteFile(path, scaffold, 0o600)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := LoadConfigStrict(path)
+			if err != nil {
+				t.Fatalf("LoadConfigStrict() error = %v", err)
+			}
+
+			want, err := scaffoldConfig(environment)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(got.Warnings()) != 0 {
+				t.Errorf("warnings = %v, want none", got.Warnings())
+			}
+
+			got.warnings = nil
+			lowerMapKeys(want)
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("loaded config = %+v, want %+v", got, want)
+			}
+
+			if got.App.Environment != environment {
+				t.Errorf("app.environment = %q, want %q", got.App.Environment, environment)
+			}
+		})
+	}
+}
+
+// lowerMapKeys lowercases the keys of every map field, as viper does for
+// maps read from a file but not for defaults.
+func lowerMapKeys(config *Config) {
+	root := reflect.ValueOf(config).Elem()
+
+	for _, leaf := range configLeaves(root.Type()) {
+		field := root.FieldByIndex(leaf.Index)
+		if field.Kind() != reflect.Map || field.Type().Key().Kind() != reflect.String {
+			continue
+		}
+
+		lowered := reflect.MakeMap(field.Type())
+		for iter := field.MapRange(); iter.Next(); {
+			lowered.SetMapIndex(reflect.ValueOf(strings.ToLower(iter.Key().String())), iter.Value())
+		}
+
+		field.Set(lowered)
+	}
+}
+
+func TestScaffoldListsEveryField(t *testing.T) {
+	scaffold, err := Scaffold("development")
+	if err != nil {
+		t.Fatalf("Scaffold() error = %v", err)
+	}
+
+	v := viper.New()
+	v.SetConfigType("yaml")
+
+	err = v.ReadConfig(bytes.NewReader(scaffold))
+	if err != nil {
+		t.Fatalf("scaffold is not valid YAML: %v", err)
+	}
+
+	for _, leaf := range configLeaves(reflect.TypeFor[Config]()) {
+		if !v.InConfig(leaf.Key) {
+			t.Errorf("scaffold is missing %s", leaf.Key)
+		}
+
+		if desc := leaf.Field.Tag.Get("desc"); !strings.Contains(string(scaffold), "# "+desc) {
+			t.Errorf("scaffold is missing the comment of %s: %q", leaf.Key, desc)
+		}
+	}
+}
+
+func TestScaffoldIsDeterministic(t *testing.T) {
+	first, err := Scaffold("production")
+	if err != nil {
+		t.Fatalf("Scaffold() error = %v", err)
+	}
+
+	for range 5 {
+		again, err := Scaffold("production")
+		if err != nil {
+			t.Fatalf("Scaffold() error = %v", err)
+		}
+
+		if !bytes.Equal(first, again) {
+			t.Fatal("Scaffold() output differs between runs")
+		}
+	}
+}
+
+func TestScaffoldUnknownEnvironment(t *testing.T) {
+	_, err := Scaffold("qa")
+	if err == nil || !strings.Contains(err.Error(), "development, production, staging") {
+		t.Errorf("Scaffold(qa) error = %v, want the known environments listed", err)
+	}
+}
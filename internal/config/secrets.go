@@ -0,0 +1,139 @@
+package config
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/LarsArtmann/template-arch-lint/pkg/errors"
+)
+
+// secretRefPattern matches a ${NAME} reference in a config value. $NAME
+// without braces is left alone, since DSNs and passwords may contain $.
+var secretRefPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// SecretRef is a ${Name} reference left in the config value at Field.
+type SecretRef struct {
+	Field string
+	Name  string
+}
+
+// ExpandSecrets replaces the ${NAME} references in every string of config
+// with lookup(NAME). It walks the whole struct by reflection, including
+// slices and map values, so new fields need no change here. References
+// lookup does not resolve stay in place for FindUnexpandedSecretRefs.
+func ExpandSecrets(config *Config, lookup func(name string) (string, bool)) {
+	rewriteStrings(reflect.ValueOf(config).Elem(), "", func(_, s string) (string, bool) {
+		expanded := secretRefPattern.ReplaceAllStringFunc(s, func(ref string) string {
+			if secret, ok := lookup(secretRefPattern.FindStringSubmatch(ref)[1]); ok {
+				return secret
+			}
+
+			return ref
+		})
+
+		return expanded, expanded != s
+	})
+}
+
+// FindUnexpandedSecretRefs returns every ${NAME} reference left in config,
+// in field order. Fields are dotted config keys, with [index] or [key] for
+// slice elements and map values.
+func FindUnexpandedSecretRefs(config *Config) []SecretRef {
+	var refs []SecretRef
+
+	rewriteStrings(reflect.ValueOf(config).Elem(), "", func(field, s string) (string, bool) {
+		for _, match := range secretRefPattern.FindAllStringSubmatch(s, -1) {
+			refs = append(refs, SecretRef{Field: field, Name: match[1]})
+		}
+
+		return s, false
+	})
+
+	return refs
+}
+
+// checkSecretRefs expands the references in config from lookup and reports
+// the unresolved ones. In strict mode they are an error listing every one,
+// otherwise a warning.
+func checkSecretRefs(config *Config, lookup func(name string) (string, bool), strict bool) (string, error) {
+	ExpandSecrets(config, lookup)
+
+	refs := FindUnexpandedSecretRefs(config)
+	if len(refs) == 0 {
+		return "", nil
+	}
+
+	unresolved := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		unresolved = append(unresolved, fmt.Sprintf("%s references unset ${%s}", ref.Field, ref.Name))
+	}
+
+	problem := "unresolved secret references: " + strings.Join(unresolved, "; ")
+	if strict {
+		return "", errors.NewConfigurationError("config", problem)
+	}
+
+	return problem, nil
+}
+
+// rewriteStrings calls rewrite with the path and content of every string
+// reachable from value and stores the result when rewrite reports a
+// change. Struct fields are named by their mapstructure tags; map values
+// are visited in key order.
+func rewriteStrings(value reflect.Value, path string, rewrite func(path, s string) (string, bool)) {
+	switch value.Kind() {
+	case reflect.String:
+		if rewritten, changed := rewrite(path, value.String()); changed {
+			value.SetString(rewritten)
+		}
+	case reflect.Pointer:
+		if !value.IsNil() {
+			rewriteStrings(value.Elem(), path, rewrite)
+		}
+	case reflect.Struct:
+		for field := range value.Type().Fields() {
+			name, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+			if !field.IsExported() || name == "-" {
+				continue
+			}
+
+			fieldPath := strings.TrimPrefix(path+"."+cmp.Or(name, field.Name), ".")
+			rewriteStrings(value.FieldByIndex(field.Index), fieldPath, rewrite)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := range value.Len() {
+			rewriteStrings(value.Index(i), fmt.Sprintf("%s[%d]", path, i), rewrite)
+		}
+	case reflect.Map:
+		keys := value.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int {
+			return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+		})
+
+		for _, key := range keys {
+			// Map values are not addressable, so each is rewritten in a
+			// copy that is stored back if anything changed.
+			elem := reflect.New(value.Type().Elem()).Elem()
+			elem.Set(value.MapIndex(key))
+
+			changed := false
+
+			rewriteStrings(elem, fmt.Sprintf("%s[%v]", path, key), func(path, s string) (string, bool) {
+				rewritten, ok := rewrite(path, s)
+				changed = changed || ok
+
+				return rewritten, ok
+			})
+
+			if changed {
+				value.SetMapIndex(key, elem)
+			}
+		}
+	default:
+		// Numbers, bools and the like hold no strings.
+	}
+}
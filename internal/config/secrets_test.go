@@ -0,0 +1,147 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func lookupIn(env map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, ok := env[name]
+
+		return value, ok
+	}
+}
+
+func TestExpandSecretsReachesEveryStringField(t *testing.T) {
+	config, err := scaffoldConfig("development")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every string and string list in Config, found by reflection, so a
+	// field added later is covered without changing this test.
+	root := reflect.ValueOf(config).Elem()
+
+	var keys []string
+
+	for _, leaf := range configLeaves(root.Type()) {
+		field := root.FieldByIndex(leaf.Index)
+
+		switch {
+		case field.Kind() == reflect.String:
+			field.SetString("${TEST_SECRET}")
+		case field.Type() == reflect.TypeFor[[]string]():
+			field.Set(reflect.ValueOf([]string{"${TEST_SECRET}"}))
+		default:
+			continue
+		}
+
+		keys = append(keys, leaf.Key)
+	}
+
+	refs := FindUnexpandedSecretRefs(config)
+	if len(refs) != len(keys) {
+		t.Fatalf("found %d references, want one in each of %d string fields", len(refs), len(keys))
+	}
+
+	ExpandSecrets(config, lookupIn(map[string]string{"TEST_SECRET": "s3cret"}))
+
+	if refs := FindUnexpandedSecretRefs(config); len(refs) != 0 {
+		t.Errorf("unexpanded after ExpandSecrets: %v", refs)
+	}
+
+	if config.Database.DSN != "s3cret" || config.Security.CORS.AllowedOrigins[0] != "s3cret" {
+		t.Errorf("dsn = %q, origins = %v, want s3cret", config.Database.DSN, config.Security.CORS.AllowedOrigins)
+	}
+}
+
+func TestRewriteStringsWalksNestedValues(t *testing.T) {
+	type upstream struct {
+		URL     string            `mapstructure:"url"`
+		Headers map[string]string `mapstructure:"headers"`
+	}
+
+	settings := struct {
+		Upstreams []upstream `mapstructure:"upstreams"`
+		Primary   *upstream  `mapstructure:"primary"`
+		Port      int        `mapstructure:"port"`
+	}{
+		Upstreams: []upstream{{
+			URL:     "https://${HOST}/api",
+			Headers: map[string]string{"Authorization": "Bearer ${TOKEN}", "Accept": "text/plain"},
+		}},
+		Primary: &upstream{URL: "${HOST}", Headers: nil},
+		Port:    8080,
+	}
+
+	var visited []string
+
+	rewriteStrings(reflect.ValueOf(&settings).Elem(), "", func(path, s string) (string, bool) {
+		visited = append(visited, path)
+		expanded := strings.NewReplacer("${HOST}", "example.com", "${TOKEN}", "t0ken").Replace(s)
+
+		return expanded, expanded != s
+	})
+
+	wantVisited := []string{
+		"upstreams[0].url", "upstreams[0].headers[Accept]", "upstreams[0].headers[Authorization]", "primary.url",
+	}
+	if !slices.Equal(visited, wantVisited) {
+		t.Errorf("visited %v, want %v", visited, wantVisited)
+	}
+
+	if settings.Upstreams[0].URL != "https://example.com/api" || settings.Primary.URL != "example.com" ||
+		settings.Upstreams[0].Headers["Authorization"] != "Bearer t0ken" {
+		t.Errorf("settings = %+v, want every reference expanded", settings)
+	}
+}
+
+func TestLoadConfigSecretRefs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	err := os.WriteFile(path, []byte(`database:
+  dsn: "postgres://app:${TEST_DB_PASSWORD}@db/app"
+jwt:
+  audience: "${TEST_AUDIENCE}"
+server:
+  well_known:
+    security_contacts: ["mailto:security@example.com", "${TEST_CONTACT}"]
+`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("TEST_DB_PASSWORD", "pa$$word")
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	if config.Database.DSN != "postgres://app:pa$$word@db/app" {
+		t.Errorf("dsn = %q, want the password expanded", config.Database.DSN)
+	}
+
+	if warnings := strings.Join(config.Warnings(), "\n"); !strings.Contains(warnings, "jwt.audience") {
+		t.Errorf("warnings = %q, want the unresolved jwt.audience", warnings)
+	}
+
+	_, err = LoadConfigStrict(path)
+	if err == nil {
+		t.Fatal("LoadConfigStrict() succeeded with unresolved references")
+	}
+
+	for _, want := range []string{
+		"server.well_known.security_contacts[1] references unset ${TEST_CONTACT}",
+		"jwt.audience references unset ${TEST_AUDIENCE}",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error = %v, want it to list %q", err, want)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json/v2"
+	stderrors "errors"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/LarsArtmann/template-arch-lint/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+// DefaultValidateMaxBytes bounds the body accepted by ValidateHandler.
+const DefaultValidateMaxBytes = 1 << 20
+
+// redactedValue replaces sensitive settings in the effective config.
+const redactedValue = "[redacted]"
+
+// sensitiveConfigKeys are never echoed back by ValidateBytes.
+var sensitiveConfigKeys = []string{"database.dsn", "jwt.secret_key"}
+
+// Violation is one failed validation rule. Field is the dotted config key.
+type Violation struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// ValidationResult is the outcome of ValidateBytes. Effective holds the
+// config with defaults applied, keyed like the config file, and is only
+// set when the document is valid.
+type ValidationResult struct {
+	Valid      bool           `json:"valid"`
+	Violations []Violation    `json:"violations"`
+	Warnings   []string       `json:"warnings,omitempty"`
+	Effective  map[string]any `json:"effective,omitempty"`
+}
+
+// ValidateBytes checks a config document without loading it: unknown and
+// removed keys, the validator tags LoadConfig enforces and the domain rules.
+// format is "yaml" or "json". Environment variables are ignored, so the
+// result depends on the document alone. The error is set only when the
+// document cannot be parsed at all.
+func ValidateBytes(data []byte, format string) (*ValidationResult, error) {
+	if format != "yaml" && format != "json" {
+		return nil, errors.NewValidationError("format", "unsupported config format "+format)
+	}
+
+	v := viper.New()
+	setDefaults(v)
+	v.SetConfigType(format)
+
+	err := v.ReadConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.NewValidationError("config", "failed to parse "+format+": "+err.Error())
+	}
+
+	warnings, removed := migrateDeprecations(v)
+	result := &ValidationResult{Valid: false, Violations: []Violation{}, Warnings: warnings, Effective: nil}
+
+	for _, dep := range removed {
+		result.Violations = append(result.Violations, ruleViolation(dep.OldKey, "removed", removedKeyProblem(dep)))
+	}
+
+	for _, key := range unknownKeys(v, reflect.TypeFor[Config]()) {
+		result.Violations = append(result.Violations, ruleViolation(key.Key, "unknown_key", key.problem()))
+	}
+
+	config := &Config{} //nolint:exhaustruct // filled by Unmarshal
+
+	err = v.Unmarshal(config, viper.DecodeHook(decodeHooks()))
+	if err != nil {
+		result.Violations = append(result.Violations, ruleViolation("", "decode", err.Error()))
+
+		return result, nil
+	}
+
+	violations, err := configViolations(config)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Violations = append(result.Violations, violations...)
+
+	if len(result.Violations) == 0 {
+		result.Valid = true
+		result.Effective = effectiveSettings(config)
+	}
+
+	return result, nil
+}
+
+// effectiveSettings renders config as nested maps keyed like the config
+// file, with sensitive values redacted.
+func effectiveSettings(config *Config) map[string]any {
+	settings := make(map[string]any)
+	root := reflect.ValueOf(config).Elem()
+
+	for _, leaf := range configLeaves(root.Type()) {
+		var value any = root.FieldByIndex(leaf.Index).Interface()
+
+		switch typed := value.(type) {
+		case time.Duration:
+			value = typed.String()
+		case map[string]time.Duration:
+			durations := make(map[string]string, len(typed))
+			for key, d := range typed {
+				durations[key] = d.String()
+			}
+
+			value = durations
+		default:
+			if slices.Contains(sensitiveConfigKeys, leaf.Key) {
+				value = redactedValue
+			}
+		}
+
+		section := settings
+		parts := strings.Split(leaf.Key, ".")
+
+		for _, part := range parts[:len(parts)-1] {
+			child, ok := section[part].(map[string]any)
+			if !ok {
+				child = make(map[string]any)
+				section[part] = child
+			}
+
+			section = child
+		}
+
+		section[parts[len(parts)-1]] = value
+	}
+
+	return settings
+}
+
+// ValidateHandler serves ValidateBytes over HTTP. The body is YAML or JSON,
+// chosen by Content-Type. It answers 200 with the effective config when the
+// document is valid, 422 with the violations when it is not, and never
+// touches the running configuration.
+func ValidateHandler(maxBodyBytes int64) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		format, ok := configFormat(r.Header.Get("Content-Type"))
+		if !ok {
+			writeValidateError(w, http.StatusUnsupportedMediaType, "unsupported_media_type",
+				"Config must be sent as application/yaml or application/json")
+
+			return
+		}
+
+		data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+		if _, tooLarge := stderrors.AsType[*http.MaxBytesError](err); tooLarge {
+			writeValidateError(w, http.StatusRequestEntityTooLarge, "request_too_large", "Config document is too large")
+
+			return
+		}
+
+		if err != nil {
+			writeValidateError(w, http.StatusBadRequest, "invalid_request", "Failed to read config document")
+
+			return
+		}
+
+		result, err := ValidateBytes(data, format)
+		if err != nil {
+			writeValidateError(w, http.StatusBadRequest, "invalid_config_document", err.Error())
+
+			return
+		}
+
+		status := http.StatusOK
+		if !result.Valid {
+			status = http.StatusUnprocessableEntity
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.MarshalWrite(w, result)
+	}
+}
+
+// configFormat maps a Content-Type to a ValidateBytes format.
+func configFormat(contentType string) (string, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", false
+	}
+
+	switch mediaType {
+	case "application/json":
+		return "json", true
+	case "application/yaml", "application/x-yaml", "text/yaml":
+		return "yaml", true
+	default:
+		return "", false
+	}
+}
+
+func writeValidateError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.MarshalWrite(w, map[string]string{"error": code, "message": message})
+}
@@ -0,0 +1,233 @@
+package config
+
+import (
+	"encoding/json/v2"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestValidateBytes(t *testing.T) {
+	full, err := os.ReadFile("testdata/full.yaml")
+	if err != nil {
+		t.Fatalf("read testdata: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		format string
+		data   string
+		want   []Violation
+	}{
+		{
+			name:   "valid yaml",
+			format: "yaml",
+			data:   string(full),
+			want:   nil,
+		},
+		{
+			name:   "valid json with defaults",
+			format: "json",
+			data:   `{"server": {"port": 9090}}`,
+			want:   nil,
+		},
+		{
+			name:   "missing required field",
+			format: "yaml",
+			data:   "database:\n  dsn: \"\"\n",
+			want:   []Violation{{Field: "database.dsn", Rule: "required", Message: "database.dsn is required"}},
+		},
+		{
+			name:   "wrong enum value",
+			format: "yaml",
+			data:   "logging:\n  format: xml\n",
+			want: []Violation{{
+				Field: "logging.format", Rule: "oneof", Message: "logging.format must be one of: json text",
+			}},
+		},
+		{
+			name:   "negative pool setting",
+			format: "yaml",
+			data:   "database:\n  max_idle_conns: -1\n  ping_timeout: 0s\n",
+			want: []Violation{
+				{Field: "database.max_idle_conns", Rule: "min", Message: "database.max_idle_conns must be at least 0"},
+				{Field: "database.ping_timeout", Rule: "gt", Message: "database.ping_timeout must be greater than 0"},
+			},
+		},
+		{
+			name:   "rs256 without jwks url",
+			format: "yaml",
+			data:   "jwt:\n  algorithm: RS256\n",
+			want: []Violation{{
+				Field: "jwt.jwks_url", Rule: "required_for_rs256",
+				Message: "jwks_url is required to verify RS256 tokens",
+			}},
+		},
+		{
+			name:   "rs256 without secret key",
+			format: "yaml",
+			data:   "jwt:\n  algorithm: RS256\n  secret_key: \"\"\n  jwks_url: https://auth.example.com/jwks\n",
+			want:   nil,
+		},
+		{
+			name:   "hs256 with short secret key",
+			format: "yaml",
+			data:   "jwt:\n  algorithm: HS256\n  secret_key: short\n",
+			want: []Violation{{
+				Field: "jwt.secret_key", Rule: "required_for_hs",
+				Message: "secret_key of at least 32 characters is required to verify HS256 tokens",
+			}},
+		},
+		{
+			name:   "hs512 without secret key",
+			format: "yaml",
+			data:   "jwt:\n  algorithm: HS512\n  secret_key: \"\"\n",
+			want: []Violation{{
+				Field: "jwt.secret_key", Rule: "required_for_hs",
+				Message: "secret_key of at least 32 characters is required to verify HS512 tokens",
+			}},
+		},
+		{
+			name:   "request timeout route without prefix",
+			format: "yaml",
+			data:   "server:\n  request_timeout_routes:\n    export: 1m\n",
+			want: []Violation{{
+				Field: "server.request_timeout_routes", Rule: "route_timeout",
+				Message: `"export"=1m0s must be "[METHOD ]/prefix" with a timeout of 0 or more`,
+			}},
+		},
+		{
+			name:   "concurrency route with unknown group",
+			format: "yaml",
+			data:   "server:\n  concurrency:\n    routes:\n      \"GET /api/v1/users/export\": bulk\n",
+			want: []Violation{{
+				Field: "server.concurrency.routes", Rule: "route_group",
+				Message: `"get /api/v1/users/export"="bulk" must be "[METHOD ]/prefix" with a group listed in groups`,
+			}},
+		},
+		{
+			name:   "concurrency policy for unknown group",
+			format: "yaml",
+			data:   "server:\n  concurrency:\n    policies:\n      bulk: reject\n",
+			want: []Violation{{
+				Field: "server.concurrency.policies", Rule: "group_policy",
+				Message: `"bulk"="reject" must name a group listed in groups and one of: queue reject`,
+			}},
+		},
+		{
+			name:   "unknown concurrency policy",
+			format: "yaml",
+			data:   "server:\n  concurrency:\n    policies:\n      exports: drop\n",
+			want: []Violation{{
+				Field: "server.concurrency.policies", Rule: "group_policy",
+				Message: `"exports"="drop" must name a group listed in groups and one of: queue reject`,
+			}},
+		},
+		{
+			name:   "unknown key",
+			format: "yaml",
+			data:   "serverr:\n  port: 9090\n",
+			want: []Violation{{
+				Field: "serverr", Rule: "unknown_key",
+				Message: `unknown config key "serverr", did you mean "server.port"?`,
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ValidateBytes([]byte(tt.data), tt.format)
+			if err != nil {
+				t.Fatalf("ValidateBytes() error = %v", err)
+			}
+
+			if result.Valid != (len(tt.want) == 0) {
+				t.Errorf("Valid = %v, violations %v", result.Valid, result.Violations)
+			}
+
+			if len(tt.want) > 0 && !slices.Equal(result.Violations, tt.want) {
+				t.Errorf("Violations = %v, want %v", result.Violations, tt.want)
+			}
+
+			if result.Valid && result.Effective == nil {
+				t.Error("valid result has no effective config")
+			}
+		})
+	}
+}
+
+func TestValidateBytesEffectiveConfig(t *testing.T) {
+	result, err := ValidateBytes([]byte("server:\n  read_timeout: 7s\njwt:\n  secret_key: "+
+		strings.Repeat("k", 40)+"\n"), "yaml")
+	if err != nil {
+		t.Fatalf("ValidateBytes() error = %v", err)
+	}
+
+	server, _ := result.Effective["server"].(map[string]any)
+	if server["read_timeout"] != "7s" {
+		t.Errorf("server.read_timeout = %v, want 7s", server["read_timeout"])
+	}
+
+	jwt, _ := result.Effective["jwt"].(map[string]any)
+	if jwt["secret_key"] != redactedValue {
+		t.Errorf("jwt.secret_key = %v, want it redacted", jwt["secret_key"])
+	}
+}
+
+func TestValidateBytesRejectsUnparsableDocument(t *testing.T) {
+	_, err := ValidateBytes([]byte("server: [unclosed"), "yaml")
+	if err == nil {
+		t.Error("expected a parse error")
+	}
+}
+
+func TestValidateHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantStatus  int
+	}{
+		{name: "valid", contentType: "application/yaml", body: "server:\n  port: 9090\n", wantStatus: http.StatusOK},
+		{
+			name:        "invalid",
+			contentType: "application/json",
+			body:        `{"serverr": {"port": 1}}`,
+			wantStatus:  http.StatusUnprocessableEntity,
+		},
+		{name: "unparsable", contentType: "application/json", body: `{`, wantStatus: http.StatusBadRequest},
+		{name: "wrong media type", contentType: "text/plain", body: "", wantStatus: http.StatusUnsupportedMediaType},
+		{
+			name:        "oversized",
+			contentType: "application/yaml",
+			body:        "app:\n  name: " + strings.Repeat("x", 2048) + "\n",
+			wantStatus:  http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	handler := ValidateHandler(1024)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/config/validate", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+
+			var body map[string]any
+
+			err := json.Unmarshal(w.Body.Bytes(), &body)
+			if err != nil {
+				t.Fatalf("response is not JSON: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package clock_test
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/clock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestClock(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Clock Suite")
+}
+
+var _ = Describe("Fake", func() {
+	start := time.Date(2026, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+	It("should stand still until moved", func() {
+		fake := clock.NewFake(start)
+		Expect(fake.Now()).To(Equal(start))
+		Expect(fake.Now()).To(Equal(start))
+	})
+
+	It("should advance by the given duration", func() {
+		fake := clock.NewFake(start)
+		fake.Advance(90 * time.Minute)
+		Expect(fake.Now()).To(Equal(start.Add(90 * time.Minute)))
+	})
+
+	It("should set any time, including an earlier one", func() {
+		fake := clock.NewFake(start)
+		fake.Set(start.AddDate(-1, 0, 0))
+		Expect(fake.Now()).To(Equal(start.AddDate(-1, 0, 0)))
+	})
+})
+
+var _ = Describe("Domain and application code", func() {
+	It("should read the time only through a Clock", func() {
+		var calls []string
+
+		for _, root := range []string{"..", "../../application"} {
+			err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+
+				if entry.IsDir() && entry.Name() == "clock" {
+					return filepath.SkipDir
+				}
+
+				if entry.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+					return nil
+				}
+
+				found, err := wallClockCalls(path)
+				calls = append(calls, found...)
+
+				return err
+			})
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		Expect(calls).To(BeEmpty(), "use an injected clock.Clock instead")
+	})
+})
+
+// wallClockCalls returns the positions of time.Now, time.Since and
+// time.Until in the file at path, whatever name it imports "time" under.
+func wallClockCalls(path string) ([]string, error) {
+	fset := token.NewFileSet()
+
+	file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	timeName := ""
+
+	for _, spec := range file.Imports {
+		if importPath, _ := strconv.Unquote(spec.Path.Value); importPath == "time" {
+			timeName = "time"
+			if spec.Name != nil {
+				timeName = spec.Name.Name
+			}
+		}
+	}
+
+	if timeName == "" {
+		return nil, nil
+	}
+
+	var calls []string
+
+	ast.Inspect(file, func(node ast.Node) bool {
+		selector, ok := node.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+
+		pkg, ok := selector.X.(*ast.Ident)
+		if ok && pkg.Name == timeName {
+			switch selector.Sel.Name {
+			case "Now", "Since", "Until":
+				calls = append(calls, fset.Position(selector.Pos()).String()+": time."+selector.Sel.Name)
+			}
+		}
+
+		return true
+	})
+
+	return calls, nil
+}
@@ -21,15 +21,23 @@ type User struct {
 	ID       values.UserID `json:"id"`
 	Created  time.Time     `json:"created"`
 	Modified time.Time     `json:"modified"`
+	// Version is the stored revision this entity was read at; zero until the
+	// first save. Repositories reject a save whose Version is stale and bump
+	// it on every successful one.
+	Version int `json:"version"`
+	// DeletedAt is when the user was soft-deleted, zero while it is active.
+	// Repository lookups skip soft-deleted users.
+	DeletedAt time.Time `json:"deleted_at,omitzero"`
 
 	// Private value objects - single source of truth, type safe
 	email values.Email    // Private - access through GetEmail() only
 	name  values.UserName // Private - access through GetUserName() only
 }
 
-// NewUser creates a new user with validation using value objects.
-func NewUser(id values.UserID, email, name string) (*User, error) {
-	// Validate and create value objects
+// NewUser parses email and name into value objects and creates the user with
+// NewUserFromValues. Invalid input fails with a validation error on the
+// email or name field.
+func NewUser(id values.UserID, email, name string, now time.Time) (*User, error) {
 	emailVO, err := values.NewEmail(email)
 	if err != nil {
 		return nil, fmt.Errorf(
@@ -48,6 +56,21 @@ func NewUser(id values.UserID, email, name string) (*User, error) {
 		)
 	}
 
+	return NewUserFromValues(id, emailVO, nameVO, now)
+}
+
+// NewUserFromValues creates a new user from already validated value objects,
+// created and modified at now. Only the zero values, which no constructor
+// returns, are rejected.
+func NewUserFromValues(id values.UserID, email values.Email, name values.UserName, now time.Time) (*User, error) {
+	if email.IsEmpty() {
+		return nil, errors.NewRequiredFieldError("email")
+	}
+
+	if name.IsEmpty() {
+		return nil, errors.NewRequiredFieldError("name")
+	}
+
 	if id.IsZero() {
 		return nil, fmt.Errorf(
 			"create user (email=%s, name=%s): %w",
@@ -57,21 +80,21 @@ func NewUser(id values.UserID, email, name string) (*User, error) {
 		)
 	}
 
-	now := time.Now()
-
 	return &User{
-		ID:       id,
-		Created:  now,
-		Modified: now,
-		email:    emailVO, // Single source of truth - value object only
-		name:     nameVO,  // Single source of truth - value object only
+		ID:        id,
+		Created:   now,
+		Modified:  now,
+		Version:   0,
+		DeletedAt: time.Time{},
+		email:     email, // Single source of truth - value object only
+		name:      name,  // Single source of truth - value object only
 	}, nil
 }
 
 // NewUserFromStrings creates a new user with string ID (for backward compatibility).
 // TODO: DEPRECATION CANDIDATE - Remove this once all callers use values.UserID directly
 // TODO: TYPE SAFETY - Prefer NewUser with proper value objects over string conversion.
-func NewUserFromStrings(id, email, name string) (*User, error) {
+func NewUserFromStrings(id, email, name string, now time.Time) (*User, error) {
 	userID, err := values.NewUserID(id)
 	if err != nil {
 		return nil, fmt.Errorf(
@@ -82,7 +105,7 @@ func NewUserFromStrings(id, email, name string) (*User, error) {
 		)
 	}
 
-	return NewUser(userID, email, name)
+	return NewUser(userID, email, name, now)
 }
 
 // Validate ensures the user is in a valid state using value objects.
@@ -132,31 +155,49 @@ func (u *User) GetUpdatedAt() time.Time {
 	return u.Modified
 }
 
-// SetEmail updates the email with validation.
-// REFACTORED: Split brain eliminated - only updates single value object field.
-func (u *User) SetEmail(email string) error {
+// SetEmail parses email and updates it with ChangeEmail.
+func (u *User) SetEmail(email string, now time.Time) error {
 	emailVO, err := values.NewEmail(email)
 	if err != nil {
 		return fmt.Errorf("email=%s: %w", email, err)
 	}
 
-	u.email = emailVO
-	u.Modified = time.Now()
-
-	return nil
+	return u.ChangeEmail(emailVO, now)
 }
 
-// SetName updates the name with validation.
-// REFACTORED: Split brain eliminated - only updates single value object field.
-func (u *User) SetName(name string) error {
+// SetName parses name and updates it with ChangeUserName.
+func (u *User) SetName(name string, now time.Time) error {
 	nameVO, err := values.NewUserName(name)
 	if err != nil {
 		return fmt.Errorf("name=%s: %w", name, err)
 	}
 
+	return u.ChangeUserName(nameVO, now)
+}
+
+// ChangeEmail updates the email, modified at now. Only the zero value is
+// rejected.
+func (u *User) ChangeEmail(email values.Email, now time.Time) error {
+	if email.IsEmpty() {
+		return errors.NewRequiredFieldError("email")
+	}
+
+	u.email = email
+	u.Modified = now
+
+	return nil
+}
+
+// ChangeUserName updates the name, modified at now. Only the zero value is
+// rejected.
+func (u *User) ChangeUserName(name values.UserName, now time.Time) error {
+	if name.IsEmpty() {
+		return errors.NewRequiredFieldError("name")
+	}
+
 	// Single source of truth - no synchronization needed
-	u.name = nameVO
-	u.Modified = time.Now()
+	u.name = name
+	u.Modified = now
 
 	return nil
 }
@@ -173,6 +214,23 @@ func (u *User) IsEmailValid() bool {
 	return !email.IsEmpty()
 }
 
+// IsDeleted reports whether the user is soft-deleted.
+func (u *User) IsDeleted() bool {
+	return !u.DeletedAt.IsZero()
+}
+
+// SoftDelete marks the user deleted at the given time.
+func (u *User) SoftDelete(at time.Time) {
+	u.DeletedAt = at
+	u.Modified = at
+}
+
+// Restore undoes SoftDelete at the given time.
+func (u *User) Restore(at time.Time) {
+	u.DeletedAt = time.Time{}
+	u.Modified = at
+}
+
 // IsNameReserved checks if the username is reserved.
 func (u *User) IsNameReserved() bool {
 	return u.GetUserName().IsReserved()
@@ -183,20 +241,24 @@ func (u *User) IsNameReserved() bool {
 func (u *User) MarshalJSON() ([]byte, error) {
 	// Create a temporary struct for JSON marshaling with string fields
 	type userJSON struct {
-		ID       string    `json:"id"`
-		Email    string    `json:"email"`
-		Name     string    `json:"name"`
-		Created  time.Time `json:"created"`
-		Modified time.Time `json:"modified"`
+		ID        string    `json:"id"`
+		Email     string    `json:"email"`
+		Name      string    `json:"name"`
+		Created   time.Time `json:"created"`
+		Modified  time.Time `json:"modified"`
+		Version   int       `json:"version"`
+		DeletedAt time.Time `json:"deleted_at,omitzero"`
 	}
 
 	// Convert value objects to strings
 	temp := userJSON{
-		ID:       u.ID.String(),
-		Email:    u.email.String(),
-		Name:     u.name.String(),
-		Created:  u.Created,
-		Modified: u.Modified,
+		ID:        u.ID.String(),
+		Email:     u.email.String(),
+		Name:      u.name.String(),
+		Created:   u.Created,
+		Modified:  u.Modified,
+		Version:   u.Version,
+		DeletedAt: u.DeletedAt,
 	}
 
 	return json.Marshal(temp)
@@ -207,11 +269,13 @@ func (u *User) MarshalJSON() ([]byte, error) {
 func (u *User) UnmarshalJSON(data []byte) error {
 	// Create a temporary struct for JSON unmarshaling
 	type userJSON struct {
-		ID       string    `json:"id"`
-		Email    string    `json:"email"`
-		Name     string    `json:"name"`
-		Created  time.Time `json:"created"`
-		Modified time.Time `json:"modified"`
+		ID        string    `json:"id"`
+		Email     string    `json:"email"`
+		Name      string    `json:"name"`
+		Created   time.Time `json:"created"`
+		Modified  time.Time `json:"modified"`
+		Version   int       `json:"version"`
+		DeletedAt time.Time `json:"deleted_at,omitzero"`
 	}
 
 	var temp userJSON
@@ -241,6 +305,8 @@ func (u *User) UnmarshalJSON(data []byte) error {
 	u.name = name
 	u.Created = temp.Created
 	u.Modified = temp.Modified
+	u.Version = temp.Version
+	u.DeletedAt = temp.DeletedAt
 
 	return nil
 }
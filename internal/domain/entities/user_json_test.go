@@ -15,7 +15,7 @@ var _ = ginkgo.Describe("User JSON Marshaling", func() {
 	ginkgo.BeforeEach(func() {
 		var err error
 
-		user, err = NewUserFromStrings("user-123", "test@example.com", "TestUser")
+		user, err = NewUserFromStrings("user-123", "test@example.com", "TestUser", time.Now())
 		gomega.Expect(err).ToNot(gomega.HaveOccurred())
 
 		// Set specific timestamps for predictable testing
@@ -67,6 +67,7 @@ var _ = ginkgo.Describe("User JSON Marshaling", func() {
 				"user-456",
 				"test+special@sub.domain.com",
 				"José María",
+				time.Now(),
 			)
 			gomega.Expect(err).ToNot(gomega.HaveOccurred())
 
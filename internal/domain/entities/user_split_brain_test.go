@@ -2,6 +2,7 @@ package entities
 
 import (
 	"encoding/json/v2"
+	"time"
 
 	ginkgo "github.com/onsi/ginkgo/v2"
 	gomega "github.com/onsi/gomega"
@@ -15,7 +16,7 @@ var _ = ginkgo.Describe("User Split Brain Behavior", func() {
 		ginkgo.BeforeEach(func() {
 			var err error
 
-			user, err = NewUserFromStrings("user-123", "test@example.com", "TestUser")
+			user, err = NewUserFromStrings("user-123", "test@example.com", "TestUser", time.Now())
 			gomega.Expect(err).ToNot(gomega.HaveOccurred())
 		})
 
@@ -35,7 +36,7 @@ var _ = ginkgo.Describe("User Split Brain Behavior", func() {
 
 		ginkgo.It("should demonstrate synchronization ELIMINATED - single source of truth", func() {
 			// When
-			err := user.SetEmail("new@example.com")
+			err := user.SetEmail("new@example.com", time.Now())
 			gomega.Expect(err).ToNot(gomega.HaveOccurred())
 
 			// REFACTORED: Only value object updated - no synchronization needed
@@ -55,6 +56,7 @@ var _ = ginkgo.Describe("User Split Brain Behavior", func() {
 					"user-123",
 					"direct@example.com",
 					"DirectUser",
+					time.Now(),
 				)
 				gomega.Expect(err).ToNot(gomega.HaveOccurred())
 
@@ -76,7 +78,7 @@ var _ = ginkgo.Describe("User Split Brain Behavior", func() {
 				// user.name = ""                       // Would not compile - field is private
 
 				// Type safety enforced - only validated setters can modify state
-				err := user.SetEmail("invalid-email-format")
+				err := user.SetEmail("invalid-email-format", time.Now())
 				gomega.Expect(err).To(gomega.HaveOccurred()) // Validation happens in setter
 
 				// User remains in valid state - invalid updates are rejected
@@ -89,7 +91,7 @@ var _ = ginkgo.Describe("User Split Brain Behavior", func() {
 		ginkgo.It("should use ONLY value objects for domain logic", func() {
 			// ACHIEVED: User entity has ONLY value object fields
 			// No more user.Email string field - only private user.email values.Email
-			user, err := NewUserFromStrings("user-123", "test@example.com", "TestUser")
+			user, err := NewUserFromStrings("user-123", "test@example.com", "TestUser", time.Now())
 			gomega.Expect(err).ToNot(gomega.HaveOccurred())
 
 			// VERIFIED:
@@ -103,7 +105,7 @@ var _ = ginkgo.Describe("User Split Brain Behavior", func() {
 
 		ginkgo.It("should have custom JSON marshaling for value objects", func() {
 			// TARGET: JSON serialization should work seamlessly
-			user, err := NewUserFromStrings("user-123", "test@example.com", "TestUser")
+			user, err := NewUserFromStrings("user-123", "test@example.com", "TestUser", time.Now())
 			gomega.Expect(err).ToNot(gomega.HaveOccurred())
 
 			// Should marshal to expected JSON structure
@@ -151,7 +153,7 @@ var _ = ginkgo.Describe("User Split Brain Behavior", func() {
 			// user.name = ""             // Field not exported
 
 			// Only valid way should be through constructors and setters
-			user, err := NewUserFromStrings("user-123", "valid@example.com", "ValidName")
+			user, err := NewUserFromStrings("user-123", "valid@example.com", "ValidName", time.Now())
 			gomega.Expect(err).ToNot(gomega.HaveOccurred())
 			gomega.Expect(user.Validate()).To(gomega.Succeed()) // Always valid
 		})
@@ -159,7 +161,7 @@ var _ = ginkgo.Describe("User Split Brain Behavior", func() {
 		ginkgo.It("should eliminate lazy initialization overhead", func() {
 			// TARGET: Value objects created once during construction
 			// No repeated validation on every getter call
-			user, err := NewUserFromStrings("user-123", "test@example.com", "TestUser")
+			user, err := NewUserFromStrings("user-123", "test@example.com", "TestUser", time.Now())
 			gomega.Expect(err).ToNot(gomega.HaveOccurred())
 
 			// Multiple getter calls should return same object (no re-validation)
@@ -175,7 +177,7 @@ var _ = ginkgo.Describe("User Split Brain Behavior", func() {
 	ginkgo.Describe("REFACTORING VALIDATION TESTS", func() {
 		ginkgo.It("should verify no string fields remain after refactoring", func() {
 			// VALIDATED: Refactoring was successful
-			user, err := NewUserFromStrings("user-123", "test@example.com", "TestUser")
+			user, err := NewUserFromStrings("user-123", "test@example.com", "TestUser", time.Now())
 			gomega.Expect(err).ToNot(gomega.HaveOccurred())
 
 			// VERIFIED: These are the ONLY ways to access data:
@@ -196,10 +198,10 @@ var _ = ginkgo.Describe("User Split Brain Behavior", func() {
 
 		ginkgo.It("should verify setter synchronization is eliminated", func() {
 			// After refactoring, setters should only update value objects
-			user, err := NewUserFromStrings("user-123", "test@example.com", "TestUser")
+			user, err := NewUserFromStrings("user-123", "test@example.com", "TestUser", time.Now())
 			gomega.Expect(err).ToNot(gomega.HaveOccurred())
 
-			err = user.SetEmail("new@example.com")
+			err = user.SetEmail("new@example.com", time.Now())
 			gomega.Expect(err).ToNot(gomega.HaveOccurred())
 
 			// Only value object should be updated (no dual field sync needed)
@@ -208,7 +210,7 @@ var _ = ginkgo.Describe("User Split Brain Behavior", func() {
 
 		ginkgo.It("should verify JSON marshaling works without string fields", func() {
 			// Validate that custom JSON marshaling handles value objects
-			user, err := NewUserFromStrings("user-123", "test@example.com", "TestUser")
+			user, err := NewUserFromStrings("user-123", "test@example.com", "TestUser", time.Now())
 			gomega.Expect(err).ToNot(gomega.HaveOccurred())
 
 			jsonBytes, err := json.Marshal(user)
@@ -224,7 +226,7 @@ var _ = ginkgo.Describe("User Split Brain Behavior", func() {
 	ginkgo.Describe("COMPATIBILITY TESTS", func() {
 		ginkgo.It("should maintain backward compatibility for existing code", func() {
 			// Existing code using User entity should continue working
-			user, err := NewUserFromStrings("user-123", "test@example.com", "TestUser")
+			user, err := NewUserFromStrings("user-123", "test@example.com", "TestUser", time.Now())
 			gomega.Expect(err).ToNot(gomega.HaveOccurred())
 
 			// These methods must continue to work after refactoring:
@@ -237,13 +239,13 @@ var _ = ginkgo.Describe("User Split Brain Behavior", func() {
 
 		ginkgo.It("should maintain validation behavior", func() {
 			// All existing validation should continue working
-			_, err := NewUserFromStrings("", "test@example.com", "TestUser")
+			_, err := NewUserFromStrings("", "test@example.com", "TestUser", time.Now())
 			gomega.Expect(err).To(gomega.HaveOccurred())
 
-			_, err = NewUserFromStrings("user-123", "invalid-email", "TestUser")
+			_, err = NewUserFromStrings("user-123", "invalid-email", "TestUser", time.Now())
 			gomega.Expect(err).To(gomega.HaveOccurred())
 
-			_, err = NewUserFromStrings("user-123", "test@example.com", "")
+			_, err = NewUserFromStrings("user-123", "test@example.com", "", time.Now())
 			gomega.Expect(err).To(gomega.HaveOccurred())
 		})
 	})
@@ -21,7 +21,7 @@ var _ = ginkgo.Describe("User Entity", func() {
 				name := "TestUser"
 
 				// When
-				user, err := NewUser(id, email, name)
+				user, err := NewUser(id, email, name, time.Now())
 
 				// Then
 				gomega.Expect(err).ToNot(gomega.HaveOccurred())
@@ -39,7 +39,7 @@ var _ = ginkgo.Describe("User Entity", func() {
 				beforeCreation := time.Now()
 
 				// When
-				user, err := NewUserFromStrings("user-123", "test@example.com", "TestUser")
+				user, err := NewUserFromStrings("user-123", "test@example.com", "TestUser", time.Now())
 
 				// Then
 				afterCreation := time.Now()
@@ -88,7 +88,7 @@ var _ = ginkgo.Describe("User Entity", func() {
 			for _, tc := range validationTestCases {
 				ginkgo.It(tc.description, func() {
 					// When
-					user, err := NewUserFromStrings(tc.id, tc.email, tc.name)
+					user, err := NewUserFromStrings(tc.id, tc.email, tc.name, time.Now())
 
 					// Then
 					gomega.Expect(err).To(gomega.HaveOccurred())
@@ -101,7 +101,7 @@ var _ = ginkgo.Describe("User Entity", func() {
 		ginkgo.Context("edge cases", func() {
 			ginkgo.It("should reject whitespace-only inputs for ID", func() {
 				// When
-				user, err := NewUserFromStrings("   ", "test@example.com", "TestUser")
+				user, err := NewUserFromStrings("   ", "test@example.com", "TestUser", time.Now())
 
 				// Then - Current implementation validates and rejects whitespace
 				gomega.Expect(err).To(gomega.HaveOccurred())
@@ -122,6 +122,7 @@ var _ = ginkgo.Describe("User Entity", func() {
 					"user-123",
 					longStringValue+"@example.com",
 					longStringValue,
+					time.Now(),
 				)
 
 				// Then - Should fail validation for overly long email/name
@@ -134,7 +135,7 @@ var _ = ginkgo.Describe("User Entity", func() {
 	ginkgo.Describe("NewUserFromStrings", func() {
 		ginkgo.It("should create user from string ID", func() {
 			// When
-			user, err := NewUserFromStrings("user-123", "test@example.com", "TestUser")
+			user, err := NewUserFromStrings("user-123", "test@example.com", "TestUser", time.Now())
 
 			// Then
 			gomega.Expect(err).ToNot(gomega.HaveOccurred())
@@ -143,11 +144,46 @@ var _ = ginkgo.Describe("User Entity", func() {
 		})
 	})
 
+	ginkgo.Describe("NewUserFromValues", func() {
+		ginkgo.It("should create user from value objects", func() {
+			// Given
+			email, err := values.NewEmail("test@example.com")
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+			name, err := values.NewUserName("TestUser")
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+			id, err := values.NewUserID("user-123")
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+			// When
+			user, err := NewUserFromValues(id, email, name, time.Now())
+
+			// Then
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+			gomega.Expect(user.GetEmail()).To(gomega.Equal(email))
+			gomega.Expect(user.GetUserName()).To(gomega.Equal(name))
+		})
+
+		ginkgo.It("should reject zero value objects", func() {
+			// Given
+			name, err := values.NewUserName("TestUser")
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+			id, err := values.NewUserID("user-123")
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+			// When
+			user, err := NewUserFromValues(id, values.Email{}, name, time.Now())
+
+			// Then
+			gomega.Expect(err).To(gomega.HaveOccurred())
+			gomega.Expect(user).To(gomega.BeNil())
+		})
+	})
+
 	ginkgo.Describe("Validate", func() {
 		ginkgo.Context("with a valid user", func() {
 			ginkgo.It("should pass validation", func() {
 				// Given
-				user, err := NewUserFromStrings("user-123", "test@example.com", "TestUser")
+				user, err := NewUserFromStrings("user-123", "test@example.com", "TestUser", time.Now())
 				gomega.Expect(err).ToNot(gomega.HaveOccurred())
 
 				// When
@@ -203,6 +239,7 @@ var _ = ginkgo.Describe("User Entity", func() {
 					userID,
 					"test@example.com",
 					"",
+					time.Now(),
 				) // Empty name will fail validation
 
 				// When - User creation should fail for empty name
@@ -218,7 +255,7 @@ var _ = ginkgo.Describe("User Entity", func() {
 				// Given - Create user with valid fields but zero timestamps
 				userID, _ := values.NewUserID("user-123")
 				// Create user via constructor then override timestamps for testing
-				user, _ := NewUser(userID, "test@example.com", "TestUser")
+				user, _ := NewUser(userID, "test@example.com", "TestUser", time.Now())
 				user.Created = time.Time{}  // zero value for testing
 				user.Modified = time.Time{} // zero value for testing
 
@@ -237,7 +274,7 @@ var _ = ginkgo.Describe("User Entity", func() {
 		ginkgo.BeforeEach(func() {
 			var err error
 
-			user, err = NewUserFromStrings("user-123", "test@example.com", "TestUser")
+			user, err = NewUserFromStrings("user-123", "test@example.com", "TestUser", time.Now())
 			gomega.Expect(err).ToNot(gomega.HaveOccurred())
 		})
 
@@ -253,7 +290,7 @@ var _ = ginkgo.Describe("User Entity", func() {
 
 			ginkgo.It("should set email with validation", func() {
 				// When
-				err := user.SetEmail("new@example.com")
+				err := user.SetEmail("new@example.com", time.Now())
 
 				// Then
 				gomega.Expect(err).ToNot(gomega.HaveOccurred())
@@ -262,12 +299,26 @@ var _ = ginkgo.Describe("User Entity", func() {
 
 			ginkgo.It("should reject invalid email", func() {
 				// When
-				err := user.SetEmail("invalid-email")
+				err := user.SetEmail("invalid-email", time.Now())
 
 				// Then
 				gomega.Expect(err).To(gomega.HaveOccurred())
 			})
 
+			ginkgo.It("should change email to a value object", func() {
+				// Given
+				email, err := values.NewEmail("changed@example.com")
+				gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+				// When
+				err = user.ChangeEmail(email, time.Now())
+
+				// Then
+				gomega.Expect(err).ToNot(gomega.HaveOccurred())
+				gomega.Expect(user.GetEmail()).To(gomega.Equal(email))
+				gomega.Expect(user.ChangeEmail(values.Email{}, time.Now())).To(gomega.HaveOccurred())
+			})
+
 			ginkgo.It("should get email domain", func() {
 				// When
 				domain := user.EmailDomain()
@@ -294,7 +345,7 @@ var _ = ginkgo.Describe("User Entity", func() {
 
 			ginkgo.It("should set name with validation", func() {
 				// When
-				err := user.SetName("NewName")
+				err := user.SetName("NewName", time.Now())
 
 				// Then
 				gomega.Expect(err).ToNot(gomega.HaveOccurred())
@@ -0,0 +1,141 @@
+package repositories
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// idempotencySweepInterval is how often InMemoryIdempotencyStore drops
+// expired records.
+const idempotencySweepInterval = time.Minute
+
+// IdempotencyRecord is what an IdempotencyStore keeps for a key: the hash
+// of the request first made with it and, once that request finished, the
+// response to replay.
+type IdempotencyRecord struct {
+	Key         string
+	RequestHash string
+	// Status is zero while the first request is still running.
+	Status      int
+	ContentType string
+	// Location is the Location header of the response, if it had one.
+	Location  string
+	Body      []byte
+	ExpiresAt time.Time
+}
+
+// Completed reports whether the record holds a response to replay.
+func (r IdempotencyRecord) Completed() bool {
+	return r.Status != 0
+}
+
+// IdempotencyStore remembers the responses of requests made under an
+// idempotency key until the record expires.
+//
+// A request first Reserves its key; the reservation makes every other
+// request with the key see a record that is not Completed. The owner then
+// Completes the record with its response or Releases the key, so the next
+// request runs afresh. A reservation that is never finished, because the
+// process died, blocks the key until it expires.
+type IdempotencyStore interface {
+	// Reserve claims record.Key for a request with record.RequestHash until
+	// record.ExpiresAt. It returns true when the key was free or its record
+	// had expired at now; otherwise it returns the record held under the
+	// key and false.
+	Reserve(ctx context.Context, record IdempotencyRecord, now time.Time) (IdempotencyRecord, bool, error)
+	// Complete stores the response of a reserved record, which keeps its
+	// reservation's expiry. A key no longer held is left alone.
+	Complete(ctx context.Context, record IdempotencyRecord) error
+	// Release drops the record of key, completed or not.
+	Release(ctx context.Context, key string) error
+}
+
+// InMemoryIdempotencyStore implements IdempotencyStore in memory, for a
+// single process.
+type InMemoryIdempotencyStore struct {
+	mu        sync.Mutex
+	records   map[string]IdempotencyRecord
+	lastSweep time.Time
+}
+
+// NewInMemoryIdempotencyStore creates an empty in-memory idempotency store.
+func NewInMemoryIdempotencyStore() *InMemoryIdempotencyStore {
+	return &InMemoryIdempotencyStore{ //nolint:exhaustruct // mu and lastSweep have valid zero values
+		records: make(map[string]IdempotencyRecord),
+	}
+}
+
+// Reserve implements IdempotencyStore. It also drops expired records, at
+// most once per idempotencySweepInterval, so memory stays bounded by the
+// keys used within their expiry.
+func (s *InMemoryIdempotencyStore) Reserve(
+	_ context.Context, record IdempotencyRecord, now time.Time,
+) (IdempotencyRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	if held, ok := s.records[record.Key]; ok && held.ExpiresAt.After(now) {
+		return copyRecord(held), false, nil
+	}
+
+	record.Status = 0
+	record.ContentType = ""
+	record.Location = ""
+	record.Body = nil
+	s.records[record.Key] = record
+
+	return record, true, nil
+}
+
+// Complete implements IdempotencyStore.
+func (s *InMemoryIdempotencyStore) Complete(_ context.Context, record IdempotencyRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	held, ok := s.records[record.Key]
+	if !ok {
+		return nil
+	}
+
+	held.Status = record.Status
+	held.ContentType = record.ContentType
+	held.Location = record.Location
+	held.Body = slices.Clone(record.Body)
+	s.records[record.Key] = held
+
+	return nil
+}
+
+// Release implements IdempotencyStore.
+func (s *InMemoryIdempotencyStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+
+	return nil
+}
+
+func (s *InMemoryIdempotencyStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < idempotencySweepInterval {
+		return
+	}
+
+	s.lastSweep = now
+
+	for key, record := range s.records {
+		if !record.ExpiresAt.After(now) {
+			delete(s.records, key)
+		}
+	}
+}
+
+func copyRecord(record IdempotencyRecord) IdempotencyRecord {
+	record.Body = slices.Clone(record.Body)
+
+	return record
+}
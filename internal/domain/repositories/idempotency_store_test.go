@@ -0,0 +1,14 @@
+package repositories_test
+
+import (
+	"testing"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/repositories"
+	repotesting "github.com/LarsArtmann/template-arch-lint/internal/testhelpers/domain/repositories"
+)
+
+func TestInMemoryIdempotencyStoreContract(t *testing.T) {
+	repotesting.RunIdempotencyStoreContract(t, func(*testing.T) repositories.IdempotencyStore {
+		return repositories.NewInMemoryIdempotencyStore()
+	})
+}
@@ -3,9 +3,11 @@ package repositories
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 	"sync"
-	"time"
 
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/clock"
 	"github.com/LarsArtmann/template-arch-lint/internal/domain/entities"
 	"github.com/LarsArtmann/template-arch-lint/internal/domain/values"
 	"github.com/LarsArtmann/template-arch-lint/pkg/errors"
@@ -17,17 +19,32 @@ import (
 type InMemoryUserRepository struct {
 	mu    sync.RWMutex
 	users map[values.UserID]*entities.User
+	// emails maps the email of each active user to its ID, as the unique
+	// index on active emails does in SQL.
+	emails map[string]values.UserID
+	// clock stamps Modified when a stored user is saved again.
+	clock clock.Clock
 }
 
-// NewInMemoryUserRepository creates a new in-memory user repository.
+// NewInMemoryUserRepository creates a new in-memory user repository on the
+// system clock.
 func NewInMemoryUserRepository() UserRepository {
+	return NewInMemoryUserRepositoryWithClock(clock.System{})
+}
+
+// NewInMemoryUserRepositoryWithClock creates a new in-memory user repository
+// that takes modification times from clock.
+func NewInMemoryUserRepositoryWithClock(clock clock.Clock) UserRepository {
 	return &InMemoryUserRepository{ //nolint:exhaustruct // mu has valid zero value
-		users: make(map[values.UserID]*entities.User),
+		users:  make(map[values.UserID]*entities.User),
+		emails: make(map[string]values.UserID),
+		clock:  clock,
 	}
 }
 
 // Save persists a user entity.
-// Thread-safe: checks email uniqueness atomically within the write lock.
+// Thread-safe: checks email uniqueness and the stored version atomically
+// within the write lock.
 func (r *InMemoryUserRepository) Save(_ context.Context, user *entities.User) error {
 	if user == nil {
 		return errors.NewValidationError("user", "user cannot be nil")
@@ -41,31 +58,160 @@ func (r *InMemoryUserRepository) Save(_ context.Context, user *entities.User) er
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	// Check if user already exists for create operations
-	if _, exists := r.users[user.ID]; exists {
-		// Update existing user's modified time
-		user.Modified = time.Now()
-	} else {
-		// For new users, check email uniqueness atomically
-		for _, existingUser := range r.users {
-			if existingUser.GetEmail().String() == user.GetEmail().String() {
-				return fmt.Errorf(
-					"user %s with email %s already exists: %w",
-					user.ID,
-					user.GetEmail(),
-					ErrUserAlreadyExists,
-				)
-			}
+	err = r.checkSave(user)
+	if err != nil {
+		return err
+	}
+
+	r.store(user)
+
+	return nil
+}
+
+// SaveAll persists users atomically: when any of them fails the checks
+// Save makes, or two of them share an ID or email, none is stored.
+func (r *InMemoryUserRepository) SaveAll(_ context.Context, users []*entities.User) error {
+	for _, user := range users {
+		if user == nil {
+			return errors.NewValidationError("user", "user cannot be nil")
+		}
+
+		err := user.Validate()
+		if err != nil {
+			return fmt.Errorf("validate user %s: %w", user.ID, err)
 		}
 	}
 
-	// Create a copy to avoid external modifications
-	userCopy := *user
-	r.users[user.ID] = &userCopy
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	batchIDs := make(map[values.UserID]bool, len(users))
+	batchEmails := make(map[string]bool, len(users))
+
+	for _, user := range users {
+		email := user.GetEmail().String()
+		if batchIDs[user.ID] || batchEmails[email] {
+			return fmt.Errorf(
+				"user %s with email %s appears twice in the batch: %w",
+				user.ID,
+				email,
+				ErrUserAlreadyExists,
+			)
+		}
+
+		batchIDs[user.ID] = true
+		batchEmails[email] = true
+
+		err := r.checkSave(user)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, user := range users {
+		r.store(user)
+	}
+
+	return nil
+}
+
+// Update persists a user that is already stored.
+func (r *InMemoryUserRepository) Update(_ context.Context, user *entities.User) error {
+	if user == nil {
+		return errors.NewValidationError("user", "user cannot be nil")
+	}
+
+	err := user.Validate()
+	if err != nil {
+		return fmt.Errorf("validate user %s: %w", user.ID, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.users[user.ID]; !exists {
+		return fmt.Errorf("update user %s: %w", user.ID, ErrUserNotFound)
+	}
+
+	err = r.checkSave(user)
+	if err != nil {
+		return err
+	}
+
+	r.store(user)
 
 	return nil
 }
 
+// checkSave enforces the version and email uniqueness rules for saving
+// user. r.mu must be held.
+func (r *InMemoryUserRepository) checkSave(user *entities.User) error {
+	stored, exists := r.users[user.ID]
+	if exists {
+		if stored.Version != user.Version {
+			return fmt.Errorf(
+				"user %s at version %d, stored version %d: %w",
+				user.ID,
+				user.Version,
+				stored.Version,
+				ErrConcurrentModification,
+			)
+		}
+
+	} else if user.Version > 0 {
+		// A user read at some version but no longer stored was deleted
+		return fmt.Errorf("user %s deleted since version %d: %w", user.ID, user.Version, ErrConcurrentModification)
+	}
+
+	// An active user's email must not belong to another active user, be
+	// it new, restored or changed; a soft-deleted user's email may be
+	// reused
+	if owner, taken := r.emails[user.GetEmail().String()]; taken && owner != user.ID && !user.IsDeleted() {
+		return fmt.Errorf(
+			"user %s with email %s already exists: %w",
+			user.ID,
+			user.GetEmail(),
+			ErrUserAlreadyExists,
+		)
+	}
+
+	return nil
+}
+
+// store bumps the version, keeps a copy of user and moves its entry in the
+// email index. r.mu must be held.
+func (r *InMemoryUserRepository) store(user *entities.User) {
+	if stored, exists := r.users[user.ID]; exists {
+		user.Modified = r.clock.Now()
+
+		r.unindex(stored)
+	}
+
+	user.Version++
+
+	r.users[user.ID] = copyUser(user)
+	if !user.IsDeleted() {
+		r.emails[user.GetEmail().String()] = user.ID
+	}
+}
+
+// unindex drops the email index entry of user if it is the one pointing at
+// it. r.mu must be held.
+func (r *InMemoryUserRepository) unindex(user *entities.User) {
+	if r.emails[user.GetEmail().String()] == user.ID {
+		delete(r.emails, user.GetEmail().String())
+	}
+}
+
+// copyUser returns a copy of user that shares nothing with it, so neither
+// callers nor the repository can change the other's entity. User holds
+// only values, so a struct copy is a deep copy.
+func copyUser(user *entities.User) *entities.User {
+	userCopy := *user
+
+	return &userCopy
+}
+
 // FindByID retrieves a user by their unique identifier.
 func (r *InMemoryUserRepository) FindByID(
 	_ context.Context,
@@ -75,34 +221,43 @@ func (r *InMemoryUserRepository) FindByID(
 	defer r.mu.RUnlock()
 
 	user, exists := r.users[id]
-	if !exists {
+	if !exists || user.IsDeleted() {
 		return nil, ErrUserNotFound
 	}
 
-	// Return a copy to prevent external modifications
-	userCopy := *user
+	return copyUser(user), nil
+}
+
+// FindByIDIncludingDeleted retrieves a user by ID even when it is soft-deleted.
+func (r *InMemoryUserRepository) FindByIDIncludingDeleted(
+	_ context.Context,
+	id values.UserID,
+) (*entities.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, exists := r.users[id]
+	if !exists {
+		return nil, ErrUserNotFound
+	}
 
-	return &userCopy, nil
+	return copyUser(user), nil
 }
 
 // FindByEmail retrieves a user by their email address.
 func (r *InMemoryUserRepository) FindByEmail(
 	_ context.Context,
-	email string,
+	email values.Email,
 ) (*entities.User, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	for _, user := range r.users {
-		if user.GetEmail().String() == email {
-			// Return a copy to prevent external modifications
-			userCopy := *user
-
-			return &userCopy, nil
-		}
+	id, exists := r.emails[email.String()]
+	if !exists {
+		return nil, ErrUserNotFound
 	}
 
-	return nil, ErrUserNotFound
+	return copyUser(r.users[id]), nil
 }
 
 // FindByUsername retrieves a user by their username (name field).
@@ -114,42 +269,146 @@ func (r *InMemoryUserRepository) FindByUsername(
 	defer r.mu.RUnlock()
 
 	for _, user := range r.users {
-		if user.GetUserName().String() == username {
-			// Return a copy to prevent external modifications
-			userCopy := *user
-
-			return &userCopy, nil
+		if !user.IsDeleted() && user.GetUserName().String() == username {
+			return copyUser(user), nil
 		}
 	}
 
 	return nil, ErrUserNotFound
 }
 
-// Delete removes a user from the repository.
+// Delete removes a user from the repository, soft-deleted or not.
 func (r *InMemoryUserRepository) Delete(_ context.Context, id values.UserID) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if _, exists := r.users[id]; !exists {
+	user, exists := r.users[id]
+	if !exists {
 		return ErrUserNotFound
 	}
 
+	r.unindex(user)
 	delete(r.users, id)
 
 	return nil
 }
 
-// List retrieves all users.
+// List retrieves all active users, oldest first.
 func (r *InMemoryUserRepository) List(_ context.Context) ([]*entities.User, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
+	return r.listWhere(func(user *entities.User) bool { return !user.IsDeleted() }), nil
+}
+
+// FindPage retrieves one sorted page of the users matching query.Spec.
+func (r *InMemoryUserRepository) FindPage(_ context.Context, query UserPageQuery) (UserPage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return PageOf(r.listWhere(func(user *entities.User) bool { return !user.IsDeleted() }), query), nil
+}
+
+// Search answers SearchPageQuery with FindPage.
+func (r *InMemoryUserRepository) Search(ctx context.Context, query string, limit int) ([]*entities.User, error) {
+	page, err := r.FindPage(ctx, SearchPageQuery(query, limit))
+
+	return page.Users, err
+}
+
+// FindAllIter calls fn with a copy of each active user in ID order. The
+// lock is not held while fn runs, so a user saved or deleted meanwhile may
+// or may not be visited.
+func (r *InMemoryUserRepository) FindAllIter(ctx context.Context, fn func(*entities.User) error) error {
+	r.mu.RLock()
+	ids := make([]values.UserID, 0, len(r.users))
+
+	for id, user := range r.users {
+		if !user.IsDeleted() {
+			ids = append(ids, id)
+		}
+	}
+	r.mu.RUnlock()
+
+	slices.SortFunc(ids, func(a, b values.UserID) int { return strings.Compare(a.String(), b.String()) })
+
+	for _, id := range ids {
+		err := ctx.Err()
+		if err != nil {
+			return err
+		}
+
+		user, found := r.activeCopy(id)
+		if !found {
+			continue
+		}
+
+		err = fn(user)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *InMemoryUserRepository) activeCopy(id values.UserID) (*entities.User, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, exists := r.users[id]
+	if !exists || user.IsDeleted() {
+		return nil, false
+	}
+
+	return copyUser(user), true
+}
+
+// ListDeleted retrieves the soft-deleted users, oldest first.
+func (r *InMemoryUserRepository) ListDeleted(_ context.Context) ([]*entities.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.listWhere((*entities.User).IsDeleted), nil
+}
+
+// Count returns the number of active users.
+func (r *InMemoryUserRepository) Count(_ context.Context) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	count := 0
+
+	for _, user := range r.users {
+		if !user.IsDeleted() {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// Exists reports whether an active user has id.
+func (r *InMemoryUserRepository) Exists(_ context.Context, id values.UserID) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, exists := r.users[id]
+
+	return exists && !user.IsDeleted(), nil
+}
+
+// listWhere returns copies of the users matching keep in creation order,
+// as SQL's ORDER BY created_at, id would. r.mu must be held.
+func (r *InMemoryUserRepository) listWhere(keep func(*entities.User) bool) []*entities.User {
 	users := make([]*entities.User, 0, len(r.users))
 	for _, user := range r.users {
-		// Return copies to prevent external modifications
-		userCopy := *user
-		users = append(users, &userCopy)
+		if keep(user) {
+			users = append(users, copyUser(user))
+		}
 	}
 
-	return users, nil
+	slices.SortFunc(users, UserSort{Field: SortByCreated, Descending: false}.Compare)
+
+	return users
 }
@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/entities"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/values"
+	"github.com/samber/mo"
+)
+
+// FindByIDOption looks up a user by ID and reports absence as None.
+// Only real failures are returned as errors.
+func FindByIDOption(ctx context.Context, repo UserRepository, id values.UserID) (mo.Option[*entities.User], error) {
+	return toOption(repo.FindByID(ctx, id))
+}
+
+// FindByEmailOption looks up a user by email and reports absence as None.
+// Only real failures are returned as errors.
+func FindByEmailOption(
+	ctx context.Context,
+	repo UserRepository,
+	email values.Email,
+) (mo.Option[*entities.User], error) {
+	return toOption(repo.FindByEmail(ctx, email))
+}
+
+// FindByUsernameOption looks up a user by username and reports absence as None.
+// Only real failures are returned as errors.
+func FindByUsernameOption(
+	ctx context.Context,
+	repo UserRepository,
+	username string,
+) (mo.Option[*entities.User], error) {
+	return toOption(repo.FindByUsername(ctx, username))
+}
+
+// toOption folds both not-found conventions into None. ErrUserNotFound is
+// the contract; a (nil, nil) result is tolerated so implementations written
+// against the old, unspecified behavior keep working.
+func toOption(user *entities.User, err error) (mo.Option[*entities.User], error) {
+	if errors.Is(err, ErrUserNotFound) { //nolint:legacyerrors // value sentinel
+		return mo.None[*entities.User](), nil
+	}
+
+	if err != nil || user == nil {
+		return mo.None[*entities.User](), err
+	}
+
+	return mo.Some(user), nil
+}
@@ -0,0 +1,88 @@
+package repositories_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/entities"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/ids"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/repositories"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/values"
+	repotesting "github.com/LarsArtmann/template-arch-lint/internal/testhelpers/domain/repositories"
+	valuestesting "github.com/LarsArtmann/template-arch-lint/internal/testhelpers/domain/values"
+)
+
+func TestInMemoryUserRepositoryContract(t *testing.T) {
+	repotesting.RunUserRepositoryContract(t, repositories.NewInMemoryUserRepository)
+}
+
+// legacyRepository reports a missing user as (nil, nil), the behavior the
+// Option variants still accept from older implementations.
+type legacyRepository struct {
+	repositories.UserRepository
+
+	err error
+}
+
+func (r legacyRepository) FindByEmail(context.Context, values.Email) (*entities.User, error) {
+	return nil, r.err
+}
+
+func TestFindByEmailOption(t *testing.T) {
+	t.Run("found", func(t *testing.T) {
+		repo := repositories.NewInMemoryUserRepository()
+
+		user, err := entities.NewUser(ids.MustGenerateUserID(), "found@example.com", "founduser", time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := repo.Save(t.Context(), user); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := repositories.FindByEmailOption(t.Context(), repo, user.GetEmail())
+		if err != nil || got.MustGet().ID != user.ID {
+			t.Errorf("FindByEmailOption() = %v, %v", got, err)
+		}
+	})
+
+	t.Run("not found sentinel", func(t *testing.T) {
+		repo := repositories.NewInMemoryUserRepository()
+
+		got, err := repositories.FindByEmailOption(t.Context(), repo, valuestesting.MustEmail(t, "x@example.com"))
+		if err != nil || got.IsPresent() {
+			t.Errorf("FindByEmailOption() = %v, %v, want None", got, err)
+		}
+	})
+
+	t.Run("legacy nil nil", func(t *testing.T) {
+		email := valuestesting.MustEmail(t, "x@example.com")
+
+		got, err := repositories.FindByEmailOption(t.Context(), legacyRepository{}, email)
+		if err != nil || got.IsPresent() {
+			t.Errorf("FindByEmailOption() = %v, %v, want None", got, err)
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		email := valuestesting.MustEmail(t, "x@example.com")
+
+		_, err := repositories.FindByEmailOption(t.Context(), legacyRepository{err: sql.ErrConnDone}, email)
+		if !errors.Is(err, sql.ErrConnDone) {
+			t.Errorf("error = %v, want sql.ErrConnDone", err)
+		}
+	})
+}
+
+func TestFindByIDOptionNotFound(t *testing.T) {
+	var id values.UserID = ids.MustGenerateUserID()
+
+	got, err := repositories.FindByIDOption(t.Context(), repositories.NewInMemoryUserRepository(), id)
+	if err != nil || got.IsPresent() {
+		t.Errorf("FindByIDOption() = %v, %v, want None", got, err)
+	}
+}
@@ -0,0 +1,40 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+)
+
+// TxManager runs a unit of work atomically. Repositories used inside fn
+// with the context it receives take part in the same transaction.
+type TxManager interface {
+	// WithinTx runs fn in a transaction, committing when fn returns nil and
+	// rolling back otherwise. Nested calls join the outer transaction.
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type inMemoryTxKey struct{}
+
+// InMemoryTxManager is the TxManager for InMemoryUserRepository. It has
+// nothing to roll back, so it only serializes units of work, which is what
+// makes a check-then-write sequence atomic.
+type InMemoryTxManager struct {
+	mu sync.Mutex
+}
+
+// NewInMemoryTxManager creates a transaction manager for in-memory repositories.
+func NewInMemoryTxManager() *InMemoryTxManager {
+	return &InMemoryTxManager{} //nolint:exhaustruct // zero mutex is ready to use
+}
+
+// WithinTx runs fn while holding the manager's lock.
+func (m *InMemoryTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ctx.Value(inMemoryTxKey{}) == m {
+		return fn(ctx)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return fn(context.WithValue(ctx, inMemoryTxKey{}, m))
+}
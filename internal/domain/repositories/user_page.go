@@ -0,0 +1,148 @@
+package repositories
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/entities"
+	"github.com/LarsArtmann/template-arch-lint/pkg/errors"
+)
+
+// UserSortField names a user attribute that pages can be ordered by.
+type UserSortField string
+
+// Sortable user attributes.
+const (
+	SortByCreated  UserSortField = "created"
+	SortByModified UserSortField = "modified"
+	SortByEmail    UserSortField = "email"
+	SortByName     UserSortField = "name"
+)
+
+// userSortColumns maps each sort field to its column in the users table.
+var userSortColumns = map[UserSortField]string{
+	SortByCreated:  "created_at",
+	SortByModified: "updated_at",
+	SortByEmail:    "email",
+	SortByName:     "name",
+}
+
+// UserSort orders a page of users; the zero value orders by creation time.
+// Users that compare equal are ordered by ID, so that consecutive pages
+// neither repeat nor skip a user.
+type UserSort struct {
+	Field      UserSortField
+	Descending bool
+}
+
+// DefaultUserSort lists the newest users first.
+var DefaultUserSort = UserSort{Field: SortByCreated, Descending: true}
+
+// ParseUserSort parses "field" or "field:asc|desc", e.g. "created:desc".
+// A missing direction means ascending.
+func ParseUserSort(s string) (UserSort, error) {
+	field, direction, hasDirection := strings.Cut(s, ":")
+
+	sort := UserSort{Field: UserSortField(field), Descending: direction == "desc"}
+	if _, ok := userSortColumns[sort.Field]; !ok {
+		return UserSort{}, errors.NewValidationError("sort",
+			fmt.Sprintf("unknown sort field %q, want created, modified, email or name", field))
+	}
+
+	if hasDirection && direction != "asc" && direction != "desc" {
+		return UserSort{}, errors.NewValidationError("sort",
+			fmt.Sprintf("unknown sort direction %q, want asc or desc", direction))
+	}
+
+	return sort, nil
+}
+
+// Compare orders a and b by s, as slices.SortFunc expects.
+func (s UserSort) Compare(a, b *entities.User) int {
+	var order int
+
+	switch cmp.Or(s.Field, SortByCreated) {
+	case SortByCreated:
+		order = a.Created.Compare(b.Created)
+	case SortByModified:
+		order = a.Modified.Compare(b.Modified)
+	case SortByEmail:
+		order = strings.Compare(a.GetEmail().String(), b.GetEmail().String())
+	case SortByName:
+		order = strings.Compare(a.GetUserName().String(), b.GetUserName().String())
+	}
+
+	if s.Descending {
+		order = -order
+	}
+
+	return cmp.Or(order, strings.Compare(a.ID.String(), b.ID.String()))
+}
+
+// OrderBy returns the SQL ORDER BY clause equivalent to Compare. Text is
+// compared bytewise in both, as SQLite's default BINARY collation does.
+func (s UserSort) OrderBy() string {
+	column, ok := userSortColumns[cmp.Or(s.Field, SortByCreated)]
+	if !ok {
+		return "ORDER BY id"
+	}
+
+	if s.Descending {
+		return "ORDER BY " + column + " DESC, id"
+	}
+
+	return "ORDER BY " + column + ", id"
+}
+
+// UserPageQuery selects one page of the users matching Spec in Sort order.
+// Offset users are skipped; a Limit of zero or less returns all the rest.
+type UserPageQuery struct {
+	Spec   UserSpecification
+	Sort   UserSort
+	Offset int
+	Limit  int
+}
+
+// UserPage is a page of users with the number of users matching the query
+// across all pages.
+type UserPage struct {
+	Users []*entities.User
+	Total int
+}
+
+// PageOf answers query from users, the active users of a repository. It is
+// the reference semantics of UserRepository.FindPage for repositories that
+// hold their users in memory.
+func PageOf(users []*entities.User, query UserPageQuery) UserPage {
+	matching := make([]*entities.User, 0, len(users))
+
+	for _, user := range users {
+		if query.Spec == nil || query.Spec.IsSatisfiedBy(user) {
+			matching = append(matching, user)
+		}
+	}
+
+	slices.SortFunc(matching, query.Sort.Compare)
+
+	start := min(max(query.Offset, 0), len(matching))
+
+	end := len(matching)
+	if query.Limit > 0 {
+		end = min(start+query.Limit, end)
+	}
+
+	return UserPage{Users: matching[start:end], Total: len(matching)}
+}
+
+// SearchPageQuery is the query UserRepository.Search answers: the first
+// limit users matching SearchSpec{Query: query}, ordered by email.
+func SearchPageQuery(query string, limit int) UserPageQuery {
+	return UserPageQuery{
+		Spec:   SearchSpec{Query: query},
+		Sort:   UserSort{Field: SortByEmail, Descending: false},
+		Offset: 0,
+		Limit:  limit,
+	}
+}
@@ -0,0 +1,58 @@
+package repositories_test
+
+import (
+	"testing"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/repositories"
+)
+
+func TestParseUserSort(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    repositories.UserSort
+		wantErr bool
+	}{
+		{input: "created", want: repositories.UserSort{Field: repositories.SortByCreated, Descending: false}},
+		{input: "created:desc", want: repositories.UserSort{Field: repositories.SortByCreated, Descending: true}},
+		{input: "modified:asc", want: repositories.UserSort{Field: repositories.SortByModified, Descending: false}},
+		{input: "email:desc", want: repositories.UserSort{Field: repositories.SortByEmail, Descending: true}},
+		{input: "name", want: repositories.UserSort{Field: repositories.SortByName, Descending: false}},
+		{input: "", wantErr: true},
+		{input: "id", wantErr: true},
+		{input: "Created", wantErr: true},
+		{input: "created:", wantErr: true},
+		{input: "created:DESC", wantErr: true},
+		{input: "created:desc:asc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := repositories.ParseUserSort(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseUserSort() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("ParseUserSort() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserSortOrderBy(t *testing.T) {
+	tests := []struct {
+		sort repositories.UserSort
+		want string
+	}{
+		{sort: repositories.UserSort{}, want: "ORDER BY created_at, id"},
+		{sort: repositories.DefaultUserSort, want: "ORDER BY created_at DESC, id"},
+		{sort: repositories.UserSort{Field: repositories.SortByModified}, want: "ORDER BY updated_at, id"},
+		{sort: repositories.UserSort{Field: repositories.SortByName, Descending: true}, want: "ORDER BY name DESC, id"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.sort.OrderBy(); got != tt.want {
+			t.Errorf("%+v.OrderBy() = %q, want %q", tt.sort, got, tt.want)
+		}
+	}
+}
@@ -12,34 +12,95 @@ import (
 // ErrUserNotFound is returned when a user is not found.
 var ErrUserNotFound = errors.NewNotFoundError("user", "")
 
-// ErrUserAlreadyExists is returned when a user already exists.
+// ErrUserAlreadyExists is returned when another active user has the email
+// of the user being saved.
 var ErrUserAlreadyExists = errors.NewConflictError("user already exists", errors.ErrorDetails{
 	Resource: "user",
+	Field:    "email",
+})
+
+// ErrConcurrentModification is returned when a user is saved from a stale
+// copy: another save changed or deleted it since the copy was read.
+var ErrConcurrentModification = errors.NewConflictError("user was modified concurrently", errors.ErrorDetails{
+	Resource: "user",
 })
 
 // UserRepository defines the contract for user data persistence.
+//
+// Soft-deleted users (entities.User.IsDeleted) are invisible to FindByID,
+// FindByEmail, FindByUsername, Search, List, FindPage, Count and Exists,
+// and their email may be taken by a new user. FindByIDIncludingDeleted and
+// ListDeleted reach them for admin use.
+//
+// Lookups of a missing user return ErrUserNotFound and never (nil, nil);
+// RunUserRepositoryContract in internal/testhelpers/domain/repositories
+// pins this for every implementation. Callers that prefer absence over an
+// error use FindByIDOption, FindByEmailOption and FindByUsernameOption.
 type UserRepository interface {
-	// Save persists a user entity
+	// Save persists a user entity. Saving an existing user succeeds only when
+	// user.Version matches the stored version, otherwise it returns
+	// ErrConcurrentModification; a successful save bumps user.Version.
 	Save(ctx context.Context, user *entities.User) error
 
+	// SaveAll saves users atomically under the same rules as Save: either
+	// every user is stored and has its Version bumped, or none is. A SQL
+	// implementation runs it as one multi-row statement in a transaction.
+	SaveAll(ctx context.Context, users []*entities.User) error
+
 	// FindByID retrieves a user by their unique identifier
 	// TODO: QUERY OPTIMIZATION - Consider implementing query hints for performance
 	FindByID(ctx context.Context, id values.UserID) (*entities.User, error)
 
+	// FindByIDIncludingDeleted retrieves a user by ID whether or not it is
+	// soft-deleted.
+	FindByIDIncludingDeleted(ctx context.Context, id values.UserID) (*entities.User, error)
+
 	// FindByEmail retrieves a user by their email address
-	// TODO: TYPE SAFETY - Replace string with values.Email for validation
-	FindByEmail(ctx context.Context, email string) (*entities.User, error)
+	FindByEmail(ctx context.Context, email values.Email) (*entities.User, error)
 
-	// FindByUsername retrieves a user by their username
+	// FindByUsername retrieves a user by their username. The match is exact
+	// and case-sensitive, as SQL = is on a TEXT column.
 	// TODO: TYPE SAFETY - Replace string with values.UserName for validation
 	FindByUsername(ctx context.Context, username string) (*entities.User, error)
 
-	// Delete removes a user from the repository
-	// TODO: SOFT DELETE - Consider adding soft delete support with deleted_at timestamp
+	// Search retrieves at most limit users whose email local part starts
+	// with query or whose name contains it, ignoring case, in email order.
+	// It answers SearchPageQuery, so SearchSpec is the reference semantics;
+	// a SQL implementation pushes the match into the query. A limit of
+	// zero or less returns every match.
+	Search(ctx context.Context, query string, limit int) ([]*entities.User, error)
+
+	// Update saves a user that is already stored, under the same version
+	// and email rules as Save. A user that is not stored, soft-deleted or
+	// not, returns ErrUserNotFound instead of being created.
+	Update(ctx context.Context, user *entities.User) error
+
+	// Delete removes a user from the repository for good, whether or not it
+	// is soft-deleted. Soft deletion is a Save of a user marked deleted.
 	Delete(ctx context.Context, id values.UserID) error
 
-	// List retrieves all users (useful for testing and admin operations)
-	// TODO: PAGINATION - Add pagination support for large datasets
-	// TODO: FILTERING - Add filtering capabilities (active/inactive, by domain, etc.)
+	// List retrieves all active users (useful for testing and admin
+	// operations) oldest first, ties broken by ID, as ORDER BY created_at, id
+	// returns them.
 	List(ctx context.Context) ([]*entities.User, error)
+
+	// FindPage retrieves one sorted page of the users matching query.Spec,
+	// with their total count. Filtering, sorting and paging happen in the
+	// repository; PageOf is the reference semantics.
+	FindPage(ctx context.Context, query UserPageQuery) (UserPage, error)
+
+	// FindAllIter calls fn with each user List would return, one at a time
+	// in ID order, so every user can be streamed without holding them all.
+	// It stops at the first error fn returns or when ctx ends, and returns
+	// that error. A SQL implementation walks a cursor.
+	FindAllIter(ctx context.Context, fn func(*entities.User) error) error
+
+	// ListDeleted retrieves the soft-deleted users in List order.
+	ListDeleted(ctx context.Context) ([]*entities.User, error)
+
+	// Count returns the number of users List would return.
+	Count(ctx context.Context) (int, error)
+
+	// Exists reports whether FindByID would find the user.
+	Exists(ctx context.Context, id values.UserID) (bool, error)
 }
@@ -0,0 +1,273 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/entities"
+)
+
+// ActiveUserDays is how many days after creation a user counts as active.
+const ActiveUserDays = 30
+
+// SQLFragment is a condition over the users table with its positional
+// arguments, ready to be placed in a WHERE clause.
+type SQLFragment struct {
+	Clause string
+	Args   []any
+}
+
+// UserSpecification selects users. IsSatisfiedBy is the reference
+// semantics; ToSQL returns an equivalent condition over the users table, or
+// false when the specification cannot be expressed in SQL and has to be
+// evaluated in memory.
+type UserSpecification interface {
+	IsSatisfiedBy(user *entities.User) bool
+	ToSQL() (SQLFragment, bool)
+}
+
+// UserMatcher is implemented by repositories that select users by
+// specification themselves, e.g. by pushing it down into a query.
+type UserMatcher interface {
+	FindMatching(ctx context.Context, spec UserSpecification) ([]*entities.User, error)
+}
+
+// And is satisfied when every specification is; an empty And matches all users.
+func And(specs ...UserSpecification) UserSpecification {
+	return andSpec(specs)
+}
+
+// Or is satisfied when any specification is; an empty Or matches no user.
+func Or(specs ...UserSpecification) UserSpecification {
+	return orSpec(specs)
+}
+
+// Not is satisfied when spec is not.
+func Not(spec UserSpecification) UserSpecification {
+	return notSpec{spec: spec}
+}
+
+// PushDown splits spec into the part that can run as SQL and a residual to
+// evaluate in memory on the rows the SQL part returns. The top-level
+// children of an And are split individually; any other specification is
+// pushed down whole or not at all. A nil residual means nothing is left.
+func PushDown(spec UserSpecification) (SQLFragment, UserSpecification) {
+	children, isAnd := spec.(andSpec)
+	if !isAnd {
+		children = andSpec{spec}
+	}
+
+	var (
+		pushed   andSpec
+		residual andSpec
+	)
+
+	for _, child := range children {
+		if _, ok := child.ToSQL(); ok {
+			pushed = append(pushed, child)
+		} else {
+			residual = append(residual, child)
+		}
+	}
+
+	fragment, _ := pushed.ToSQL()
+	if len(residual) == 0 {
+		return fragment, nil
+	}
+
+	return fragment, residual
+}
+
+type andSpec []UserSpecification
+
+func (s andSpec) IsSatisfiedBy(user *entities.User) bool {
+	for _, spec := range s {
+		if !spec.IsSatisfiedBy(user) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s andSpec) ToSQL() (SQLFragment, bool) {
+	return joinSQL(s, " AND ", "1 = 1")
+}
+
+type orSpec []UserSpecification
+
+func (s orSpec) IsSatisfiedBy(user *entities.User) bool {
+	for _, spec := range s {
+		if spec.IsSatisfiedBy(user) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s orSpec) ToSQL() (SQLFragment, bool) {
+	return joinSQL(s, " OR ", "1 = 0")
+}
+
+type notSpec struct {
+	spec UserSpecification
+}
+
+func (s notSpec) IsSatisfiedBy(user *entities.User) bool {
+	return !s.spec.IsSatisfiedBy(user)
+}
+
+func (s notSpec) ToSQL() (SQLFragment, bool) {
+	inner, ok := s.spec.ToSQL()
+	if !ok {
+		return SQLFragment{}, false
+	}
+
+	return SQLFragment{Clause: "NOT (" + inner.Clause + ")", Args: inner.Args}, true
+}
+
+// joinSQL joins the fragments of specs with op, or returns empty when there
+// are none. It fails when any spec cannot be expressed in SQL.
+func joinSQL(specs []UserSpecification, op, empty string) (SQLFragment, bool) {
+	if len(specs) == 0 {
+		return SQLFragment{Clause: empty, Args: nil}, true
+	}
+
+	clauses := make([]string, 0, len(specs))
+
+	var args []any
+
+	for _, spec := range specs {
+		fragment, ok := spec.ToSQL()
+		if !ok {
+			return SQLFragment{}, false
+		}
+
+		clauses = append(clauses, "("+fragment.Clause+")")
+		args = append(args, fragment.Args...)
+	}
+
+	return SQLFragment{Clause: strings.Join(clauses, op), Args: args}, true
+}
+
+// EmailDomainSpec matches users whose email domain is exactly Domain.
+type EmailDomainSpec struct {
+	Domain string
+}
+
+// IsSatisfiedBy implements UserSpecification.
+func (s EmailDomainSpec) IsSatisfiedBy(user *entities.User) bool {
+	return user.EmailDomain() == s.Domain
+}
+
+// ToSQL implements UserSpecification. It compares the text after the @
+// rather than using LIKE, which SQLite matches case-insensitively.
+func (s EmailDomainSpec) ToSQL() (SQLFragment, bool) {
+	return SQLFragment{Clause: "substr(email, instr(email, '@') + 1) = ?", Args: []any{s.Domain}}, true
+}
+
+// CreatedAfterSpec matches users created strictly after Time.
+type CreatedAfterSpec struct {
+	Time time.Time
+}
+
+// IsSatisfiedBy implements UserSpecification.
+func (s CreatedAfterSpec) IsSatisfiedBy(user *entities.User) bool {
+	return user.Created.After(s.Time)
+}
+
+// ToSQL implements UserSpecification. The comparison is textual, so it
+// relies on created_at holding UTC timestamps as the driver writes them.
+func (s CreatedAfterSpec) ToSQL() (SQLFragment, bool) {
+	return SQLFragment{Clause: "created_at > ?", Args: []any{s.Time.UTC()}}, true
+}
+
+// ActiveSpec matches users that are not soft-deleted and were created
+// within ActiveUserDays before AsOf.
+type ActiveSpec struct {
+	AsOf time.Time
+}
+
+// IsSatisfiedBy implements UserSpecification.
+func (s ActiveSpec) IsSatisfiedBy(user *entities.User) bool {
+	return !user.IsDeleted() && s.createdAfter().IsSatisfiedBy(user)
+}
+
+// ToSQL implements UserSpecification.
+func (s ActiveSpec) ToSQL() (SQLFragment, bool) {
+	createdAfter, _ := s.createdAfter().ToSQL()
+
+	return SQLFragment{Clause: "deleted_at IS NULL AND " + createdAfter.Clause, Args: createdAfter.Args}, true
+}
+
+func (s ActiveSpec) createdAfter() CreatedAfterSpec {
+	return CreatedAfterSpec{Time: s.AsOf.AddDate(0, 0, -ActiveUserDays)}
+}
+
+// NamePrefixSpec matches users whose name starts with Prefix, case-sensitively.
+type NamePrefixSpec struct {
+	Prefix string
+}
+
+// IsSatisfiedBy implements UserSpecification.
+func (s NamePrefixSpec) IsSatisfiedBy(user *entities.User) bool {
+	return strings.HasPrefix(user.GetUserName().String(), s.Prefix)
+}
+
+// ToSQL implements UserSpecification. It compares a substring rather than
+// using LIKE, which would treat % and _ in Prefix as wildcards.
+func (s NamePrefixSpec) ToSQL() (SQLFragment, bool) {
+	return SQLFragment{Clause: "substr(name, 1, length(?)) = ?", Args: []any{s.Prefix, s.Prefix}}, true
+}
+
+// likeEscaper escapes the LIKE wildcards, and the escape character itself,
+// so that they match literally under ESCAPE '\'.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// SearchSpec matches users whose email local part starts with Query or
+// whose name contains it, ignoring case. Only ASCII letters are folded, as
+// SQLite's lower() and LIKE do, so other letters must match exactly. An
+// empty Query matches every user.
+type SearchSpec struct {
+	Query string
+}
+
+// IsSatisfiedBy implements UserSpecification.
+func (s SearchSpec) IsSatisfiedBy(user *entities.User) bool {
+	query := foldASCII(s.Query)
+	local, _, _ := strings.Cut(user.GetEmail().String(), "@")
+
+	return strings.HasPrefix(foldASCII(local), query) ||
+		strings.Contains(foldASCII(user.GetUserName().String()), query)
+}
+
+// ToSQL implements UserSpecification. % and _ in Query are escaped to match
+// literally. Case is folded with lower() on both sides rather than COLLATE
+// NOCASE, which LIKE ignores: it follows PRAGMA case_sensitive_like
+// instead. PostgreSQL would use ILIKE for the same condition.
+//
+// Neither condition can use an index. The name match is a substring
+// search, and SQLite's LIKE optimization needs a bare NOCASE column rather
+// than an expression, so every active row is scanned. If that gets slow,
+// an FTS5 table over email and name is the way to go.
+func (s SearchSpec) ToSQL() (SQLFragment, bool) {
+	pattern := likeEscaper.Replace(foldASCII(s.Query))
+
+	return SQLFragment{
+		Clause: `lower(substr(email, 1, instr(email, '@') - 1)) LIKE ? ESCAPE '\' OR lower(name) LIKE ? ESCAPE '\'`,
+		Args:   []any{pattern + "%", "%" + pattern + "%"},
+	}, true
+}
+
+// foldASCII lowercases the ASCII letters of s and leaves the rest alone.
+func foldASCII(s string) string {
+	return strings.Map(func(r rune) rune {
+		if 'A' <= r && r <= 'Z' {
+			return r + 'a' - 'A'
+		}
+
+		return r
+	}, s)
+}
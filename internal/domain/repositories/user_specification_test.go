@@ -0,0 +1,121 @@
+package repositories_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/entities"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/ids"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/repositories"
+)
+
+// constSpec is a fixed truth value without a SQL form.
+type constSpec bool
+
+func (s constSpec) IsSatisfiedBy(*entities.User) bool { return bool(s) }
+
+func (s constSpec) ToSQL() (repositories.SQLFragment, bool) { return repositories.SQLFragment{}, false }
+
+func TestUserSpecificationComposition(t *testing.T) {
+	user, err := entities.NewUser(ids.MustGenerateUserID(), "spec@example.com", "specuser", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	yes, no := constSpec(true), constSpec(false)
+
+	tests := []struct {
+		name string
+		spec repositories.UserSpecification
+		want bool
+	}{
+		{name: "And()", spec: repositories.And(), want: true},
+		{name: "And(T, T)", spec: repositories.And(yes, yes), want: true},
+		{name: "And(T, F)", spec: repositories.And(yes, no), want: false},
+		{name: "And(F, T)", spec: repositories.And(no, yes), want: false},
+		{name: "And(F, F)", spec: repositories.And(no, no), want: false},
+		{name: "Or()", spec: repositories.Or(), want: false},
+		{name: "Or(T, T)", spec: repositories.Or(yes, yes), want: true},
+		{name: "Or(T, F)", spec: repositories.Or(yes, no), want: true},
+		{name: "Or(F, T)", spec: repositories.Or(no, yes), want: true},
+		{name: "Or(F, F)", spec: repositories.Or(no, no), want: false},
+		{name: "Not(T)", spec: repositories.Not(yes), want: false},
+		{name: "Not(F)", spec: repositories.Not(no), want: true},
+		{name: "Not(And(T, F))", spec: repositories.Not(repositories.And(yes, no)), want: true},
+		{name: "Not(Or(F, F))", spec: repositories.Not(repositories.Or(no, no)), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.spec.IsSatisfiedBy(user); got != tt.want {
+				t.Errorf("IsSatisfiedBy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserSpecificationConcreteSpecs(t *testing.T) {
+	user, err := entities.NewUser(ids.MustGenerateUserID(), "jane@example.com", "Jane Doe", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	user.Created = now.AddDate(0, 0, -10)
+
+	tests := []struct {
+		name string
+		spec repositories.UserSpecification
+		want bool
+	}{
+		{name: "domain match", spec: repositories.EmailDomainSpec{Domain: "example.com"}, want: true},
+		{name: "domain suffix only", spec: repositories.EmailDomainSpec{Domain: "ample.com"}, want: false},
+		{name: "domain case differs", spec: repositories.EmailDomainSpec{Domain: "EXAMPLE.com"}, want: false},
+		{name: "created after earlier", spec: repositories.CreatedAfterSpec{Time: now.AddDate(0, 0, -11)}, want: true},
+		{name: "created after exact", spec: repositories.CreatedAfterSpec{Time: user.Created}, want: false},
+		{name: "active recent", spec: repositories.ActiveSpec{AsOf: now}, want: true},
+		{name: "active too old", spec: repositories.ActiveSpec{AsOf: now.AddDate(0, 0, 25)}, want: false},
+		{name: "name prefix", spec: repositories.NamePrefixSpec{Prefix: "Jane"}, want: true},
+		{name: "name prefix case", spec: repositories.NamePrefixSpec{Prefix: "jane"}, want: false},
+		{name: "name prefix wildcard", spec: repositories.NamePrefixSpec{Prefix: "J%"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.spec.IsSatisfiedBy(user); got != tt.want {
+				t.Errorf("IsSatisfiedBy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	user.SoftDelete(now)
+
+	if (repositories.ActiveSpec{AsOf: now}).IsSatisfiedBy(user) {
+		t.Error("a soft-deleted user is active")
+	}
+}
+
+func TestPushDownSplitsAnd(t *testing.T) {
+	domain := repositories.EmailDomainSpec{Domain: "example.com"}
+	prefix := repositories.NamePrefixSpec{Prefix: "J"}
+
+	fragment, residual := repositories.PushDown(repositories.And(domain, constSpec(true), prefix))
+	if residual == nil {
+		t.Fatal("residual = nil, want the spec without a SQL form")
+	}
+
+	want := "(substr(email, instr(email, '@') + 1) = ?) AND (substr(name, 1, length(?)) = ?)"
+	if fragment.Clause != want || len(fragment.Args) != 3 {
+		t.Errorf("fragment = %+v, want %q with 3 args", fragment, want)
+	}
+
+	fragment, residual = repositories.PushDown(repositories.Or(domain, constSpec(true)))
+	if residual == nil || fragment.Clause != "1 = 1" {
+		t.Errorf("Or with an in-memory child = %+v, %v, want it evaluated in memory whole", fragment, residual)
+	}
+
+	_, residual = repositories.PushDown(repositories.Not(repositories.Or(domain, prefix)))
+	if residual != nil {
+		t.Errorf("residual = %v, want a fully pushed down spec", residual)
+	}
+}
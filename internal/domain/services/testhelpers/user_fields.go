@@ -0,0 +1,22 @@
+package services_test
+
+import (
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/values"
+	"github.com/onsi/gomega"
+)
+
+// CreateTestEmail creates a new Email from a string, failing on error.
+func CreateTestEmail(email string) values.Email {
+	emailVO, err := values.NewEmail(email)
+	gomega.ExpectWithOffset(1, err).ToNot(gomega.HaveOccurred())
+
+	return emailVO
+}
+
+// CreateTestUserName creates a new UserName from a string, failing on error.
+func CreateTestUserName(name string) values.UserName {
+	nameVO, err := values.NewUserName(name)
+	gomega.ExpectWithOffset(1, err).ToNot(gomega.HaveOccurred())
+
+	return nameVO
+}
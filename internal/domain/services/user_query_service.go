@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/clock"
 	"github.com/LarsArtmann/template-arch-lint/internal/domain/entities"
 	"github.com/LarsArtmann/template-arch-lint/internal/domain/repositories"
 	"github.com/LarsArtmann/template-arch-lint/internal/domain/values"
@@ -25,6 +26,10 @@ type UserQueryService interface {
 	// ListUsers retrieves all users in the system.
 	ListUsers(ctx context.Context) ([]*entities.User, error)
 
+	// SearchUsers retrieves at most limit users whose email local part
+	// starts with query or whose name contains it, ignoring case.
+	SearchUsers(ctx context.Context, query string, limit int) ([]*entities.User, error)
+
 	// GetUserEmailsWithResult retrieves all user emails using Result pattern.
 	GetUserEmailsWithResult(ctx context.Context) mo.Result[[]string]
 
@@ -47,12 +52,15 @@ type UserQueryService interface {
 // userQueryServiceImpl implements UserQueryService interface.
 type userQueryServiceImpl struct {
 	userRepo repositories.UserRepository
+	clock    clock.Clock
 }
 
-// NewUserQueryService creates a new instance of UserQueryService.
-func NewUserQueryService(userRepo repositories.UserRepository) UserQueryService {
+// NewUserQueryService creates a new instance of UserQueryService. clock
+// decides which users filters count as active.
+func NewUserQueryService(userRepo repositories.UserRepository, clock clock.Clock) UserQueryService {
 	return &userQueryServiceImpl{
 		userRepo: userRepo,
+		clock:    clock,
 	}
 }
 
@@ -82,15 +90,15 @@ func (s *userQueryServiceImpl) GetUserByEmail(
 	ctx context.Context,
 	email string,
 ) (*entities.User, error) {
-	// TODO: Add email validation using Email value object
 	// TODO: Add caching by email for performance
 	// TODO: Add rate limiting for email lookups
 	// TODO: Consider case-insensitive email matching
-	if email == "" {
-		return nil, domainerrors.NewValidationError("email", "email cannot be empty")
+	emailVO, err := parseEmail(email)
+	if err != nil {
+		return nil, err
 	}
 
-	user, err := s.userRepo.FindByEmail(ctx, email)
+	user, err := s.userRepo.FindByEmail(ctx, emailVO)
 	if err != nil {
 		return nil, domainerrors.WrapRepoError("get by email", "user", err)
 	}
@@ -105,7 +113,26 @@ func (s *userQueryServiceImpl) ListUsers(ctx context.Context) ([]*entities.User,
 	// TODO: Add filtering capabilities
 	// TODO: Add caching for frequently accessed lists
 	// TODO: Consider streaming for very large result sets
-	return s.userRepo.List(ctx)
+	users, err := s.userRepo.List(ctx)
+	if err != nil {
+		return nil, domainerrors.WrapRepoError("list", "users", err)
+	}
+
+	return users, nil
+}
+
+// SearchUsers retrieves at most limit users matching query, in email order.
+func (s *userQueryServiceImpl) SearchUsers(
+	ctx context.Context,
+	query string,
+	limit int,
+) ([]*entities.User, error) {
+	users, err := s.userRepo.Search(ctx, query, limit)
+	if err != nil {
+		return nil, domainerrors.WrapRepoError("search", "users", err)
+	}
+
+	return users, nil
 }
 
 // GetUserEmailsWithResult retrieves all user emails using Result pattern.
@@ -128,14 +155,14 @@ func (s *userQueryServiceImpl) FindUserByEmailOption(
 	ctx context.Context,
 	email string,
 ) mo.Option[*entities.User] {
-	// TODO: Add email validation using Email value object
 	// TODO: Add caching support
 	// TODO: Add audit logging for security compliance
-	if email == "" {
+	emailVO, err := values.NewEmail(email)
+	if err != nil {
 		return mo.None[*entities.User]()
 	}
 
-	user, err := s.userRepo.FindByEmail(ctx, email)
+	user, err := s.userRepo.FindByEmail(ctx, emailVO)
 	if err != nil {
 		// Log error but return None for Option pattern
 		return mo.None[*entities.User]()
@@ -180,42 +207,14 @@ func (s *userQueryServiceImpl) GetUsersWithFilters(
 	ctx context.Context,
 	filters UserFilters,
 ) ([]*entities.User, error) {
-	// TODO: Add database-level filtering for better performance
 	// TODO: Add validation for filter parameters
-	// TODO: Add support for complex filter combinations
 	// TODO: Add filter result caching
-	users, err := s.userRepo.List(ctx)
+	users, err := findUsersMatching(ctx, s.userRepo, filters.Specification(s.clock.Now()))
 	if err != nil {
-		return nil, fmt.Errorf(
-			"filters=%+v: %w",
-			filters,
-			domainerrors.WrapRepoError("list for filtering", "user", err),
-		)
+		return nil, fmt.Errorf("filters=%+v: %w", filters, err)
 	}
 
-	filtered := lo.Filter(users, func(user *entities.User, _ int) bool {
-		if filters.Domain != nil && *filters.Domain != "" {
-			email := user.GetEmail().String()
-			if _, after, ok := strings.Cut(email, "@"); ok {
-				domain := after
-				if domain != *filters.Domain {
-					return false
-				}
-			}
-		}
-
-		if filters.Active != nil {
-			// TODO: Implement user.IsActive() method when UserStatus value object exists
-			// For now, assume all users are active
-			if !*filters.Active {
-				return false
-			}
-		}
-
-		return true
-	})
-
-	return filtered, nil
+	return users, nil
 }
 
 // GetUsersByEmailDomains retrieves users grouped by their email domains.
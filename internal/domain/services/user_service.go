@@ -5,12 +5,10 @@
 // TODO: TYPE SAFETY EMERGENCY - Replace ALL string parameters with value objects (Email, UserName)
 // TODO: SPLIT BRAIN RISK - Inconsistent error handling patterns (some use Result[T], others don't)
 // TODO: VALIDATION CONSISTENCY - Extract validation logic to dedicated validator following DDD patterns
-// TODO: TRANSACTION SAFETY - Add proper transaction boundaries for data consistency
 // TODO: PERFORMANCE - Add caching layer, pagination, query optimization
 // TODO: DOMAIN MODELING - Create proper domain events for user lifecycle changes
 // TODO: FUNCTIONAL PROGRAMMING - Standardize on Result[T] pattern for all operations
 // TODO: PRIMITIVE OBSESSION - Remove all string primitives, use value objects everywhere
-// TODO: CONCURRENCY SAFETY - Add optimistic locking to prevent concurrent update issues
 // TODO: BUSINESS RULES EXTRACTION - Extract business rules to specification pattern
 // TODO: OBSERVABILITY - Add comprehensive logging, metrics, and tracing
 package services
@@ -23,6 +21,8 @@ import (
 	"strings"
 	"time"
 
+	"charm.land/log/v2"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/clock"
 	"github.com/LarsArtmann/template-arch-lint/internal/domain/entities"
 	"github.com/LarsArtmann/template-arch-lint/internal/domain/repositories"
 	"github.com/LarsArtmann/template-arch-lint/internal/domain/values"
@@ -33,16 +33,13 @@ import (
 
 // Validation constraints.
 const (
-	userActiveDays        = 30
-	emailMaxLengthService = 254
-	nameMaxLengthService  = 100
-	hoursPerDay           = 24
+	userActiveDays = repositories.ActiveUserDays
+	hoursPerDay    = 24
 )
 
 // TODO: TYPE SAFETY - Replace *string with proper value objects (DomainName value object)
-// TODO: SPECIFICATION PATTERN - Replace with UserSpecification interface for complex filtering
-// TODO: BUILDER PATTERN - Add UserFiltersBuilder for better API design
-// UserFilters represents the available filters for user queries.
+// UserFilters represents the available filters for user queries. New
+// callers should build a repositories.UserSpecification instead.
 type UserFilters struct {
 	Domain *string // TODO: PRIMITIVE OBSESSION - Should be values.DomainName
 	Active *bool   // TODO: DOMAIN MODELING - Could be values.UserStatus enum
@@ -56,6 +53,11 @@ type UserFilters struct {
 // UserService handles business logic for user operations.
 type UserService struct {
 	userRepo repositories.UserRepository
+	tx       repositories.TxManager
+	// fallbackLogger logs for contexts that carry no request logger.
+	fallbackLogger *log.Logger
+	// clock stamps entities and decides which users count as active.
+	clock clock.Clock
 	// TODO: MISSING DEPENDENCIES - Should inject: logger, cache, eventPublisher, validator
 }
 
@@ -66,39 +68,102 @@ type UserService struct {
 func NewUserService(userRepo repositories.UserRepository) *UserService {
 	// TODO: NIL SAFETY - Add validation: if userRepo == nil { panic("userRepo cannot be nil") }
 	return &UserService{
-		userRepo: userRepo,
+		userRepo:       userRepo,
+		tx:             repositories.NewInMemoryTxManager(),
+		fallbackLogger: log.Default(),
+		clock:          clock.System{},
 	}
 }
 
-// CreateUser creates a new user with business validation.
+// WithTxManager replaces the transaction manager that makes check-then-write
+// sequences atomic. It must match the repository, e.g. a SQL transaction
+// manager for a SQL repository; the default suits the in-memory repository.
+func (s *UserService) WithTxManager(tx repositories.TxManager) *UserService {
+	s.tx = tx
+
+	return s
+}
+
+// WithClock replaces the system clock, e.g. with a clock.Fake in tests.
+func (s *UserService) WithClock(clock clock.Clock) *UserService {
+	s.clock = clock
+
+	return s
+}
+
+// WithLogger replaces the logger used when a context carries none. Request
+// contexts carry the request-scoped logger, which takes precedence.
+func (s *UserService) WithLogger(logger *log.Logger) *UserService {
+	s.fallbackLogger = logger
+
+	return s
+}
+
+// CreateUserV2 creates a new user. Email and name are value objects, so
+// they are valid by construction; the email uniqueness check and the save
+// run in one transaction.
 // TODO: ARCHITECTURAL IMPROVEMENT - Consider splitting this large service (511 lines) into smaller, focused services
-// TODO: TYPE SAFETY - Migrate from string parameters to value objects (email values.Email, name values.UserName)
-// TODO: BUSINESS RULES - Extract validation logic to dedicated validator service.
+func (s *UserService) CreateUserV2(
+	ctx context.Context,
+	id values.UserID,
+	email values.Email,
+	name values.UserName,
+) (*entities.User, error) {
+	start := s.clock.Now()
+
+	var user *entities.User
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+
+		user, err = s.createUserTx(ctx, id, email, name)
+
+		return err
+	})
+	s.logOutcome(ctx, log.InfoLevel, "create_user", id, start, err)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// CreateUser parses email and name into value objects and creates the user
+// with CreateUserV2. Invalid input fails with a validation error.
+//
+// Deprecated: Use CreateUserV2 with values.Email and values.UserName.
 func (s *UserService) CreateUser(
 	ctx context.Context,
 	id values.UserID,
 	email, name string,
 ) (*entities.User, error) {
-	// Business rule: Validate email format
-	if err := s.validateEmail(email); err != nil {
-		return nil, domainerrors.NewValidationError("email", err.Error())
+	emailVO, nameVO, err := parseUserFields(email, name)
+	if err != nil {
+		return nil, err
 	}
 
-	// Business rule: Validate username
-	if err := s.validateUserName(name); err != nil {
-		return nil, domainerrors.NewValidationError("name", err.Error())
-	}
+	return s.CreateUserV2(ctx, id, emailVO, nameVO)
+}
 
+// createUserTx checks email uniqueness and saves the user; CreateUserV2 runs
+// it in one transaction so two creations cannot both pass the check.
+func (s *UserService) createUserTx(
+	ctx context.Context,
+	id values.UserID,
+	email values.Email,
+	name values.UserName,
+) (*entities.User, error) {
 	// Business rule: Check if user already exists
-	existingUser, err := s.userRepo.FindByEmail(ctx, email)
-	if err != nil && !errors.Is(err, repositories.ErrUserNotFound) { //nolint:legacyerrors // value sentinel
+	existingUser, err := repositories.FindByEmailOption(ctx, s.userRepo, email)
+	if err != nil {
 		return nil, domainerrors.NewInternalError(
 			fmt.Sprintf("failed to check existing user (id=%s, email=%s)", id, email),
 			err,
 		)
 	}
 
-	if existingUser != nil {
+	if existingUser.IsPresent() {
 		return nil, fmt.Errorf(
 			"user with email %s already exists: %w",
 			email,
@@ -107,7 +172,7 @@ func (s *UserService) CreateUser(
 	}
 
 	// Create new user entity
-	user, err := entities.NewUser(id, email, name)
+	user, err := entities.NewUserFromValues(id, email, name, s.clock.Now())
 	if err != nil {
 		return nil, fmt.Errorf("create user (id=%s, email=%s): %w", id, email, err)
 	}
@@ -126,9 +191,17 @@ func (s *UserService) CreateUser(
 // GetUser retrieves a user by ID with business logic.
 // TODO: ERROR HANDLING - Consider using Result[T] pattern for better functional error handling.
 func (s *UserService) GetUser(ctx context.Context, id values.UserID) (*entities.User, error) {
+	start := s.clock.Now()
+
 	user, err := s.userRepo.FindByID(ctx, id)
 	if err != nil {
-		return nil, domainerrors.WrapRepoError("get", "user", err, id.String())
+		err = domainerrors.WrapRepoError("get", "user", err, id.String())
+	}
+
+	s.logOutcome(ctx, log.DebugLevel, "get_user", id, start, err)
+
+	if err != nil {
+		return nil, err
 	}
 
 	// Business logic: Could add user activity tracking, audit logging, etc.
@@ -137,11 +210,12 @@ func (s *UserService) GetUser(ctx context.Context, id values.UserID) (*entities.
 
 // GetUserByEmail retrieves a user by email.
 func (s *UserService) GetUserByEmail(ctx context.Context, email string) (*entities.User, error) {
-	if err := s.validateEmail(email); err != nil {
+	emailVO, err := parseEmail(email)
+	if err != nil {
 		return nil, err
 	}
 
-	user, err := s.userRepo.FindByEmail(ctx, email)
+	user, err := s.userRepo.FindByEmail(ctx, emailVO)
 	if err != nil {
 		return nil, domainerrors.WrapRepoError("get by email", "user", err, email)
 	}
@@ -149,36 +223,97 @@ func (s *UserService) GetUserByEmail(ctx context.Context, email string) (*entiti
 	return user, nil
 }
 
-// UpdateUser updates user information with business rules.
-// TODO: TRANSACTION SAFETY - Consider implementing optimistic locking to prevent concurrent updates
-// TODO: VALUE OBJECTS - Replace string parameters with proper value objects for type safety.
+// UpdateUserV2 updates a user's email and name. The read, the email
+// uniqueness check and the save run in one transaction; a concurrent update
+// between the read and the save fails with
+// repositories.ErrConcurrentModification.
+func (s *UserService) UpdateUserV2(
+	ctx context.Context,
+	id values.UserID,
+	email values.Email,
+	name values.UserName,
+) (*entities.User, error) {
+	start := s.clock.Now()
+
+	var updated *entities.User
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		user, err := s.GetUser(ctx, id)
+		if err != nil {
+			return fmt.Errorf("id=%s, email=%s: %w", id, email, err)
+		}
+
+		if err := s.checkEmailChange(ctx, user, email); err != nil {
+			return fmt.Errorf("id=%s, email=%s: %w", id, email, err)
+		}
+
+		updated, err = s.applyUserUpdates(ctx, user, email, name)
+
+		return err
+	})
+	s.logOutcome(ctx, log.InfoLevel, "update_user", id, start, err)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// UpdateUser parses email and name into value objects and updates the user
+// with UpdateUserV2. Invalid input fails with a validation error.
+//
+// Deprecated: Use UpdateUserV2 with values.Email and values.UserName.
 func (s *UserService) UpdateUser(
 	ctx context.Context,
 	id values.UserID,
 	email, name string,
 ) (*entities.User, error) {
-	user, err := s.GetUser(ctx, id)
+	emailVO, nameVO, err := parseUserFields(email, name)
 	if err != nil {
-		return nil, fmt.Errorf("id=%s, email=%s: %w", id, email, err)
+		return nil, fmt.Errorf("id=%s: %w", id, err)
 	}
 
-	if err := s.validateUserUpdates(ctx, user, email, name); err != nil {
-		return nil, fmt.Errorf("id=%s, email=%s: %w", id, email, err)
+	return s.UpdateUserV2(ctx, id, emailVO, nameVO)
+}
+
+// logger returns the request-scoped logger of ctx, or the fallback logger
+// when ctx carries none.
+func (s *UserService) logger(ctx context.Context) *log.Logger {
+	if logger, ok := ctx.Value(log.ContextKey).(*log.Logger); ok {
+		return logger
 	}
 
-	return s.applyUserUpdates(ctx, user, email, name)
+	return s.fallbackLogger
 }
 
-// validateNameUpdate(user *entities.User, name string) error {
-// 	if name != user.GetUserName().String() {
-// 		err := s.validateUserName(name)
-// 		if err != nil {
-// 			return err
-// 		}
-// 	}
-//
-// 	return nil
-// }
+// logOutcome logs one finished operation on the user id. Successes and
+// missing users log at level, requests the business rules reject (invalid
+// input, conflicts) at Warn and other failures at Error.
+func (s *UserService) logOutcome(
+	ctx context.Context, level log.Level, operation string, id values.UserID, start time.Time, err error,
+) {
+	logger := s.logger(ctx).With(
+		"operation", operation,
+		"user_id", id.String(),
+		"duration_ms", float64(s.clock.Now().Sub(start))/float64(time.Millisecond),
+	)
+
+	_, notFound := domainerrors.AsNotFoundError(err)
+	_, invalid := domainerrors.AsValidationError(err)
+	_, conflict := domainerrors.AsConflictError(err)
+
+	switch {
+	case err == nil:
+		logger.Log(level, "User operation succeeded")
+	case notFound:
+		logger.Log(level, "User not found", "error", err)
+	case invalid || conflict:
+		logger.Warn("User operation rejected", "error", err)
+	default:
+		logger.Error("User operation failed", "error", err)
+	}
+}
 
 func extractEmails(users []*entities.User) []string {
 	return lo.Map(users, func(user *entities.User, _ int) string {
@@ -186,66 +321,37 @@ func extractEmails(users []*entities.User) []string {
 	})
 }
 
-func (s *UserService) validateUserUpdates(
-	ctx context.Context,
-	user *entities.User,
-	email, name string,
-) error {
-	err := s.validateEmailUpdate(ctx, user, email)
-	if err != nil {
-		return fmt.Errorf("email=%s: %w", email, err)
-	}
-
-	return s.validateNameUpdate(user, name)
-}
-
-func (s *UserService) validateEmailUpdate(
-	ctx context.Context,
-	user *entities.User,
-	email string,
-) error {
-	if email == user.GetEmail().String() {
+// checkEmailChange checks that a changed email is not taken by another user.
+func (s *UserService) checkEmailChange(ctx context.Context, user *entities.User, email values.Email) error {
+	if email == user.GetEmail() {
 		return nil
 	}
 
-	err := s.validateEmail(email)
-	if err != nil {
-		return fmt.Errorf("email=%s: %w", email, err)
-	}
-
 	return s.checkEmailAvailability(ctx, email)
 }
 
-func (s *UserService) checkEmailAvailability(ctx context.Context, email string) error {
-	existingUser, err := s.userRepo.FindByEmail(ctx, email)
-	if err != nil && !errors.Is(err, repositories.ErrUserNotFound) { //nolint:legacyerrors // value sentinel
+func (s *UserService) checkEmailAvailability(ctx context.Context, email values.Email) error {
+	existingUser, err := repositories.FindByEmailOption(ctx, s.userRepo, email)
+	if err != nil {
 		return domainerrors.WrapServiceError(fmt.Sprintf("check existing email (%s)", email), err)
 	}
 
-	if existingUser != nil {
+	if existingUser.IsPresent() {
 		return fmt.Errorf("email %s already in use: %w", email, repositories.ErrUserAlreadyExists)
 	}
 
 	return nil
 }
 
-func (s *UserService) validateNameUpdate(user *entities.User, name string) error {
-	if name != user.GetUserName().String() {
-		err := s.validateUserName(name)
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
-
 func (s *UserService) applyUserUpdates(
 	ctx context.Context,
 	user *entities.User,
-	email, name string,
+	email values.Email,
+	name values.UserName,
 ) (*entities.User, error) {
-	err := user.SetEmail(email)
+	now := s.clock.Now()
+
+	err := user.ChangeEmail(email, now)
 	if err != nil {
 		return nil, domainerrors.WrapServiceError(
 			fmt.Sprintf("set email for user %s", user.ID),
@@ -253,12 +359,17 @@ func (s *UserService) applyUserUpdates(
 		)
 	}
 
-	err = user.SetName(name)
+	err = user.ChangeUserName(name, now)
 	if err != nil {
 		return nil, domainerrors.WrapServiceError(fmt.Sprintf("set name for user %s", user.ID), err)
 	}
 
 	err = s.userRepo.Save(ctx, user)
+	if errors.Is(err, repositories.ErrConcurrentModification) { //nolint:legacyerrors // value sentinel
+		// Surface the conflict as-is so callers can re-read and retry
+		return nil, fmt.Errorf("save updated user %s: %w", user.ID, err)
+	}
+
 	if err != nil {
 		return nil, domainerrors.WrapRepoError("save updated", "user", err, user.ID.String())
 	}
@@ -266,22 +377,183 @@ func (s *UserService) applyUserUpdates(
 	return user, nil
 }
 
-// DeleteUser removes a user with business rules.
-// TODO: SOFT DELETE - Consider implementing soft delete for audit trails
-// TODO: CASCADE DELETE - Handle dependent entity cleanup (audit logs, user sessions, etc.)
-func (s *UserService) DeleteUser(ctx context.Context, id values.UserID) error {
-	// Business rule: Check if user exists before deletion
-	_, err := s.userRepo.FindByID(ctx, id)
+// UserFields is the complete representation of a user written by ReplaceUser.
+type UserFields struct {
+	Email string
+	Name  string
+}
+
+// ReplaceUser creates the user with id when it does not exist and otherwise
+// replaces it with fields. Every field is taken from fields, never from the
+// stored user, and goes through the same validation and email uniqueness
+// checks as CreateUser, in one transaction. The bool result reports whether
+// the user was created.
+//
+// A non-zero expectedVersion, e.g. from an If-Match header, makes the
+// replacement conditional: it fails with
+// repositories.ErrConcurrentModification unless the stored user is still
+// at that version when it is saved, and never creates the user.
+func (s *UserService) ReplaceUser(
+	ctx context.Context,
+	id values.UserID,
+	fields UserFields,
+	expectedVersion int,
+) (*entities.User, bool, error) {
+	start := s.clock.Now()
+
+	var (
+		user    *entities.User
+		created bool
+	)
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+
+		user, created, err = s.replaceUserTx(ctx, id, fields, expectedVersion)
+
+		return err
+	})
+	s.logOutcome(ctx, log.InfoLevel, "replace_user", id, start, err)
+
 	if err != nil {
-		return domainerrors.WrapRepoError("find for deletion", "user", err, id.String())
+		return nil, false, err
 	}
 
-	// Business logic: Could add soft delete, cascade operations, etc.
-	if err := s.userRepo.Delete(ctx, id); err != nil {
-		return domainerrors.WrapRepoError("delete", "user", err, id.String())
+	return user, created, nil
+}
+
+func (s *UserService) replaceUserTx(
+	ctx context.Context,
+	id values.UserID,
+	fields UserFields,
+	expectedVersion int,
+) (*entities.User, bool, error) {
+	email, name, err := parseUserFields(fields.Email, fields.Name)
+	if err != nil {
+		return nil, false, err
 	}
 
-	return nil
+	existing, err := repositories.FindByIDOption(ctx, s.userRepo, id)
+	if err != nil {
+		return nil, false, domainerrors.WrapRepoError("find for replace", "user", err, id.String())
+	}
+
+	current, found := existing.Get()
+	if !found && expectedVersion != 0 {
+		return nil, false, fmt.Errorf("user %s gone, expected version %d: %w",
+			id, expectedVersion, repositories.ErrConcurrentModification)
+	}
+
+	if !found {
+		user, err := s.CreateUserV2(ctx, id, email, name)
+
+		return user, true, err
+	}
+
+	// Saving at the expected version lets the repository's version check
+	// reject the replacement if the user changed since the caller read it.
+	if expectedVersion != 0 {
+		current.Version = expectedVersion
+	}
+
+	err = s.checkEmailChange(ctx, current, email)
+	if err != nil {
+		return nil, false, fmt.Errorf("id=%s, email=%s: %w", id, fields.Email, err)
+	}
+
+	user, err := s.applyUserUpdates(ctx, current, email, name)
+
+	return user, false, err
+}
+
+// DeleteUser soft-deletes a user: it stays stored with DeletedAt set but is
+// hidden from lookups and listings, and its email becomes free to reuse.
+// RestoreUser undoes it and PurgeUser removes the user for good.
+func (s *UserService) DeleteUser(ctx context.Context, id values.UserID) error {
+	start := s.clock.Now()
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		// Business rule: Check if user exists before deletion
+		user, err := s.userRepo.FindByID(ctx, id)
+		if err != nil {
+			return domainerrors.WrapRepoError("find for deletion", "user", err, id.String())
+		}
+
+		user.SoftDelete(s.clock.Now())
+
+		if err := s.userRepo.Save(ctx, user); err != nil {
+			return domainerrors.WrapRepoError("delete", "user", err, id.String())
+		}
+
+		return nil
+	})
+	s.logOutcome(ctx, log.InfoLevel, "delete_user", id, start, err)
+
+	return err
+}
+
+// RestoreUser brings back a soft-deleted user. It fails with
+// repositories.ErrUserAlreadyExists when another active user has taken the
+// email in the meantime, and returns an active user unchanged.
+func (s *UserService) RestoreUser(ctx context.Context, id values.UserID) (*entities.User, error) {
+	start := s.clock.Now()
+
+	var restored *entities.User
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		user, err := s.userRepo.FindByIDIncludingDeleted(ctx, id)
+		if err != nil {
+			return domainerrors.WrapRepoError("find for restore", "user", err, id.String())
+		}
+
+		if !user.IsDeleted() {
+			restored = user
+
+			return nil
+		}
+
+		if err := s.checkEmailAvailability(ctx, user.GetEmail()); err != nil {
+			return fmt.Errorf("restore user %s: %w", id, err)
+		}
+
+		user.Restore(s.clock.Now())
+
+		if err := s.userRepo.Save(ctx, user); err != nil {
+			return domainerrors.WrapRepoError("restore", "user", err, id.String())
+		}
+
+		restored = user
+
+		return nil
+	})
+	s.logOutcome(ctx, log.InfoLevel, "restore_user", id, start, err)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return restored, nil
+}
+
+// PurgeUser removes a user for good, whether or not it is soft-deleted.
+func (s *UserService) PurgeUser(ctx context.Context, id values.UserID) error {
+	start := s.clock.Now()
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		_, err := s.userRepo.FindByIDIncludingDeleted(ctx, id)
+		if err != nil {
+			return domainerrors.WrapRepoError("find for purge", "user", err, id.String())
+		}
+
+		if err := s.userRepo.Delete(ctx, id); err != nil {
+			return domainerrors.WrapRepoError("purge", "user", err, id.String())
+		}
+
+		return nil
+	})
+	s.logOutcome(ctx, log.InfoLevel, "purge_user", id, start, err)
+
+	return err
 }
 
 // ListUsers retrieves all users with business logic.
@@ -295,6 +567,26 @@ func (s *UserService) ListUsers(ctx context.Context) ([]*entities.User, error) {
 	return users, nil
 }
 
+// ExportUsers calls fn with every active user in ID order without loading
+// them all, for streaming exports. Errors from fn and ctx come back as is.
+func (s *UserService) ExportUsers(ctx context.Context, fn func(*entities.User) error) error {
+	return s.userRepo.FindAllIter(ctx, fn)
+}
+
+// ListUsersPage retrieves one sorted page of the users matching query.Spec.
+// The repository filters, sorts and pages.
+func (s *UserService) ListUsersPage(
+	ctx context.Context,
+	query repositories.UserPageQuery,
+) (repositories.UserPage, error) {
+	page, err := s.userRepo.FindPage(ctx, query)
+	if err != nil {
+		return repositories.UserPage{}, domainerrors.NewInternalError("failed to list users page", err)
+	}
+
+	return page, nil
+}
+
 // FilterActiveUsers demonstrates functional programming with lo library.
 func (s *UserService) FilterActiveUsers(ctx context.Context) ([]*entities.User, error) {
 	users, err := s.userRepo.List(ctx)
@@ -302,11 +594,11 @@ func (s *UserService) FilterActiveUsers(ctx context.Context) ([]*entities.User,
 		return nil, domainerrors.NewInternalError("failed to list users", err)
 	}
 
+	// Business rule: Users created in the last 30 days are considered active
+	thirtyDaysAgo := s.clock.Now().AddDate(0, 0, -userActiveDays)
+
 	// Functional operations using samber/lo
 	activeUsers := lo.Filter(users, func(user *entities.User, _ int) bool {
-		// Business rule: Users created in the last 30 days are considered active
-		thirtyDaysAgo := time.Now().AddDate(0, 0, -userActiveDays)
-
 		return user.Created.After(thirtyDaysAgo)
 	})
 
@@ -333,42 +625,46 @@ func (s *UserService) CreateUserWithResult(
 	id values.UserID,
 	email, name string,
 ) mo.Result[*entities.User] {
-	// Step 1: Validate inputs
-	if validationResult := s.validateUserInputsResult(email, name); validationResult.IsError() {
-		return mo.Err[*entities.User](validationResult.Error())
+	// Step 1: Parse inputs into value objects
+	inputResult := s.parseUserInputsResult(email, name)
+	if inputResult.IsError() {
+		return mo.Err[*entities.User](inputResult.Error())
 	}
 
+	input := inputResult.MustGet()
+
 	// Step 2: Check user doesn't exist
-	if existsResult := s.checkUserNotExistsResult(ctx, email); existsResult.IsError() {
+	if existsResult := s.checkUserNotExistsResult(ctx, input.email); existsResult.IsError() {
 		return mo.Err[*entities.User](existsResult.Error())
 	}
 
 	// Step 3: Create and save user
-	return s.createAndSaveUserResult(ctx, id, email, name)
+	return s.createAndSaveUserResult(ctx, id, input.email, input.name)
 }
 
-// validateUserInputsResult validates user inputs using Result pattern.
-func (s *UserService) validateUserInputsResult(email, name string) mo.Result[struct{}] {
-	err := s.validateEmail(email)
-	if err != nil {
-		return mo.Err[struct{}](domainerrors.NewValidationError("email", err.Error()))
-	}
+// userInput is an email and name that passed value object validation.
+type userInput struct {
+	email values.Email
+	name  values.UserName
+}
 
-	err = s.validateUserName(name)
+// parseUserInputsResult parses user inputs into value objects using Result pattern.
+func (s *UserService) parseUserInputsResult(email, name string) mo.Result[userInput] {
+	emailVO, nameVO, err := parseUserFields(email, name)
 	if err != nil {
-		return mo.Err[struct{}](domainerrors.NewValidationError("name", err.Error()))
+		return mo.Err[userInput](err)
 	}
 
-	return mo.Ok(struct{}{})
+	return mo.Ok(userInput{email: emailVO, name: nameVO})
 }
 
 // checkUserNotExistsResult checks if user exists using Result pattern.
 func (s *UserService) checkUserNotExistsResult(
 	ctx context.Context,
-	email string,
+	email values.Email,
 ) mo.Result[*entities.User] {
-	existingUser, err := s.userRepo.FindByEmail(ctx, email)
-	if err != nil && !errors.Is(err, repositories.ErrUserNotFound) { //nolint:legacyerrors // value sentinel
+	existingUser, err := repositories.FindByEmailOption(ctx, s.userRepo, email)
+	if err != nil {
 		return mo.Err[*entities.User](
 			domainerrors.NewInternalError(
 				fmt.Sprintf("failed to check existing user (email=%s)", email),
@@ -377,7 +673,7 @@ func (s *UserService) checkUserNotExistsResult(
 		)
 	}
 
-	if existingUser != nil {
+	if existingUser.IsPresent() {
 		return mo.Err[*entities.User](
 			fmt.Errorf(
 				"user with email %s already exists: %w",
@@ -395,9 +691,10 @@ func (s *UserService) checkUserNotExistsResult(
 func (s *UserService) createAndSaveUserResult(
 	ctx context.Context,
 	id values.UserID,
-	email, name string,
+	email values.Email,
+	name values.UserName,
 ) mo.Result[*entities.User] {
-	user, err := entities.NewUser(id, email, name)
+	user, err := entities.NewUserFromValues(id, email, name, s.clock.Now())
 	if err != nil {
 		return mo.Err[*entities.User](
 			fmt.Errorf("create user (id=%s, email=%s): %w", id, email, err),
@@ -417,20 +714,55 @@ func (s *UserService) createAndSaveUserResult(
 }
 
 // FindUserByEmailOption demonstrates Option pattern.
+// Repository failures are folded into None as well.
 func (s *UserService) FindUserByEmailOption(
 	ctx context.Context,
 	email string,
 ) mo.Option[*entities.User] {
-	if err := s.validateEmail(email); err != nil {
+	emailVO, err := values.NewEmail(email)
+	if err != nil {
 		return mo.None[*entities.User]()
 	}
 
-	user, err := s.userRepo.FindByEmail(ctx, email)
+	user, err := repositories.FindByEmailOption(ctx, s.userRepo, emailVO)
 	if err != nil {
 		return mo.None[*entities.User]()
 	}
 
-	return mo.Some(user)
+	return user
+}
+
+// ImportUsers creates one user per entry with a generated ID. The result has
+// one error per entry, nil on success, so callers can feed fixed-size chunks
+// of a larger stream. Entries after a cancelled context fail with its error.
+func (s *UserService) ImportUsers(ctx context.Context, batch []UserFields) []error {
+	results := make([]error, len(batch))
+
+	for i, fields := range batch {
+		if err := ctx.Err(); err != nil {
+			results[i] = err
+
+			continue
+		}
+
+		id, err := values.GenerateUserID()
+		if err != nil {
+			results[i] = domainerrors.NewInternalError("failed to generate user ID", err)
+
+			continue
+		}
+
+		email, name, err := parseUserFields(fields.Email, fields.Name)
+		if err != nil {
+			results[i] = err
+
+			continue
+		}
+
+		_, results[i] = s.CreateUserV2(ctx, id, email, name)
+	}
+
+	return results
 }
 
 // BatchValidateUsers demonstrates functional operations for batch processing.
@@ -463,7 +795,8 @@ func (s *UserService) GetUserStats(ctx context.Context) (map[string]int, error)
 	stats["total"] = len(users)
 
 	// Count active users (created in last 30 days) using functional operations
-	thirtyDaysAgo := time.Now().AddDate(0, 0, -userActiveDays)
+	now := s.clock.Now()
+	thirtyDaysAgo := now.AddDate(0, 0, -userActiveDays)
 	activeCount := lo.CountBy(users, func(user *entities.User) bool {
 		return user.Created.After(thirtyDaysAgo)
 	})
@@ -481,7 +814,6 @@ func (s *UserService) GetUserStats(ctx context.Context) (map[string]int, error)
 	stats["domains"] = len(domainCounts)
 
 	// Calculate average days since registration using lo.Reduce
-	now := time.Now()
 	totalDays := lo.Reduce(users, func(acc int, user *entities.User, _ int) int {
 		days := max(
 			// Ensure non-negative days
@@ -499,41 +831,70 @@ func (s *UserService) GetUserStats(ctx context.Context) (map[string]int, error)
 	return stats, nil
 }
 
-// GetUsersWithFilters demonstrates advanced functional programming with type-safe filters.
+// GetUsersWithFilters retrieves the users matching filters via
+// FindUsersMatching. An empty Domain is ignored; Active false selects the
+// users that are not active.
 func (s *UserService) GetUsersWithFilters(
 	ctx context.Context,
 	filters UserFilters,
 ) ([]*entities.User, error) {
-	users, err := s.userRepo.List(ctx)
+	users, err := s.FindUsersMatching(ctx, filters.Specification(s.clock.Now()))
 	if err != nil {
-		return nil, fmt.Errorf(
-			"filters=%+v: %w",
-			filters,
-			domainerrors.NewInternalError("failed to list users", err),
-		)
+		return nil, fmt.Errorf("filters=%+v: %w", filters, err)
+	}
+
+	return users, nil
+}
+
+// FindUsersMatching retrieves the users satisfying spec. A repository that
+// implements repositories.UserMatcher selects them itself; otherwise every
+// user is listed and filtered in memory.
+func (s *UserService) FindUsersMatching(
+	ctx context.Context,
+	spec repositories.UserSpecification,
+) ([]*entities.User, error) {
+	return findUsersMatching(ctx, s.userRepo, spec)
+}
+
+func findUsersMatching(
+	ctx context.Context,
+	repo repositories.UserRepository,
+	spec repositories.UserSpecification,
+) ([]*entities.User, error) {
+	if matcher, ok := repo.(repositories.UserMatcher); ok {
+		users, err := matcher.FindMatching(ctx, spec)
+		if err != nil {
+			return nil, domainerrors.NewInternalError("failed to find matching users", err)
+		}
+
+		return users, nil
 	}
 
-	filteredUsers := users
+	users, err := repo.List(ctx)
+	if err != nil {
+		return nil, domainerrors.NewInternalError("failed to list users", err)
+	}
+
+	return lo.Filter(users, func(user *entities.User, _ int) bool {
+		return spec.IsSatisfiedBy(user)
+	}), nil
+}
 
-	// Filter by domain if specified
-	if filters.Domain != nil {
-		domainStr := *filters.Domain
-		filteredUsers = lo.Filter(filteredUsers, func(user *entities.User, _ int) bool {
-			parts := strings.Split(user.GetEmail().String(), "@")
+// Specification builds the user specification equivalent to the filters,
+// evaluating activity as of now.
+func (f UserFilters) Specification(now time.Time) repositories.UserSpecification {
+	specs := make([]repositories.UserSpecification, 0, 2)
 
-			return len(parts) > 1 && parts[1] == domainStr
-		})
+	if f.Domain != nil && *f.Domain != "" {
+		specs = append(specs, repositories.EmailDomainSpec{Domain: *f.Domain})
 	}
 
-	// Filter by active status if specified
-	if filters.Active != nil && *filters.Active {
-		thirtyDaysAgo := time.Now().AddDate(0, 0, -userActiveDays)
-		filteredUsers = lo.Filter(filteredUsers, func(user *entities.User, _ int) bool {
-			return user.Created.After(thirtyDaysAgo)
-		})
+	if f.Active != nil {
+		active := repositories.ActiveSpec{AsOf: now}
+		specs = append(specs, lo.Ternary[repositories.UserSpecification](*f.Active, active, repositories.Not(active)))
 	}
 
-	return filteredUsers, nil
+	return repositories.And(specs...)
 }
 
 // ValidateUserBatchWithEither demonstrates Either pattern for batch operations.
@@ -592,83 +953,28 @@ func (s *UserService) GetUsersByEmailDomains(
 	return filteredUsers, nil
 }
 
-// validateEmail enforces business rules for email validation.
-func (s *UserService) validateEmail(email string) error {
-	if email == "" {
-		return domainerrors.NewRequiredFieldError("email")
-	}
-
-	// Basic email validation
-	if !strings.Contains(email, "@") || !strings.Contains(email, ".") {
-		return domainerrors.NewValidationError("email", "must be a valid email address")
-	}
-
-	if len(email) > emailMaxLengthService {
-		return domainerrors.NewValidationError("email", "too long (max 254 characters)")
-	}
-
-	// Business rule: No spaces in email
-	if strings.Contains(email, " ") {
-		return domainerrors.NewValidationError("email", "cannot contain spaces")
-	}
-
-	return nil
-}
-
-// validateUserName enforces business rules for display name validation.
-func (s *UserService) validateUserName(name string) error {
-	err := s.validateNameNotEmpty(name)
+// parseUserFields converts raw email and name input into value objects,
+// which own the validation rules. A failure is a validation error on the
+// email or name field.
+func parseUserFields(email, name string) (values.Email, values.UserName, error) {
+	emailVO, err := parseEmail(email)
 	if err != nil {
-		return err
+		return values.Email{}, values.UserName{}, err
 	}
 
-	err = s.validateNameLength(name)
+	nameVO, err := values.NewUserName(name)
 	if err != nil {
-		return err
+		return values.Email{}, values.UserName{}, domainerrors.NewValidationError("name", err.Error())
 	}
 
-	err = s.validateNameWhitespace(name)
-	if err != nil {
-		return err
-	}
-
-	return s.validateNameContainsLetter(name)
+	return emailVO, nameVO, nil
 }
 
-func (s *UserService) validateNameNotEmpty(name string) error {
-	if name == "" {
-		return domainerrors.NewRequiredFieldError("name")
-	}
-
-	return nil
-}
-
-func (s *UserService) validateNameLength(name string) error {
-	if len(name) < 2 {
-		return domainerrors.NewValidationError("name", "too short (min 2 characters)")
-	}
-
-	if len(name) > nameMaxLengthService {
-		return domainerrors.NewValidationError("name", "too long (max 100 characters)")
-	}
-
-	return nil
-}
-
-func (s *UserService) validateNameWhitespace(name string) error {
-	if strings.TrimSpace(name) != name {
-		return domainerrors.NewValidationError("name", "cannot have leading or trailing spaces")
-	}
-
-	return nil
-}
-
-func (s *UserService) validateNameContainsLetter(name string) error {
-	for _, char := range name {
-		if (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') {
-			return nil
-		}
+func parseEmail(email string) (values.Email, error) {
+	emailVO, err := values.NewEmail(email)
+	if err != nil {
+		return values.Email{}, domainerrors.NewValidationError("email", err.Error())
 	}
 
-	return domainerrors.NewValidationError("name", "must contain at least one letter")
+	return emailVO, nil
 }
@@ -0,0 +1,249 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/entities"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/repositories"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/values"
+	domainerrors "github.com/LarsArtmann/template-arch-lint/pkg/errors"
+)
+
+// ErrDuplicateInBatch is returned for a batch item that repeats the email or
+// ID of an earlier item in the same batch.
+var ErrDuplicateInBatch = domainerrors.NewConflictError("duplicate entry within the batch", domainerrors.ErrorDetails{
+	Resource: "user",
+})
+
+// ErrBatchAborted is returned for the items of a fail-fast batch that were
+// valid themselves but not written because another item failed.
+var ErrBatchAborted = domainerrors.NewConflictError("batch aborted by a failed item", domainerrors.ErrorDetails{
+	Resource: "user",
+})
+
+// BatchPolicy decides what a batch operation does when some items fail.
+type BatchPolicy int
+
+const (
+	// BatchFailFast writes nothing unless every item succeeds.
+	BatchFailFast BatchPolicy = iota
+	// BatchBestEffort writes the items that succeed and reports the rest.
+	BatchBestEffort
+)
+
+// CreateUserInput is one user to create with CreateUsersBatch.
+type CreateUserInput struct {
+	ID    values.UserID
+	Email string
+	Name  string
+}
+
+// UserBatchResult is the outcome of one CreateUsersBatch item: the created
+// user, or the error that kept it from being created.
+type UserBatchResult struct {
+	User *entities.User
+	Err  error
+}
+
+// CreateUsersBatch creates users in one transaction with a single
+// repository write, applying the same validation and email uniqueness rules
+// as CreateUser. The result has one entry per input, in input order.
+// Emails repeated within the batch fail with ErrDuplicateInBatch before the
+// repository is consulted.
+func (s *UserService) CreateUsersBatch(
+	ctx context.Context,
+	inputs []CreateUserInput,
+	policy BatchPolicy,
+) []UserBatchResult {
+	results := make([]UserBatchResult, len(inputs))
+	users := s.prepareUsersBatch(inputs, results)
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		for i, user := range users {
+			if user != nil {
+				results[i].Err = s.checkBatchEmailAvailable(ctx, user)
+			}
+		}
+
+		failed := slices.ContainsFunc(results, func(result UserBatchResult) bool { return result.Err != nil })
+		if policy == BatchFailFast && failed {
+			return ErrBatchAborted
+		}
+
+		pending := make([]*entities.User, 0, len(users))
+
+		for i, user := range users {
+			if user != nil && results[i].Err == nil {
+				pending = append(pending, user)
+			}
+		}
+
+		if len(pending) == 0 {
+			return nil
+		}
+
+		err := s.userRepo.SaveAll(ctx, pending)
+		if err != nil {
+			return fmt.Errorf("save batch of %d users: %w", len(pending), err)
+		}
+
+		return nil
+	})
+
+	for i, user := range users {
+		if results[i].Err != nil {
+			continue
+		}
+
+		if err != nil {
+			results[i].Err = err
+
+			continue
+		}
+
+		results[i].User = user
+	}
+
+	return results
+}
+
+// prepareUsersBatch builds the entity for every valid input and records the
+// error of every other one in results. Failed positions hold nil.
+func (s *UserService) prepareUsersBatch(inputs []CreateUserInput, results []UserBatchResult) []*entities.User {
+	users := make([]*entities.User, len(inputs))
+	firstByEmail := make(map[string]int, len(inputs))
+	now := s.clock.Now()
+
+	for i, input := range inputs {
+		if first, seen := firstByEmail[input.Email]; seen {
+			results[i].Err = fmt.Errorf("email %s repeats batch item %d: %w", input.Email, first, ErrDuplicateInBatch)
+
+			continue
+		}
+
+		firstByEmail[input.Email] = i
+
+		email, name, err := parseUserFields(input.Email, input.Name)
+		if err != nil {
+			results[i].Err = err
+
+			continue
+		}
+
+		user, err := entities.NewUserFromValues(input.ID, email, name, now)
+		if err != nil {
+			results[i].Err = fmt.Errorf("create user (id=%s, email=%s): %w", input.ID, input.Email, err)
+
+			continue
+		}
+
+		users[i] = user
+	}
+
+	return users
+}
+
+func (s *UserService) checkBatchEmailAvailable(ctx context.Context, user *entities.User) error {
+	email := user.GetEmail()
+
+	existing, err := repositories.FindByEmailOption(ctx, s.userRepo, email)
+	if err != nil {
+		return domainerrors.NewInternalError(
+			fmt.Sprintf("failed to check existing user (id=%s, email=%s)", user.ID, email),
+			err,
+		)
+	}
+
+	if existing.IsPresent() {
+		return fmt.Errorf("user with email %s already exists: %w", email, repositories.ErrUserAlreadyExists)
+	}
+
+	return nil
+}
+
+// DeleteUsersBatch soft-deletes users like DeleteUser, in one transaction. The result has one
+// error per ID, nil on success, in input order. A missing user fails like
+// DeleteUser; an ID repeated within the batch fails with ErrDuplicateInBatch.
+// Under BatchFailFast no user is deleted unless all of them exist.
+func (s *UserService) DeleteUsersBatch(ctx context.Context, ids []values.UserID, policy BatchPolicy) []error {
+	results := make([]error, len(ids))
+	firstByID := make(map[values.UserID]int, len(ids))
+
+	for i, id := range ids {
+		if first, seen := firstByID[id]; seen {
+			results[i] = fmt.Errorf("user %s repeats batch item %d: %w", id, first, ErrDuplicateInBatch)
+
+			continue
+		}
+
+		firstByID[id] = i
+	}
+
+	users := make([]*entities.User, len(ids))
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		for i, id := range ids {
+			if results[i] != nil {
+				continue
+			}
+
+			user, err := s.userRepo.FindByID(ctx, id)
+			if err != nil {
+				results[i] = domainerrors.WrapRepoError("find for deletion", "user", err, id.String())
+
+				continue
+			}
+
+			users[i] = user
+		}
+
+		failed := slices.ContainsFunc(results, func(err error) bool { return err != nil })
+		if policy == BatchFailFast && failed {
+			return ErrBatchAborted
+		}
+
+		return s.deleteUsersBatchTx(ctx, users, results, policy)
+	})
+	if err != nil {
+		for i := range results {
+			if results[i] == nil {
+				results[i] = err
+			}
+		}
+	}
+
+	return results
+}
+
+// deleteUsersBatchTx soft-deletes every user without a recorded error. Under
+// BatchFailFast the first failure aborts the transaction.
+func (s *UserService) deleteUsersBatchTx(
+	ctx context.Context,
+	users []*entities.User,
+	results []error,
+	policy BatchPolicy,
+) error {
+	deletedAt := s.clock.Now()
+
+	for i, user := range users {
+		if results[i] != nil {
+			continue
+		}
+
+		user.SoftDelete(deletedAt)
+
+		err := s.userRepo.Save(ctx, user)
+		if err == nil {
+			continue
+		}
+
+		results[i] = domainerrors.WrapRepoError("delete", "user", err, user.ID.String())
+		if policy == BatchFailFast {
+			return ErrBatchAborted
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,178 @@
+package services_test
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/entities"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/repositories"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/services"
+	servicestesthelpers "github.com/LarsArtmann/template-arch-lint/internal/domain/services/testhelpers"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/values"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// writeCountingRepository counts the write calls that reach the repository.
+type writeCountingRepository struct {
+	repositories.UserRepository
+
+	saves    atomic.Int64
+	saveAlls atomic.Int64
+}
+
+func (r *writeCountingRepository) Save(ctx context.Context, user *entities.User) error {
+	r.saves.Add(1)
+
+	return r.UserRepository.Save(ctx, user)
+}
+
+func (r *writeCountingRepository) SaveAll(ctx context.Context, users []*entities.User) error {
+	r.saveAlls.Add(1)
+
+	return r.UserRepository.SaveAll(ctx, users)
+}
+
+var _ = Describe("UserService batch operations", func() {
+	var (
+		userService *services.UserService
+		userRepo    repositories.UserRepository
+		ctx         context.Context
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		userRepo = repositories.NewInMemoryUserRepository()
+		userService = services.NewUserService(userRepo)
+	})
+
+	input := func(id, email string) services.CreateUserInput {
+		return services.CreateUserInput{ID: servicestesthelpers.CreateTestUserID(id), Email: email, Name: "Batch User"}
+	}
+
+	Describe("CreateUsersBatch", func() {
+		It("should create every user in input order", func() {
+			results := userService.CreateUsersBatch(ctx, []services.CreateUserInput{
+				input("batch-1", "one@example.com"),
+				input("batch-2", "two@example.com"),
+			}, services.BatchFailFast)
+
+			Expect(results).To(HaveLen(2))
+			Expect(results[0].Err).ToNot(HaveOccurred())
+			Expect(results[0].User.GetEmail().String()).To(Equal("one@example.com"))
+			Expect(results[1].Err).ToNot(HaveOccurred())
+			Expect(results[1].User.GetEmail().String()).To(Equal("two@example.com"))
+		})
+
+		It("should reject an email repeated within the batch", func() {
+			results := userService.CreateUsersBatch(ctx, []services.CreateUserInput{
+				input("batch-1", "same@example.com"),
+				input("batch-2", "same@example.com"),
+			}, services.BatchBestEffort)
+
+			Expect(results[0].Err).ToNot(HaveOccurred())
+			Expect(results[1].Err).To(MatchError(services.ErrDuplicateInBatch))
+			Expect(results[1].User).To(BeNil())
+		})
+
+		It("should report an email that already exists", func() {
+			_, err := userService.CreateUser(ctx, servicestesthelpers.CreateTestUserID("existing"),
+				"taken@example.com", "Existing User")
+			Expect(err).ToNot(HaveOccurred())
+
+			results := userService.CreateUsersBatch(ctx, []services.CreateUserInput{
+				input("batch-1", "fresh@example.com"),
+				input("batch-2", "taken@example.com"),
+			}, services.BatchFailFast)
+
+			Expect(results[1].Err).To(MatchError(repositories.ErrUserAlreadyExists))
+			Expect(results[0].Err).To(MatchError(services.ErrBatchAborted))
+
+			users, err := userService.ListUsers(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(users).To(HaveLen(1))
+		})
+
+		It("should commit the successful rows in best-effort mode", func() {
+			results := userService.CreateUsersBatch(ctx, []services.CreateUserInput{
+				input("batch-1", "good@example.com"),
+				input("batch-2", "not-an-email"),
+				input("batch-3", "also-good@example.com"),
+			}, services.BatchBestEffort)
+
+			Expect(results[0].Err).ToNot(HaveOccurred())
+			Expect(results[1].Err).To(HaveOccurred())
+			Expect(results[2].Err).ToNot(HaveOccurred())
+
+			for _, result := range []services.UserBatchResult{results[0], results[2]} {
+				stored, err := userService.GetUser(ctx, result.User.ID)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(stored.GetEmail()).To(Equal(result.User.GetEmail()))
+			}
+		})
+
+		It("should write a 1000-user batch with a single repository call", func() {
+			counting := &writeCountingRepository{UserRepository: userRepo} //nolint:exhaustruct // zero counters
+			userService = services.NewUserService(counting)
+
+			inputs := make([]services.CreateUserInput, 1000)
+			for i := range inputs {
+				inputs[i] = input(fmt.Sprintf("bulk-%d", i), fmt.Sprintf("bulk%d@example.com", i))
+			}
+
+			results := userService.CreateUsersBatch(ctx, inputs, services.BatchFailFast)
+			for _, result := range results {
+				Expect(result.Err).ToNot(HaveOccurred())
+			}
+
+			Expect(counting.saveAlls.Load()).To(Equal(int64(1)))
+			Expect(counting.saves.Load()).To(BeZero())
+
+			users, err := userService.ListUsers(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(users).To(HaveLen(1000))
+		})
+	})
+
+	Describe("DeleteUsersBatch", func() {
+		var kept, removed values.UserID
+
+		BeforeEach(func() {
+			results := userService.CreateUsersBatch(ctx, []services.CreateUserInput{
+				input("delete-1", "keep@example.com"),
+				input("delete-2", "remove@example.com"),
+			}, services.BatchFailFast)
+			kept, removed = results[0].User.ID, results[1].User.ID
+		})
+
+		It("should delete nothing in fail-fast mode when a user is missing", func() {
+			missing := servicestesthelpers.CreateTestUserID("missing")
+
+			errs := userService.DeleteUsersBatch(ctx, []values.UserID{removed, missing}, services.BatchFailFast)
+
+			Expect(errs[0]).To(MatchError(services.ErrBatchAborted))
+			Expect(errs[1]).To(MatchError(repositories.ErrUserNotFound))
+
+			_, err := userService.GetUser(ctx, removed)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should delete the existing users in best-effort mode", func() {
+			missing := servicestesthelpers.CreateTestUserID("missing")
+
+			errs := userService.DeleteUsersBatch(ctx,
+				[]values.UserID{removed, missing, removed}, services.BatchBestEffort)
+
+			Expect(errs[0]).ToNot(HaveOccurred())
+			Expect(errs[1]).To(MatchError(repositories.ErrUserNotFound))
+			Expect(errs[2]).To(MatchError(services.ErrDuplicateInBatch))
+
+			_, err := userService.GetUser(ctx, removed)
+			Expect(err).To(MatchError(repositories.ErrUserNotFound))
+
+			_, err = userService.GetUser(ctx, kept)
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+})
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/LarsArtmann/template-arch-lint/internal/domain/entities"
 	"github.com/LarsArtmann/template-arch-lint/internal/domain/repositories"
@@ -27,6 +28,14 @@ func (m *mockRepositoryForBench) Save(_ context.Context, user *entities.User) er
 	return nil
 }
 
+func (m *mockRepositoryForBench) SaveAll(_ context.Context, users []*entities.User) error {
+	for _, user := range users {
+		m.users[user.ID.String()] = user
+	}
+
+	return nil
+}
+
 func (m *mockRepositoryForBench) FindByID(
 	_ context.Context,
 	id values.UserID,
@@ -39,12 +48,19 @@ func (m *mockRepositoryForBench) FindByID(
 	return user, nil
 }
 
+func (m *mockRepositoryForBench) FindByIDIncludingDeleted(
+	ctx context.Context,
+	id values.UserID,
+) (*entities.User, error) {
+	return m.FindByID(ctx, id)
+}
+
 func (m *mockRepositoryForBench) FindByEmail(
 	_ context.Context,
-	email string,
+	email values.Email,
 ) (*entities.User, error) {
 	for _, user := range m.users {
-		if user.GetEmail().String() == email {
+		if user.GetEmail() == email {
 			return user, nil
 		}
 	}
@@ -74,6 +90,52 @@ func (m *mockRepositoryForBench) List(_ context.Context) ([]*entities.User, erro
 	return users, nil
 }
 
+func (m *mockRepositoryForBench) FindPage(
+	ctx context.Context,
+	query repositories.UserPageQuery,
+) (repositories.UserPage, error) {
+	users, _ := m.List(ctx)
+
+	return repositories.PageOf(users, query), nil
+}
+
+func (m *mockRepositoryForBench) Search(ctx context.Context, query string, limit int) ([]*entities.User, error) {
+	page, _ := m.FindPage(ctx, repositories.SearchPageQuery(query, limit))
+
+	return page.Users, nil
+}
+
+func (m *mockRepositoryForBench) FindAllIter(ctx context.Context, fn func(*entities.User) error) error {
+	users, _ := m.List(ctx)
+
+	for _, user := range users {
+		err := fn(user)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *mockRepositoryForBench) ListDeleted(_ context.Context) ([]*entities.User, error) {
+	return []*entities.User{}, nil
+}
+
+func (m *mockRepositoryForBench) Update(ctx context.Context, user *entities.User) error {
+	return m.Save(ctx, user)
+}
+
+func (m *mockRepositoryForBench) Count(_ context.Context) (int, error) {
+	return len(m.users), nil
+}
+
+func (m *mockRepositoryForBench) Exists(_ context.Context, id values.UserID) (bool, error) {
+	_, exists := m.users[id.String()]
+
+	return exists, nil
+}
+
 func (m *mockRepositoryForBench) Delete(_ context.Context, id values.UserID) error {
 	delete(m.users, id.String())
 
@@ -94,7 +156,7 @@ func setupBenchmarkService(b *testing.B, userCount int) (*UserService, context.C
 		email := fmt.Sprintf("user%d@example.com", i)
 		name := fmt.Sprintf("User %d", i)
 
-		user, err := entities.NewUser(userID, email, name)
+		user, err := entities.NewUser(userID, email, name, time.Now())
 		if err != nil {
 			b.Fatalf("Failed to create test user: %v", err)
 		}
@@ -126,6 +188,47 @@ func BenchmarkCreateUser(b *testing.B) {
 	}
 }
 
+// BenchmarkCreateUsersBatch compares a 1000-user CreateUsersBatch with
+// calling CreateUser in a loop.
+func BenchmarkCreateUsersBatch(b *testing.B) {
+	const batchSize = 1000
+
+	inputs := make([]CreateUserInput, batchSize)
+	for i := range inputs {
+		userID, _ := values.NewUserID(fmt.Sprintf("bench-user-%d", i))
+		inputs[i] = CreateUserInput{
+			ID:    userID,
+			Email: fmt.Sprintf("benchuser%d@example.com", i),
+			Name:  fmt.Sprintf("Bench User %d", i),
+		}
+	}
+
+	b.Run("loop", func(b *testing.B) {
+		for b.Loop() {
+			service := NewUserService(newMockRepositoryForBench())
+
+			for _, input := range inputs {
+				_, err := service.CreateUser(b.Context(), input.ID, input.Email, input.Name)
+				if err != nil {
+					b.Fatalf("CreateUser failed: %v", err)
+				}
+			}
+		}
+	})
+
+	b.Run("batch", func(b *testing.B) {
+		for b.Loop() {
+			service := NewUserService(newMockRepositoryForBench())
+
+			for _, result := range service.CreateUsersBatch(b.Context(), inputs, BatchFailFast) {
+				if result.Err != nil {
+					b.Fatalf("CreateUsersBatch failed: %v", result.Err)
+				}
+			}
+		}
+	})
+}
+
 // BenchmarkGetUser measures user retrieval performance.
 func BenchmarkGetUser(b *testing.B) {
 	const userCount = 1000
@@ -357,7 +460,7 @@ func BenchmarkEntityCreation(b *testing.B) {
 		email := fmt.Sprintf("entitytest%d@example.com", i)
 		name := fmt.Sprintf("Entity Test User %d", i)
 
-		user, err := entities.NewUser(userID, email, name)
+		user, err := entities.NewUser(userID, email, name, time.Now())
 		if err != nil {
 			b.Fatalf("NewUser failed: %v", err)
 		}
@@ -0,0 +1,56 @@
+package services_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/clock"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/repositories"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/services"
+	servicestesthelpers "github.com/LarsArtmann/template-arch-lint/internal/domain/services/testhelpers"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("UserService with a fake clock", func() {
+	var (
+		userService *services.UserService
+		fake        *clock.Fake
+		ctx         context.Context
+		created     time.Time
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		created = time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+		fake = clock.NewFake(created)
+		userService = services.NewUserService(repositories.NewInMemoryUserRepositoryWithClock(fake)).WithClock(fake)
+
+		user, err := userService.CreateUser(ctx, servicestesthelpers.CreateTestUserID("clock"),
+			"clock@example.com", "Clock User")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(user.Created).To(Equal(created))
+	})
+
+	It("should count a user as active until exactly 30 days after creation", func() {
+		fake.Set(created.AddDate(0, 0, 30).Add(-time.Second))
+
+		active, err := userService.FilterActiveUsers(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(active).To(HaveLen(1))
+
+		stats, err := userService.GetUserStats(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stats).To(HaveKeyWithValue("active", 1))
+
+		fake.Advance(time.Second)
+
+		active, err = userService.FilterActiveUsers(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(active).To(BeEmpty())
+
+		stats, err = userService.GetUserStats(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stats).To(HaveKeyWithValue("active", 0))
+	})
+})
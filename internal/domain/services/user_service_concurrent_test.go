@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"sync"
 	"time"
 
@@ -12,6 +13,7 @@ import (
 	"github.com/LarsArtmann/template-arch-lint/internal/domain/services"
 	servicestesthelpers "github.com/LarsArtmann/template-arch-lint/internal/domain/services/testhelpers"
 	"github.com/LarsArtmann/template-arch-lint/internal/domain/values"
+	domainerrors "github.com/LarsArtmann/template-arch-lint/pkg/errors"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
@@ -283,7 +285,7 @@ var _ = Describe("🔄 UserService Concurrent Access Testing", func() {
 		})
 
 		Context("with concurrent updates to same user", func() {
-			It("should handle concurrent updates with last-write-wins semantics", func() {
+			It("should let one update win and reject the rest as concurrent modifications", func() {
 				const numUpdaters = 10
 
 				var wg sync.WaitGroup
@@ -314,13 +316,17 @@ var _ = Describe("🔄 UserService Concurrent Access Testing", func() {
 				close(results)
 				close(updateResults)
 
-				// Count successful updates
+				// Count successful updates; every failure must be a version conflict
 				successCount := 0
 
 				for err := range results {
 					if err == nil {
 						successCount++
+
+						continue
 					}
+
+					Expect(err).To(MatchError(repositories.ErrConcurrentModification))
 				}
 
 				// At least some updates should succeed (depending on timing)
@@ -334,6 +340,72 @@ var _ = Describe("🔄 UserService Concurrent Access Testing", func() {
 			})
 		})
 
+		Context("with two stale copies racing", func() {
+			It("should save exactly one copy", func() {
+				first, err := userRepo.FindByID(ctx, testUser.ID)
+				Expect(err).ToNot(HaveOccurred())
+				second, err := userRepo.FindByID(ctx, testUser.ID)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(first.SetName("First Writer", time.Now())).To(Succeed())
+				Expect(second.SetName("Second Writer", time.Now())).To(Succeed())
+
+				var wg sync.WaitGroup
+
+				start := make(chan struct{})
+				results := make(chan error, 2)
+
+				for _, stale := range []*entities.User{first, second} {
+					wg.Go(func() {
+						<-start
+						results <- userRepo.Save(ctx, stale)
+					})
+				}
+
+				close(start)
+				wg.Wait()
+				close(results)
+
+				var errs []error
+				for err := range results {
+					if err != nil {
+						errs = append(errs, err)
+					}
+				}
+
+				Expect(errs).To(HaveLen(1))
+				Expect(errs[0]).To(MatchError(repositories.ErrConcurrentModification))
+
+				stored, err := userRepo.FindByID(ctx, testUser.ID)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(stored.Version).To(Equal(testUser.Version + 1))
+				Expect(stored.GetUserName().String()).To(BeElementOf("First Writer", "Second Writer"))
+			})
+
+			It("should reject an update whose read went stale as a conflict", func() {
+				staleRepo := &interleavingRepository{UserRepository: userRepo}
+				staleService := services.NewUserService(staleRepo)
+
+				// Another writer commits between the stale read and its save
+				staleRepo.afterFind = func() {
+					_, err := userService.UpdateUser(ctx, testUser.ID, "winner@example.com", "Winner")
+					Expect(err).ToNot(HaveOccurred())
+				}
+
+				_, err := staleService.UpdateUser(ctx, testUser.ID, "loser@example.com", "Loser")
+
+				Expect(err).To(MatchError(repositories.ErrConcurrentModification))
+
+				domainErr, ok := errors.AsType[domainerrors.DomainError](err)
+				Expect(ok).To(BeTrue())
+				Expect(domainErr.HTTPStatus()).To(Equal(http.StatusConflict))
+
+				stored, err := userService.GetUser(ctx, testUser.ID)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(stored.GetEmail().String()).To(Equal("winner@example.com"))
+			})
+		})
+
 		Context("with concurrent updates to different users", func() {
 			It("should handle updates to different users independently", func() {
 				const numUsers = 10
@@ -657,3 +729,22 @@ var _ = Describe("🔄 UserService Concurrent Access Testing", func() {
 		})
 	})
 })
+
+// interleavingRepository runs afterFind once, right after the first FindByID,
+// to commit a competing write before the caller saves.
+type interleavingRepository struct {
+	repositories.UserRepository
+
+	afterFind func()
+}
+
+func (r *interleavingRepository) FindByID(ctx context.Context, id values.UserID) (*entities.User, error) {
+	user, err := r.UserRepository.FindByID(ctx, id)
+
+	if hook := r.afterFind; hook != nil {
+		r.afterFind = nil
+		hook()
+	}
+
+	return user, err
+}
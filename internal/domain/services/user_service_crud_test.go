@@ -106,6 +106,63 @@ var _ = Describe("UserService CRUD Operations", func() {
 		})
 	})
 
+	Describe("ReplaceUser", func() {
+		It("should create an absent user and report it as created", func() {
+			id := createTestUserID("test-user-1")
+			fields := services.UserFields{Email: defaultTestEmail, Name: defaultTestName}
+
+			user, created, err := userService.ReplaceUser(ctx, id, fields, 0)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(created).To(BeTrue())
+			Expect(user.ID).To(Equal(id))
+		})
+
+		It("should replace every field of an existing user", func() {
+			id := createTestUserID("test-user-1")
+			_, err := userService.CreateUser(ctx, id, defaultTestEmail, defaultTestName)
+			Expect(err).ToNot(HaveOccurred())
+
+			fields := services.UserFields{Email: "replaced@example.com", Name: "Replaced User"}
+			user, created, err := userService.ReplaceUser(ctx, id, fields, 0)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(created).To(BeFalse())
+			Expect(user.GetEmail().String()).To(Equal("replaced@example.com"))
+			Expect(user.GetUserName().String()).To(Equal("Replaced User"))
+		})
+
+		It("should not keep a stored field the replacement leaves empty", func() {
+			id := createTestUserID("test-user-1")
+			_, err := userService.CreateUser(ctx, id, defaultTestEmail, defaultTestName)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, _, err = userService.ReplaceUser(ctx, id, services.UserFields{Email: defaultTestEmail, Name: ""}, 0)
+			_, isValidationError := errors.AsValidationError(err)
+			Expect(isValidationError).To(BeTrue())
+		})
+
+		It("should replace only at the expected version", func() {
+			id := createTestUserID("test-user-1")
+			stored, err := userService.CreateUser(ctx, id, defaultTestEmail, defaultTestName)
+			Expect(err).ToNot(HaveOccurred())
+
+			fields := services.UserFields{Email: "first@example.com", Name: "First Writer"}
+			user, _, err := userService.ReplaceUser(ctx, id, fields, stored.Version)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(user.Version).To(Equal(stored.Version + 1))
+
+			fields = services.UserFields{Email: "second@example.com", Name: "Second Writer"}
+			_, _, err = userService.ReplaceUser(ctx, id, fields, stored.Version)
+			Expect(err).To(MatchError(repositories.ErrConcurrentModification))
+		})
+
+		It("should not create a user at an expected version", func() {
+			fields := services.UserFields{Email: defaultTestEmail, Name: defaultTestName}
+
+			_, _, err := userService.ReplaceUser(ctx, createTestUserID("test-user-1"), fields, 1)
+			Expect(err).To(MatchError(repositories.ErrConcurrentModification))
+		})
+	})
+
 	Describe("DeleteUser", func() {
 		Context("when user exists", func() {
 			It("should delete user successfully", func() {
@@ -69,6 +69,11 @@ func (r *FailingUserRepository) Save(ctx context.Context, user *entities.User) e
 	return nil
 }
 
+// SaveAll fails like Save and counts as one save call.
+func (r *FailingUserRepository) SaveAll(ctx context.Context, _ []*entities.User) error {
+	return r.Save(ctx, nil)
+}
+
 func (r *FailingUserRepository) FindByID(
 	ctx context.Context,
 	id values.UserID,
@@ -91,9 +96,17 @@ func (r *FailingUserRepository) FindByID(
 	return nil, repositories.ErrUserNotFound
 }
 
+// FindByIDIncludingDeleted fails like FindByID and counts as one call to it.
+func (r *FailingUserRepository) FindByIDIncludingDeleted(
+	ctx context.Context,
+	id values.UserID,
+) (*entities.User, error) {
+	return r.FindByID(ctx, id)
+}
+
 func (r *FailingUserRepository) FindByEmail(
 	ctx context.Context,
-	email string,
+	email values.Email,
 ) (*entities.User, error) {
 	r.findByEmailCallCount++
 	if r.findByEmailError != nil {
@@ -134,6 +147,48 @@ func (r *FailingUserRepository) List(ctx context.Context) ([]*entities.User, err
 	return []*entities.User{}, nil
 }
 
+// FindPage fails like List and counts as one call to it.
+func (r *FailingUserRepository) FindPage(
+	ctx context.Context,
+	query repositories.UserPageQuery,
+) (repositories.UserPage, error) {
+	users, err := r.List(ctx)
+	if err != nil {
+		return repositories.UserPage{}, err
+	}
+
+	return repositories.PageOf(users, query), nil
+}
+
+// Search fails like List and counts as one call to it.
+func (r *FailingUserRepository) Search(ctx context.Context, query string, limit int) ([]*entities.User, error) {
+	page, err := r.FindPage(ctx, repositories.SearchPageQuery(query, limit))
+
+	return page.Users, err
+}
+
+// FindAllIter fails like List and counts as one call to it.
+func (r *FailingUserRepository) FindAllIter(ctx context.Context, fn func(*entities.User) error) error {
+	users, err := r.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		err = fn(user)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ListDeleted fails like List and counts as one call to it.
+func (r *FailingUserRepository) ListDeleted(ctx context.Context) ([]*entities.User, error) {
+	return r.List(ctx)
+}
+
 func (r *FailingUserRepository) Count(ctx context.Context) (int, error) {
 	r.countCallCount++
 	if r.countError != nil {
@@ -290,6 +345,7 @@ var _ = Describe("🚨 UserService Error Path Testing", func() {
 					createTestUserID("test-user"),
 					"test@example.com",
 					"Test User",
+					time.Now(),
 				)
 				failingRepo.testUser = testUser
 				failingRepo.findByIDError = nil
@@ -305,43 +361,46 @@ var _ = Describe("🚨 UserService Error Path Testing", func() {
 			})
 		})
 
-		Context("DeleteUser with repository failures", func() {
-			It("should handle Delete repository errors", func() {
-				// Make FindByID succeed but Delete fail
+		Context("DeleteUser and PurgeUser with repository failures", func() {
+			It("should handle Save repository errors", func() {
+				// Make FindByID succeed but the soft-deleting Save fail
 				testUser, _ := entities.NewUser(
 					createTestUserID("test-user"),
 					"test@example.com",
 					"Test User",
+					time.Now(),
 				)
 				failingRepo.testUser = testUser
 				failingRepo.findByIDError = nil
-				failingRepo.deleteError = sql.ErrConnDone
+				failingRepo.saveError = sql.ErrConnDone
 
 				id := createTestUserID("test-user")
 				err := userService.DeleteUser(ctx, id)
 
 				expectInternalErrorWithCause(err, sql.ErrConnDone, "failed to delete user")
-				Expect(failingRepo.deleteCallCount).To(Equal(1))
+				Expect(failingRepo.saveCallCount).To(Equal(1))
+				Expect(failingRepo.deleteCallCount).To(BeZero())
 			})
 
-			It("should handle foreign key constraint errors", func() {
-				// Make FindByID succeed but Delete fail
+			It("should handle foreign key constraint errors on purge", func() {
+				// Make FindByIDIncludingDeleted succeed but Delete fail
 				testUser, _ := entities.NewUser(
 					createTestUserID("test-user"),
 					"test@example.com",
 					"Test User",
+					time.Now(),
 				)
 				failingRepo.testUser = testUser
 				failingRepo.findByIDError = nil
 				failingRepo.deleteError = errForeignKeyConstraint
 
 				id := createTestUserID("test-user")
-				err := userService.DeleteUser(ctx, id)
+				err := userService.PurgeUser(ctx, id)
 
 				Expect(err).To(HaveOccurred())
 				Expect(
 					err.Error(),
-				).To(ContainSubstring("failed to delete user"))
+				).To(ContainSubstring("failed to purge user"))
 				Expect(err.Error()).To(ContainSubstring("FOREIGN KEY constraint failed"))
 			})
 		})
@@ -353,6 +412,7 @@ var _ = Describe("🚨 UserService Error Path Testing", func() {
 					createTestUserID("test-user"),
 					"test@example.com",
 					"Test User",
+					time.Now(),
 				)
 				failingRepo.testUser = testUser
 				failingRepo.findByIDError = nil
@@ -0,0 +1,103 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json/v2"
+	"strings"
+
+	"charm.land/log/v2"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/repositories"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/services"
+	servicestesthelpers "github.com/LarsArtmann/template-arch-lint/internal/domain/services/testhelpers"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// logRecords decodes the JSON log lines in buf.
+func logRecords(buf *bytes.Buffer) []map[string]any {
+	var records []map[string]any
+
+	for line := range strings.Lines(buf.String()) {
+		var record map[string]any
+		Expect(json.Unmarshal([]byte(line), &record)).To(Succeed())
+
+		records = append(records, record)
+	}
+
+	return records
+}
+
+func newJSONLogger(buf *bytes.Buffer) *log.Logger {
+	logger := log.New(buf)
+	logger.SetFormatter(log.JSONFormatter)
+	logger.SetLevel(log.DebugLevel)
+
+	return logger
+}
+
+var _ = Describe("UserService logging", func() {
+	var (
+		userService *services.UserService
+		requestLogs bytes.Buffer
+		fallback    bytes.Buffer
+		ctx         context.Context
+	)
+
+	BeforeEach(func() {
+		requestLogs.Reset()
+		fallback.Reset()
+
+		userService = services.NewUserService(repositories.NewInMemoryUserRepository()).
+			WithLogger(newJSONLogger(&fallback))
+		ctx = log.WithContext(context.Background(), newJSONLogger(&requestLogs).With("request_id", "req-1"))
+	})
+
+	It("should log mutations at Info through the request logger", func() {
+		id := servicestesthelpers.CreateTestUserID("logged")
+		_, err := userService.CreateUser(ctx, id, "logged@example.com", "Logged User")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(logRecords(&requestLogs)).To(ContainElement(SatisfyAll(
+			HaveKeyWithValue("level", "info"),
+			HaveKeyWithValue("operation", "create_user"),
+			HaveKeyWithValue("user_id", id.String()),
+			HaveKeyWithValue("request_id", "req-1"),
+			HaveKey("duration_ms"),
+		)))
+		Expect(fallback.String()).To(BeEmpty())
+	})
+
+	It("should log lookups at Debug and business-rule rejections at Warn", func() {
+		id := servicestesthelpers.CreateTestUserID("rejected")
+		_, err := userService.CreateUser(ctx, id, "taken@example.com", "First User")
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = userService.GetUser(ctx, id)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = userService.CreateUser(ctx, servicestesthelpers.CreateTestUserID("duplicate"),
+			"taken@example.com", "Second User")
+		Expect(err).To(MatchError(repositories.ErrUserAlreadyExists))
+
+		records := logRecords(&requestLogs)
+		Expect(records).To(ContainElement(SatisfyAll(
+			HaveKeyWithValue("level", "debug"),
+			HaveKeyWithValue("operation", "get_user"),
+		)))
+		Expect(records).To(ContainElement(SatisfyAll(
+			HaveKeyWithValue("level", "warn"),
+			HaveKeyWithValue("operation", "create_user"),
+			HaveKey("error"),
+		)))
+	})
+
+	It("should fall back to the injected logger without a request logger", func() {
+		_, err := userService.CreateUser(context.Background(), servicestesthelpers.CreateTestUserID("fallback"),
+			"fallback@example.com", "Fallback User")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(logRecords(&fallback)).To(ContainElement(HaveKeyWithValue("operation", "create_user")))
+		Expect(requestLogs.String()).To(BeEmpty())
+	})
+})
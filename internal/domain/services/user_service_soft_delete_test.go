@@ -0,0 +1,86 @@
+package services_test
+
+import (
+	"context"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/entities"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/repositories"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/services"
+	servicestesthelpers "github.com/LarsArtmann/template-arch-lint/internal/domain/services/testhelpers"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("UserService soft delete", func() {
+	var (
+		userService *services.UserService
+		userRepo    repositories.UserRepository
+		ctx         context.Context
+		user        *entities.User
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		userRepo = repositories.NewInMemoryUserRepository()
+		userService = services.NewUserService(userRepo)
+
+		var err error
+
+		user, err = userService.CreateUser(ctx, servicestesthelpers.CreateTestUserID("soft-delete"),
+			"soft@example.com", "Soft User")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(userService.DeleteUser(ctx, user.ID)).To(Succeed())
+	})
+
+	It("should hide a deleted user from lookups and listings", func() {
+		_, err := userService.GetUser(ctx, user.ID)
+		Expect(err).To(MatchError(repositories.ErrUserNotFound))
+
+		_, err = userService.GetUserByEmail(ctx, "soft@example.com")
+		Expect(err).To(HaveOccurred())
+
+		users, err := userService.ListUsers(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(users).To(BeEmpty())
+	})
+
+	It("should keep a deleted user for admin lookups", func() {
+		stored, err := userRepo.FindByIDIncludingDeleted(ctx, user.ID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stored.IsDeleted()).To(BeTrue())
+
+		deleted, err := userRepo.ListDeleted(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deleted).To(HaveLen(1))
+	})
+
+	It("should find a restored user again", func() {
+		restored, err := userService.RestoreUser(ctx, user.ID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(restored.IsDeleted()).To(BeFalse())
+
+		found, err := userService.GetUser(ctx, user.ID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(found.GetEmail().String()).To(Equal("soft@example.com"))
+	})
+
+	It("should remove a purged user for good", func() {
+		Expect(userService.PurgeUser(ctx, user.ID)).To(Succeed())
+
+		_, err := userRepo.FindByIDIncludingDeleted(ctx, user.ID)
+		Expect(err).To(MatchError(repositories.ErrUserNotFound))
+
+		_, err = userService.RestoreUser(ctx, user.ID)
+		Expect(err).To(MatchError(repositories.ErrUserNotFound))
+	})
+
+	It("should let a new user register a deleted user's email", func() {
+		_, err := userService.CreateUser(ctx, servicestesthelpers.CreateTestUserID("re-register"),
+			"soft@example.com", "New Owner")
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = userService.RestoreUser(ctx, user.ID)
+		Expect(err).To(MatchError(repositories.ErrUserAlreadyExists))
+	})
+})
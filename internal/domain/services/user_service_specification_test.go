@@ -0,0 +1,74 @@
+package services_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/entities"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/repositories"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/services"
+	servicestesthelpers "github.com/LarsArtmann/template-arch-lint/internal/domain/services/testhelpers"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("UserService specifications", func() {
+	var (
+		userService *services.UserService
+		ctx         context.Context
+		recent      *entities.User
+		old         *entities.User
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		userRepo := repositories.NewInMemoryUserRepository()
+		userService = services.NewUserService(userRepo)
+
+		var err error
+
+		recent, err = userService.CreateUser(ctx, servicestesthelpers.CreateTestUserID("recent"),
+			"recent@example.com", "Recent User")
+		Expect(err).ToNot(HaveOccurred())
+
+		old, err = userService.CreateUser(ctx, servicestesthelpers.CreateTestUserID("old"),
+			"old@corp.com", "Old User")
+		Expect(err).ToNot(HaveOccurred())
+
+		old.Created = time.Now().AddDate(0, 0, -repositories.ActiveUserDays-1)
+		Expect(userRepo.Save(ctx, old)).To(Succeed())
+	})
+
+	It("should find users matching a composed specification", func() {
+		users, err := userService.FindUsersMatching(ctx, repositories.Or(
+			repositories.EmailDomainSpec{Domain: "corp.com"},
+			repositories.NamePrefixSpec{Prefix: "Rec"},
+		))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(users).To(ConsistOf(HaveField("ID", recent.ID), HaveField("ID", old.ID)))
+
+		users, err = userService.FindUsersMatching(ctx, repositories.Not(repositories.ActiveSpec{AsOf: time.Now()}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(users).To(ConsistOf(HaveField("ID", old.ID)))
+	})
+
+	It("should keep GetUsersWithFilters as a wrapper over specifications", func() {
+		active, inactive, domain := true, false, "example.com"
+
+		users, err := userService.GetUsersWithFilters(ctx, services.UserFilters{Active: &active})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(users).To(ConsistOf(HaveField("ID", recent.ID)))
+
+		users, err = userService.GetUsersWithFilters(ctx, services.UserFilters{Active: &inactive})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(users).To(ConsistOf(HaveField("ID", old.ID)))
+
+		users, err = userService.GetUsersWithFilters(ctx, services.UserFilters{Domain: &domain, Active: &inactive})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(users).To(BeEmpty())
+
+		users, err = userService.GetUsersWithFilters(ctx, services.UserFilters{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(users).To(HaveLen(2))
+	})
+})
@@ -142,6 +142,34 @@ var _ = Describe("UserService", func() {
 		})
 	})
 
+	Describe("CreateUserV2", func() {
+		It("should create a user from value objects", func() {
+			id := createTestUserID("test-user-v2")
+			user, err := userService.CreateUserV2(ctx, id,
+				servicestesthelpers.CreateTestEmail(defaultTestEmail),
+				servicestesthelpers.CreateTestUserName(defaultTestName))
+			expectSuccessfulUserCreation(user, err, id, defaultTestEmail, defaultTestName)
+		})
+
+		It("should reject an email that is already taken", func() {
+			createDefaultTestUser("test-user-1")
+
+			user, err := userService.CreateUserV2(ctx, createTestUserID("test-user-2"),
+				servicestesthelpers.CreateTestEmail(defaultTestEmail),
+				servicestesthelpers.CreateTestUserName("Other User"))
+
+			Expect(user).To(BeNil())
+			Expect(err).To(MatchError(repositories.ErrUserAlreadyExists))
+		})
+
+		It("should reject zero value objects", func() {
+			user, err := userService.CreateUserV2(ctx, createTestUserID("test-user-zero"),
+				values.Email{}, servicestesthelpers.CreateTestUserName(defaultTestName))
+
+			assertValidationError(user, err)
+		})
+	})
+
 	Describe("GetUser", func() {
 		Context("when user exists", func() {
 			It("should return the user", func() {
@@ -216,6 +244,35 @@ var _ = Describe("UserService", func() {
 		})
 	})
 
+	Describe("UpdateUserV2", func() {
+		var existingUser *entities.User
+
+		BeforeEach(func() {
+			existingUser = createDefaultTestUser("test-user-1")
+		})
+
+		It("should update the user from value objects", func() {
+			user, err := userService.UpdateUserV2(ctx, existingUser.ID,
+				servicestesthelpers.CreateTestEmail("updated@example.com"),
+				servicestesthelpers.CreateTestUserName("Updated User"))
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(user.GetEmail().String()).To(Equal("updated@example.com"))
+			Expect(user.GetUserName().String()).To(Equal("Updated User"))
+		})
+
+		It("should reject an email owned by another user", func() {
+			createValidTestUser("test-user-2", "other@example.com", "Other User")
+
+			user, err := userService.UpdateUserV2(ctx, existingUser.ID,
+				servicestesthelpers.CreateTestEmail("other@example.com"),
+				servicestesthelpers.CreateTestUserName(defaultTestName))
+
+			Expect(user).To(BeNil())
+			Expect(err).To(MatchError(repositories.ErrUserAlreadyExists))
+		})
+	})
+
 	Describe("DeleteUser", func() {
 		var existingUser *entities.User
 
@@ -391,6 +448,10 @@ var _ = Describe("UserService", func() {
 			DescribeTable(
 				"should handle complex email validation scenarios",
 				func(email string, shouldSucceed bool, description string) {
+					// The value object is the single source of the rules
+					_, voErr := values.NewEmail(email)
+					Expect(voErr == nil).To(Equal(shouldSucceed), description)
+
 					id := createTestUserID("edge-case-user")
 					user, err := userService.CreateUser(ctx, id, email, "Test User")
 
@@ -499,6 +560,10 @@ var _ = Describe("UserService", func() {
 			DescribeTable(
 				"should handle complex name validation scenarios",
 				func(name string, shouldSucceed bool, description string) {
+					// The value object is the single source of the rules
+					_, voErr := values.NewUserName(name)
+					Expect(voErr == nil).To(Equal(shouldSucceed), description)
+
 					id := createTestUserID("name-edge-case")
 					user, err := userService.CreateUser(ctx, id, defaultTestEmail, name)
 
@@ -0,0 +1,100 @@
+package services_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/entities"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/repositories"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/services"
+	servicestesthelpers "github.com/LarsArtmann/template-arch-lint/internal/domain/services/testhelpers"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/values"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// unconstrainedRepository stores users without an email unique constraint
+// and widens the gap between the uniqueness check and the save, so only
+// the service's transaction keeps duplicates out.
+type unconstrainedRepository struct {
+	repositories.UserRepository
+
+	mu    sync.Mutex
+	users []*entities.User
+}
+
+func (r *unconstrainedRepository) FindByEmail(_ context.Context, email values.Email) (*entities.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, user := range r.users {
+		if user.GetEmail() == email {
+			return user, nil
+		}
+	}
+
+	return nil, repositories.ErrUserNotFound
+}
+
+func (r *unconstrainedRepository) Save(_ context.Context, user *entities.User) error {
+	time.Sleep(10 * time.Millisecond)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.users = append(r.users, user)
+
+	return nil
+}
+
+var _ = Describe("UserService transactions", func() {
+	It("should not let two concurrent creations with the same email both succeed", func() {
+		const creators = 2
+
+		repo := &unconstrainedRepository{} //nolint:exhaustruct // starts empty
+		userService := services.NewUserService(repo).WithTxManager(repositories.NewInMemoryTxManager())
+
+		var wg sync.WaitGroup
+
+		start := make(chan struct{})
+		results := make(chan error, creators)
+
+		for i := range creators {
+			id := servicestesthelpers.CreateTestUserID(fmt.Sprintf("tx-user-%d", i))
+
+			wg.Go(func() {
+				<-start
+
+				_, err := userService.CreateUser(context.Background(), id, "same@example.com", "Same User")
+				results <- err
+			})
+		}
+
+		close(start)
+		wg.Wait()
+		close(results)
+
+		var errs []error
+		for err := range results {
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		Expect(errs).To(HaveLen(1))
+		Expect(errs[0]).To(MatchError(repositories.ErrUserAlreadyExists))
+		Expect(repo.users).To(HaveLen(1))
+	})
+
+	It("should join the outer transaction when units of work nest", func() {
+		tx := repositories.NewInMemoryTxManager()
+
+		err := tx.WithinTx(context.Background(), func(ctx context.Context) error {
+			return tx.WithinTx(ctx, func(context.Context) error { return nil })
+		})
+
+		Expect(err).ToNot(HaveOccurred())
+	})
+})
@@ -1,6 +1,7 @@
 package values
 
 import (
+	"encoding/json/v2"
 	"fmt"
 	"regexp"
 	"strings"
@@ -79,6 +80,27 @@ func (e Email) IsEmpty() bool {
 	return e.value == ""
 }
 
+// MarshalJSON implements json.Marshaler interface, writing the email as a
+// plain string.
+func (e Email) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.value)
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface. The string goes
+// through NewEmail, so an invalid email is rejected.
+func (e *Email) UnmarshalJSON(data []byte) error {
+	return unmarshalString(data, func(str string) error {
+		parsed, err := NewEmail(str)
+		if err != nil {
+			return err
+		}
+
+		*e = parsed
+
+		return nil
+	})
+}
+
 // validateEmailFormat enforces business rules for email validation.
 func validateEmailFormat(email string) error {
 	err := validateEmailNotEmpty(email)
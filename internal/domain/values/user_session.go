@@ -25,8 +25,8 @@ type SessionToken struct {
 	expires time.Time
 }
 
-// NewSessionToken creates a new session token with expiration.
-func NewSessionToken(duration time.Duration) (SessionToken, error) {
+// NewSessionToken creates a new session token expiring duration after now.
+func NewSessionToken(duration time.Duration, now time.Time) (SessionToken, error) {
 	bytes := make([]byte, sessionTokenByteLength)
 	if _, err := rand.Read(bytes); err != nil {
 		return SessionToken{}, fmt.Errorf(
@@ -37,7 +37,7 @@ func NewSessionToken(duration time.Duration) (SessionToken, error) {
 	}
 
 	token := hex.EncodeToString(bytes)
-	expires := time.Now().Add(duration)
+	expires := now.Add(duration)
 
 	return SessionToken{
 		value:   token,
@@ -100,14 +100,14 @@ func (t SessionToken) Expires() time.Time {
 	return t.expires
 }
 
-// IsExpired checks if the session token has expired.
-func (t SessionToken) IsExpired() bool {
-	return time.Now().After(t.expires)
+// IsExpired checks if the session token has expired by now.
+func (t SessionToken) IsExpired(now time.Time) bool {
+	return now.After(t.expires)
 }
 
-// IsValid checks if the session token is still valid.
-func (t SessionToken) IsValid() bool {
-	return !t.IsExpired() && t.value != ""
+// IsValid checks if the session token is still valid at now.
+func (t SessionToken) IsValid(now time.Time) bool {
+	return !t.IsExpired(now) && t.value != ""
 }
 
 // MarshalJSON implements json.Marshaler interface.
@@ -153,13 +153,13 @@ type AuditTrail struct {
 	metadata  map[string]string
 }
 
-// NewAuditTrail creates a new audit trail entry.
-func NewAuditTrail(userID ids.UserID, action, resource, ip, userAgent string) AuditTrail {
+// NewAuditTrail creates a new audit trail entry recorded at the given time.
+func NewAuditTrail(userID ids.UserID, action, resource, ip, userAgent string, at time.Time) AuditTrail {
 	return AuditTrail{
 		userID:    userID,
 		action:    action,
 		resource:  resource,
-		timestamp: time.Now().UTC(),
+		timestamp: at.UTC(),
 		ip:        ip,
 		userAgent: userAgent,
 		metadata:  make(map[string]string),
@@ -1,6 +1,7 @@
 package values
 
 import (
+	"encoding/json/v2"
 	"fmt"
 	"regexp"
 	"strings"
@@ -81,6 +82,27 @@ func (u UserName) IsEmpty() bool {
 	return u.value == ""
 }
 
+// MarshalJSON implements json.Marshaler interface, writing the username as a
+// plain string.
+func (u UserName) MarshalJSON() ([]byte, error) {
+	return json.Marshal(u.value)
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface. The string goes
+// through NewUserName, so an invalid username is rejected.
+func (u *UserName) UnmarshalJSON(data []byte) error {
+	return unmarshalString(data, func(str string) error {
+		parsed, err := NewUserName(str)
+		if err != nil {
+			return err
+		}
+
+		*u = parsed
+
+		return nil
+	})
+}
+
 // IsReserved checks if the username is in the reserved list.
 func (u UserName) IsReserved() bool {
 	return reservedUsernameSet[strings.ToLower(u.value)]
@@ -100,6 +100,28 @@ var _ = Describe("Value Objects", func() {
 				})
 			})
 		})
+
+		Describe("JSON marshaling", func() {
+			It("should round-trip as a plain string", func() {
+				email, err := values.NewEmail("Test.User@example.com")
+				Expect(err).ToNot(HaveOccurred())
+
+				data, err := json.Marshal(email)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(string(data)).To(Equal(`"Test.User@example.com"`))
+
+				var unmarshaled values.Email
+				Expect(json.Unmarshal(data, &unmarshaled)).To(Succeed())
+				Expect(unmarshaled).To(Equal(email))
+			})
+
+			It("should reject an invalid email", func() {
+				var unmarshaled values.Email
+				Expect(json.Unmarshal([]byte(`"not-an-email"`), &unmarshaled)).ToNot(Succeed())
+				Expect(json.Unmarshal([]byte(`42`), &unmarshaled)).ToNot(Succeed())
+				Expect(unmarshaled.IsEmpty()).To(BeTrue())
+			})
+		})
 	})
 
 	Describe("UserName", func() {
@@ -161,6 +183,24 @@ var _ = Describe("Value Objects", func() {
 				})
 			})
 
+			Describe("JSON marshaling", func() {
+				It("should round-trip as a plain string", func() {
+					data, err := json.Marshal(username)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(string(data)).To(Equal(`"john doe"`))
+
+					var unmarshaled values.UserName
+					Expect(json.Unmarshal(data, &unmarshaled)).To(Succeed())
+					Expect(unmarshaled).To(Equal(username))
+				})
+
+				It("should reject a reserved name", func() {
+					var unmarshaled values.UserName
+					Expect(json.Unmarshal([]byte(`"admin"`), &unmarshaled)).ToNot(Succeed())
+					Expect(unmarshaled.IsEmpty()).To(BeTrue())
+				})
+			})
+
 			Describe("IsReserved", func() {
 				It("should prevent creating reserved names", func() {
 					_, err := values.NewUserName("admin")
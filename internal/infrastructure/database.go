@@ -1,21 +1,212 @@
 package infrastructure
 
 import (
+	"context"
 	"database/sql"
+	stderrors "errors"
 	"fmt"
 )
 
+const (
+	createSettingsTableSQL = `CREATE TABLE IF NOT EXISTS settings (
+    key TEXT PRIMARY KEY,
+    value TEXT NOT NULL
+)`
+	selectSettingSQL         = `SELECT value FROM settings WHERE key = ?`
+	insertSettingIfAbsentSQL = `INSERT INTO settings (key, value) VALUES (?, ?) ON CONFLICT(key) DO NOTHING`
+	selectAllSettingsSQL     = `SELECT key, value FROM settings`
+	upsertSettingSQL         = `INSERT INTO settings (key, value) VALUES (?, ?)
+ON CONFLICT(key) DO UPDATE SET value = excluded.value`
+	deleteSettingSQL = `DELETE FROM settings WHERE key = ?`
+)
+
 // Database represents an infrastructure concern.
 type Database struct {
-	db *sql.DB
+	db      *sql.DB
+	retrier *Retrier
 }
 
 // NewDatabase creates a new database connection.
 func NewDatabase(dsn string) (*Database, error) {
 	db, err := sql.Open("sqlite3", dsn)
 	if err != nil {
-		return nil, fmt.Errorf("dsn=%s: %w", dsn, err)
+		return nil, fmt.Errorf("dsn=%s: %w", redactDSN(dsn), err)
+	}
+
+	return &Database{db: db, retrier: NewRetrier(DefaultRetryPolicy(), nil)}, nil
+}
+
+// NewDatabaseFromDB wraps a connection pool that is already open, for
+// example the one the migrations run on.
+func NewDatabaseFromDB(db *sql.DB) *Database {
+	return &Database{db: db, retrier: NewRetrier(DefaultRetryPolicy(), nil)}
+}
+
+// WithRetrier replaces the retrier used for transient busy/locked errors.
+func (d *Database) WithRetrier(retrier *Retrier) *Database {
+	d.retrier = retrier
+
+	return d
+}
+
+// BeginTx starts a transaction, retrying while the database is busy.
+// Beginning a transaction has no side effects, so it is always safe to retry.
+func (d *Database) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	var tx *sql.Tx
+
+	err := d.retrier.Do(ctx, "begin_tx", func(ctx context.Context) error {
+		var beginErr error
+
+		tx, beginErr = d.db.BeginTx(ctx, opts)
+
+		return beginErr
+	})
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+
+	return tx, nil
+}
+
+// EnsureSettings creates the settings table when it does not exist yet.
+func (d *Database) EnsureSettings(ctx context.Context) error {
+	_, err := d.db.ExecContext(ctx, createSettingsTableSQL)
+	if err != nil {
+		return fmt.Errorf("create settings table: %w", err)
+	}
+
+	return nil
+}
+
+// GetSetting reads a value from the settings table.
+func (d *Database) GetSetting(ctx context.Context, key string) (string, bool, error) {
+	var value string
+
+	err := d.retrier.Do(ctx, "get_setting", func(ctx context.Context) error {
+		return d.db.QueryRowContext(ctx, selectSettingSQL, key).Scan(&value)
+	})
+	if stderrors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+
+	if err != nil {
+		return "", false, fmt.Errorf("get setting %s: %w", key, err)
+	}
+
+	return value, true, nil
+}
+
+// SetSettingIfAbsent writes value unless key is already set and returns the stored value.
+func (d *Database) SetSettingIfAbsent(ctx context.Context, key, value string) (string, error) {
+	err := d.EnsureSettings(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = d.db.ExecContext(ctx, insertSettingIfAbsentSQL, key, value)
+	if err != nil {
+		return "", fmt.Errorf("set setting %s: %w", key, err)
+	}
+
+	stored, _, err := d.GetSetting(ctx, key)
+	if err != nil {
+		return "", err
+	}
+
+	return stored, nil
+}
+
+// ListSettings returns every entry of the settings table.
+func (d *Database) ListSettings(ctx context.Context) (map[string]string, error) {
+	err := d.EnsureSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := d.db.QueryContext(ctx, selectAllSettingsSQL)
+	if err != nil {
+		return nil, fmt.Errorf("list settings: %w", err)
+	}
+	defer rows.Close()
+
+	settings := make(map[string]string)
+
+	for rows.Next() {
+		var key, value string
+
+		err = rows.Scan(&key, &value)
+		if err != nil {
+			return nil, fmt.Errorf("scan setting: %w", err)
+		}
+
+		settings[key] = value
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("list settings: %w", err)
+	}
+
+	return settings, nil
+}
+
+// ReplaceSettings makes the settings table equal to settings in one
+// transaction. Keys for which preserve returns true are left untouched.
+func (d *Database) ReplaceSettings(
+	ctx context.Context,
+	settings map[string]string,
+	preserve func(key string) bool,
+) error {
+	current, err := d.ListSettings(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := d.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	err = replaceSettingsTx(ctx, tx, current, settings, preserve)
+	if err != nil {
+		return stderrors.Join(err, tx.Rollback())
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("commit settings: %w", err)
+	}
+
+	return nil
+}
+
+func replaceSettingsTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	current, settings map[string]string,
+	preserve func(key string) bool,
+) error {
+	for key := range current {
+		if _, keep := settings[key]; keep || preserve(key) {
+			continue
+		}
+
+		_, err := tx.ExecContext(ctx, deleteSettingSQL, key)
+		if err != nil {
+			return fmt.Errorf("delete setting %s: %w", key, err)
+		}
+	}
+
+	for key, value := range settings {
+		if preserve(key) {
+			continue
+		}
+
+		_, err := tx.ExecContext(ctx, upsertSettingSQL, key, value)
+		if err != nil {
+			return fmt.Errorf("set setting %s: %w", key, err)
+		}
 	}
 
-	return &Database{db: db}, nil
+	return nil
 }
@@ -0,0 +1,237 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/LarsArtmann/template-arch-lint/pkg/errors"
+)
+
+// AllowDangerousTestDBEnv is the opt-in switch that lets test helpers run against
+// a database that is neither in-memory nor located in a temporary directory.
+const AllowDangerousTestDBEnv = "ALLOW_DANGEROUS_TEST_DB"
+
+// EnvironmentSettingKey is the settings key holding the environment marker.
+const EnvironmentSettingKey = "environment"
+
+// Environment names recognised by the database guardrails.
+const (
+	EnvironmentDevelopment = "development"
+	EnvironmentProduction  = "production"
+)
+
+// networkDSNMarkers identify DSNs that point at a database server rather than a local file.
+var networkDSNMarkers = []string{
+	"postgres://", "postgresql://", "mysql://", "tcp(", "@tcp(", "host=", "sqlserver://",
+}
+
+// CheckTestDSN refuses DSNs that could point at shared data.
+// Only in-memory SQLite and files below the OS temp directory are accepted,
+// unless ALLOW_DANGEROUS_TEST_DB=1 is set explicitly.
+func CheckTestDSN(dsn string) error {
+	if os.Getenv(AllowDangerousTestDBEnv) == "1" {
+		return nil
+	}
+
+	if IsEphemeralDSN(dsn) {
+		return nil
+	}
+
+	return errors.NewConfigurationError(
+		"database.dsn",
+		fmt.Sprintf(
+			"refusing to run tests against %q: use :memory: or a temp file, or set %s=1",
+			redactDSN(dsn),
+			AllowDangerousTestDBEnv,
+		),
+	)
+}
+
+// IsEphemeralDSN reports whether dsn is an in-memory SQLite database
+// or a file inside the OS temp directory.
+func IsEphemeralDSN(dsn string) bool {
+	trimmed := strings.TrimSpace(dsn)
+	if trimmed == "" || isNetworkDSN(trimmed) {
+		return false
+	}
+
+	if trimmed == ":memory:" || strings.HasPrefix(trimmed, "file::memory:") {
+		return true
+	}
+
+	path := strings.TrimPrefix(trimmed, "file:")
+	path, rawQuery, _ := strings.Cut(path, "?")
+
+	query, err := url.ParseQuery(rawQuery)
+	if err == nil && query.Get("mode") == "memory" {
+		return true
+	}
+
+	return isBelowTempDir(path)
+}
+
+func isNetworkDSN(dsn string) bool {
+	lower := strings.ToLower(dsn)
+	for _, marker := range networkDSNMarkers {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isBelowTempDir(path string) bool {
+	if path == "" {
+		return false
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+
+	tempDir, err := filepath.Abs(os.TempDir())
+	if err != nil {
+		return false
+	}
+
+	rel, err := filepath.Rel(tempDir, absPath)
+	if err != nil {
+		return false
+	}
+
+	return rel != "." && !strings.HasPrefix(rel, "..")
+}
+
+// passwordKeyword matches the password of a keyword/value DSN such as
+// "host=db password=secret", quoted or not, and of a URL query.
+var passwordKeyword = regexp.MustCompile(`(?i)(\bpassword\s*=\s*)('(?:[^'\\]|\\.)*'|[^\s&;]*)`)
+
+// redactDSN strips credentials so refusals can be logged safely: password
+// keywords, the user info of URLs and the user:password@ prefix of MySQL
+// DSNs.
+func redactDSN(dsn string) string {
+	dsn = passwordKeyword.ReplaceAllString(dsn, "${1}***")
+
+	if scheme, rest, ok := strings.Cut(dsn, "://"); ok {
+		if at := strings.LastIndex(rest, "@"); at >= 0 {
+			return scheme + "://***@" + rest[at+1:]
+		}
+
+		return dsn
+	}
+
+	if creds, target, ok := strings.Cut(dsn, "@"); ok && !strings.ContainsAny(creds, " =") {
+		return "***@" + target
+	}
+
+	return dsn
+}
+
+// DestructiveOperation names an operation that can lose data.
+type DestructiveOperation string
+
+// Destructive operations guarded by ConfirmDestructive.
+const (
+	OperationDelete      DestructiveOperation = "delete"
+	OperationBulk        DestructiveOperation = "bulk"
+	OperationSeed        DestructiveOperation = "seed"
+	OperationMigrateDown DestructiveOperation = "migrate-down"
+)
+
+// ConfirmDestructive decides whether op may run against a database stamped with env.
+// Development databases never need confirmation. Every other environment requires
+// either assumeYes or a confirmation matching the environment name, and
+// down-migrations are refused in production even with assumeYes.
+func ConfirmDestructive(op DestructiveOperation, env, confirmation string, assumeYes bool) error {
+	if op == OperationMigrateDown && env == EnvironmentProduction {
+		return errors.NewConfigurationError(
+			"environment",
+			"down-migrations are not allowed against a production database",
+		)
+	}
+
+	if env == EnvironmentDevelopment || assumeYes {
+		return nil
+	}
+
+	if strings.TrimSpace(confirmation) != env {
+		return errors.NewValidationError(
+			"confirmation",
+			fmt.Sprintf("%s against %q requires typing the environment name to confirm", op, env),
+		)
+	}
+
+	return nil
+}
+
+// SettingsStore is the key/value store holding the environment marker.
+// Database implements it on top of the settings table.
+type SettingsStore interface {
+	GetSetting(ctx context.Context, key string) (string, bool, error)
+	SetSettingIfAbsent(ctx context.Context, key, value string) (string, error)
+}
+
+// EnvironmentMarker stamps and reads the environment a database belongs to.
+// The value is cached after the first lookup because it never changes once written.
+type EnvironmentMarker struct {
+	store SettingsStore
+
+	mu     sync.Mutex
+	cached string
+}
+
+// NewEnvironmentMarker creates a marker backed by store.
+func NewEnvironmentMarker(store SettingsStore) *EnvironmentMarker {
+	return &EnvironmentMarker{store: store} //nolint:exhaustruct // mu and cached have valid zero values
+}
+
+// Stamp writes env as the database environment on first boot.
+// An existing marker is never overwritten; the stamped value is returned.
+func (m *EnvironmentMarker) Stamp(ctx context.Context, env string) (string, error) {
+	if env == "" {
+		return "", errors.NewRequiredFieldError("environment")
+	}
+
+	stamped, err := m.store.SetSettingIfAbsent(ctx, EnvironmentSettingKey, env)
+	if err != nil {
+		return "", fmt.Errorf("stamp environment %s: %w", env, err)
+	}
+
+	m.mu.Lock()
+	m.cached = stamped
+	m.mu.Unlock()
+
+	return stamped, nil
+}
+
+// Environment returns the stamped environment.
+// Databases that were never stamped are treated as production so guards fail closed.
+func (m *EnvironmentMarker) Environment(ctx context.Context) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.cached != "" {
+		return m.cached, nil
+	}
+
+	env, found, err := m.store.GetSetting(ctx, EnvironmentSettingKey)
+	if err != nil {
+		return "", fmt.Errorf("read environment marker: %w", err)
+	}
+
+	if !found {
+		return EnvironmentProduction, nil
+	}
+
+	m.cached = env
+
+	return env, nil
+}
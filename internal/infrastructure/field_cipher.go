@@ -0,0 +1,220 @@
+package infrastructure
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/LarsArtmann/template-arch-lint/pkg/errors"
+)
+
+// encryptedFieldPrefix marks a column value written by FieldCipher.
+// Values without it are treated as legacy plaintext so encryption can be
+// enabled on an existing database and rows migrate as they are rewritten.
+const encryptedFieldPrefix = "enc:v1:"
+
+// fieldKeySize is the AES-256 key length in bytes.
+const fieldKeySize = 32
+
+// FieldCipher encrypts PII column values with AES-256-GCM.
+// Every value carries the ID of the key it was encrypted with, so several
+// keys can be active during a rotation.
+type FieldCipher struct {
+	activeKeyID string
+	aeads       map[string]cipher.AEAD
+	indexKey    []byte
+}
+
+// NewFieldCipher creates a cipher that encrypts with activeKeyID and can
+// decrypt with any key in keys. indexKey is the HMAC key for blind indexes.
+func NewFieldCipher(activeKeyID string, keys map[string][]byte, indexKey []byte) (*FieldCipher, error) {
+	if _, ok := keys[activeKeyID]; !ok {
+		return nil, errors.NewConfigurationError(
+			"database.encryption.active_key_id",
+			fmt.Sprintf("encryption key %q is not available from the secrets provider", activeKeyID),
+		)
+	}
+
+	if len(indexKey) < fieldKeySize {
+		return nil, errors.NewConfigurationError(
+			"database.encryption.index_key",
+			fmt.Sprintf("blind index key must be at least %d bytes", fieldKeySize),
+		)
+	}
+
+	aeads := make(map[string]cipher.AEAD, len(keys))
+
+	for keyID, key := range keys {
+		if strings.Contains(keyID, ":") {
+			return nil, errors.NewConfigurationError("database.encryption.keys", "key IDs must not contain ':'")
+		}
+
+		if len(key) != fieldKeySize {
+			return nil, errors.NewConfigurationError(
+				"database.encryption.keys",
+				fmt.Sprintf("encryption key %q must be %d bytes, got %d", keyID, fieldKeySize, len(key)),
+			)
+		}
+
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, errors.NewInternalError("failed to create AES cipher", err)
+		}
+
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, errors.NewInternalError("failed to create GCM cipher", err)
+		}
+
+		aeads[keyID] = aead
+	}
+
+	return &FieldCipher{activeKeyID: activeKeyID, aeads: aeads, indexKey: indexKey}, nil
+}
+
+// ActiveKeyID returns the ID of the key used for new encryptions.
+func (c *FieldCipher) ActiveKeyID() string {
+	return c.activeKeyID
+}
+
+// Encrypt encrypts plaintext under the active key with a random nonce.
+func (c *FieldCipher) Encrypt(plaintext string) (string, error) {
+	aead := c.aeads[c.activeKeyID]
+
+	nonce := make([]byte, aead.NonceSize())
+
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return "", errors.NewInternalError("failed to generate nonce", err)
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), []byte(c.activeKeyID))
+
+	return encryptedFieldPrefix + c.activeKeyID + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt returns the plaintext of value. Legacy plaintext is returned unchanged.
+func (c *FieldCipher) Decrypt(value string) (string, error) {
+	keyID, payload, encrypted := splitEncryptedField(value)
+	if !encrypted {
+		return value, nil
+	}
+
+	aead, ok := c.aeads[keyID]
+	if !ok {
+		return "", errors.NewConfigurationError(
+			"database.encryption.keys",
+			fmt.Sprintf("value was encrypted with key %q, which is not loaded", keyID),
+		)
+	}
+
+	sealed, err := base64.RawStdEncoding.DecodeString(payload)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", errors.NewInternalError("malformed encrypted field", err)
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(keyID))
+	if err != nil {
+		return "", errors.NewInternalError("failed to decrypt field", err)
+	}
+
+	return string(plaintext), nil
+}
+
+// NeedsRotation reports whether value is plaintext or encrypted with a non-active key.
+func (c *FieldCipher) NeedsRotation(value string) bool {
+	keyID, _, encrypted := splitEncryptedField(value)
+
+	return !encrypted || keyID != c.activeKeyID
+}
+
+// Reencrypt decrypts value and encrypts it again under the active key.
+func (c *FieldCipher) Reencrypt(value string) (string, error) {
+	plaintext, err := c.Decrypt(value)
+	if err != nil {
+		return "", err
+	}
+
+	return c.Encrypt(plaintext)
+}
+
+// BlindIndex returns a deterministic HMAC of a normalized value.
+// It is stored next to the ciphertext so equality lookups and unique
+// constraints keep working without decrypting.
+func (c *FieldCipher) BlindIndex(value string) string {
+	mac := hmac.New(sha256.New, c.indexKey)
+	mac.Write([]byte(strings.ToLower(strings.TrimSpace(value))))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func splitEncryptedField(value string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(value, encryptedFieldPrefix)
+	if !ok {
+		return "", "", false
+	}
+
+	keyID, payload, ok := strings.Cut(rest, ":")
+
+	return keyID, payload, ok
+}
+
+// EncryptedRow is a row with encrypted columns keyed by column name.
+type EncryptedRow struct {
+	ID     string
+	Fields map[string]string
+}
+
+// EncryptedRowStore gives the rotation job batch access to encrypted rows.
+type EncryptedRowStore interface {
+	// RowsNotOnKey returns up to limit rows with at least one field not encrypted under keyID.
+	RowsNotOnKey(ctx context.Context, keyID string, limit int) ([]EncryptedRow, error)
+	UpdateRow(ctx context.Context, row EncryptedRow) error
+}
+
+// RotateKeys re-encrypts rows in batches until none remain on an old key.
+// Both the old and new keys must be loaded while it runs. It returns the
+// number of rows rewritten.
+func (c *FieldCipher) RotateKeys(ctx context.Context, store EncryptedRowStore, batchSize int) (int, error) {
+	rotated := 0
+
+	for {
+		rows, err := store.RowsNotOnKey(ctx, c.activeKeyID, batchSize)
+		if err != nil {
+			return rotated, errors.NewDatabaseError("list rows for key rotation", err, true)
+		}
+
+		if len(rows) == 0 {
+			return rotated, nil
+		}
+
+		for _, row := range rows {
+			for column, value := range row.Fields {
+				if !c.NeedsRotation(value) {
+					continue
+				}
+
+				row.Fields[column], err = c.Reencrypt(value)
+				if err != nil {
+					return rotated, err
+				}
+			}
+
+			err = store.UpdateRow(ctx, row)
+			if err != nil {
+				return rotated, errors.NewDatabaseError("update rotated row", err, true)
+			}
+
+			rotated++
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"maps"
+	"strings"
+	"testing"
+
+	"github.com/LarsArtmann/template-arch-lint/pkg/errors"
+)
+
+var (
+	testKeyOld   = bytes.Repeat([]byte{1}, fieldKeySize)
+	testKeyNew   = bytes.Repeat([]byte{2}, fieldKeySize)
+	testIndexKey = bytes.Repeat([]byte{3}, fieldKeySize)
+)
+
+func newTestCipher(t *testing.T, activeKeyID string) *FieldCipher {
+	t.Helper()
+
+	fieldCipher, err := NewFieldCipher(
+		activeKeyID,
+		map[string][]byte{"k1": testKeyOld, "k2": testKeyNew},
+		testIndexKey,
+	)
+	if err != nil {
+		t.Fatalf("NewFieldCipher() failed: %v", err)
+	}
+
+	return fieldCipher
+}
+
+// fakeRowStore holds encrypted rows in memory for the rotation job.
+type fakeRowStore struct {
+	rows map[string]EncryptedRow
+}
+
+func (s *fakeRowStore) RowsNotOnKey(_ context.Context, keyID string, limit int) ([]EncryptedRow, error) {
+	var batch []EncryptedRow
+
+	for _, row := range s.rows {
+		for _, value := range row.Fields {
+			if !strings.HasPrefix(value, encryptedFieldPrefix+keyID+":") {
+				batch = append(batch, EncryptedRow{ID: row.ID, Fields: maps.Clone(row.Fields)})
+
+				break
+			}
+		}
+
+		if len(batch) == limit {
+			break
+		}
+	}
+
+	return batch, nil
+}
+
+func (s *fakeRowStore) UpdateRow(_ context.Context, row EncryptedRow) error {
+	s.rows[row.ID] = row
+
+	return nil
+}
+
+func TestFieldCipherRoundTrip(t *testing.T) {
+	fieldCipher := newTestCipher(t, "k1")
+
+	encrypted, err := fieldCipher.Encrypt("jane@example.com")
+	if err != nil {
+		t.Fatalf("Encrypt() failed: %v", err)
+	}
+
+	if strings.Contains(encrypted, "jane") || !strings.HasPrefix(encrypted, "enc:v1:k1:") {
+		t.Errorf("unexpected ciphertext format %q", encrypted)
+	}
+
+	again, _ := fieldCipher.Encrypt("jane@example.com")
+	if again == encrypted {
+		t.Error("expected a fresh nonce per encryption")
+	}
+
+	decrypted, err := fieldCipher.Decrypt(encrypted)
+	if err != nil {
+		t.Fatalf("Decrypt() failed: %v", err)
+	}
+
+	if decrypted != "jane@example.com" {
+		t.Errorf("Decrypt() = %q", decrypted)
+	}
+
+	plaintext, err := fieldCipher.Decrypt("legacy@example.com")
+	if err != nil || plaintext != "legacy@example.com" {
+		t.Errorf("legacy plaintext should pass through, got %q, %v", plaintext, err)
+	}
+}
+
+func TestFieldCipherRejectsTamperedKeyID(t *testing.T) {
+	fieldCipher := newTestCipher(t, "k1")
+
+	encrypted, _ := fieldCipher.Encrypt("jane@example.com")
+	tampered := strings.Replace(encrypted, ":k1:", ":k2:", 1)
+
+	_, err := fieldCipher.Decrypt(tampered)
+	if err == nil {
+		t.Error("expected authentication failure when the key ID is swapped")
+	}
+}
+
+func TestFieldCipherBlindIndex(t *testing.T) {
+	fieldCipher := newTestCipher(t, "k1")
+
+	if fieldCipher.BlindIndex("Jane@Example.com ") != fieldCipher.BlindIndex("jane@example.com") {
+		t.Error("blind index should normalize case and whitespace")
+	}
+
+	if fieldCipher.BlindIndex("jane@example.com") == fieldCipher.BlindIndex("john@example.com") {
+		t.Error("different values must produce different blind indexes")
+	}
+
+	rotated := newTestCipher(t, "k2")
+	if rotated.BlindIndex("jane@example.com") != fieldCipher.BlindIndex("jane@example.com") {
+		t.Error("blind index must not depend on the active encryption key")
+	}
+}
+
+func TestFieldCipherRotationLeavesNoRowsOnOldKey(t *testing.T) {
+	ctx := context.Background()
+	oldCipher := newTestCipher(t, "k1")
+	store := &fakeRowStore{rows: make(map[string]EncryptedRow)}
+
+	for _, id := range []string{"a", "b", "c", "d", "e"} {
+		email, _ := oldCipher.Encrypt(id + "@example.com")
+		store.rows[id] = EncryptedRow{ID: id, Fields: map[string]string{"email": email, "name": "plain " + id}}
+	}
+
+	newCipher := newTestCipher(t, "k2")
+
+	rotated, err := newCipher.RotateKeys(ctx, store, 2)
+	if err != nil {
+		t.Fatalf("RotateKeys() failed: %v", err)
+	}
+
+	if rotated != 5 {
+		t.Errorf("RotateKeys() rotated %d rows, want 5", rotated)
+	}
+
+	remaining, _ := store.RowsNotOnKey(ctx, "k2", 100)
+	if len(remaining) != 0 {
+		t.Errorf("%d rows still on an old key", len(remaining))
+	}
+
+	decrypted, err := newCipher.Decrypt(store.rows["c"].Fields["email"])
+	if err != nil || decrypted != "c@example.com" {
+		t.Errorf("rotated value decrypts to %q, %v", decrypted, err)
+	}
+}
+
+func TestNewFieldCipherMissingKey(t *testing.T) {
+	_, err := NewFieldCipher("k9", map[string][]byte{"k1": testKeyOld}, testIndexKey)
+
+	configErr, ok := errors.AsConfigurationError(err)
+	if !ok {
+		t.Fatalf("expected configuration error, got %v", err)
+	}
+
+	if !strings.Contains(configErr.Error(), `"k9"`) {
+		t.Errorf("error should name the missing key: %v", configErr)
+	}
+}
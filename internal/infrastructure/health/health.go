@@ -0,0 +1,173 @@
+// Package health serves the liveness and readiness probes. Components that
+// the server cannot work without register a Checker, and readiness fails
+// while any critical check fails or the server is shutting down.
+package health
+
+import (
+	"context"
+	"encoding/json/v2"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"charm.land/log/v2"
+	"github.com/larsartmann/httputil"
+)
+
+// Checker probes one dependency.
+type Checker interface {
+	// Name keys the check in the readiness response.
+	Name() string
+	// Check returns nil when the dependency is usable. ctx carries the
+	// registry's check timeout.
+	Check(ctx context.Context) error
+}
+
+// CheckResult is one check in the readiness response. Causes are logged,
+// not returned, because driver errors may name hosts or users.
+type CheckResult struct {
+	Status   httputil.HealthStatus `json:"status"`
+	Critical bool                  `json:"critical"`
+}
+
+// ReadinessResponse is the body of the readiness probe.
+type ReadinessResponse struct {
+	httputil.HealthResponse
+
+	ShuttingDown bool                   `json:"shutting_down,omitzero"`
+	Checks       map[string]CheckResult `json:"checks"`
+}
+
+type registration struct {
+	checker  Checker
+	critical bool
+}
+
+// Registry holds the registered checks and the shutdown state.
+type Registry struct {
+	timeout      time.Duration
+	shuttingDown atomic.Bool
+
+	mu     sync.RWMutex
+	checks []registration
+}
+
+// NewRegistry creates an empty registry whose checks each get timeout.
+func NewRegistry(timeout time.Duration) *Registry {
+	return &Registry{timeout: timeout} //nolint:exhaustruct // checks are registered later
+}
+
+// Register adds a critical check: readiness fails while it fails.
+func (r *Registry) Register(checker Checker) {
+	r.add(checker, true)
+}
+
+// RegisterOptional adds a check that is reported but does not fail readiness.
+func (r *Registry) RegisterOptional(checker Checker) {
+	r.add(checker, false)
+}
+
+func (r *Registry) add(checker Checker, critical bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.checks = append(r.checks, registration{checker: checker, critical: critical})
+}
+
+// SetShuttingDown fails both probes from now on, so load balancers stop
+// routing to the server while it drains.
+func (r *Registry) SetShuttingDown() {
+	r.shuttingDown.Store(true)
+}
+
+// Ready runs every check concurrently and reports whether the server may
+// receive traffic.
+func (r *Registry) Ready(ctx context.Context) (bool, ReadinessResponse) {
+	r.mu.RLock()
+	checks := append([]registration(nil), r.checks...)
+	r.mu.RUnlock()
+
+	results := make([]CheckResult, len(checks))
+
+	var wg sync.WaitGroup
+
+	for i, reg := range checks {
+		wg.Go(func() {
+			results[i] = r.run(ctx, reg)
+		})
+	}
+
+	wg.Wait()
+
+	response := ReadinessResponse{
+		HealthResponse: httputil.HealthResponse{Status: httputil.HealthStatusUp},
+		ShuttingDown:   r.shuttingDown.Load(),
+		Checks:         make(map[string]CheckResult, len(checks)),
+	}
+	ready := !response.ShuttingDown
+
+	for i, reg := range checks {
+		response.Checks[reg.checker.Name()] = results[i]
+		if reg.critical && results[i].Status == httputil.HealthStatusDown {
+			ready = false
+		}
+	}
+
+	if !ready {
+		response.Status = httputil.HealthStatusDown
+	}
+
+	return ready, response
+}
+
+func (r *Registry) run(ctx context.Context, reg registration) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	result := CheckResult{Status: httputil.HealthStatusUp, Critical: reg.critical}
+
+	err := reg.checker.Check(ctx)
+	if err != nil {
+		log.FromContext(ctx).Warn("Health check failed", "check", reg.checker.Name(), "error", err)
+
+		result.Status = httputil.HealthStatusDown
+	}
+
+	return result
+}
+
+// LiveHandler serves the liveness probe: 200 while the process runs, 503
+// once shutdown has begun. It checks no dependencies, so a database outage
+// does not get the process restarted.
+func (r *Registry) LiveHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		status, body := http.StatusOK, httputil.HealthResponse{Status: httputil.HealthStatusUp}
+		if r.shuttingDown.Load() {
+			status, body = http.StatusServiceUnavailable, httputil.HealthResponse{Status: httputil.HealthStatusDown}
+		}
+
+		writeJSON(w, status, body)
+	}
+}
+
+// ReadyHandler serves the readiness probe: 200 with every check's status
+// when the server may receive traffic, 503 otherwise.
+func (r *Registry) ReadyHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		ready, response := r.Ready(req.Context())
+
+		status := http.StatusOK
+		if !ready {
+			status = http.StatusServiceUnavailable
+		}
+
+		writeJSON(w, status, response)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.MarshalWrite(w, body)
+}
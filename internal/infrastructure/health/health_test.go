@@ -0,0 +1,153 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json/v2"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/infrastructure/health"
+)
+
+type stubChecker struct {
+	name string
+	err  error
+}
+
+func (s stubChecker) Name() string                { return s.name }
+func (s stubChecker) Check(context.Context) error { return s.err }
+
+// slowChecker blocks until its context ends.
+type slowChecker struct{}
+
+func (slowChecker) Name() string { return "slow" }
+
+func (slowChecker) Check(ctx context.Context) error {
+	<-ctx.Done()
+
+	return ctx.Err()
+}
+
+func serveReady(t *testing.T, registry *health.Registry, wantStatus int) health.ReadinessResponse {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	registry.ReadyHandler()(w, httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/health/ready", nil))
+
+	if w.Code != wantStatus {
+		t.Fatalf("ready status = %d, want %d: %s", w.Code, wantStatus, w.Body.String())
+	}
+
+	var response health.ReadinessResponse
+
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		t.Fatalf("readiness response is not JSON: %v", err)
+	}
+
+	return response
+}
+
+func TestReadyReportsEachCheck(t *testing.T) {
+	failure := errors.New("connection refused")
+
+	tests := []struct {
+		name       string
+		database   error
+		cache      error
+		wantStatus int
+		wantDown   []string
+	}{
+		{name: "all up", wantStatus: http.StatusOK},
+		{name: "critical check down", database: failure, wantStatus: http.StatusServiceUnavailable,
+			wantDown: []string{"database"}},
+		{name: "optional check down", cache: failure, wantStatus: http.StatusOK, wantDown: []string{"cache"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := health.NewRegistry(time.Second)
+			registry.Register(stubChecker{name: "database", err: tt.database})
+			registry.RegisterOptional(stubChecker{name: "cache", err: tt.cache})
+
+			response := serveReady(t, registry, tt.wantStatus)
+
+			if len(response.Checks) != 2 || !response.Checks["database"].Critical || response.Checks["cache"].Critical {
+				t.Fatalf("checks = %+v, want a critical database and an optional cache", response.Checks)
+			}
+
+			for name, check := range response.Checks {
+				wantDown := false
+
+				for _, down := range tt.wantDown {
+					wantDown = wantDown || down == name
+				}
+
+				if (check.Status == "down") != wantDown {
+					t.Errorf("check %s status = %s, want down = %v", name, check.Status, wantDown)
+				}
+			}
+		})
+	}
+}
+
+func TestReadyDoesNotExposeCauses(t *testing.T) {
+	registry := health.NewRegistry(time.Second)
+	registry.Register(stubChecker{name: "database", err: errors.New("password authentication failed")})
+
+	w := httptest.NewRecorder()
+	registry.ReadyHandler()(w, httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/health/ready", nil))
+
+	if body := w.Body.String(); body == "" || strings.Contains(body, "password") {
+		t.Errorf("readiness body %q exposes the cause", body)
+	}
+}
+
+func TestReadyTimesOutSlowChecks(t *testing.T) {
+	registry := health.NewRegistry(50 * time.Millisecond)
+	registry.Register(slowChecker{})
+
+	start := time.Now()
+	response := serveReady(t, registry, http.StatusServiceUnavailable)
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("readiness took %s, want the check cut off at its timeout", elapsed)
+	}
+
+	if response.Checks["slow"].Status != "down" {
+		t.Errorf("slow check = %+v, want down", response.Checks["slow"])
+	}
+}
+
+func TestShuttingDownFailsBothProbes(t *testing.T) {
+	registry := health.NewRegistry(time.Second)
+	registry.Register(stubChecker{name: "database", err: nil})
+
+	live := func() int {
+		w := httptest.NewRecorder()
+		registry.LiveHandler()(w, httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/health/live", nil))
+
+		return w.Code
+	}
+
+	if code := live(); code != http.StatusOK {
+		t.Fatalf("live before shutdown = %d, want 200", code)
+	}
+
+	serveReady(t, registry, http.StatusOK)
+
+	registry.SetShuttingDown()
+
+	if code := live(); code != http.StatusServiceUnavailable {
+		t.Errorf("live during shutdown = %d, want 503", code)
+	}
+
+	response := serveReady(t, registry, http.StatusServiceUnavailable)
+	if !response.ShuttingDown || response.Checks["database"].Status != "up" {
+		t.Errorf("readiness during shutdown = %+v, want shutting down with the database still up", response)
+	}
+}
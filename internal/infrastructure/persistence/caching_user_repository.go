@@ -0,0 +1,476 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"charm.land/log/v2"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/entities"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/repositories"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/values"
+)
+
+var _ repositories.UserRepository = (*CachingUserRepository)(nil)
+
+// CachingUserRepository is a read-through cache in front of another
+// UserRepository. FindByID and FindByEmail are served from memory for ttl;
+// Save, SaveAll, Update and Delete evict the user under its ID and every
+// email it was cached by. FindByUsername, List, Count, Exists and the
+// lookups that include soft-deleted users always reach the wrapped
+// repository.
+//
+// Concurrent misses of the same key share one lookup of the wrapped
+// repository, and with WithNegativeTTL its errors are cached too, so a burst
+// of requests for one user reaches the database once.
+//
+// The cache is local to the process, so it only stays coherent when every
+// write goes through this instance. Cached lookups log at Debug through the
+// context logger, so request-scoped fields reach them.
+type CachingUserRepository struct {
+	next        repositories.UserRepository
+	ttl         time.Duration
+	jitter      float64
+	negativeTTL time.Duration
+	now         func() time.Time
+
+	mu      sync.Mutex
+	byID    map[values.UserID]cachedUser
+	byEmail map[string]values.UserID
+	// failed holds the cached errors by lookup key.
+	failed map[string]cachedError
+	// loads are the lookups in progress by lookup key.
+	loads map[string]*userLoad
+	// generation counts evictions. A lookup that started before an eviction
+	// must not store what it read, because the write may have made it stale.
+	generation uint64
+
+	hits, misses, negativeHits, sharedLoads atomic.Int64
+}
+
+// CacheStats counts the lookups of a CachingUserRepository. Misses are the
+// lookups that reached the wrapped repository; SharedLoads are the misses
+// that waited for another caller's lookup instead.
+type CacheStats struct {
+	Hits         int64 `json:"hits"`
+	Misses       int64 `json:"misses"`
+	NegativeHits int64 `json:"negative_hits"`
+	SharedLoads  int64 `json:"shared_loads"`
+}
+
+type cachedUser struct {
+	user    entities.User
+	email   string
+	expires time.Time
+}
+
+type cachedError struct {
+	err     error
+	expires time.Time
+}
+
+// userLoad is one lookup of the wrapped repository; done is closed once
+// user and err are set.
+type userLoad struct {
+	done chan struct{}
+	user *entities.User
+	err  error
+}
+
+// errLookupPanicked is returned to the callers that waited for a lookup
+// that panicked. The caller whose lookup panicked gets the panic itself.
+var errLookupPanicked = errors.New("user lookup panicked")
+
+// NewCachingUserRepository wraps next with a cache whose entries live for ttl.
+func NewCachingUserRepository(next repositories.UserRepository, ttl time.Duration) *CachingUserRepository {
+	return &CachingUserRepository{ //nolint:exhaustruct // counters start at zero
+		next:        next,
+		ttl:         ttl,
+		jitter:      0,
+		negativeTTL: 0,
+		now:         time.Now,
+		mu:          sync.Mutex{},
+		byID:        make(map[values.UserID]cachedUser),
+		byEmail:     make(map[string]values.UserID),
+		failed:      make(map[string]cachedError),
+		loads:       make(map[string]*userLoad),
+		generation:  0,
+	}
+}
+
+// WithJitter shortens each entry's lifetime by a random fraction of ttl up
+// to jitter, so entries cached together do not all expire together.
+func (r *CachingUserRepository) WithJitter(jitter float64) *CachingUserRepository {
+	r.jitter = min(max(jitter, 0), 1)
+
+	return r
+}
+
+// WithNegativeTTL caches the errors of lookups, such as a user that does
+// not exist, for ttl. Zero, the default, caches no errors. Cancellations
+// are never cached, since they say nothing about the user.
+func (r *CachingUserRepository) WithNegativeTTL(ttl time.Duration) *CachingUserRepository {
+	r.negativeTTL = ttl
+
+	return r
+}
+
+// Stats returns the lookup counters.
+func (r *CachingUserRepository) Stats() CacheStats {
+	return CacheStats{
+		Hits:         r.hits.Load(),
+		Misses:       r.misses.Load(),
+		NegativeHits: r.negativeHits.Load(),
+		SharedLoads:  r.sharedLoads.Load(),
+	}
+}
+
+// Save writes through to the wrapped repository and evicts the user.
+func (r *CachingUserRepository) Save(ctx context.Context, user *entities.User) error {
+	if user != nil {
+		defer r.evict(user.ID, user.GetEmail().String())
+	}
+
+	return r.next.Save(ctx, user)
+}
+
+// SaveAll writes through to the wrapped repository and evicts every user.
+func (r *CachingUserRepository) SaveAll(ctx context.Context, users []*entities.User) error {
+	defer func() {
+		for _, user := range users {
+			if user != nil {
+				r.evict(user.ID, user.GetEmail().String())
+			}
+		}
+	}()
+
+	return r.next.SaveAll(ctx, users)
+}
+
+// FindByID returns the cached user or loads and caches it.
+func (r *CachingUserRepository) FindByID(ctx context.Context, id values.UserID) (*entities.User, error) {
+	start := time.Now()
+
+	if user, ok := r.lookup(id); ok {
+		r.hits.Add(1)
+		logLookup(ctx, "find_by_id", id, true, start)
+
+		return user, nil
+	}
+
+	key := idKey(id)
+	if err := r.lookupError(key); err != nil {
+		logLookup(ctx, "find_by_id", id, true, start)
+
+		return nil, err
+	}
+
+	user, err := r.load(ctx, key, func(ctx context.Context) (*entities.User, error) {
+		return r.next.FindByID(ctx, id)
+	})
+	logLookup(ctx, "find_by_id", id, false, start)
+
+	return user, err
+}
+
+// FindByIDIncludingDeleted bypasses the cache.
+func (r *CachingUserRepository) FindByIDIncludingDeleted(
+	ctx context.Context,
+	id values.UserID,
+) (*entities.User, error) {
+	return r.next.FindByIDIncludingDeleted(ctx, id)
+}
+
+// FindByEmail returns the cached user or loads and caches it.
+func (r *CachingUserRepository) FindByEmail(ctx context.Context, email values.Email) (*entities.User, error) {
+	start := time.Now()
+
+	r.mu.Lock()
+	id, indexed := r.byEmail[email.String()]
+	r.mu.Unlock()
+
+	if indexed {
+		if user, ok := r.lookup(id); ok && user.GetEmail() == email {
+			r.hits.Add(1)
+			logLookup(ctx, "find_by_email", id, true, start)
+
+			return user, nil
+		}
+	}
+
+	key := emailKey(email.String())
+	if err := r.lookupError(key); err != nil {
+		logLookup(ctx, "find_by_email", values.UserID{}, true, start)
+
+		return nil, err
+	}
+
+	user, err := r.load(ctx, key, func(ctx context.Context) (*entities.User, error) {
+		return r.next.FindByEmail(ctx, email)
+	})
+	if err != nil {
+		logLookup(ctx, "find_by_email", values.UserID{}, false, start)
+
+		return nil, err
+	}
+
+	logLookup(ctx, "find_by_email", user.ID, false, start)
+
+	return user, nil
+}
+
+// Update writes through to the wrapped repository and evicts the user.
+func (r *CachingUserRepository) Update(ctx context.Context, user *entities.User) error {
+	if user != nil {
+		defer r.evict(user.ID, user.GetEmail().String())
+	}
+
+	return r.next.Update(ctx, user)
+}
+
+// FindByUsername bypasses the cache.
+func (r *CachingUserRepository) FindByUsername(ctx context.Context, username string) (*entities.User, error) {
+	return r.next.FindByUsername(ctx, username)
+}
+
+// Delete removes the user from the wrapped repository and evicts it.
+func (r *CachingUserRepository) Delete(ctx context.Context, id values.UserID) error {
+	defer r.evict(id, "")
+
+	return r.next.Delete(ctx, id)
+}
+
+// List bypasses the cache.
+func (r *CachingUserRepository) List(ctx context.Context) ([]*entities.User, error) {
+	return r.next.List(ctx)
+}
+
+// FindPage bypasses the cache.
+func (r *CachingUserRepository) FindPage(
+	ctx context.Context,
+	query repositories.UserPageQuery,
+) (repositories.UserPage, error) {
+	return r.next.FindPage(ctx, query)
+}
+
+// Search bypasses the cache.
+func (r *CachingUserRepository) Search(ctx context.Context, query string, limit int) ([]*entities.User, error) {
+	return r.next.Search(ctx, query, limit)
+}
+
+// FindAllIter bypasses the cache.
+func (r *CachingUserRepository) FindAllIter(ctx context.Context, fn func(*entities.User) error) error {
+	return r.next.FindAllIter(ctx, fn)
+}
+
+// ListDeleted bypasses the cache.
+func (r *CachingUserRepository) ListDeleted(ctx context.Context) ([]*entities.User, error) {
+	return r.next.ListDeleted(ctx)
+}
+
+// Count bypasses the cache.
+func (r *CachingUserRepository) Count(ctx context.Context) (int, error) {
+	return r.next.Count(ctx)
+}
+
+// Exists bypasses the cache.
+func (r *CachingUserRepository) Exists(ctx context.Context, id values.UserID) (bool, error) {
+	return r.next.Exists(ctx, id)
+}
+
+// load runs fetch for key unless a lookup of key is already in progress,
+// in which case it waits for that lookup's result instead. A waiter whose
+// ctx ends stops waiting; the lookup itself runs with the context of the
+// caller that started it. A panicking fetch panics in that caller, fails
+// the waiters with errLookupPanicked and caches nothing.
+func (r *CachingUserRepository) load(
+	ctx context.Context, key string, fetch func(context.Context) (*entities.User, error),
+) (*entities.User, error) {
+	r.mu.Lock()
+
+	if call, ok := r.loads[key]; ok {
+		r.mu.Unlock()
+		r.sharedLoads.Add(1)
+
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		if call.err != nil || call.user == nil {
+			return nil, call.err
+		}
+
+		user := *call.user
+
+		return &user, nil
+	}
+
+	call := &userLoad{done: make(chan struct{}), user: nil, err: nil}
+	r.loads[key] = call
+	generation := r.generation
+	r.mu.Unlock()
+
+	r.misses.Add(1)
+
+	completed := false
+
+	defer func() {
+		if !completed {
+			call.err = fmt.Errorf("%w: %s", errLookupPanicked, key)
+		}
+
+		r.finish(key, call, generation, completed)
+	}()
+
+	// The waiters and the cache copy call.user after this caller has
+	// returned, so it gets a private copy the caller cannot mutate.
+	user, err := fetch(ctx)
+	call.err = err
+
+	if user != nil {
+		shared := *user
+		call.user = &shared
+	}
+
+	completed = true
+
+	return user, err
+}
+
+// finish ends the lookup of key and, unless it panicked or an eviction
+// happened meanwhile, caches its user or error.
+func (r *CachingUserRepository) finish(key string, call *userLoad, generation uint64, cache bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	defer close(call.done)
+
+	delete(r.loads, key)
+
+	if !cache || r.generation != generation {
+		return
+	}
+
+	switch {
+	case call.err == nil && call.user != nil:
+		r.storeLocked(call.user)
+	case call.err != nil && r.negativeTTL > 0 && !isCancellation(call.err):
+		r.failed[key] = cachedError{err: call.err, expires: r.now().Add(r.negativeTTL)}
+	}
+}
+
+func isCancellation(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+func idKey(id values.UserID) string {
+	return "id:" + id.String()
+}
+
+func emailKey(email string) string {
+	return "email:" + email
+}
+
+// logLookup logs a cached lookup of the user id, which is the zero ID when
+// an email matched nobody.
+func logLookup(ctx context.Context, operation string, id values.UserID, hit bool, start time.Time) {
+	log.FromContext(ctx).Debug("User cache lookup",
+		"operation", operation,
+		"user_id", id.String(),
+		"cache_hit", hit,
+		"duration_ms", float64(time.Since(start))/float64(time.Millisecond),
+	)
+}
+
+// lookup returns a copy of the cached user so callers cannot change the
+// cached entity. User holds only values, so a struct copy is a deep copy.
+func (r *CachingUserRepository) lookup(id values.UserID) (*entities.User, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.byID[id]
+	if !ok {
+		return nil, false
+	}
+
+	if !r.now().Before(entry.expires) {
+		r.remove(id)
+
+		return nil, false
+	}
+
+	user := entry.user
+
+	return &user, true
+}
+
+// lookupError returns the cached error of the lookup key, or nil.
+func (r *CachingUserRepository) lookupError(key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.failed[key]
+	if !ok {
+		return nil
+	}
+
+	if !r.now().Before(entry.expires) {
+		delete(r.failed, key)
+
+		return nil
+	}
+
+	r.negativeHits.Add(1)
+
+	return entry.err
+}
+
+// storeLocked caches user for ttl less the jitter. r.mu must be held.
+func (r *CachingUserRepository) storeLocked(user *entities.User) {
+	r.remove(user.ID)
+
+	ttl := r.ttl
+	if r.jitter > 0 {
+		ttl -= time.Duration(rand.Float64() * r.jitter * float64(ttl)) //nolint:gosec // jitter needs no crypto
+	}
+
+	email := user.GetEmail().String()
+	r.byID[user.ID] = cachedUser{user: *user, email: email, expires: r.now().Add(ttl)}
+	r.byEmail[email] = user.ID
+}
+
+// evict drops the user cached under id and the email key it was cached by,
+// plus email itself, which after a Save may be a key it was never cached
+// under yet.
+func (r *CachingUserRepository) evict(id values.UserID, email string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.generation++
+	r.remove(id)
+	delete(r.failed, idKey(id))
+	delete(r.failed, emailKey(email))
+
+	if cachedID, ok := r.byEmail[email]; ok {
+		r.remove(cachedID)
+	}
+}
+
+// remove drops the entry for id and its email index. r.mu must be held.
+func (r *CachingUserRepository) remove(id values.UserID) {
+	entry, ok := r.byID[id]
+	if !ok {
+		return
+	}
+
+	delete(r.byID, id)
+
+	if r.byEmail[entry.email] == id {
+		delete(r.byEmail, entry.email)
+	}
+}
@@ -0,0 +1,529 @@
+package persistence
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"charm.land/log/v2"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/entities"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/ids"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/repositories"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/values"
+	"github.com/LarsArtmann/template-arch-lint/internal/testhelpers/base"
+	repotesting "github.com/LarsArtmann/template-arch-lint/internal/testhelpers/domain/repositories"
+	valuestesting "github.com/LarsArtmann/template-arch-lint/internal/testhelpers/domain/values"
+)
+
+// countingRepository counts the lookups that reach the wrapped repository.
+type countingRepository struct {
+	repositories.UserRepository
+
+	lookups atomic.Int64
+}
+
+func (r *countingRepository) FindByID(ctx context.Context, id values.UserID) (*entities.User, error) {
+	r.lookups.Add(1)
+
+	return r.UserRepository.FindByID(ctx, id)
+}
+
+func (r *countingRepository) FindByEmail(ctx context.Context, email values.Email) (*entities.User, error) {
+	r.lookups.Add(1)
+
+	return r.UserRepository.FindByEmail(ctx, email)
+}
+
+func newCountingCache(t *testing.T) (*CachingUserRepository, *countingRepository, *entities.User) {
+	t.Helper()
+
+	counting := &countingRepository{ //nolint:exhaustruct // zero counter
+		UserRepository: repositories.NewInMemoryUserRepository(),
+	}
+	cache := NewCachingUserRepository(counting, time.Minute)
+
+	user, err := entities.NewUser(ids.MustGenerateUserID(), "cached@example.com", "cacheduser", time.Now())
+	if err != nil {
+		t.Fatalf("NewUser(time.Now()) error = %v", err)
+	}
+
+	err = cache.Save(t.Context(), user)
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	return cache, counting, user
+}
+
+func TestCachingUserRepositoryContract(t *testing.T) {
+	repotesting.RunUserRepositoryContract(t, func() repositories.UserRepository {
+		return NewCachingUserRepository(repositories.NewInMemoryUserRepository(), time.Minute)
+	})
+}
+
+func TestCachingUserRepositoryNegativeCacheContract(t *testing.T) {
+	repotesting.RunUserRepositoryContract(t, func() repositories.UserRepository {
+		return NewCachingUserRepository(repositories.NewInMemoryUserRepository(), time.Minute).
+			WithNegativeTTL(time.Minute)
+	})
+}
+
+func TestCachingUserRepositoryHitsAndMisses(t *testing.T) {
+	cache, counting, user := newCountingCache(t)
+
+	for range 3 {
+		_, err := cache.FindByID(t.Context(), user.ID)
+		if err != nil {
+			t.Fatalf("FindByID() error = %v", err)
+		}
+	}
+
+	_, err := cache.FindByEmail(t.Context(), valuestesting.MustEmail(t, "cached@example.com"))
+	if err != nil {
+		t.Fatalf("FindByEmail() error = %v", err)
+	}
+
+	if got := counting.lookups.Load(); got != 1 {
+		t.Errorf("lookups reaching the repository = %d, want 1", got)
+	}
+
+	_, err = cache.FindByEmail(t.Context(), valuestesting.MustEmail(t, "missing@example.com"))
+	if err == nil {
+		t.Fatal("FindByEmail() found a user that does not exist")
+	}
+
+	_, err = cache.FindByEmail(t.Context(), valuestesting.MustEmail(t, "missing@example.com"))
+	if err == nil || counting.lookups.Load() != 3 {
+		t.Errorf("misses are cached: lookups = %d, want 3", counting.lookups.Load())
+	}
+}
+
+func TestCachingUserRepositoryExpiresEntries(t *testing.T) {
+	cache, counting, user := newCountingCache(t)
+
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	_, _ = cache.FindByID(t.Context(), user.ID)
+	now = now.Add(time.Minute)
+	_, _ = cache.FindByID(t.Context(), user.ID)
+
+	if got := counting.lookups.Load(); got != 2 {
+		t.Errorf("lookups = %d, want the expired entry reloaded", got)
+	}
+}
+
+func TestCachingUserRepositoryEvictsOldEmailOnUpdate(t *testing.T) {
+	cache, _, user := newCountingCache(t)
+
+	cached, err := cache.FindByEmail(t.Context(), valuestesting.MustEmail(t, "cached@example.com"))
+	if err != nil {
+		t.Fatalf("FindByEmail() error = %v", err)
+	}
+
+	err = cached.SetEmail("moved@example.com", time.Now())
+	if err != nil {
+		t.Fatalf("SetEmail() error = %v", err)
+	}
+
+	err = cache.Save(t.Context(), cached)
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	_, err = cache.FindByEmail(t.Context(), valuestesting.MustEmail(t, "cached@example.com"))
+	if err == nil {
+		t.Error("old email still finds the user after the email changed")
+	}
+
+	moved, err := cache.FindByID(t.Context(), user.ID)
+	if err != nil || moved.GetEmail().String() != "moved@example.com" {
+		t.Errorf("FindByID() = %v, %v, want the new email", moved, err)
+	}
+}
+
+func TestCachingUserRepositoryEvictsOnDelete(t *testing.T) {
+	cache, _, user := newCountingCache(t)
+
+	_, _ = cache.FindByID(t.Context(), user.ID)
+
+	err := cache.Delete(t.Context(), user.ID)
+	if err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	_, err = cache.FindByEmail(t.Context(), valuestesting.MustEmail(t, "cached@example.com"))
+	if err == nil {
+		t.Error("deleted user is still served from the cache")
+	}
+}
+
+func TestCachingUserRepositoryIsolatesCallerMutations(t *testing.T) {
+	cache, _, user := newCountingCache(t)
+
+	first, err := cache.FindByID(t.Context(), user.ID)
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+
+	err = first.SetName("mutatedname", time.Now())
+	if err != nil {
+		t.Fatalf("SetName() error = %v", err)
+	}
+
+	second, err := cache.FindByID(t.Context(), user.ID)
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+
+	if second.GetUserName().String() != "cacheduser" {
+		t.Errorf("cached name = %q, want the caller's change kept out of the cache", second.GetUserName())
+	}
+}
+
+// sqliteRoundTripRepository adds one SQLite query to every FindByID, the
+// cost a SQL-backed repository pays per lookup.
+type sqliteRoundTripRepository struct {
+	repositories.UserRepository
+
+	db *sql.DB
+}
+
+func (r sqliteRoundTripRepository) FindByID(ctx context.Context, id values.UserID) (*entities.User, error) {
+	var found string
+
+	err := r.db.QueryRowContext(ctx, "SELECT ?", id.String()).Scan(&found)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.UserRepository.FindByID(ctx, id)
+}
+
+func BenchmarkFindByIDRepeated(b *testing.B) {
+	db := base.OpenTestDB(b, filepath.Join(b.TempDir(), "bench.db"))
+	raw := sqliteRoundTripRepository{UserRepository: repositories.NewInMemoryUserRepository(), db: db}
+
+	user, err := entities.NewUser(ids.MustGenerateUserID(), "bench@example.com", "benchuser", time.Now())
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	err = raw.Save(b.Context(), user)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for _, bench := range []struct {
+		name string
+		repo repositories.UserRepository
+	}{
+		{name: "raw", repo: raw},
+		{name: "cached", repo: NewCachingUserRepository(raw, time.Minute)},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			for b.Loop() {
+				_, err := bench.repo.FindByID(b.Context(), user.ID)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestCachingUserRepositoryLogsThroughRequestLogger(t *testing.T) {
+	cache, _, user := newCountingCache(t)
+
+	var logs bytes.Buffer
+
+	logger := log.New(&logs)
+	logger.SetLevel(log.DebugLevel)
+	ctx := log.WithContext(t.Context(), logger.With("request_id", "req-7"))
+
+	_, err := cache.FindByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+
+	for _, want := range []string{"request_id=req-7", "operation=find_by_id", "user_id=" + user.ID.String()} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("repository log %q does not contain %q", logs.String(), want)
+		}
+	}
+}
+
+// gatedRepository blocks every FindByID until gate is closed, then runs
+// lookup, so a test can line up concurrent misses.
+type gatedRepository struct {
+	repositories.UserRepository
+
+	gate    chan struct{}
+	lookups atomic.Int64
+	lookup  func() (*entities.User, error)
+}
+
+func (r *gatedRepository) FindByID(context.Context, values.UserID) (*entities.User, error) {
+	r.lookups.Add(1)
+	<-r.gate
+
+	return r.lookup()
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met within 2s")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestCachingUserRepositorySharesConcurrentMisses(t *testing.T) {
+	user, err := entities.NewUser(ids.MustGenerateUserID(), "shared@example.com", "shareduser", time.Now())
+	if err != nil {
+		t.Fatalf("NewUser(time.Now()) error = %v", err)
+	}
+
+	gated := &gatedRepository{ //nolint:exhaustruct // zero counter
+		UserRepository: repositories.NewInMemoryUserRepository(),
+		gate:           make(chan struct{}),
+		lookup:         func() (*entities.User, error) { return user, nil },
+	}
+	cache := NewCachingUserRepository(gated, time.Minute)
+
+	const callers = 100
+
+	var (
+		wg    sync.WaitGroup
+		found atomic.Int64
+	)
+
+	for range callers {
+		wg.Go(func() {
+			got, err := cache.FindByID(t.Context(), user.ID)
+			if err == nil && got.ID == user.ID {
+				found.Add(1)
+			}
+		})
+	}
+
+	waitFor(t, func() bool { return cache.Stats().SharedLoads == callers-1 })
+	close(gated.gate)
+	wg.Wait()
+
+	if got := gated.lookups.Load(); got != 1 {
+		t.Errorf("lookups = %d, want %d simultaneous misses to share one", got, callers)
+	}
+
+	if got := found.Load(); got != callers {
+		t.Errorf("%d of %d callers got the user", got, callers)
+	}
+
+	stats := cache.Stats()
+	if stats.Misses != 1 || stats.SharedLoads != callers-1 {
+		t.Errorf("stats = %+v, want 1 miss and %d shared loads", stats, callers-1)
+	}
+}
+
+func TestCachingUserRepositoryIsolatesLoaderMutations(t *testing.T) {
+	user, err := entities.NewUser(ids.MustGenerateUserID(), "loader@example.com", "loaderuser", time.Now())
+	if err != nil {
+		t.Fatalf("NewUser(time.Now()) error = %v", err)
+	}
+
+	gated := &gatedRepository{ //nolint:exhaustruct // zero counter
+		UserRepository: repositories.NewInMemoryUserRepository(),
+		gate:           make(chan struct{}),
+		lookup:         func() (*entities.User, error) { return user, nil },
+	}
+	cache := NewCachingUserRepository(gated, time.Minute)
+
+	const waiters = 5
+
+	var wg sync.WaitGroup
+
+	// The caller that starts the lookup changes its user at once, as
+	// UserService does when it applies an update.
+	wg.Go(func() {
+		got, err := cache.FindByID(t.Context(), user.ID)
+		if err == nil {
+			_ = got.SetName("loaderchanged", time.Now())
+		}
+	})
+	waitFor(t, func() bool { return cache.Stats().Misses == 1 })
+
+	names := make(chan string, waiters)
+
+	for range waiters {
+		wg.Go(func() {
+			got, err := cache.FindByID(t.Context(), user.ID)
+			if err == nil {
+				names <- got.GetUserName().String()
+			}
+		})
+	}
+
+	waitFor(t, func() bool { return cache.Stats().SharedLoads == waiters })
+	close(gated.gate)
+	wg.Wait()
+	close(names)
+
+	for name := range names {
+		if name != "loaderuser" {
+			t.Errorf("waiter got name %q, want the loaded user unchanged", name)
+		}
+	}
+
+	cached, err := cache.FindByID(t.Context(), user.ID)
+	if err != nil || cached.GetUserName().String() != "loaderuser" {
+		t.Errorf("cached user = %v, %v, want the loaded user unchanged", cached, err)
+	}
+}
+
+func TestCachingUserRepositoryLookupPanic(t *testing.T) {
+	user, err := entities.NewUser(ids.MustGenerateUserID(), "panic@example.com", "panicuser", time.Now())
+	if err != nil {
+		t.Fatalf("NewUser(time.Now()) error = %v", err)
+	}
+
+	var panicking atomic.Bool
+
+	panicking.Store(true)
+
+	gated := &gatedRepository{ //nolint:exhaustruct // zero counter
+		UserRepository: repositories.NewInMemoryUserRepository(),
+		gate:           make(chan struct{}),
+		lookup: func() (*entities.User, error) {
+			if panicking.Load() {
+				panic("lookup failed")
+			}
+
+			return user, nil
+		},
+	}
+	cache := NewCachingUserRepository(gated, time.Minute).WithNegativeTTL(time.Minute)
+
+	var (
+		wg        sync.WaitGroup
+		recovered any
+		waiterErr error
+	)
+
+	wg.Go(func() {
+		defer func() { recovered = recover() }()
+
+		_, _ = cache.FindByID(t.Context(), user.ID)
+	})
+	waitFor(t, func() bool { return gated.lookups.Load() == 1 })
+
+	wg.Go(func() { _, waiterErr = cache.FindByID(t.Context(), user.ID) })
+	waitFor(t, func() bool { return cache.Stats().SharedLoads == 1 })
+
+	close(gated.gate)
+	wg.Wait()
+
+	if recovered != "lookup failed" {
+		t.Errorf("caller that ran the lookup recovered %v, want the panic", recovered)
+	}
+
+	if !errors.Is(waiterErr, errLookupPanicked) {
+		t.Errorf("waiter error = %v, want errLookupPanicked", waiterErr)
+	}
+
+	panicking.Store(false)
+
+	got, err := cache.FindByID(t.Context(), user.ID)
+	if err != nil || got.ID != user.ID {
+		t.Errorf("FindByID() after the panic = %v, %v, want the user looked up again", got, err)
+	}
+}
+
+func TestCachingUserRepositoryJitterBounds(t *testing.T) {
+	cache := NewCachingUserRepository(repositories.NewInMemoryUserRepository(), time.Minute).WithJitter(0.2)
+
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	var shortest, longest time.Duration = time.Minute, 0
+
+	for range 200 {
+		user, err := entities.NewUser(ids.MustGenerateUserID(), "jitter@example.com", "jitteruser", time.Now())
+		if err != nil {
+			t.Fatalf("NewUser(time.Now()) error = %v", err)
+		}
+
+		cache.mu.Lock()
+		cache.storeLocked(user)
+		lifetime := cache.byID[user.ID].expires.Sub(now)
+		cache.mu.Unlock()
+
+		shortest, longest = min(shortest, lifetime), max(longest, lifetime)
+	}
+
+	if shortest < 48*time.Second || longest > time.Minute {
+		t.Errorf("lifetimes span [%v, %v], want within [48s, 1m]", shortest, longest)
+	}
+
+	if longest-shortest < time.Second {
+		t.Errorf("lifetimes span [%v, %v], want them spread", shortest, longest)
+	}
+}
+
+func TestCachingUserRepositoryNegativeCacheExpires(t *testing.T) {
+	cache, counting, _ := newCountingCache(t)
+	cache.WithNegativeTTL(5 * time.Second)
+
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	missing := ids.MustGenerateUserID()
+
+	for range 2 {
+		_, err := cache.FindByID(t.Context(), missing)
+		if !errors.Is(err, repositories.ErrUserNotFound) {
+			t.Fatalf("FindByID() error = %v, want ErrUserNotFound", err)
+		}
+	}
+
+	if got := counting.lookups.Load(); got != 1 {
+		t.Errorf("lookups = %d, want the error served from the cache", got)
+	}
+
+	now = now.Add(5 * time.Second)
+
+	_, _ = cache.FindByID(t.Context(), missing)
+
+	if got := counting.lookups.Load(); got != 2 {
+		t.Errorf("lookups = %d, want the expired error looked up again", got)
+	}
+
+	user, err := entities.NewUser(missing, "late@example.com", "lateuser", time.Now())
+	if err != nil {
+		t.Fatalf("NewUser(time.Now()) error = %v", err)
+	}
+
+	err = cache.Save(t.Context(), user)
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	if _, err := cache.FindByID(t.Context(), missing); err != nil {
+		t.Errorf("FindByID() after Save error = %v, want the cached error evicted", err)
+	}
+
+	if stats := cache.Stats(); stats.NegativeHits != 1 {
+		t.Errorf("negative hits = %d, want 1", stats.NegativeHits)
+	}
+}
@@ -0,0 +1,153 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json/v2"
+	"net/http"
+	"time"
+
+	"github.com/LarsArtmann/template-arch-lint/pkg/errors"
+	"github.com/larsartmann/httputil"
+)
+
+// PoolConfig describes the database connection and its pool. It mirrors
+// config.DatabaseConfig, which infrastructure may not import; zero limits
+// and durations mean unlimited, as in database/sql.
+type PoolConfig struct {
+	Driver          string
+	DSN             string
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	PingTimeout     time.Duration
+}
+
+// NewDB opens the database, applies the pool settings and pings it within
+// cfg.PingTimeout, so an unreachable database fails startup instead of the
+// first request. The driver must be registered by the caller.
+func NewDB(ctx context.Context, cfg PoolConfig) (*sql.DB, error) {
+	db, err := sql.Open(cfg.Driver, cfg.DSN)
+	if err != nil {
+		return nil, errors.NewDatabaseError("open "+cfg.Driver+" database", err, false)
+	}
+
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+	err = ping(ctx, db, cfg.PingTimeout)
+	if err != nil {
+		_ = db.Close()
+
+		return nil, err
+	}
+
+	return db, nil
+}
+
+func ping(ctx context.Context, db *sql.DB, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	err := db.PingContext(ctx)
+	if err != nil {
+		return errors.NewDatabaseError("ping database", err, true)
+	}
+
+	return nil
+}
+
+// PoolStats is the part of sql.DBStats that shows pool saturation: a
+// growing WaitCount with InUse at MaxOpenConnections means requests queue
+// for a connection.
+type PoolStats struct {
+	MaxOpenConnections int   `json:"max_open_connections"`
+	OpenConnections    int   `json:"open_connections"`
+	InUse              int   `json:"in_use"`
+	Idle               int   `json:"idle"`
+	WaitCount          int64 `json:"wait_count"`
+	WaitDurationMillis int64 `json:"wait_duration_ms"`
+}
+
+// Stats returns the current pool statistics of db.
+func Stats(db *sql.DB) PoolStats {
+	stats := db.Stats()
+
+	return PoolStats{
+		MaxOpenConnections: stats.MaxOpenConnections,
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		WaitCount:          stats.WaitCount,
+		WaitDurationMillis: stats.WaitDuration.Milliseconds(),
+	}
+}
+
+// HealthResponse extends httputil.HealthResponse with database details.
+type HealthResponse struct {
+	httputil.HealthResponse
+
+	Database DatabaseHealth `json:"database"`
+}
+
+// DatabaseHealth is the database section of the health response.
+type DatabaseHealth struct {
+	PoolStats
+
+	Status httputil.HealthStatus `json:"status"`
+	Error  string                `json:"error,omitempty"`
+}
+
+// HealthHandler serves /health with the database reachability and pool
+// statistics. It pings db within timeout and answers 503 when the ping fails.
+func HealthHandler(db *sql.DB, timeout time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response := HealthResponse{
+			HealthResponse: httputil.HealthResponse{Status: httputil.HealthStatusUp},
+			Database:       DatabaseHealth{PoolStats: Stats(db), Status: httputil.HealthStatusUp, Error: ""},
+		}
+		status := http.StatusOK
+
+		if ping(r.Context(), db, timeout) != nil {
+			response.Status = httputil.HealthStatusDown
+			response.Database.Status = httputil.HealthStatusDown
+			// The driver error may name hosts or users, so it stays out of
+			// an unauthenticated endpoint.
+			response.Database.Error = "database unreachable"
+			status = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.MarshalWrite(w, response)
+	}
+}
+
+// DatabaseChecker is the readiness check of the database.
+type DatabaseChecker struct {
+	db *sql.DB
+}
+
+// NewDatabaseChecker creates a readiness check that pings db.
+func NewDatabaseChecker(db *sql.DB) DatabaseChecker {
+	return DatabaseChecker{db: db}
+}
+
+// Name implements health.Checker.
+func (DatabaseChecker) Name() string {
+	return "database"
+}
+
+// Check implements health.Checker by pinging the database within the
+// deadline of ctx.
+func (c DatabaseChecker) Check(ctx context.Context) error {
+	err := c.db.PingContext(ctx)
+	if err != nil {
+		return errors.NewDatabaseError("ping database", err, true)
+	}
+
+	return nil
+}
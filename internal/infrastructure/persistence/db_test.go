@@ -0,0 +1,156 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json/v2"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func testPoolConfig(t *testing.T) PoolConfig {
+	t.Helper()
+
+	return PoolConfig{
+		Driver:          "sqlite3",
+		DSN:             filepath.Join(t.TempDir(), "app.db"),
+		MaxOpenConns:    3,
+		MaxIdleConns:    1,
+		ConnMaxLifetime: time.Minute,
+		ConnMaxIdleTime: time.Minute,
+		PingTimeout:     time.Second,
+	}
+}
+
+func TestNewDBAppliesPoolSettings(t *testing.T) {
+	db, err := NewDB(t.Context(), testPoolConfig(t))
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	defer db.Close()
+
+	conns := make([]*sql.Conn, 0, 3)
+
+	for range 3 {
+		conn, err := db.Conn(t.Context())
+		if err != nil {
+			t.Fatalf("Conn() error = %v", err)
+		}
+
+		conns = append(conns, conn)
+	}
+
+	stats := Stats(db)
+	if stats.MaxOpenConnections != 3 || stats.InUse != 3 {
+		t.Errorf("stats with every connection taken = %+v, want 3 of 3 in use", stats)
+	}
+
+	for _, conn := range conns {
+		_ = conn.Close()
+	}
+
+	stats = Stats(db)
+	if stats.Idle != 1 || stats.InUse != 0 {
+		t.Errorf("stats after release = %+v, want 1 idle connection kept", stats)
+	}
+}
+
+func TestNewDBFailsWhenDatabaseIsUnreachable(t *testing.T) {
+	cfg := testPoolConfig(t)
+	cfg.DSN = "file:" + filepath.Join(t.TempDir(), "missing", "app.db") + "?mode=ro"
+
+	db, err := NewDB(t.Context(), cfg)
+	if err == nil {
+		_ = db.Close()
+
+		t.Fatal("NewDB() succeeded for a database that cannot be opened")
+	}
+}
+
+func TestNewDBRejectsUnknownDriver(t *testing.T) {
+	cfg := testPoolConfig(t)
+	cfg.Driver = "no-such-driver"
+
+	_, err := NewDB(t.Context(), cfg)
+	if err == nil {
+		t.Fatal("NewDB() succeeded with an unregistered driver")
+	}
+}
+
+func TestHealthHandler(t *testing.T) {
+	db, err := NewDB(t.Context(), testPoolConfig(t))
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+
+	handler := HealthHandler(db, time.Second)
+
+	response := serveHealth(t, handler, http.StatusOK)
+	if response.Status != "up" || response.Database.Status != "up" {
+		t.Errorf("health = %+v, want up", response)
+	}
+
+	if response.Database.MaxOpenConnections != 3 || response.Database.OpenConnections == 0 {
+		t.Errorf("pool stats = %+v, want the configured pool", response.Database.PoolStats)
+	}
+
+	_ = db.Close()
+
+	response = serveHealth(t, handler, http.StatusServiceUnavailable)
+	if response.Status != "down" || response.Database.Error == "" {
+		t.Errorf("health of a closed database = %+v, want down with an error", response)
+	}
+}
+
+func serveHealth(t *testing.T, handler http.HandlerFunc, wantStatus int) HealthResponse {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/health", nil))
+
+	if w.Code != wantStatus {
+		t.Fatalf("status = %d, want %d: %s", w.Code, wantStatus, w.Body.String())
+	}
+
+	var response HealthResponse
+
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		t.Fatalf("response is not JSON: %v", err)
+	}
+
+	for _, field := range []string{`"in_use"`, `"idle"`, `"wait_count"`, `"wait_duration_ms"`} {
+		if !strings.Contains(w.Body.String(), field) {
+			t.Errorf("health output %s has no %s field", w.Body.String(), field)
+		}
+	}
+
+	return response
+}
+
+func TestDatabaseChecker(t *testing.T) {
+	db, err := NewDB(t.Context(), testPoolConfig(t))
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+
+	checker := NewDatabaseChecker(db)
+
+	err = checker.Check(t.Context())
+	if err != nil {
+		t.Errorf("Check() on an open database = %v, want nil", err)
+	}
+
+	_ = db.Close()
+
+	err = checker.Check(t.Context())
+	if err == nil {
+		t.Error("Check() on a closed database succeeded")
+	}
+}
@@ -0,0 +1,132 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	stderrors "errors"
+	"time"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/repositories"
+	"github.com/LarsArtmann/template-arch-lint/pkg/errors"
+)
+
+const (
+	// reserveIdempotencyKeySQL inserts a reservation, or replaces a record
+	// that has expired; it changes no row while the key is held.
+	reserveIdempotencyKeySQL = `INSERT INTO idempotency_keys
+    (idempotency_key, request_hash, status, content_type, location, body, expires_at)
+VALUES (?, ?, 0, '', '', NULL, ?)
+ON CONFLICT (idempotency_key) DO UPDATE SET
+    request_hash = excluded.request_hash, status = 0, content_type = '', location = '', body = NULL,
+    expires_at = excluded.expires_at
+WHERE idempotency_keys.expires_at <= ?`
+	selectIdempotencyKeySQL = `SELECT request_hash, status, content_type, location, body, expires_at
+FROM idempotency_keys WHERE idempotency_key = ?`
+	completeIdempotencyKeySQL = `UPDATE idempotency_keys SET status = ?, content_type = ?, location = ?, body = ?
+WHERE idempotency_key = ?`
+	releaseIdempotencyKeySQL       = `DELETE FROM idempotency_keys WHERE idempotency_key = ?`
+	deleteExpiredIdempotencyKeySQL = `DELETE FROM idempotency_keys WHERE expires_at <= ?`
+)
+
+var _ repositories.IdempotencyStore = (*SQLIdempotencyStore)(nil)
+
+// SQLIdempotencyStore implements repositories.IdempotencyStore on the
+// idempotency_keys table, so replays work across processes sharing the
+// database.
+type SQLIdempotencyStore struct {
+	db DBTX
+}
+
+// NewSQLIdempotencyStore creates an idempotency store on db, which must be
+// migrated.
+func NewSQLIdempotencyStore(db DBTX) *SQLIdempotencyStore {
+	return &SQLIdempotencyStore{db: db}
+}
+
+// Reserve implements repositories.IdempotencyStore with a single upsert,
+// so two processes cannot both claim a key.
+func (s *SQLIdempotencyStore) Reserve(
+	ctx context.Context, record repositories.IdempotencyRecord, now time.Time,
+) (repositories.IdempotencyRecord, bool, error) {
+	db := Executor(ctx, s.db)
+
+	result, err := db.ExecContext(ctx, reserveIdempotencyKeySQL,
+		record.Key, record.RequestHash, record.ExpiresAt.UnixNano(), now.UnixNano())
+	if err != nil {
+		return repositories.IdempotencyRecord{}, false, errors.NewDatabaseError("reserve idempotency key", err, true)
+	}
+
+	reserved, err := result.RowsAffected()
+	if err != nil {
+		return repositories.IdempotencyRecord{}, false, errors.NewDatabaseError("reserve idempotency key", err, false)
+	}
+
+	if reserved == 1 {
+		record.Status = 0
+		record.ContentType = ""
+		record.Location = ""
+		record.Body = nil
+
+		return record, true, nil
+	}
+
+	held := repositories.IdempotencyRecord{Key: record.Key} //nolint:exhaustruct // scanned below
+
+	var expiresAt int64
+
+	err = db.QueryRowContext(ctx, selectIdempotencyKeySQL, record.Key).
+		Scan(&held.RequestHash, &held.Status, &held.ContentType, &held.Location, &held.Body, &expiresAt)
+	if stderrors.Is(err, sql.ErrNoRows) {
+		// Released since the upsert: it reads as still running, so the
+		// caller comes back and reserves it.
+		record.Status = 0
+
+		return record, false, nil
+	}
+
+	if err != nil {
+		return repositories.IdempotencyRecord{}, false, errors.NewDatabaseError("read idempotency key", err, true)
+	}
+
+	held.ExpiresAt = time.Unix(0, expiresAt)
+
+	return held, false, nil
+}
+
+// Complete implements repositories.IdempotencyStore.
+func (s *SQLIdempotencyStore) Complete(ctx context.Context, record repositories.IdempotencyRecord) error {
+	_, err := Executor(ctx, s.db).ExecContext(ctx, completeIdempotencyKeySQL,
+		record.Status, record.ContentType, record.Location, record.Body, record.Key)
+	if err != nil {
+		return errors.NewDatabaseError("complete idempotency key", err, true)
+	}
+
+	return nil
+}
+
+// Release implements repositories.IdempotencyStore.
+func (s *SQLIdempotencyStore) Release(ctx context.Context, key string) error {
+	_, err := Executor(ctx, s.db).ExecContext(ctx, releaseIdempotencyKeySQL, key)
+	if err != nil {
+		return errors.NewDatabaseError("release idempotency key", err, true)
+	}
+
+	return nil
+}
+
+// DeleteExpired removes the records expired at now and returns how many
+// there were. Reserve reuses an expired key by itself; this only reclaims
+// the space of keys that are never sent again.
+func (s *SQLIdempotencyStore) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result, err := Executor(ctx, s.db).ExecContext(ctx, deleteExpiredIdempotencyKeySQL, now.UnixNano())
+	if err != nil {
+		return 0, errors.NewDatabaseError("delete expired idempotency keys", err, true)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.NewDatabaseError("delete expired idempotency keys", err, false)
+	}
+
+	return deleted, nil
+}
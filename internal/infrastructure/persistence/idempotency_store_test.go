@@ -0,0 +1,63 @@
+package persistence
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/repositories"
+	"github.com/LarsArtmann/template-arch-lint/internal/infrastructure/persistence/migrations"
+	"github.com/LarsArtmann/template-arch-lint/internal/testhelpers/base"
+	repotesting "github.com/LarsArtmann/template-arch-lint/internal/testhelpers/domain/repositories"
+)
+
+// openMigratedDB opens a fresh SQLite database with every migration applied.
+func openMigratedDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db := base.OpenTestDB(t, filepath.Join(t.TempDir(), "app.db")+"?_busy_timeout=5000")
+
+	_, err := migrations.Up(t.Context(), db)
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	return db
+}
+
+func TestSQLIdempotencyStoreContract(t *testing.T) {
+	repotesting.RunIdempotencyStoreContract(t, func(t *testing.T) repositories.IdempotencyStore {
+		return NewSQLIdempotencyStore(openMigratedDB(t))
+	})
+}
+
+func TestSQLIdempotencyStoreDeleteExpired(t *testing.T) {
+	store := NewSQLIdempotencyStore(openMigratedDB(t))
+	now := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	for key, ttl := range map[string]time.Duration{"old": time.Minute, "new": time.Hour} {
+		_, _, err := store.Reserve(t.Context(), repositories.IdempotencyRecord{ //nolint:exhaustruct // a reservation
+			Key:         key,
+			RequestHash: "h",
+			ExpiresAt:   now.Add(ttl),
+		}, now)
+		if err != nil {
+			t.Fatalf("Reserve(%q) error = %v", key, err)
+		}
+	}
+
+	deleted, err := store.DeleteExpired(t.Context(), now.Add(time.Minute))
+	if err != nil || deleted != 1 {
+		t.Fatalf("DeleteExpired() = %d, %v, want 1 record deleted", deleted, err)
+	}
+
+	_, reserved, err := store.Reserve(t.Context(), repositories.IdempotencyRecord{ //nolint:exhaustruct // a reservation
+		Key:         "new",
+		RequestHash: "h",
+		ExpiresAt:   now.Add(2 * time.Hour),
+	}, now.Add(time.Minute))
+	if err != nil || reserved {
+		t.Errorf("Reserve(new) = %v, %v, want the unexpired record kept", reserved, err)
+	}
+}
@@ -0,0 +1,404 @@
+// Package migrations applies the versioned SQL schema migrations embedded in
+// the binary and records them in a schema_migrations table.
+//
+// Each file in sql/ is one migration named by its sortable file name, for
+// example 0001_create_users.sql. A file holds a "-- +goose Up" section and an
+// optional "-- +goose Down" section, the layout sqlc also reads the schema
+// from. Applied migrations must never be edited: the runner compares
+// checksums and refuses to continue when one changed.
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/infrastructure"
+	"github.com/LarsArtmann/template-arch-lint/pkg/errors"
+)
+
+const (
+	upMarker   = "-- +goose Up"
+	downMarker = "-- +goose Down"
+)
+
+const (
+	createTableSQL = `CREATE TABLE IF NOT EXISTS schema_migrations (
+    name TEXT PRIMARY KEY,
+    checksum TEXT NOT NULL,
+    applied_at TEXT NOT NULL,
+    duration_ns INTEGER NOT NULL,
+    applied_by TEXT NOT NULL
+)`
+	selectAppliedSQL = `SELECT name, checksum, applied_at, duration_ns, applied_by FROM schema_migrations`
+	insertAppliedSQL = `INSERT INTO schema_migrations (name, checksum, applied_at, duration_ns, applied_by)
+VALUES (?, ?, ?, ?, ?)`
+	deleteAppliedSQL = `DELETE FROM schema_migrations WHERE name = ?`
+)
+
+//go:embed sql/*.sql
+var embedded embed.FS
+
+// Migration is a migration file split into its up and down sections. The
+// embedded infrastructure.Migration holds the whole file, so the checksum
+// covers both directions.
+type Migration struct {
+	infrastructure.Migration
+
+	Up   string
+	Down string
+}
+
+// Embedded returns the migrations compiled into the binary, in order.
+func Embedded() ([]Migration, error) {
+	dir, err := fs.Sub(embedded, "sql")
+	if err != nil {
+		return nil, errors.NewInternalError("open embedded migrations", err)
+	}
+
+	return Load(dir)
+}
+
+// Load reads every *.sql file at the root of fsys as a migration, ordered by name.
+func Load(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, errors.NewInternalError("list migrations", err)
+	}
+
+	migrations := make([]Migration, 0, len(files))
+
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, errors.NewInternalError("read migration "+file, err)
+		}
+
+		migration, err := parse(strings.TrimSuffix(path.Base(file), ".sql"), string(data))
+		if err != nil {
+			return nil, err
+		}
+
+		migrations = append(migrations, migration)
+	}
+
+	return migrations, nil
+}
+
+// parse splits a migration file at its up and down markers.
+func parse(name, content string) (Migration, error) {
+	var (
+		up, down strings.Builder
+		section  *strings.Builder
+	)
+
+	for line := range strings.Lines(content) {
+		switch strings.TrimSpace(line) {
+		case upMarker:
+			section = &up
+		case downMarker:
+			section = &down
+		default:
+			if section != nil {
+				section.WriteString(line)
+			}
+		}
+	}
+
+	if strings.TrimSpace(up.String()) == "" {
+		return Migration{}, errors.NewValidationError(name, "migration has no \""+upMarker+"\" section")
+	}
+
+	return Migration{
+		Migration: infrastructure.Migration{Name: name, SQL: content},
+		Up:        up.String(),
+		Down:      down.String(),
+	}, nil
+}
+
+// Up applies the embedded migrations that db has not seen yet.
+func Up(ctx context.Context, db *sql.DB) ([]infrastructure.AppliedMigration, error) {
+	runner, err := embeddedRunner(db)
+	if err != nil {
+		return nil, err
+	}
+
+	return runner.Up(ctx)
+}
+
+// Down rolls back the latest migration applied to db.
+func Down(ctx context.Context, db *sql.DB) (string, error) {
+	runner, err := embeddedRunner(db)
+	if err != nil {
+		return "", err
+	}
+
+	return runner.Down(ctx)
+}
+
+// Status compares the migrations applied to db with the embedded ones.
+func Status(ctx context.Context, db *sql.DB) (infrastructure.SchemaStatus, error) {
+	runner, err := embeddedRunner(db)
+	if err != nil {
+		return infrastructure.SchemaStatus{}, err
+	}
+
+	return runner.Status(ctx)
+}
+
+func embeddedRunner(db *sql.DB) (*Runner, error) {
+	migrations, err := Embedded()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewRunner(db, migrations), nil
+}
+
+// Runner applies a fixed set of migrations to one database. Its statements
+// use "?" placeholders, as SQLite and MySQL expect.
+type Runner struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// NewRunner creates a runner for migrations, which must be ordered by name.
+func NewRunner(db *sql.DB, migrations []Migration) *Runner {
+	return &Runner{db: db, migrations: migrations}
+}
+
+// Status creates the schema_migrations table if needed and reports which
+// migrations are applied, pending, unknown or edited.
+func (r *Runner) Status(ctx context.Context) (infrastructure.SchemaStatus, error) {
+	applied, err := r.applied(ctx)
+	if err != nil {
+		return infrastructure.SchemaStatus{}, err
+	}
+
+	embedded := make([]infrastructure.Migration, 0, len(r.migrations))
+	for _, m := range r.migrations {
+		embedded = append(embedded, m.Migration)
+	}
+
+	return infrastructure.BuildSchemaStatus(embedded, applied), nil
+}
+
+// Up applies every pending migration in order, each in its own transaction
+// together with its schema_migrations row, and returns what it applied. It
+// refuses to run when the database holds migrations this binary does not
+// know or an applied migration was edited, because either means the schema
+// is not what the migrations describe.
+func (r *Runner) Up(ctx context.Context) ([]infrastructure.AppliedMigration, error) {
+	status, err := r.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkRunnable(status)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make([]infrastructure.AppliedMigration, 0, len(status.Pending))
+
+	for _, m := range r.migrations {
+		if !slices.Contains(status.Pending, m.Name) {
+			continue
+		}
+
+		record, err := r.apply(ctx, m)
+		if err != nil {
+			return applied, err
+		}
+
+		applied = append(applied, record)
+	}
+
+	return applied, nil
+}
+
+// Down rolls back the latest applied migration and returns its name, or ""
+// when nothing is applied. It refuses to run against a database whose
+// environment marker says production; unstamped databases count as production.
+func (r *Runner) Down(ctx context.Context) (string, error) {
+	err := r.checkDownAllowed(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	status, err := r.Status(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	err = checkRunnable(status)
+	if err != nil {
+		return "", err
+	}
+
+	if status.Version == "" {
+		return "", nil
+	}
+
+	index := slices.IndexFunc(r.migrations, func(m Migration) bool { return m.Name == status.Version })
+	m := r.migrations[index]
+
+	if strings.TrimSpace(m.Down) == "" {
+		return "", errors.NewValidationError(m.Name, "migration has no \""+downMarker+"\" section")
+	}
+
+	err = r.inTx(ctx, "roll back migration "+m.Name, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, m.Down)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, deleteAppliedSQL, m.Name)
+
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return m.Name, nil
+}
+
+// checkDownAllowed reads the environment marker of the database. Callers
+// confirm the rollback themselves, so only the production refusal of
+// infrastructure.ConfirmDestructive applies here.
+func (r *Runner) checkDownAllowed(ctx context.Context) error {
+	settings := infrastructure.NewDatabaseFromDB(r.db)
+
+	err := settings.EnsureSettings(ctx)
+	if err != nil {
+		return err
+	}
+
+	env, err := infrastructure.NewEnvironmentMarker(settings).Environment(ctx)
+	if err != nil {
+		return err
+	}
+
+	return infrastructure.ConfirmDestructive(infrastructure.OperationMigrateDown, env, "", true)
+}
+
+func (r *Runner) apply(ctx context.Context, m Migration) (infrastructure.AppliedMigration, error) {
+	var record infrastructure.AppliedMigration
+
+	err := r.inTx(ctx, "apply migration "+m.Name, func(tx *sql.Tx) error {
+		start := time.Now()
+
+		_, err := tx.ExecContext(ctx, m.Up)
+		if err != nil {
+			return err
+		}
+
+		record = infrastructure.NewAppliedMigration(m.Migration, start, time.Since(start))
+		_, err = tx.ExecContext(ctx, insertAppliedSQL, record.Name, record.Checksum,
+			record.AppliedAt.Format(time.RFC3339Nano), int64(record.Duration), record.AppliedBy)
+
+		return err
+	})
+
+	return record, err
+}
+
+func (r *Runner) inTx(ctx context.Context, operation string, fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.NewDatabaseError(operation, err, true)
+	}
+
+	err = fn(tx)
+	if err != nil {
+		_ = tx.Rollback()
+
+		return errors.NewDatabaseError(operation, err, false)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.NewDatabaseError(operation, err, false)
+	}
+
+	return nil
+}
+
+func (r *Runner) applied(ctx context.Context) ([]infrastructure.AppliedMigration, error) {
+	_, err := r.db.ExecContext(ctx, createTableSQL)
+	if err != nil {
+		return nil, errors.NewDatabaseError("create schema_migrations", err, true)
+	}
+
+	rows, err := r.db.QueryContext(ctx, selectAppliedSQL)
+	if err != nil {
+		return nil, errors.NewDatabaseError("list applied migrations", err, true)
+	}
+	defer rows.Close()
+
+	var applied []infrastructure.AppliedMigration
+
+	for rows.Next() {
+		var (
+			record     infrastructure.AppliedMigration
+			appliedAt  string
+			durationNs int64
+		)
+
+		err = rows.Scan(&record.Name, &record.Checksum, &appliedAt, &durationNs, &record.AppliedBy)
+		if err != nil {
+			return nil, errors.NewDatabaseError("scan applied migration", err, false)
+		}
+
+		record.AppliedAt, err = time.Parse(time.RFC3339Nano, appliedAt)
+		if err != nil {
+			return nil, errors.NewInternalError("parse applied_at of migration "+record.Name, err)
+		}
+
+		record.Duration = time.Duration(durationNs)
+		applied = append(applied, record)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, errors.NewDatabaseError("list applied migrations", err, true)
+	}
+
+	return applied, nil
+}
+
+// checkRunnable rejects a schema that the migrations no longer describe.
+func checkRunnable(status infrastructure.SchemaStatus) error {
+	if status.Dirty {
+		return errors.NewConflictError(
+			"database has migrations unknown to this binary: "+strings.Join(status.Unknown, ", "),
+			errors.ErrorDetails{
+				Resource: "schema_migrations",
+				Reason:   "unknown_migration",
+			},
+		)
+	}
+
+	if len(status.ChecksumMismatches) > 0 {
+		names := make([]string, 0, len(status.ChecksumMismatches))
+		for _, mismatch := range status.ChecksumMismatches {
+			names = append(names, mismatch.Name)
+		}
+
+		return errors.NewConflictError(
+			"applied migrations were edited: "+strings.Join(names, ", "),
+			errors.ErrorDetails{
+				Resource: "schema_migrations",
+				Reason:   "checksum_mismatch",
+			},
+		)
+	}
+
+	return nil
+}
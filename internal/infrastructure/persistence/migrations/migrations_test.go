@@ -0,0 +1,283 @@
+package migrations
+
+import (
+	"database/sql"
+	stderrors "errors"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/infrastructure"
+	"github.com/LarsArtmann/template-arch-lint/internal/testhelpers/base"
+	"github.com/LarsArtmann/template-arch-lint/pkg/errors"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	return base.OpenTestDB(t, filepath.Join(t.TempDir(), "app.db"))
+}
+
+// openUnstampedDB opens a database without an environment marker, as a
+// database created outside the test helpers would be.
+func openUnstampedDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	dsn := filepath.Join(t.TempDir(), "app.db")
+	base.RequireSafeTestDSN(t, dsn)
+
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+
+	t.Cleanup(func() { _ = db.Close() })
+
+	return db
+}
+
+func loadTestMigrations(t *testing.T, fsys fstest.MapFS) []Migration {
+	t.Helper()
+
+	migrations, err := Load(fsys)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	return migrations
+}
+
+func testMigrations() fstest.MapFS {
+	return fstest.MapFS{
+		"0001_create_notes.sql": {Data: []byte("-- +goose Up\nCREATE TABLE notes (id TEXT PRIMARY KEY);\n" +
+			"-- +goose Down\nDROP TABLE notes;\n")},
+		"0002_add_note_body.sql": {Data: []byte("-- +goose Up\nALTER TABLE notes ADD COLUMN body TEXT;\n" +
+			"-- +goose Down\nALTER TABLE notes DROP COLUMN body;\n")},
+	}
+}
+
+func appliedNames(applied []infrastructure.AppliedMigration) []string {
+	names := make([]string, 0, len(applied))
+	for _, a := range applied {
+		names = append(names, a.Name)
+	}
+
+	return names
+}
+
+func TestUpAppliesEmbeddedMigrationsToFreshDatabase(t *testing.T) {
+	db := openTestDB(t)
+
+	applied, err := Up(t.Context(), db)
+	if err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	want := []string{
+		"0001_create_users", "0002_add_user_version", "0003_soft_delete_users", "0004_create_idempotency_keys",
+		"0005_add_idempotency_location",
+	}
+	if got := appliedNames(applied); !slices.Equal(got, want) {
+		t.Errorf("applied = %v, want %v", got, want)
+	}
+
+	_, err = db.ExecContext(t.Context(),
+		`INSERT INTO users (id, email, name, version) VALUES ('u1', 'a@example.com', 'A', 1)`)
+	if err != nil {
+		t.Errorf("users table does not match the migrations: %v", err)
+	}
+
+	status, err := Status(t.Context(), db)
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+
+	if status.Version != "0005_add_idempotency_location" || len(status.Pending) != 0 {
+		t.Errorf("status = %+v, want fully migrated", status)
+	}
+
+	applied, err = Up(t.Context(), db)
+	if err != nil || len(applied) != 0 {
+		t.Errorf("second Up() = %v, %v, want nothing applied", appliedNames(applied), err)
+	}
+}
+
+func TestUsersEmailUniqueAmongActiveUsersOnly(t *testing.T) {
+	db := openTestDB(t)
+
+	_, err := Up(t.Context(), db)
+	if err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	insert := `INSERT INTO users (id, email, name) VALUES (?, 'same@example.com', 'A')`
+
+	_, err = db.ExecContext(t.Context(), insert, "u1")
+	if err != nil {
+		t.Fatalf("insert first user: %v", err)
+	}
+
+	_, err = db.ExecContext(t.Context(), insert, "u2")
+	if err == nil {
+		t.Error("two active users share an email")
+	}
+
+	_, err = db.ExecContext(t.Context(), `UPDATE users SET deleted_at = CURRENT_TIMESTAMP WHERE id = 'u1'`)
+	if err != nil {
+		t.Fatalf("soft-delete first user: %v", err)
+	}
+
+	_, err = db.ExecContext(t.Context(), insert, "u2")
+	if err != nil {
+		t.Errorf("email of a soft-deleted user cannot be reused: %v", err)
+	}
+}
+
+func TestUpAppliesOnlyPendingMigrations(t *testing.T) {
+	db := openTestDB(t)
+	migrations := loadTestMigrations(t, testMigrations())
+
+	_, err := NewRunner(db, migrations[:1]).Up(t.Context())
+	if err != nil {
+		t.Fatalf("Up() with the first migration error = %v", err)
+	}
+
+	applied, err := NewRunner(db, migrations).Up(t.Context())
+	if err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	if got := appliedNames(applied); !slices.Equal(got, []string{"0002_add_note_body"}) {
+		t.Errorf("applied = %v, want only 0002_add_note_body", got)
+	}
+}
+
+func TestUpRejectsEditedMigration(t *testing.T) {
+	db := openTestDB(t)
+	fsys := testMigrations()
+
+	_, err := NewRunner(db, loadTestMigrations(t, fsys)[:1]).Up(t.Context())
+	if err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	fsys["0001_create_notes.sql"] = &fstest.MapFile{
+		Data: []byte("-- +goose Up\nCREATE TABLE notes (id TEXT PRIMARY KEY, title TEXT);\n"),
+	}
+	runner := NewRunner(db, loadTestMigrations(t, fsys))
+
+	applied, err := runner.Up(t.Context())
+	if _, ok := stderrors.AsType[*errors.ConflictError](err); !ok {
+		t.Fatalf("Up() error = %v, want a conflict", err)
+	}
+
+	if len(applied) != 0 {
+		t.Errorf("applied %v after detecting an edited migration", appliedNames(applied))
+	}
+
+	status, err := runner.Status(t.Context())
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+
+	if len(status.ChecksumMismatches) != 1 || status.ChecksumMismatches[0].Name != "0001_create_notes" {
+		t.Errorf("checksum mismatches = %+v, want 0001_create_notes", status.ChecksumMismatches)
+	}
+}
+
+func TestUpRefusesUnknownMigrations(t *testing.T) {
+	db := openTestDB(t)
+	migrations := loadTestMigrations(t, testMigrations())
+
+	_, err := NewRunner(db, migrations).Up(t.Context())
+	if err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	_, err = NewRunner(db, migrations[:1]).Up(t.Context())
+	if _, ok := stderrors.AsType[*errors.ConflictError](err); !ok {
+		t.Errorf("Up() after a downgrade error = %v, want a conflict", err)
+	}
+}
+
+func TestDownRollsBackLatestMigration(t *testing.T) {
+	db := openTestDB(t)
+	runner := NewRunner(db, loadTestMigrations(t, testMigrations()))
+
+	_, err := runner.Up(t.Context())
+	if err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	name, err := runner.Down(t.Context())
+	if err != nil || name != "0002_add_note_body" {
+		t.Fatalf("Down() = %q, %v, want 0002_add_note_body", name, err)
+	}
+
+	status, err := runner.Status(t.Context())
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+
+	if !slices.Equal(status.Pending, []string{"0002_add_note_body"}) {
+		t.Errorf("pending = %v, want the rolled back migration", status.Pending)
+	}
+
+	_, err = runner.Up(t.Context())
+	if err != nil {
+		t.Errorf("Up() after Down() error = %v", err)
+	}
+}
+
+func TestDownRefusesProductionDatabase(t *testing.T) {
+	tests := []struct {
+		name  string
+		stamp string
+	}{
+		{name: "stamped production", stamp: infrastructure.EnvironmentProduction},
+		{name: "never stamped"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := openUnstampedDB(t)
+			runner := NewRunner(db, loadTestMigrations(t, testMigrations()))
+
+			_, err := runner.Up(t.Context())
+			if err != nil {
+				t.Fatalf("Up() error = %v", err)
+			}
+
+			if tt.stamp != "" {
+				_, err = infrastructure.NewEnvironmentMarker(infrastructure.NewDatabaseFromDB(db)).
+					Stamp(t.Context(), tt.stamp)
+				if err != nil {
+					t.Fatalf("Stamp() error = %v", err)
+				}
+			}
+
+			name, err := runner.Down(t.Context())
+			if _, ok := stderrors.AsType[*errors.ConfigurationError](err); !ok {
+				t.Fatalf("Down() = %q, %v, want a configuration error", name, err)
+			}
+
+			status, err := runner.Status(t.Context())
+			if err != nil {
+				t.Fatalf("Status() error = %v", err)
+			}
+
+			if status.Version != "0002_add_note_body" {
+				t.Errorf("version = %q, want the latest migration still applied", status.Version)
+			}
+		})
+	}
+}
+
+func TestLoadRejectsMigrationWithoutUpSection(t *testing.T) {
+	_, err := Load(fstest.MapFS{"0001_broken.sql": {Data: []byte("CREATE TABLE broken (id TEXT);\n")}})
+	if _, ok := stderrors.AsType[*errors.ValidationError](err); !ok {
+		t.Errorf("Load() error = %v, want a validation error", err)
+	}
+}
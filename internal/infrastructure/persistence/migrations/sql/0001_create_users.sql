@@ -1,3 +1,4 @@
+-- +goose Up
 -- Users table schema
 CREATE TABLE users (
     id TEXT PRIMARY KEY,
@@ -9,4 +10,9 @@ CREATE TABLE users (
 
 -- Indexes for performance
 CREATE INDEX idx_users_email ON users(email);
-CREATE INDEX idx_users_created_at ON users(created_at);
\ No newline at end of file
+CREATE INDEX idx_users_created_at ON users(created_at);
+
+-- +goose Down
+DROP INDEX idx_users_created_at;
+DROP INDEX idx_users_email;
+DROP TABLE users;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- Optimistic locking: every update must name the version it read.
+-- Existing rows start at version 1.
+ALTER TABLE users ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN version;
@@ -0,0 +1,50 @@
+-- +goose Up
+-- Soft delete: deleted_at is set instead of removing the row. Email stays
+-- unique among active users only, so the table is rebuilt without the
+-- column-level UNIQUE constraint, which SQLite cannot drop in place.
+CREATE TABLE users_new (
+    id TEXT PRIMARY KEY,
+    email TEXT NOT NULL,
+    name TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    version INTEGER NOT NULL DEFAULT 1,
+    deleted_at DATETIME
+);
+
+INSERT INTO users_new (id, email, name, created_at, updated_at, version)
+SELECT id, email, name, created_at, updated_at, version FROM users;
+
+DROP INDEX idx_users_created_at;
+DROP INDEX idx_users_email;
+DROP TABLE users;
+ALTER TABLE users_new RENAME TO users;
+
+CREATE INDEX idx_users_email ON users(email);
+CREATE INDEX idx_users_created_at ON users(created_at);
+CREATE UNIQUE INDEX idx_users_email_active ON users(email) WHERE deleted_at IS NULL;
+
+-- +goose Down
+-- Purges soft-deleted rows: they may share an email with an active user.
+DELETE FROM users WHERE deleted_at IS NOT NULL;
+
+CREATE TABLE users_old (
+    id TEXT PRIMARY KEY,
+    email TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    version INTEGER NOT NULL DEFAULT 1
+);
+
+INSERT INTO users_old (id, email, name, created_at, updated_at, version)
+SELECT id, email, name, created_at, updated_at, version FROM users;
+
+DROP INDEX idx_users_email_active;
+DROP INDEX idx_users_created_at;
+DROP INDEX idx_users_email;
+DROP TABLE users;
+ALTER TABLE users_old RENAME TO users;
+
+CREATE INDEX idx_users_email ON users(email);
+CREATE INDEX idx_users_created_at ON users(created_at);
@@ -0,0 +1,18 @@
+-- +goose Up
+-- Responses to requests made under an Idempotency-Key header. status is 0
+-- while the first request with a key still runs; expires_at is in Unix
+-- nanoseconds, so it compares the same in every driver.
+CREATE TABLE idempotency_keys (
+    idempotency_key TEXT PRIMARY KEY,
+    request_hash TEXT NOT NULL,
+    status INTEGER NOT NULL DEFAULT 0,
+    content_type TEXT NOT NULL DEFAULT '',
+    body BLOB,
+    expires_at INTEGER NOT NULL
+);
+
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
+
+-- +goose Down
+DROP INDEX idx_idempotency_keys_expires_at;
+DROP TABLE idempotency_keys;
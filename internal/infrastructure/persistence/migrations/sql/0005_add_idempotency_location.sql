@@ -0,0 +1,6 @@
+-- +goose Up
+-- The Location of a replayed 201 Created, so a retry gets the same response.
+ALTER TABLE idempotency_keys ADD COLUMN location TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE idempotency_keys DROP COLUMN location;
@@ -0,0 +1,179 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"time"
+
+	"charm.land/log/v2"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/entities"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/repositories"
+	"github.com/LarsArtmann/template-arch-lint/pkg/errors"
+)
+
+var _ repositories.UserMatcher = (*SQLUserMatcher)(nil)
+
+const selectUsers = `SELECT id, email, name, created_at, updated_at, version, deleted_at FROM users`
+
+// SQLUserMatcher selects and pages users from the users table by
+// specification, the read side of a SQL user repository. The parts of a specification with a
+// SQL form become the WHERE clause; the rest is evaluated in memory on the
+// returned rows and logged as a warning, since it reads more rows than it
+// returns. Soft-deleted users are never returned.
+type SQLUserMatcher struct {
+	db     *sql.DB
+	logger *log.Logger
+}
+
+// NewSQLUserMatcher returns a matcher over the users table of db that logs
+// in-memory fallbacks to logger.
+func NewSQLUserMatcher(db *sql.DB, logger *log.Logger) *SQLUserMatcher {
+	return &SQLUserMatcher{db: db, logger: logger}
+}
+
+// FindMatching implements repositories.UserMatcher.
+func (m *SQLUserMatcher) FindMatching(
+	ctx context.Context,
+	spec repositories.UserSpecification,
+) ([]*entities.User, error) {
+	where, residual := m.pushDown(spec)
+
+	return m.query(ctx, where, "", residual)
+}
+
+// FindPage selects one sorted page of the users matching query.Spec. When
+// the whole specification has a SQL form, the database sorts, counts and
+// pages; otherwise every row the SQL part selects is read and paged here.
+func (m *SQLUserMatcher) FindPage(
+	ctx context.Context,
+	query repositories.UserPageQuery,
+) (repositories.UserPage, error) {
+	spec := query.Spec
+	if spec == nil {
+		spec = repositories.And()
+	}
+
+	where, residual := m.pushDown(spec)
+	if residual != nil {
+		users, err := m.query(ctx, where, "", residual)
+		if err != nil {
+			return repositories.UserPage{}, err
+		}
+
+		return repositories.PageOf(users, repositories.UserPageQuery{ //nolint:exhaustruct // users already match
+			Sort:   query.Sort,
+			Offset: query.Offset,
+			Limit:  query.Limit,
+		}), nil
+	}
+
+	var total int
+
+	err := m.db.QueryRowContext(ctx, "SELECT count(*) FROM users "+where.Clause, where.Args...).Scan(&total)
+	if err != nil {
+		return repositories.UserPage{}, errors.NewDatabaseError("count matching users", err, true)
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = -1 // SQLite reads a negative LIMIT as no limit
+	}
+
+	page := where
+	page.Args = append(slices.Clip(where.Args), limit, max(query.Offset, 0))
+
+	users, err := m.query(ctx, page, " "+query.Sort.OrderBy()+" LIMIT ? OFFSET ?", nil)
+	if err != nil {
+		return repositories.UserPage{}, err
+	}
+
+	return repositories.UserPage{Users: users, Total: total}, nil
+}
+
+// Search selects the users repositories.UserRepository.Search returns. The
+// whole SearchSpec has a SQL form, so the database matches, sorts and
+// limits.
+func (m *SQLUserMatcher) Search(ctx context.Context, query string, limit int) ([]*entities.User, error) {
+	page, err := m.FindPage(ctx, repositories.SearchPageQuery(query, limit))
+
+	return page.Users, err
+}
+
+// pushDown returns the WHERE clause for the SQL part of spec, excluding
+// soft-deleted users, and the residual to evaluate in memory.
+func (m *SQLUserMatcher) pushDown(
+	spec repositories.UserSpecification,
+) (repositories.SQLFragment, repositories.UserSpecification) {
+	fragment, residual := repositories.PushDown(spec)
+	if residual != nil {
+		m.logger.Warn("User specification filtered in memory: no SQL form",
+			"spec", fmt.Sprintf("%T", residual), "where", fragment.Clause)
+	}
+
+	return repositories.SQLFragment{
+		Clause: "WHERE deleted_at IS NULL AND (" + fragment.Clause + ")",
+		Args:   fragment.Args,
+	}, residual
+}
+
+// query selects the users in where, followed by suffix, and keeps those
+// satisfying residual unless it is nil.
+func (m *SQLUserMatcher) query(
+	ctx context.Context,
+	where repositories.SQLFragment,
+	suffix string,
+	residual repositories.UserSpecification,
+) ([]*entities.User, error) {
+	rows, err := m.db.QueryContext(ctx, selectUsers+" "+where.Clause+suffix, where.Args...)
+	if err != nil {
+		return nil, errors.NewDatabaseError("query matching users", err, true)
+	}
+	defer rows.Close()
+
+	users := make([]*entities.User, 0)
+
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		if residual == nil || residual.IsSatisfiedBy(user) {
+			users = append(users, user)
+		}
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, errors.NewDatabaseError("read matching users", err, true)
+	}
+
+	return users, nil
+}
+
+func scanUser(rows *sql.Rows) (*entities.User, error) {
+	var (
+		id, email, name   string
+		created, modified time.Time
+		version           int
+		deletedAt         sql.NullTime
+	)
+
+	err := rows.Scan(&id, &email, &name, &created, &modified, &version, &deletedAt)
+	if err != nil {
+		return nil, errors.NewDatabaseError("scan user row", err, false)
+	}
+
+	user, err := entities.NewUserFromStrings(id, email, name, created)
+	if err != nil {
+		return nil, errors.NewDatabaseError(fmt.Sprintf("decode user row %s", id), err, false)
+	}
+
+	user.Modified = modified
+	user.Version = version
+	user.DeletedAt = deletedAt.Time
+
+	return user, nil
+}
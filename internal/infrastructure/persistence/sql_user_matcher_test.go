@@ -0,0 +1,314 @@
+package persistence
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"charm.land/log/v2"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/entities"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/ids"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/repositories"
+	"github.com/LarsArtmann/template-arch-lint/internal/infrastructure/persistence/migrations"
+	"github.com/LarsArtmann/template-arch-lint/internal/testhelpers/base"
+)
+
+var matcherNow = time.Date(2026, time.March, 15, 12, 0, 0, 0, time.UTC)
+
+// inMemorySpec hides the SQL form of a specification so that it is
+// evaluated on every row.
+type inMemorySpec struct {
+	repositories.UserSpecification
+}
+
+func (inMemorySpec) ToSQL() (repositories.SQLFragment, bool) {
+	return repositories.SQLFragment{}, false
+}
+
+// seedMatcherDB creates a migrated database holding n users spread over a
+// few domains, name prefixes and creation times, every seventh of them
+// soft-deleted, and returns it with the users as written.
+func seedMatcherDB(tb testing.TB, n int) (*sql.DB, []*entities.User) {
+	tb.Helper()
+
+	db := base.OpenTestDB(tb, filepath.Join(tb.TempDir(), "matcher.db"))
+
+	_, err := migrations.Up(tb.Context(), db)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	domains := []string{"example.com", "Example.com", "corp.example.com", "rare.org"}
+	names := []string{"Alice", "alice", "Alan", "Bob"}
+	users := make([]*entities.User, 0, n)
+
+	tx, err := db.BeginTx(tb.Context(), nil)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	for i := range n {
+		user, err := entities.NewUser(ids.MustGenerateUserID(),
+			fmt.Sprintf("user%d@%s", i, domains[i%len(domains)]), fmt.Sprintf("%s %d", names[i%len(names)], i),
+			time.Now())
+		if err != nil {
+			tb.Fatal(err)
+		}
+
+		user.Created = matcherNow.Add(-time.Duration(i)*time.Hour - time.Duration(i%3)*time.Millisecond)
+		user.Modified = user.Created
+
+		var deletedAt any
+		if i%7 == 0 {
+			user.SoftDelete(matcherNow)
+			deletedAt = matcherNow
+		}
+
+		_, err = tx.ExecContext(tb.Context(),
+			`INSERT INTO users (id, email, name, created_at, updated_at, version, deleted_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			user.ID.String(), user.GetEmail().String(), user.GetUserName().String(),
+			user.Created, user.Modified, user.Version, deletedAt)
+		if err != nil {
+			tb.Fatal(err)
+		}
+
+		users = append(users, user)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return db, users
+}
+
+// matchedIDs returns the IDs of users in sorted order.
+func matchedIDs(users []*entities.User) []string {
+	result := pageIDs(users)
+	slices.Sort(result)
+
+	return result
+}
+
+func TestSQLUserMatcherMatchesInMemoryEvaluation(t *testing.T) {
+	db, users := seedMatcherDB(t, 200)
+	matcher := NewSQLUserMatcher(db, log.New(io.Discard))
+
+	domain := repositories.EmailDomainSpec{Domain: "example.com"}
+	recent := repositories.CreatedAfterSpec{Time: matcherNow.Add(-100*time.Hour - time.Millisecond)}
+	active := repositories.ActiveSpec{AsOf: matcherNow.AddDate(0, 0, 25)}
+	prefix := repositories.NamePrefixSpec{Prefix: "Al"}
+
+	tests := []struct {
+		name string
+		spec repositories.UserSpecification
+	}{
+		{name: "domain", spec: domain},
+		{name: "created after", spec: recent},
+		{name: "active", spec: active},
+		{name: "name prefix", spec: prefix},
+		{name: "search", spec: repositories.SearchSpec{Query: "AL"}},
+		{name: "not active", spec: repositories.Not(active)},
+		{name: "and", spec: repositories.And(domain, prefix, recent)},
+		{name: "or", spec: repositories.Or(domain, repositories.Not(prefix))},
+		{name: "empty and", spec: repositories.And()},
+		{name: "empty or", spec: repositories.Or()},
+		{name: "partly in memory", spec: repositories.And(domain, inMemorySpec{prefix})},
+		{name: "wholly in memory", spec: repositories.Or(recent, inMemorySpec{prefix})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := make([]*entities.User, 0)
+
+			for _, user := range users {
+				if !user.IsDeleted() && tt.spec.IsSatisfiedBy(user) {
+					want = append(want, user)
+				}
+			}
+
+			got, err := matcher.FindMatching(t.Context(), tt.spec)
+			if err != nil {
+				t.Fatalf("FindMatching() error = %v", err)
+			}
+
+			if !slices.Equal(matchedIDs(got), matchedIDs(want)) {
+				t.Errorf("FindMatching() returned %d users, in-memory evaluation %d", len(got), len(want))
+			}
+		})
+	}
+}
+
+func TestSQLUserMatcherRestoresUserFields(t *testing.T) {
+	db, users := seedMatcherDB(t, 2)
+
+	got, err := NewSQLUserMatcher(db, log.New(io.Discard)).FindMatching(t.Context(), repositories.And())
+	if err != nil {
+		t.Fatalf("FindMatching() error = %v", err)
+	}
+
+	if len(got) != 1 {
+		t.Fatalf("FindMatching() returned %d users, want 1", len(got))
+	}
+
+	want := users[1]
+	if got[0].ID != want.ID || got[0].GetEmail() != want.GetEmail() || !got[0].Created.Equal(want.Created) ||
+		got[0].Version != want.Version || got[0].IsDeleted() {
+		t.Errorf("FindMatching() = %+v, want %+v", got[0], want)
+	}
+}
+
+func TestSQLUserMatcherFindPageMatchesPageOf(t *testing.T) {
+	db, users := seedMatcherDB(t, 60)
+	matcher := NewSQLUserMatcher(db, log.New(io.Discard))
+
+	active := make([]*entities.User, 0, len(users))
+	for _, user := range users {
+		if !user.IsDeleted() {
+			active = append(active, user)
+		}
+	}
+
+	specs := map[string]repositories.UserSpecification{
+		"all":    nil,
+		"domain": repositories.EmailDomainSpec{Domain: "example.com"},
+		"partly in memory": repositories.And(
+			repositories.EmailDomainSpec{Domain: "example.com"},
+			inMemorySpec{repositories.NamePrefixSpec{Prefix: "Al"}},
+		),
+	}
+	fields := []repositories.UserSortField{
+		repositories.SortByCreated, repositories.SortByModified, repositories.SortByEmail, repositories.SortByName,
+	}
+
+	for name, spec := range specs {
+		for _, field := range fields {
+			for _, descending := range []bool{false, true} {
+				query := repositories.UserPageQuery{
+					Spec:   spec,
+					Sort:   repositories.UserSort{Field: field, Descending: descending},
+					Offset: 5,
+					Limit:  7,
+				}
+
+				t.Run(fmt.Sprintf("%s/%s/desc=%v", name, field, descending), func(t *testing.T) {
+					got, err := matcher.FindPage(t.Context(), query)
+					if err != nil {
+						t.Fatalf("FindPage() error = %v", err)
+					}
+
+					want := repositories.PageOf(active, query)
+					if got.Total != want.Total || !slices.Equal(pageIDs(got.Users), pageIDs(want.Users)) {
+						t.Errorf("FindPage() = %v of %d, want %v of %d",
+							pageIDs(got.Users), got.Total, pageIDs(want.Users), want.Total)
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestSQLUserMatcherSearchMatchesInMemory(t *testing.T) {
+	db, users := seedMatcherDB(t, 30)
+	matcher := NewSQLUserMatcher(db, log.New(io.Discard))
+
+	for _, extra := range [][2]string{
+		{"a_b@example.com", "Émile"},
+		{"a%b@example.com", "émile"},
+		{"axb@example.com", "under_score"},
+		{"Mixed.Case@example.com", "Bob"},
+	} {
+		user, err := entities.NewUser(ids.MustGenerateUserID(), extra[0], extra[1], time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = db.ExecContext(t.Context(),
+			`INSERT INTO users (id, email, name, created_at, updated_at, version) VALUES (?, ?, ?, ?, ?, ?)`,
+			user.ID.String(), user.GetEmail().String(), user.GetUserName().String(),
+			user.Created, user.Modified, user.Version)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		users = append(users, user)
+	}
+
+	active := make([]*entities.User, 0, len(users))
+	for _, user := range users {
+		if !user.IsDeleted() {
+			active = append(active, user)
+		}
+	}
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{query: "a_b", want: 1},
+		{query: "a%", want: 1},
+		{query: "r_s", want: 1},
+		{query: `\`, want: 0},
+		{query: "mixed.c", want: 1},
+		{query: "ALICE", want: 13},
+		{query: "ice 1", want: 5},
+		{query: "É", want: 1},
+		{query: "nobody", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got, err := matcher.Search(t.Context(), tt.query, 0)
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+
+			want := repositories.PageOf(active, repositories.SearchPageQuery(tt.query, 0)).Users
+			if !slices.Equal(pageIDs(got), pageIDs(want)) || len(got) != tt.want {
+				t.Errorf("Search() = %d users, in-memory %d, want %d", len(got), len(want), tt.want)
+			}
+		})
+	}
+}
+
+// pageIDs returns the IDs of users in page order.
+func pageIDs(users []*entities.User) []string {
+	result := make([]string, 0, len(users))
+	for _, user := range users {
+		result = append(result, user.ID.String())
+	}
+
+	return result
+}
+
+func BenchmarkFindMatching(b *testing.B) {
+	db, _ := seedMatcherDB(b, 10_000)
+	matcher := NewSQLUserMatcher(db, log.New(io.Discard))
+	spec := repositories.And(
+		repositories.EmailDomainSpec{Domain: "rare.org"},
+		repositories.NamePrefixSpec{Prefix: "Bob"},
+	)
+
+	for _, bench := range []struct {
+		name string
+		spec repositories.UserSpecification
+	}{
+		{name: "pushdown", spec: spec},
+		{name: "in-memory", spec: inMemorySpec{spec}},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			for b.Loop() {
+				_, err := matcher.FindMatching(b.Context(), bench.spec)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
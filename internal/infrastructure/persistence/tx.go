@@ -0,0 +1,102 @@
+// Package persistence implements repository transactions on database/sql.
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	stderrors "errors"
+	"fmt"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/repositories"
+)
+
+// Beginner starts transactions. *sql.DB satisfies it, and so does
+// *infrastructure.Database, which retries while SQLite is busy.
+type Beginner interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// DBTX is the query surface shared by *sql.DB and *sql.Tx, matching the
+// interface sqlc generates for its Queries type.
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type txKey struct{}
+
+var _ repositories.TxManager = (*TxManager)(nil)
+
+// TxManager runs units of work in a sql.Tx carried by the context.
+type TxManager struct {
+	db   Beginner
+	opts *sql.TxOptions
+}
+
+// NewTxManager creates a transaction manager on db. opts may be nil.
+func NewTxManager(db Beginner, opts *sql.TxOptions) *TxManager {
+	return &TxManager{db: db, opts: opts}
+}
+
+// WithinTx runs fn in a transaction stored in its context. It commits when
+// fn returns nil and rolls back on an error or panic. A context that
+// already carries a transaction joins it instead of starting another.
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := TxFromContext(ctx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := m.db.BeginTx(ctx, m.opts)
+	if err != nil {
+		return fmt.Errorf("begin unit of work: %w", err)
+	}
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			_ = tx.Rollback()
+
+			panic(recovered)
+		}
+	}()
+
+	err = fn(context.WithValue(ctx, txKey{}, tx))
+	if err != nil {
+		return stderrors.Join(err, rollback(tx))
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("commit unit of work: %w", err)
+	}
+
+	return nil
+}
+
+// TxFromContext returns the transaction started by WithinTx, if any.
+func TxFromContext(ctx context.Context) (*sql.Tx, bool) {
+	tx, ok := ctx.Value(txKey{}).(*sql.Tx)
+
+	return tx, ok
+}
+
+// Executor returns the transaction in ctx, or db outside a unit of work.
+// SQL repositories run every query through it, so they take part in a
+// transaction without being told about it.
+func Executor(ctx context.Context, db DBTX) DBTX {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx
+	}
+
+	return db
+}
+
+func rollback(tx *sql.Tx) error {
+	err := tx.Rollback()
+	if err != nil {
+		return fmt.Errorf("roll back unit of work: %w", err)
+	}
+
+	return nil
+}
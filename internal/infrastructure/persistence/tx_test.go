@@ -0,0 +1,173 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	stderrors "errors"
+	"slices"
+	"sync"
+	"testing"
+)
+
+var errUnitOfWork = stderrors.New("unit of work failed")
+
+// txLog is a database/sql driver that records transaction boundaries.
+type txLog struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (l *txLog) record(event string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.events = append(l.events, event)
+}
+
+func (l *txLog) Open(string) (driver.Conn, error) { return &txLogConn{log: l}, nil }
+
+type txLogConn struct{ log *txLog }
+
+func (c *txLogConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c *txLogConn) Close() error                        { return nil }
+
+func (c *txLogConn) Begin() (driver.Tx, error) {
+	c.log.record("begin")
+
+	return txLogTx{log: c.log}, nil
+}
+
+type txLogTx struct{ log *txLog }
+
+func (t txLogTx) Commit() error {
+	t.log.record("commit")
+
+	return nil
+}
+
+func (t txLogTx) Rollback() error {
+	t.log.record("rollback")
+
+	return nil
+}
+
+func openTxLog(t *testing.T) (*sql.DB, *txLog) {
+	t.Helper()
+
+	log := &txLog{} //nolint:exhaustruct // starts empty
+
+	db := sql.OpenDB(txLogConnector{log: log})
+	t.Cleanup(func() { _ = db.Close() })
+
+	return db, log
+}
+
+type txLogConnector struct{ log *txLog }
+
+func (c txLogConnector) Connect(context.Context) (driver.Conn, error) { return c.log.Open("") }
+func (c txLogConnector) Driver() driver.Driver                        { return c.log }
+
+func TestWithinTxCommitsOnSuccess(t *testing.T) {
+	db, log := openTxLog(t)
+	manager := NewTxManager(db, nil)
+
+	var inTx bool
+
+	err := manager.WithinTx(t.Context(), func(ctx context.Context) error {
+		_, inTx = TxFromContext(ctx)
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !inTx {
+		t.Error("context passed to fn carries no transaction")
+	}
+
+	assertEvents(t, log, "begin", "commit")
+}
+
+func TestWithinTxRollsBackOnError(t *testing.T) {
+	db, log := openTxLog(t)
+
+	err := NewTxManager(db, nil).WithinTx(t.Context(), func(context.Context) error {
+		return errUnitOfWork
+	})
+	if !stderrors.Is(err, errUnitOfWork) {
+		t.Fatalf("error = %v, want errUnitOfWork", err)
+	}
+
+	assertEvents(t, log, "begin", "rollback")
+}
+
+func TestWithinTxRollsBackOnPanic(t *testing.T) {
+	db, log := openTxLog(t)
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("panic was swallowed")
+		}
+
+		assertEvents(t, log, "begin", "rollback")
+	}()
+
+	_ = NewTxManager(db, nil).WithinTx(t.Context(), func(context.Context) error {
+		panic("unit of work panicked")
+	})
+}
+
+func TestWithinTxJoinsOuterTransaction(t *testing.T) {
+	db, log := openTxLog(t)
+	manager := NewTxManager(db, nil)
+
+	err := manager.WithinTx(t.Context(), func(outer context.Context) error {
+		outerTx, _ := TxFromContext(outer)
+
+		return manager.WithinTx(outer, func(inner context.Context) error {
+			if innerTx, _ := TxFromContext(inner); innerTx != outerTx {
+				t.Error("nested WithinTx started a second transaction")
+			}
+
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assertEvents(t, log, "begin", "commit")
+}
+
+func TestExecutor(t *testing.T) {
+	db, _ := openTxLog(t)
+
+	if got := Executor(t.Context(), db); got != db {
+		t.Errorf("Executor outside a transaction = %T, want the *sql.DB", got)
+	}
+
+	err := NewTxManager(db, nil).WithinTx(t.Context(), func(ctx context.Context) error {
+		tx, _ := TxFromContext(ctx)
+		if got := Executor(ctx, db); got != tx {
+			t.Errorf("Executor inside a transaction = %T, want the *sql.Tx", got)
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func assertEvents(t *testing.T, log *txLog, want ...string) {
+	t.Helper()
+
+	log.mu.Lock()
+	defer log.mu.Unlock()
+
+	if !slices.Equal(log.events, want) {
+		t.Errorf("events = %v, want %v", log.events, want)
+	}
+}
@@ -0,0 +1,183 @@
+package infrastructure
+
+import (
+	"context"
+	stderrors "errors"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Retry outcomes recorded by RetryMetrics.
+const (
+	RetryOutcomeRecovered = "recovered"
+	RetryOutcomeExhausted = "exhausted"
+	RetryOutcomeCanceled  = "canceled"
+)
+
+const (
+	defaultRetryMaxAttempts = 5
+	defaultRetryMaxElapsed  = 2 * time.Second
+	defaultRetryBaseDelay   = 10 * time.Millisecond
+	defaultRetryMaxDelay    = 500 * time.Millisecond
+)
+
+// Postgres SQLSTATE codes that are safe to retry.
+const (
+	sqlStateSerializationFailure = "40001"
+	sqlStateDeadlockDetected     = "40P01"
+)
+
+// sqliteRetriableMessages are the driver messages for SQLITE_BUSY and SQLITE_LOCKED.
+var sqliteRetriableMessages = []string{
+	"database is locked",
+	"database table is locked",
+	"sqlite_busy",
+	"sqlite_locked",
+}
+
+// RetryPolicy bounds how long a transient database error is retried.
+type RetryPolicy struct {
+	MaxAttempts int
+	MaxElapsed  time.Duration
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// DefaultRetryPolicy returns the policy used when none is configured.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: defaultRetryMaxAttempts,
+		MaxElapsed:  defaultRetryMaxElapsed,
+		BaseDelay:   defaultRetryBaseDelay,
+		MaxDelay:    defaultRetryMaxDelay,
+	}
+}
+
+// RetryMetrics receives one event per retried operation.
+type RetryMetrics interface {
+	RecordRetry(operation, outcome string, retries int)
+}
+
+// RetryCounter is an in-process RetryMetrics counting retries by operation and outcome.
+type RetryCounter struct {
+	mu     sync.Mutex
+	counts map[string]map[string]int
+}
+
+// NewRetryCounter creates an empty RetryCounter.
+func NewRetryCounter() *RetryCounter {
+	return &RetryCounter{counts: make(map[string]map[string]int)} //nolint:exhaustruct // mu has a valid zero value
+}
+
+// RecordRetry implements RetryMetrics.
+func (c *RetryCounter) RecordRetry(operation, outcome string, retries int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.counts[operation] == nil {
+		c.counts[operation] = make(map[string]int)
+	}
+
+	c.counts[operation][outcome] += retries
+}
+
+// Count returns the number of retries recorded for operation and outcome.
+func (c *RetryCounter) Count(operation, outcome string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.counts[operation][outcome]
+}
+
+// IsRetriableDBError reports whether err is a transient lock or serialization
+// failure that can succeed when the same operation runs again.
+func IsRetriableDBError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var stateErr interface{ SQLState() string }
+	if stderrors.As(err, &stateErr) {
+		state := stateErr.SQLState()
+
+		return state == sqlStateSerializationFailure || state == sqlStateDeadlockDetected
+	}
+
+	message := strings.ToLower(err.Error())
+	for _, retriable := range sqliteRetriableMessages {
+		if strings.Contains(message, retriable) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Retrier re-runs database operations that failed with a retriable error.
+// Only wrap operations that are safe to repeat: reads, single statements,
+// or transactions that were rolled back before returning the error.
+type Retrier struct {
+	policy  RetryPolicy
+	metrics RetryMetrics
+}
+
+// NewRetrier creates a Retrier. metrics may be nil.
+func NewRetrier(policy RetryPolicy, metrics RetryMetrics) *Retrier {
+	return &Retrier{policy: policy, metrics: metrics}
+}
+
+// Do runs fn until it succeeds, returns a non-retriable error, or the policy
+// budget is spent. On exhaustion the last driver error is returned unchanged.
+func (r *Retrier) Do(ctx context.Context, operation string, fn func(context.Context) error) error {
+	start := time.Now()
+
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			r.record(operation, RetryOutcomeRecovered, attempt-1)
+
+			return nil
+		}
+
+		if !IsRetriableDBError(err) {
+			return err
+		}
+
+		delay := r.backoff(attempt)
+		if attempt >= r.policy.MaxAttempts || time.Since(start)+delay > r.policy.MaxElapsed {
+			r.record(operation, RetryOutcomeExhausted, attempt-1)
+
+			return err
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			r.record(operation, RetryOutcomeCanceled, attempt)
+
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns an exponential delay with full jitter for the given attempt.
+func (r *Retrier) backoff(attempt int) time.Duration {
+	ceiling := min(r.policy.BaseDelay<<min(attempt-1, 30), r.policy.MaxDelay)
+	if ceiling <= 0 {
+		return 0
+	}
+
+	return rand.N(ceiling) //nolint:gosec // jitter does not need a cryptographic source
+}
+
+func (r *Retrier) record(operation, outcome string, retries int) {
+	if r.metrics == nil || (retries == 0 && outcome == RetryOutcomeRecovered) {
+		return
+	}
+
+	r.metrics.RecordRetry(operation, outcome, retries)
+}
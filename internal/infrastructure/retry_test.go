@@ -0,0 +1,139 @@
+package infrastructure
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+)
+
+var errBusy = stderrors.New("database is locked (5) (SQLITE_BUSY)")
+
+type sqlStateError string
+
+func (e sqlStateError) Error() string    { return "pq: " + string(e) }
+func (e sqlStateError) SQLState() string { return string(e) }
+
+func fastRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 4,
+		MaxElapsed:  time.Second,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    2 * time.Millisecond,
+	}
+}
+
+func TestIsRetriableDBError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "sqlite busy", err: errBusy, want: true},
+		{name: "sqlite locked table", err: stderrors.New("database table is locked"), want: true},
+		{name: "postgres serialization failure", err: sqlStateError("40001"), want: true},
+		{name: "postgres deadlock", err: sqlStateError("40P01"), want: true},
+		{name: "postgres unique violation", err: sqlStateError("23505"), want: false},
+		{name: "constraint failure", err: stderrors.New("UNIQUE constraint failed: users.email"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetriableDBError(tt.err); got != tt.want {
+				t.Errorf("IsRetriableDBError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetrierRecoversWithinBudget(t *testing.T) {
+	counter := NewRetryCounter()
+	calls := 0
+
+	err := NewRetrier(fastRetryPolicy(), counter).Do(context.Background(), "save_user", func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return errBusy
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Do() error = %v, want nil", err)
+	}
+
+	if calls != 3 {
+		t.Errorf("expected 3 calls, got %d", calls)
+	}
+
+	if got := counter.Count("save_user", RetryOutcomeRecovered); got != 2 {
+		t.Errorf("recovered retries = %d, want 2", got)
+	}
+}
+
+func TestRetrierExhaustionReturnsOriginalError(t *testing.T) {
+	counter := NewRetryCounter()
+	calls := 0
+
+	err := NewRetrier(fastRetryPolicy(), counter).Do(context.Background(), "list_users", func(context.Context) error {
+		calls++
+
+		return errBusy
+	})
+	if err != errBusy { //nolint:errorlint // the original error must pass through unwrapped
+		t.Fatalf("Do() error = %v, want original busy error", err)
+	}
+
+	if calls != fastRetryPolicy().MaxAttempts {
+		t.Errorf("expected %d calls, got %d", fastRetryPolicy().MaxAttempts, calls)
+	}
+
+	if got := counter.Count("list_users", RetryOutcomeExhausted); got != calls-1 {
+		t.Errorf("exhausted retries = %d, want %d", got, calls-1)
+	}
+}
+
+func TestRetrierNonRetriablePassthrough(t *testing.T) {
+	errConstraint := stderrors.New("UNIQUE constraint failed: users.email")
+	calls := 0
+
+	err := NewRetrier(fastRetryPolicy(), nil).Do(context.Background(), "save_user", func(context.Context) error {
+		calls++
+
+		return errConstraint
+	})
+	if err != errConstraint { //nolint:errorlint // the original error must pass through unwrapped
+		t.Fatalf("Do() error = %v, want %v", err, errConstraint)
+	}
+
+	if calls != 1 {
+		t.Errorf("non-retriable error retried: %d calls", calls)
+	}
+}
+
+func TestRetrierContextCancellationAbortsSleep(t *testing.T) {
+	policy := RetryPolicy{
+		MaxAttempts: 10,
+		MaxElapsed:  time.Minute,
+		BaseDelay:   10 * time.Second,
+		MaxDelay:    10 * time.Second,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+
+	// Jitter can pick a near-zero delay, so keep failing until cancellation wins.
+	err := NewRetrier(policy, nil).Do(ctx, "get_user", func(context.Context) error {
+		return errBusy
+	})
+	if !stderrors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Do() error = %v, want context deadline exceeded", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("backoff sleep was not aborted promptly: %s", elapsed)
+	}
+}
@@ -0,0 +1,148 @@
+package infrastructure
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"runtime/debug"
+	"slices"
+	"time"
+)
+
+const unknownBinaryVersion = "unknown"
+
+// Migration is one embedded schema migration, identified by a sortable name
+// such as "0001_create_users".
+type Migration struct {
+	Name string
+	SQL  string
+}
+
+// Checksum returns the SHA-256 of the migration SQL.
+func (m Migration) Checksum() string {
+	sum := sha256.Sum256([]byte(m.SQL))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// AppliedMigration is the metadata recorded when a migration runs.
+type AppliedMigration struct {
+	Name      string        `json:"name"`
+	Checksum  string        `json:"checksum"`
+	AppliedAt time.Time     `json:"applied_at"`
+	Duration  time.Duration `json:"duration"`
+	AppliedBy string        `json:"applied_by"`
+}
+
+// NewAppliedMigration records m as applied at appliedAt by the running binary.
+func NewAppliedMigration(m Migration, appliedAt time.Time, duration time.Duration) AppliedMigration {
+	return AppliedMigration{
+		Name:      m.Name,
+		Checksum:  m.Checksum(),
+		AppliedAt: appliedAt.UTC(),
+		Duration:  duration,
+		AppliedBy: BinaryVersion(),
+	}
+}
+
+// BinaryVersion returns the module version from build info.
+func BinaryVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok || info.Main.Version == "" {
+		return unknownBinaryVersion
+	}
+
+	return info.Main.Version
+}
+
+// ChecksumMismatch reports a migration whose SQL changed after it was applied.
+type ChecksumMismatch struct {
+	Name     string `json:"name"`
+	Applied  string `json:"applied_checksum"`
+	Embedded string `json:"embedded_checksum"`
+}
+
+// SchemaStatus compares the migrations applied to a database with the
+// migrations embedded in the running binary.
+type SchemaStatus struct {
+	Version            string             `json:"version"`
+	History            []AppliedMigration `json:"history"`
+	Pending            []string           `json:"pending"`
+	Unknown            []string           `json:"unknown"`
+	ChecksumMismatches []ChecksumMismatch `json:"checksum_mismatches"`
+	Dirty              bool               `json:"dirty"`
+}
+
+// BuildSchemaStatus derives the schema status from the embedded and applied migrations.
+// Dirty is set when the database holds migrations this binary does not know,
+// which usually means the binary was downgraded.
+func BuildSchemaStatus(embedded []Migration, applied []AppliedMigration) SchemaStatus {
+	byName := make(map[string]Migration, len(embedded))
+	for _, m := range embedded {
+		byName[m.Name] = m
+	}
+
+	appliedNames := make(map[string]bool, len(applied))
+	status := SchemaStatus{
+		Version:            "",
+		History:            slices.Clone(applied),
+		Pending:            []string{},
+		Unknown:            []string{},
+		ChecksumMismatches: []ChecksumMismatch{},
+		Dirty:              false,
+	}
+
+	slices.SortFunc(status.History, func(a, b AppliedMigration) int {
+		return a.AppliedAt.Compare(b.AppliedAt)
+	})
+
+	for _, a := range status.History {
+		appliedNames[a.Name] = true
+		status.Version = max(status.Version, a.Name)
+
+		m, known := byName[a.Name]
+		if !known {
+			status.Unknown = append(status.Unknown, a.Name)
+
+			continue
+		}
+
+		if checksum := m.Checksum(); checksum != a.Checksum {
+			status.ChecksumMismatches = append(status.ChecksumMismatches, ChecksumMismatch{
+				Name:     a.Name,
+				Applied:  a.Checksum,
+				Embedded: checksum,
+			})
+		}
+	}
+
+	for _, m := range embedded {
+		if !appliedNames[m.Name] {
+			status.Pending = append(status.Pending, m.Name)
+		}
+	}
+
+	slices.Sort(status.Pending)
+	status.Dirty = len(status.Unknown) > 0
+
+	return status
+}
+
+// Warnings returns human-readable problems for health checks and logs.
+// An empty result means the schema matches the binary.
+func (s SchemaStatus) Warnings() []string {
+	warnings := make([]string, 0, len(s.Unknown)+len(s.ChecksumMismatches))
+
+	for _, name := range s.Unknown {
+		warnings = append(warnings, fmt.Sprintf(
+			"migration %s is applied but unknown to this binary (downgrade?)", name))
+	}
+
+	for _, mismatch := range s.ChecksumMismatches {
+		warnings = append(warnings, fmt.Sprintf(
+			"migration %s was edited after it was applied: checksum %s, embedded %s",
+			mismatch.Name, mismatch.Applied, mismatch.Embedded))
+	}
+
+	return warnings
+}
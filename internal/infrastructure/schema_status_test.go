@@ -0,0 +1,111 @@
+package infrastructure
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+var testMigrations = []Migration{
+	{Name: "0001_create_users", SQL: "CREATE TABLE users (id TEXT PRIMARY KEY);"},
+	{Name: "0002_add_email_index", SQL: "CREATE INDEX idx_users_email ON users(email);"},
+	{Name: "0003_create_settings", SQL: "CREATE TABLE settings (key TEXT PRIMARY KEY);"},
+}
+
+func applied(m Migration, at time.Time) AppliedMigration {
+	return NewAppliedMigration(m, at, 5*time.Millisecond)
+}
+
+func TestNewAppliedMigrationRecordsMetadata(t *testing.T) {
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.FixedZone("CET", 3600))
+	record := NewAppliedMigration(testMigrations[0], at, 12*time.Millisecond)
+
+	if record.Name != "0001_create_users" {
+		t.Errorf("Name = %q", record.Name)
+	}
+
+	if record.Checksum != testMigrations[0].Checksum() || len(record.Checksum) != 64 {
+		t.Errorf("Checksum = %q, want sha256 of the SQL", record.Checksum)
+	}
+
+	if !record.AppliedAt.Equal(at) || record.AppliedAt.Location() != time.UTC {
+		t.Errorf("AppliedAt = %v, want %v in UTC", record.AppliedAt, at)
+	}
+
+	if record.Duration != 12*time.Millisecond {
+		t.Errorf("Duration = %v", record.Duration)
+	}
+
+	if record.AppliedBy == "" {
+		t.Error("AppliedBy should carry the binary version")
+	}
+}
+
+func TestBuildSchemaStatusHistoryAndPending(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	history := []AppliedMigration{
+		applied(testMigrations[1], base.Add(time.Hour)),
+		applied(testMigrations[0], base),
+	}
+
+	status := BuildSchemaStatus(testMigrations, history)
+
+	if status.Version != "0002_add_email_index" {
+		t.Errorf("Version = %q, want 0002_add_email_index", status.Version)
+	}
+
+	if status.History[0].Name != "0001_create_users" {
+		t.Errorf("history is not ordered by applied_at: %v", status.History)
+	}
+
+	if !slices.Equal(status.Pending, []string{"0003_create_settings"}) {
+		t.Errorf("Pending = %v", status.Pending)
+	}
+
+	if status.Dirty || len(status.Warnings()) != 0 {
+		t.Errorf("clean schema reported problems: %v", status.Warnings())
+	}
+}
+
+func TestBuildSchemaStatusUnknownMigration(t *testing.T) {
+	future := Migration{Name: "0004_from_newer_binary", SQL: "ALTER TABLE users ADD COLUMN x TEXT;"}
+	history := []AppliedMigration{
+		applied(testMigrations[0], time.Now()),
+		applied(future, time.Now()),
+	}
+
+	status := BuildSchemaStatus(testMigrations, history)
+
+	if !status.Dirty {
+		t.Error("expected unknown applied migration to mark the schema dirty")
+	}
+
+	if !slices.Equal(status.Unknown, []string{"0004_from_newer_binary"}) {
+		t.Errorf("Unknown = %v", status.Unknown)
+	}
+
+	if len(status.Warnings()) != 1 {
+		t.Errorf("Warnings() = %v, want one downgrade warning", status.Warnings())
+	}
+}
+
+func TestBuildSchemaStatusChecksumMismatch(t *testing.T) {
+	record := applied(testMigrations[0], time.Now())
+	edited := slices.Clone(testMigrations)
+	edited[0].SQL += "\nCREATE INDEX idx_users_name ON users(name);"
+
+	status := BuildSchemaStatus(edited, []AppliedMigration{record})
+
+	if len(status.ChecksumMismatches) != 1 {
+		t.Fatalf("ChecksumMismatches = %v, want one", status.ChecksumMismatches)
+	}
+
+	mismatch := status.ChecksumMismatches[0]
+	if mismatch.Name != "0001_create_users" || mismatch.Applied == mismatch.Embedded {
+		t.Errorf("unexpected mismatch %+v", mismatch)
+	}
+
+	if len(status.Warnings()) != 1 {
+		t.Errorf("Warnings() = %v, want checksum warning", status.Warnings())
+	}
+}
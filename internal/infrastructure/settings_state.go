@@ -0,0 +1,154 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json/v2"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+const settingsStateSchemaVersion = 1
+
+// secretSettingMarkers identify settings whose values are exported as
+// references only.
+var secretSettingMarkers = []string{"secret", "password", "token", "credential", "private_key", "api_key"}
+
+// SettingsSnapshotStore reads and replaces the whole settings table.
+type SettingsSnapshotStore interface {
+	ListSettings(ctx context.Context) (map[string]string, error)
+	// ReplaceSettings atomically makes the table equal to settings. Keys for
+	// which preserve returns true are neither written nor deleted.
+	ReplaceSettings(ctx context.Context, settings map[string]string, preserve func(key string) bool) error
+}
+
+// settingsDocument is the exported form of the settings table.
+type settingsDocument struct {
+	Values     map[string]string `json:"values"`
+	SecretRefs []string          `json:"secret_refs"`
+}
+
+// SettingsState exports the settings table as a StateSubsystem. The
+// environment marker is never exported, and secret settings are exported
+// by key only: import keeps the target's current value.
+type SettingsState struct {
+	store SettingsSnapshotStore
+}
+
+// NewSettingsState creates the settings subsystem.
+func NewSettingsState(store SettingsSnapshotStore) *SettingsState {
+	return &SettingsState{store: store}
+}
+
+// Name implements StateSubsystem.
+func (s *SettingsState) Name() string { return "settings" }
+
+// SchemaVersion implements StateSubsystem.
+func (s *SettingsState) SchemaVersion() int { return settingsStateSchemaVersion }
+
+// Export implements StateSubsystem.
+func (s *SettingsState) Export(ctx context.Context) ([]byte, error) {
+	current, err := s.store.ListSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	document := settingsDocument{Values: make(map[string]string), SecretRefs: []string{}}
+
+	for key, value := range current {
+		switch {
+		case key == EnvironmentSettingKey:
+		case isSecretSetting(key):
+			document.SecretRefs = append(document.SecretRefs, key)
+		default:
+			document.Values[key] = value
+		}
+	}
+
+	slices.Sort(document.SecretRefs)
+
+	data, err := json.Marshal(document, json.Deterministic(true))
+	if err != nil {
+		return nil, fmt.Errorf("encode settings state: %w", err)
+	}
+
+	return data, nil
+}
+
+// Diff implements StateSubsystem.
+func (s *SettingsState) Diff(ctx context.Context, data []byte) ([]string, error) {
+	document, err := decodeSettingsDocument(data)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := s.store.ListSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []string
+
+	for _, key := range slices.Sorted(maps.Keys(document.Values)) {
+		value, exists := current[key]
+
+		switch {
+		case !exists:
+			changes = append(changes, "add "+key)
+		case value != document.Values[key]:
+			changes = append(changes, "change "+key)
+		}
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(current)) {
+		_, imported := document.Values[key]
+		if !imported && !s.preserved(document, key) {
+			changes = append(changes, "remove "+key)
+		}
+	}
+
+	for _, key := range document.SecretRefs {
+		if _, exists := current[key]; !exists {
+			changes = append(changes, "secret "+key+" must be set manually")
+		}
+	}
+
+	return changes, nil
+}
+
+// Import implements StateSubsystem.
+func (s *SettingsState) Import(ctx context.Context, data []byte) error {
+	document, err := decodeSettingsDocument(data)
+	if err != nil {
+		return err
+	}
+
+	return s.store.ReplaceSettings(ctx, document.Values, func(key string) bool {
+		return s.preserved(document, key)
+	})
+}
+
+// preserved reports whether key keeps its current value on import.
+func (s *SettingsState) preserved(document settingsDocument, key string) bool {
+	return key == EnvironmentSettingKey || isSecretSetting(key) || slices.Contains(document.SecretRefs, key)
+}
+
+func decodeSettingsDocument(data []byte) (settingsDocument, error) {
+	var document settingsDocument
+
+	err := json.Unmarshal(data, &document)
+	if err != nil {
+		return document, fmt.Errorf("decode settings state: %w", err)
+	}
+
+	return document, nil
+}
+
+func isSecretSetting(key string) bool {
+	lower := strings.ToLower(key)
+
+	return slices.ContainsFunc(secretSettingMarkers, func(marker string) bool {
+		return strings.Contains(lower, marker)
+	})
+}
@@ -0,0 +1,315 @@
+package infrastructure
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json/v2"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"time"
+)
+
+// StateArchiveVersion is the archive format written by StateRegistry.Export.
+const StateArchiveVersion = 1
+
+const (
+	stateManifestName = "manifest.json"
+	stateDocumentExt  = ".json"
+
+	// maxStateDocumentSize bounds each archive entry so a corrupt archive
+	// cannot exhaust memory.
+	maxStateDocumentSize = 64 << 20
+)
+
+// StateSubsystem exports and imports one kind of runtime-mutable state.
+// Exports must not contain user data or secret values. Import must be atomic:
+// either the whole document is applied or the subsystem is left unchanged.
+type StateSubsystem interface {
+	Name() string
+	SchemaVersion() int
+	Export(ctx context.Context) ([]byte, error)
+	Diff(ctx context.Context, document []byte) ([]string, error)
+	Import(ctx context.Context, document []byte) error
+}
+
+// StateManifest describes the documents in a state archive.
+type StateManifest struct {
+	FormatVersion int                          `json:"format_version"`
+	Environment   string                       `json:"environment"`
+	CreatedAt     time.Time                    `json:"created_at"`
+	Subsystems    map[string]StateDocumentInfo `json:"subsystems"`
+}
+
+// StateDocumentInfo is the manifest entry of one subsystem document.
+type StateDocumentInfo struct {
+	SchemaVersion int    `json:"schema_version"`
+	Checksum      string `json:"checksum"`
+}
+
+// StateArchive is a verified archive read by ReadStateArchive.
+type StateArchive struct {
+	Manifest  StateManifest
+	Documents map[string][]byte
+}
+
+// StateImportPlan is what an import would do, shown before applying it.
+type StateImportPlan struct {
+	Warnings []string            `json:"warnings"`
+	Changes  map[string][]string `json:"changes"`
+}
+
+// StateImportResult is the outcome of importing one subsystem.
+type StateImportResult struct {
+	Subsystem string `json:"subsystem"`
+	Applied   bool   `json:"applied"`
+	Error     string `json:"error,omitempty"`
+}
+
+// StateRegistry holds the subsystems that take part in export and import.
+type StateRegistry struct {
+	subsystems []StateSubsystem
+}
+
+// NewStateRegistry creates an empty registry.
+func NewStateRegistry() *StateRegistry {
+	return &StateRegistry{subsystems: nil}
+}
+
+// Register adds a subsystem. Names must be unique.
+func (r *StateRegistry) Register(subsystem StateSubsystem) error {
+	if r.subsystem(subsystem.Name()) != nil {
+		return fmt.Errorf("state subsystem %q already registered", subsystem.Name())
+	}
+
+	r.subsystems = append(r.subsystems, subsystem)
+
+	return nil
+}
+
+func (r *StateRegistry) subsystem(name string) StateSubsystem {
+	index := slices.IndexFunc(r.subsystems, func(s StateSubsystem) bool { return s.Name() == name })
+	if index < 0 {
+		return nil
+	}
+
+	return r.subsystems[index]
+}
+
+// Export writes every registered subsystem to w as a tar.gz archive with a
+// manifest of schema versions and checksums.
+func (r *StateRegistry) Export(ctx context.Context, w io.Writer, environment string, now time.Time) error {
+	manifest := StateManifest{
+		FormatVersion: StateArchiveVersion,
+		Environment:   environment,
+		CreatedAt:     now.UTC(),
+		Subsystems:    make(map[string]StateDocumentInfo, len(r.subsystems)),
+	}
+	documents := make(map[string][]byte, len(r.subsystems))
+
+	for _, subsystem := range r.subsystems {
+		document, err := subsystem.Export(ctx)
+		if err != nil {
+			return fmt.Errorf("export state %s: %w", subsystem.Name(), err)
+		}
+
+		documents[subsystem.Name()] = document
+		manifest.Subsystems[subsystem.Name()] = StateDocumentInfo{
+			SchemaVersion: subsystem.SchemaVersion(),
+			Checksum:      stateChecksum(document),
+		}
+	}
+
+	manifestData, err := json.Marshal(manifest, json.Deterministic(true))
+	if err != nil {
+		return fmt.Errorf("encode state manifest: %w", err)
+	}
+
+	return writeStateArchive(w, manifestData, documents, now)
+}
+
+func writeStateArchive(w io.Writer, manifest []byte, documents map[string][]byte, now time.Time) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	err := writeTarEntry(tw, stateManifestName, manifest, now)
+
+	for _, name := range slices.Sorted(maps.Keys(documents)) {
+		if err != nil {
+			break
+		}
+
+		err = writeTarEntry(tw, name+stateDocumentExt, documents[name], now)
+	}
+
+	err = stderrors.Join(err, tw.Close(), gz.Close())
+	if err != nil {
+		return fmt.Errorf("write state archive: %w", err)
+	}
+
+	return nil
+}
+
+func writeTarEntry(tw *tar.Writer, name string, data []byte, modTime time.Time) error {
+	err := tw.WriteHeader(&tar.Header{ //nolint:exhaustruct // only regular-file fields apply
+		Name:    name,
+		Mode:    0o600,
+		Size:    int64(len(data)),
+		ModTime: modTime.UTC(),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = tw.Write(data)
+
+	return err
+}
+
+// ReadStateArchive reads an archive written by Export and verifies every
+// document against the manifest checksums.
+func ReadStateArchive(r io.Reader) (*StateArchive, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("open state archive: %w", err)
+	}
+	defer gz.Close()
+
+	entries, err := readTarEntries(tar.NewReader(gz))
+	if err != nil {
+		return nil, err
+	}
+
+	manifestData, ok := entries[stateManifestName]
+	if !ok {
+		return nil, stderrors.New("state archive has no manifest")
+	}
+
+	archive := &StateArchive{Documents: make(map[string][]byte)} //nolint:exhaustruct // manifest decoded below
+
+	err = json.Unmarshal(manifestData, &archive.Manifest)
+	if err != nil {
+		return nil, fmt.Errorf("decode state manifest: %w", err)
+	}
+
+	for name, info := range archive.Manifest.Subsystems {
+		document, ok := entries[name+stateDocumentExt]
+		if !ok {
+			return nil, fmt.Errorf("state archive is missing the %s document", name)
+		}
+
+		if stateChecksum(document) != info.Checksum {
+			return nil, fmt.Errorf("state document %s does not match its checksum", name)
+		}
+
+		archive.Documents[name] = document
+	}
+
+	return archive, nil
+}
+
+func readTarEntries(tr *tar.Reader) (map[string][]byte, error) {
+	entries := make(map[string][]byte)
+
+	for {
+		header, err := tr.Next()
+		if stderrors.Is(err, io.EOF) {
+			return entries, nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("read state archive: %w", err)
+		}
+
+		if header.Size > maxStateDocumentSize {
+			return nil, fmt.Errorf("state archive entry %s exceeds %d bytes", header.Name, maxStateDocumentSize)
+		}
+
+		data, err := io.ReadAll(io.LimitReader(tr, maxStateDocumentSize))
+		if err != nil {
+			return nil, fmt.Errorf("read state archive entry %s: %w", header.Name, err)
+		}
+
+		entries[header.Name] = data
+	}
+}
+
+// Plan checks that archive can be imported into environment and returns the
+// per-subsystem diff. Incompatible versions are errors; an environment
+// mismatch or a subsystem this binary does not know is a warning.
+func (r *StateRegistry) Plan(ctx context.Context, archive *StateArchive, environment string) (StateImportPlan, error) {
+	plan := StateImportPlan{Warnings: nil, Changes: make(map[string][]string)}
+
+	if archive.Manifest.FormatVersion > StateArchiveVersion {
+		return plan, fmt.Errorf("state archive format %d is newer than supported format %d",
+			archive.Manifest.FormatVersion, StateArchiveVersion)
+	}
+
+	if archive.Manifest.Environment != environment {
+		plan.Warnings = append(plan.Warnings, fmt.Sprintf(
+			"archive was exported from environment %q, importing into %q",
+			archive.Manifest.Environment, environment))
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(archive.Manifest.Subsystems)) {
+		subsystem := r.subsystem(name)
+		if subsystem == nil {
+			plan.Warnings = append(plan.Warnings,
+				fmt.Sprintf("subsystem %q is not registered and will be skipped", name))
+
+			continue
+		}
+
+		info := archive.Manifest.Subsystems[name]
+		if info.SchemaVersion > subsystem.SchemaVersion() {
+			return plan, fmt.Errorf("state %s has schema version %d, newer than supported version %d",
+				name, info.SchemaVersion, subsystem.SchemaVersion())
+		}
+
+		changes, err := subsystem.Diff(ctx, archive.Documents[name])
+		if err != nil {
+			return plan, fmt.Errorf("diff state %s: %w", name, err)
+		}
+
+		plan.Changes[name] = changes
+	}
+
+	return plan, nil
+}
+
+// Apply imports every registered subsystem present in archive. Subsystems are
+// independent: a failure is reported and leaves that subsystem unchanged
+// while the others are still applied. Call Plan first.
+func (r *StateRegistry) Apply(ctx context.Context, archive *StateArchive) []StateImportResult {
+	results := make([]StateImportResult, 0, len(archive.Documents))
+
+	for _, subsystem := range r.subsystems {
+		document, ok := archive.Documents[subsystem.Name()]
+		if !ok {
+			continue
+		}
+
+		result := StateImportResult{Subsystem: subsystem.Name(), Applied: true, Error: ""}
+
+		err := subsystem.Import(ctx, document)
+		if err != nil {
+			result.Applied = false
+			result.Error = err.Error()
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+func stateChecksum(data []byte) string {
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
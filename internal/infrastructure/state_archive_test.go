@@ -0,0 +1,339 @@
+package infrastructure
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"maps"
+	"strings"
+	"testing"
+	"time"
+)
+
+var errImportFailed = errors.New("import failed")
+
+// fakeSnapshotStore is an in-memory SettingsSnapshotStore whose replace is
+// all-or-nothing, like the transactional Database implementation.
+type fakeSnapshotStore struct {
+	values     map[string]string
+	replaceErr error
+}
+
+func (s *fakeSnapshotStore) ListSettings(context.Context) (map[string]string, error) {
+	return maps.Clone(s.values), nil
+}
+
+func (s *fakeSnapshotStore) ReplaceSettings(
+	_ context.Context,
+	settings map[string]string,
+	preserve func(string) bool,
+) error {
+	if s.replaceErr != nil {
+		return s.replaceErr
+	}
+
+	next := make(map[string]string)
+
+	for key, value := range s.values {
+		if preserve(key) {
+			next[key] = value
+		}
+	}
+
+	for key, value := range settings {
+		if !preserve(key) {
+			next[key] = value
+		}
+	}
+
+	s.values = next
+
+	return nil
+}
+
+func newTestRegistry(t *testing.T, store SettingsSnapshotStore) *StateRegistry {
+	t.Helper()
+
+	registry := NewStateRegistry()
+
+	err := registry.Register(NewSettingsState(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return registry
+}
+
+func exportArchive(t *testing.T, registry *StateRegistry, env string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	err := registry.Export(t.Context(), &buf, env, time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestStateArchiveRoundTrip(t *testing.T) {
+	source := &fakeSnapshotStore{values: map[string]string{
+		"maintenance_banner":  "off",
+		"quota.default":       "100",
+		EnvironmentSettingKey: "production",
+		"smtp_password":       "hunter2",
+	}}
+	data := exportArchive(t, newTestRegistry(t, source), "production")
+
+	target := &fakeSnapshotStore{values: map[string]string{
+		"stale":               "x",
+		EnvironmentSettingKey: "production",
+		"smtp_password":       "target-secret",
+	}}
+	registry := newTestRegistry(t, target)
+
+	archive, err := ReadStateArchive(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ReadStateArchive() error = %v", err)
+	}
+
+	plan, err := registry.Plan(t.Context(), archive, "production")
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+
+	if len(plan.Warnings) != 0 {
+		t.Errorf("unexpected warnings: %v", plan.Warnings)
+	}
+
+	want := "add maintenance_banner, add quota.default, remove stale"
+	if got := strings.Join(plan.Changes["settings"], ", "); got != want {
+		t.Errorf("changes = %q, want %q", got, want)
+	}
+
+	results := registry.Apply(t.Context(), archive)
+	if len(results) != 1 || !results[0].Applied {
+		t.Fatalf("Apply() = %+v", results)
+	}
+
+	wantValues := map[string]string{
+		"maintenance_banner":  "off",
+		"quota.default":       "100",
+		EnvironmentSettingKey: "production",
+		"smtp_password":       "target-secret",
+	}
+	if !maps.Equal(target.values, wantValues) {
+		t.Errorf("imported settings = %v, want %v", target.values, wantValues)
+	}
+}
+
+func TestStateArchiveNeverContainsSecretValues(t *testing.T) {
+	store := &fakeSnapshotStore{values: map[string]string{
+		"smtp_password":    "hunter2",
+		"webhook.token":    "tok-123",
+		"stripe_api_key":   "sk_live_abc",
+		"feature.checkout": "on",
+	}}
+	data := exportArchive(t, newTestRegistry(t, store), "staging")
+
+	contents := archiveContents(t, data)
+	for _, secret := range []string{"hunter2", "tok-123", "sk_live_abc"} {
+		if strings.Contains(contents, secret) {
+			t.Errorf("archive contains secret value %q", secret)
+		}
+	}
+
+	if !strings.Contains(contents, "smtp_password") {
+		t.Error("archive should reference the secret setting by key")
+	}
+}
+
+func TestStateArchiveDetectsTampering(t *testing.T) {
+	store := &fakeSnapshotStore{values: map[string]string{"maintenance_banner": "off"}}
+	data := exportArchive(t, newTestRegistry(t, store), "staging")
+
+	tampered := rewriteArchive(t, data, "settings.json", func(doc []byte) []byte {
+		return bytes.Replace(doc, []byte(`"off"`), []byte(`"on!"`), 1)
+	})
+
+	_, err := ReadStateArchive(bytes.NewReader(tampered))
+	if err == nil || !strings.Contains(err.Error(), "checksum") {
+		t.Errorf("ReadStateArchive() error = %v, want checksum failure", err)
+	}
+}
+
+func TestStateArchiveEnvironmentMismatchWarns(t *testing.T) {
+	store := &fakeSnapshotStore{values: map[string]string{}}
+	registry := newTestRegistry(t, store)
+
+	archive, err := ReadStateArchive(bytes.NewReader(exportArchive(t, registry, "production")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := registry.Plan(t.Context(), archive, "staging")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(plan.Warnings) != 1 || !strings.Contains(plan.Warnings[0], `"production"`) {
+		t.Errorf("warnings = %v, want environment mismatch", plan.Warnings)
+	}
+}
+
+func TestStateArchiveRejectsNewerSchema(t *testing.T) {
+	store := &fakeSnapshotStore{values: map[string]string{}}
+	registry := newTestRegistry(t, store)
+
+	archive, err := ReadStateArchive(bytes.NewReader(exportArchive(t, registry, "staging")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	archive.Manifest.Subsystems["settings"] = StateDocumentInfo{SchemaVersion: 99, Checksum: ""}
+
+	_, err = registry.Plan(t.Context(), archive, "staging")
+	if err == nil {
+		t.Error("Plan() should reject a newer subsystem schema")
+	}
+}
+
+// staticState is a StateSubsystem with a fixed document and import outcome.
+type staticState struct {
+	name      string
+	importErr error
+	imported  bool
+}
+
+func (s *staticState) Name() string                                   { return s.name }
+func (s *staticState) SchemaVersion() int                             { return 1 }
+func (s *staticState) Export(context.Context) ([]byte, error)         { return []byte(`{}`), nil }
+func (s *staticState) Diff(context.Context, []byte) ([]string, error) { return nil, nil }
+
+func (s *staticState) Import(context.Context, []byte) error {
+	if s.importErr != nil {
+		return s.importErr
+	}
+
+	s.imported = true
+
+	return nil
+}
+
+func TestStateArchivePartialFailureLeavesSubsystemUnchanged(t *testing.T) {
+	settings := &fakeSnapshotStore{values: map[string]string{"maintenance_banner": "off"}}
+	source := newTestRegistry(t, settings)
+
+	err := source.Register(&staticState{name: "quotas", importErr: nil, imported: false})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	archive, err := ReadStateArchive(bytes.NewReader(exportArchive(t, source, "staging")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	target := &fakeSnapshotStore{values: map[string]string{"stale": "x"}, replaceErr: errImportFailed}
+	quotas := &staticState{name: "quotas", importErr: nil, imported: false}
+	registry := newTestRegistry(t, target)
+
+	err = registry.Register(quotas)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := registry.Apply(t.Context(), archive)
+
+	if len(results) != 2 || results[0].Applied || results[0].Error == "" || !results[1].Applied {
+		t.Errorf("Apply() = %+v, want settings failed and quotas applied", results)
+	}
+
+	if !quotas.imported {
+		t.Error("a failing subsystem must not block the others")
+	}
+
+	if !maps.Equal(target.values, map[string]string{"stale": "x"}) {
+		t.Errorf("failed subsystem was modified: %v", target.values)
+	}
+}
+
+func TestStateRegistryRejectsDuplicateNames(t *testing.T) {
+	registry := newTestRegistry(t, &fakeSnapshotStore{values: nil})
+
+	err := registry.Register(NewSettingsState(&fakeSnapshotStore{values: nil}))
+	if err == nil {
+		t.Error("Register() should reject a duplicate subsystem name")
+	}
+}
+
+func archiveContents(t *testing.T, data []byte) string {
+	t.Helper()
+
+	var contents strings.Builder
+
+	rewriteArchive(t, data, "", func(doc []byte) []byte {
+		contents.Write(doc)
+
+		return doc
+	})
+
+	return contents.String()
+}
+
+// rewriteArchive applies edit to the entry called name, or to every entry
+// when name is empty, and returns the re-packed archive.
+func rewriteArchive(t *testing.T, data []byte, name string, edit func([]byte) []byte) []byte {
+	t.Helper()
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+
+	zw := gzip.NewWriter(&out)
+	tr := tar.NewReader(gz)
+	tw := tar.NewWriter(zw)
+
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if name == "" || header.Name == name {
+			body = edit(body)
+		}
+
+		header.Size = int64(len(body))
+
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := tw.Write(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := errors.Join(tw.Close(), zw.Close()); err != nil {
+		t.Fatal(err)
+	}
+
+	return out.Bytes()
+}
@@ -0,0 +1,44 @@
+package base
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/infrastructure"
+	_ "github.com/mattn/go-sqlite3" // registers the sqlite3 driver OpenTestDB uses
+)
+
+// RequireSafeTestDSN aborts the test when dsn could point at shared data.
+// Set ALLOW_DANGEROUS_TEST_DB=1 to run against a real database on purpose.
+func RequireSafeTestDSN(tb testing.TB, dsn string) {
+	tb.Helper()
+
+	err := infrastructure.CheckTestDSN(dsn)
+	if err != nil {
+		tb.Fatalf("unsafe test database: %v", err)
+	}
+}
+
+// OpenTestDB opens the SQLite database at dsn after RequireSafeTestDSN has
+// accepted it, stamps it as a development database so destructive
+// operations are allowed, and closes it when the test ends.
+func OpenTestDB(tb testing.TB, dsn string) *sql.DB {
+	tb.Helper()
+
+	RequireSafeTestDSN(tb, dsn)
+
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		tb.Fatalf("open test database: %v", err)
+	}
+
+	tb.Cleanup(func() { _ = db.Close() })
+
+	_, err = infrastructure.NewEnvironmentMarker(infrastructure.NewDatabaseFromDB(db)).
+		Stamp(tb.Context(), infrastructure.EnvironmentDevelopment)
+	if err != nil {
+		tb.Fatalf("stamp test database: %v", err)
+	}
+
+	return db
+}
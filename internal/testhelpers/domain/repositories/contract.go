@@ -0,0 +1,653 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/entities"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/ids"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/repositories"
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/values"
+)
+
+type userRepo = repositories.UserRepository
+
+// RunUserRepositoryContract checks that an implementation follows the
+// UserRepository contract. newRepo must return an empty repository.
+func RunUserRepositoryContract(t *testing.T, newRepo func() repositories.UserRepository) {
+	t.Helper()
+
+	lookups := []struct {
+		name string
+		find func(context.Context, userRepo, *entities.User) (*entities.User, error)
+		miss func(context.Context, userRepo) (*entities.User, error)
+	}{
+		{
+			name: "FindByID",
+			find: func(ctx context.Context, repo userRepo, u *entities.User) (*entities.User, error) {
+				return repo.FindByID(ctx, u.ID)
+			},
+			miss: func(ctx context.Context, repo userRepo) (*entities.User, error) {
+				return repo.FindByID(ctx, ids.MustGenerateUserID())
+			},
+		},
+		{
+			name: "FindByEmail",
+			find: func(ctx context.Context, repo userRepo, u *entities.User) (*entities.User, error) {
+				return repo.FindByEmail(ctx, u.GetEmail())
+			},
+			miss: func(ctx context.Context, repo userRepo) (*entities.User, error) {
+				email, err := values.NewEmail("missing@example.com")
+				if err != nil {
+					return nil, err
+				}
+
+				return repo.FindByEmail(ctx, email)
+			},
+		},
+		{
+			name: "FindByUsername",
+			find: func(ctx context.Context, repo userRepo, u *entities.User) (*entities.User, error) {
+				return repo.FindByUsername(ctx, u.GetUserName().String())
+			},
+			miss: func(ctx context.Context, repo userRepo) (*entities.User, error) {
+				return repo.FindByUsername(ctx, "missing-user")
+			},
+		},
+	}
+
+	for _, lookup := range lookups {
+		t.Run(lookup.name+" not found", func(t *testing.T) {
+			user, err := lookup.miss(t.Context(), newRepo())
+			if !errors.Is(err, repositories.ErrUserNotFound) { //nolint:legacyerrors // value sentinel
+				t.Errorf("error = %v, want ErrUserNotFound", err)
+			}
+
+			if user != nil {
+				t.Errorf("user = %v, want nil alongside ErrUserNotFound", user)
+			}
+		})
+
+		t.Run(lookup.name+" found", func(t *testing.T) {
+			repo := newRepo()
+			saved := saveContractUser(t, repo)
+
+			user, err := lookup.find(t.Context(), repo, saved)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if user == nil || user.ID != saved.ID {
+				t.Errorf("user = %v, want %s", user, saved.ID)
+			}
+		})
+
+		t.Run(lookup.name+" soft-deleted", func(t *testing.T) {
+			repo := newRepo()
+			saved := softDeleteContractUser(t, repo)
+
+			_, err := lookup.find(t.Context(), repo, saved)
+			if !errors.Is(err, repositories.ErrUserNotFound) { //nolint:legacyerrors // value sentinel
+				t.Errorf("error = %v, want ErrUserNotFound for a soft-deleted user", err)
+			}
+		})
+	}
+
+	t.Run("soft-deleted user reachable for admin", func(t *testing.T) {
+		repo := newRepo()
+		deleted := softDeleteContractUser(t, repo)
+
+		user, err := repo.FindByIDIncludingDeleted(t.Context(), deleted.ID)
+		if err != nil || !user.IsDeleted() {
+			t.Errorf("FindByIDIncludingDeleted() = %v, %v, want the soft-deleted user", user, err)
+		}
+
+		active, err := repo.List(t.Context())
+		if err != nil || len(active) != 0 {
+			t.Errorf("List() = %v, %v, want no active users", active, err)
+		}
+
+		listed, err := repo.ListDeleted(t.Context())
+		if err != nil || len(listed) != 1 || listed[0].ID != deleted.ID {
+			t.Errorf("ListDeleted() = %v, %v, want the soft-deleted user", listed, err)
+		}
+	})
+
+	t.Run("soft-deleted email reusable", func(t *testing.T) {
+		repo := newRepo()
+		deleted := softDeleteContractUser(t, repo)
+
+		err := repo.Save(t.Context(), newContractUsers(t, deleted.GetEmail().String())[0])
+		if err != nil {
+			t.Fatalf("save user with a soft-deleted email: %v", err)
+		}
+
+		deleted.Restore(time.Now())
+
+		err = repo.Save(t.Context(), deleted)
+		if !errors.Is(err, repositories.ErrUserAlreadyExists) { //nolint:legacyerrors // value sentinel
+			t.Errorf("restore onto a reused email: error = %v, want ErrUserAlreadyExists", err)
+		}
+	})
+
+	t.Run("Save duplicate email", func(t *testing.T) {
+		repo := newRepo()
+		saveContractUser(t, repo)
+		duplicate := newContractUsers(t, "contract@example.com")[0]
+
+		err := repo.Save(t.Context(), duplicate)
+		if !errors.Is(err, repositories.ErrUserAlreadyExists) { //nolint:legacyerrors // value sentinel
+			t.Errorf("error = %v, want ErrUserAlreadyExists", err)
+		}
+
+		if duplicate.Version != 0 {
+			t.Errorf("version of the rejected user = %d, want 0", duplicate.Version)
+		}
+	})
+
+	for _, write := range []struct {
+		name string
+		save func(userRepo, context.Context, *entities.User) error
+	}{
+		{name: "Save", save: userRepo.Save},
+		{name: "Update", save: userRepo.Update},
+	} {
+		t.Run(write.name+" changing to a taken email", func(t *testing.T) {
+			repo := newRepo()
+			taken := saveContractUser(t, repo)
+			users := newContractUsers(t, "other@example.com")
+
+			err := repo.SaveAll(t.Context(), users)
+			if err != nil {
+				t.Fatalf("save user: %v", err)
+			}
+
+			other := findContractUser(t, repo, users[0])
+
+			err = other.ChangeEmail(taken.GetEmail(), time.Now())
+			if err != nil {
+				t.Fatalf("change email: %v", err)
+			}
+
+			err = write.save(repo, t.Context(), other)
+			if !errors.Is(err, repositories.ErrUserAlreadyExists) { //nolint:legacyerrors // value sentinel
+				t.Errorf("error = %v, want ErrUserAlreadyExists", err)
+			}
+
+			found, err := repo.FindByEmail(t.Context(), taken.GetEmail())
+			if err != nil || found.ID != taken.ID {
+				t.Errorf("FindByEmail() = %v, %v, want %s", found, err, taken.ID)
+			}
+
+			// The email the user gave up is free for others once saved
+			other = findContractUser(t, repo, users[0])
+
+			err = other.SetEmail("moved@example.com", time.Now())
+			if err != nil {
+				t.Fatalf("change email: %v", err)
+			}
+
+			err = write.save(repo, t.Context(), other)
+			if err != nil {
+				t.Fatalf("move to a free email: %v", err)
+			}
+
+			err = repo.Save(t.Context(), newContractUsers(t, "other@example.com")[0])
+			if err != nil {
+				t.Errorf("save user with the released email: %v", err)
+			}
+		})
+	}
+
+	t.Run("stored users are isolated from callers", func(t *testing.T) {
+		repo := newRepo()
+		saved := saveContractUser(t, repo)
+		email := saved.GetEmail()
+
+		err := saved.SetName("changedaftersave", time.Now())
+		if err != nil {
+			t.Fatalf("set name: %v", err)
+		}
+
+		saved.Created = saved.Created.Add(-time.Hour)
+
+		found := findContractUser(t, repo, saved)
+		if found.GetUserName().String() != "contractuser" || found.Created.Equal(saved.Created) {
+			t.Errorf("stored user changed with the saved entity: %+v", found)
+		}
+
+		err = found.SetEmail("changedafterfind@example.com", time.Now())
+		if err != nil {
+			t.Fatalf("set email: %v", err)
+		}
+
+		listed, err := repo.List(t.Context())
+		if err != nil || len(listed) != 1 || listed[0].GetEmail() != email {
+			t.Errorf("List() = %v, %v, want the user as stored", listed, err)
+		}
+
+		listed[0].SoftDelete(time.Now())
+
+		again, err := repo.FindByEmail(t.Context(), email)
+		if err != nil || again.IsDeleted() {
+			t.Errorf("stored user changed with a found entity: %v, %v", again, err)
+		}
+	})
+
+	t.Run("List orders by creation time", func(t *testing.T) {
+		repo := newRepo()
+		users := newContractUsers(t, "b@example.com", "c@example.com", "a@example.com", "d@example.com")
+		created := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+		for i, offset := range []time.Duration{2, 0, 1, 1} {
+			users[i].Created = created.Add(offset * time.Hour)
+			users[i].Modified = users[i].Created
+		}
+
+		err := repo.SaveAll(t.Context(), users)
+		if err != nil {
+			t.Fatalf("save users: %v", err)
+		}
+
+		tied := []*entities.User{users[2], users[3]}
+		slices.SortFunc(tied, func(a, b *entities.User) int { return strings.Compare(a.ID.String(), b.ID.String()) })
+		want := []*entities.User{users[1], tied[0], tied[1], users[0]}
+
+		listed, err := repo.List(t.Context())
+		if err != nil || !slices.Equal(contractIDs(listed), contractIDs(want)) {
+			t.Errorf("List() = %v, %v, want %v", contractIDs(listed), err, contractIDs(want))
+		}
+	})
+
+	t.Run("Delete not found", func(t *testing.T) {
+		err := newRepo().Delete(t.Context(), ids.MustGenerateUserID())
+		if !errors.Is(err, repositories.ErrUserNotFound) { //nolint:legacyerrors // value sentinel
+			t.Errorf("error = %v, want ErrUserNotFound", err)
+		}
+	})
+
+	t.Run("Save bumps version", func(t *testing.T) {
+		repo := newRepo()
+		user := saveContractUser(t, repo)
+
+		if user.Version != 1 {
+			t.Fatalf("version after create = %d, want 1", user.Version)
+		}
+
+		err := repo.Save(t.Context(), user)
+		if err != nil {
+			t.Fatalf("save user: %v", err)
+		}
+
+		if user.Version != 2 {
+			t.Errorf("version after update = %d, want 2", user.Version)
+		}
+	})
+
+	t.Run("Save stale copy", func(t *testing.T) {
+		repo := newRepo()
+		saved := saveContractUser(t, repo)
+		first := findContractUser(t, repo, saved)
+		second := findContractUser(t, repo, saved)
+
+		err := repo.Save(t.Context(), first)
+		if err != nil {
+			t.Fatalf("save first copy: %v", err)
+		}
+
+		err = repo.Save(t.Context(), second)
+		if !errors.Is(err, repositories.ErrConcurrentModification) { //nolint:legacyerrors // value sentinel
+			t.Errorf("error = %v, want ErrConcurrentModification", err)
+		}
+	})
+
+	t.Run("Save after delete", func(t *testing.T) {
+		repo := newRepo()
+		saved := saveContractUser(t, repo)
+
+		err := repo.Delete(t.Context(), saved.ID)
+		if err != nil {
+			t.Fatalf("delete user: %v", err)
+		}
+
+		err = repo.Save(t.Context(), saved)
+		if !errors.Is(err, repositories.ErrConcurrentModification) { //nolint:legacyerrors // value sentinel
+			t.Errorf("error = %v, want ErrConcurrentModification", err)
+		}
+	})
+
+	t.Run("Update stored user", func(t *testing.T) {
+		repo := newRepo()
+		user := saveContractUser(t, repo)
+
+		err := repo.Update(t.Context(), user)
+		if err != nil {
+			t.Fatalf("update user: %v", err)
+		}
+
+		if user.Version != 2 {
+			t.Errorf("version after update = %d, want 2", user.Version)
+		}
+	})
+
+	t.Run("Update not found", func(t *testing.T) {
+		repo := newRepo()
+		user := newContractUsers(t, "never-saved@example.com")[0]
+
+		err := repo.Update(t.Context(), user)
+		if !errors.Is(err, repositories.ErrUserNotFound) { //nolint:legacyerrors // value sentinel
+			t.Errorf("error = %v, want ErrUserNotFound", err)
+		}
+
+		_, err = repo.FindByID(t.Context(), user.ID)
+		if !errors.Is(err, repositories.ErrUserNotFound) { //nolint:legacyerrors // value sentinel
+			t.Errorf("Update created the missing user: error = %v", err)
+		}
+	})
+
+	t.Run("Update stale copy", func(t *testing.T) {
+		repo := newRepo()
+		saved := saveContractUser(t, repo)
+		first := findContractUser(t, repo, saved)
+		second := findContractUser(t, repo, saved)
+
+		err := repo.Update(t.Context(), first)
+		if err != nil {
+			t.Fatalf("update first copy: %v", err)
+		}
+
+		err = repo.Update(t.Context(), second)
+		if !errors.Is(err, repositories.ErrConcurrentModification) { //nolint:legacyerrors // value sentinel
+			t.Errorf("error = %v, want ErrConcurrentModification", err)
+		}
+	})
+
+	t.Run("Count and Exists skip soft-deleted users", func(t *testing.T) {
+		repo := newRepo()
+		deleted := softDeleteContractUser(t, repo)
+		active := newContractUsers(t, "active@example.com")[0]
+
+		err := repo.Save(t.Context(), active)
+		if err != nil {
+			t.Fatalf("save user: %v", err)
+		}
+
+		count, err := repo.Count(t.Context())
+		if err != nil || count != 1 {
+			t.Errorf("Count() = %d, %v, want 1", count, err)
+		}
+
+		for _, check := range []struct {
+			id   values.UserID
+			want bool
+		}{{active.ID, true}, {deleted.ID, false}, {ids.MustGenerateUserID(), false}} {
+			exists, err := repo.Exists(t.Context(), check.id)
+			if err != nil || exists != check.want {
+				t.Errorf("Exists(%s) = %v, %v, want %v", check.id, exists, err, check.want)
+			}
+		}
+	})
+
+	t.Run("Count empty", func(t *testing.T) {
+		count, err := newRepo().Count(t.Context())
+		if err != nil || count != 0 {
+			t.Errorf("Count() = %d, %v, want 0", count, err)
+		}
+	})
+
+	t.Run("FindByUsername is case-sensitive", func(t *testing.T) {
+		repo := newRepo()
+		saved := saveContractUser(t, repo)
+
+		_, err := repo.FindByUsername(t.Context(), strings.ToUpper(saved.GetUserName().String()))
+		if !errors.Is(err, repositories.ErrUserNotFound) { //nolint:legacyerrors // value sentinel
+			t.Errorf("error = %v, want ErrUserNotFound for a differently cased username", err)
+		}
+	})
+
+	t.Run("SaveAll stores every user", func(t *testing.T) {
+		repo := newRepo()
+		users := newContractUsers(t, "first@example.com", "second@example.com")
+
+		err := repo.SaveAll(t.Context(), users)
+		if err != nil {
+			t.Fatalf("save users: %v", err)
+		}
+
+		for _, user := range users {
+			if user.Version != 1 {
+				t.Errorf("version of %s = %d, want 1", user.ID, user.Version)
+			}
+
+			findContractUser(t, repo, user)
+		}
+	})
+
+	t.Run("SaveAll is all or nothing", func(t *testing.T) {
+		repo := newRepo()
+		saveContractUser(t, repo)
+		users := newContractUsers(t, "fresh@example.com", "contract@example.com")
+
+		err := repo.SaveAll(t.Context(), users)
+		if !errors.Is(err, repositories.ErrUserAlreadyExists) { //nolint:legacyerrors // value sentinel
+			t.Errorf("error = %v, want ErrUserAlreadyExists", err)
+		}
+
+		_, err = repo.FindByID(t.Context(), users[0].ID)
+		if !errors.Is(err, repositories.ErrUserNotFound) { //nolint:legacyerrors // value sentinel
+			t.Errorf("valid user of a rejected batch was stored: error = %v", err)
+		}
+	})
+
+	t.Run("FindPage filters sorts and pages", func(t *testing.T) {
+		repo := newRepo()
+		softDeleteContractUser(t, repo)
+
+		users := newContractUsers(t, "b@example.com", "c@example.org", "a@example.com", "d@example.com")
+
+		err := repo.SaveAll(t.Context(), users)
+		if err != nil {
+			t.Fatalf("save users: %v", err)
+		}
+
+		page, err := repo.FindPage(t.Context(), repositories.UserPageQuery{
+			Spec:   repositories.EmailDomainSpec{Domain: "example.com"},
+			Sort:   repositories.UserSort{Field: repositories.SortByEmail, Descending: true},
+			Offset: 1,
+			Limit:  1,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if page.Total != 3 || len(page.Users) != 1 || page.Users[0].ID != users[0].ID {
+			t.Errorf("page = %d users of %d, want b@example.com of 3", len(page.Users), page.Total)
+		}
+	})
+
+	t.Run("Search folds case and escapes wildcards", func(t *testing.T) {
+		repo := newRepo()
+		softDeleteContractUser(t, repo)
+
+		users := newContractUsers(t, "Ann_Lee@example.com", "annxlee@example.com", "bob@example.com")
+
+		err := repo.SaveAll(t.Context(), users)
+		if err != nil {
+			t.Fatalf("save users: %v", err)
+		}
+
+		tests := []struct {
+			query string
+			want  []*entities.User
+		}{
+			{query: "ANN", want: []*entities.User{users[0], users[1]}},
+			{query: "ann_", want: []*entities.User{users[0]}},
+			{query: "%lee", want: nil},
+			{query: "@example", want: nil},
+			{query: "TRACTU", want: users},
+			{query: "nobody", want: nil},
+		}
+
+		for _, tt := range tests {
+			found, err := repo.Search(t.Context(), tt.query, 0)
+			if err != nil {
+				t.Fatalf("search %q: %v", tt.query, err)
+			}
+
+			if found == nil || !slices.Equal(contractIDs(found), contractIDs(tt.want)) {
+				t.Errorf("search %q = %v, want %v", tt.query, contractIDs(found), contractIDs(tt.want))
+			}
+		}
+	})
+
+	t.Run("Search limit", func(t *testing.T) {
+		repo := newRepo()
+		users := newContractUsers(t, "c@example.com", "a@example.com", "b@example.com")
+
+		err := repo.SaveAll(t.Context(), users)
+		if err != nil {
+			t.Fatalf("save users: %v", err)
+		}
+
+		found, err := repo.Search(t.Context(), "contract", 2)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(found) != 2 || found[0].ID != users[1].ID || found[1].ID != users[2].ID {
+			t.Errorf("found %v, want a@ and b@example.com", contractIDs(found))
+		}
+	})
+
+	t.Run("FindAllIter visits active users in ID order", func(t *testing.T) {
+		repo := newRepo()
+		softDeleteContractUser(t, repo)
+
+		users := newContractUsers(t, "a@example.com", "b@example.com", "c@example.com")
+
+		err := repo.SaveAll(t.Context(), users)
+		if err != nil {
+			t.Fatalf("save users: %v", err)
+		}
+
+		var visited []string
+
+		err = repo.FindAllIter(t.Context(), func(user *entities.User) error {
+			visited = append(visited, user.ID.String())
+
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(visited) != len(users) || !slices.IsSorted(visited) {
+			t.Errorf("visited %v, want the %d active users in ID order", visited, len(users))
+		}
+	})
+
+	t.Run("FindAllIter stops at the first error", func(t *testing.T) {
+		repo := newRepo()
+
+		err := repo.SaveAll(t.Context(), newContractUsers(t, "a@example.com", "b@example.com"))
+		if err != nil {
+			t.Fatalf("save users: %v", err)
+		}
+
+		errStop := errors.New("stop")
+		calls := 0
+
+		err = repo.FindAllIter(t.Context(), func(*entities.User) error {
+			calls++
+
+			return errStop
+		})
+		if !errors.Is(err, errStop) || calls != 1 {
+			t.Errorf("error = %v after %d calls, want errStop after 1", err, calls)
+		}
+	})
+
+	t.Run("List empty", func(t *testing.T) {
+		users, err := newRepo().List(t.Context())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if users == nil || len(users) != 0 {
+			t.Errorf("users = %v, want empty non-nil slice", users)
+		}
+	})
+}
+
+func saveContractUser(t *testing.T, repo repositories.UserRepository) *entities.User {
+	t.Helper()
+
+	user, err := entities.NewUser(ids.MustGenerateUserID(), "contract@example.com", "contractuser", time.Now())
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	err = repo.Save(t.Context(), user)
+	if err != nil {
+		t.Fatalf("save user: %v", err)
+	}
+
+	return user
+}
+
+// softDeleteContractUser saves a user and then soft-deletes it.
+func softDeleteContractUser(t *testing.T, repo repositories.UserRepository) *entities.User {
+	t.Helper()
+
+	user := saveContractUser(t, repo)
+	user.SoftDelete(time.Now())
+
+	err := repo.Save(t.Context(), user)
+	if err != nil {
+		t.Fatalf("soft-delete user: %v", err)
+	}
+
+	return user
+}
+
+func newContractUsers(t *testing.T, emails ...string) []*entities.User {
+	t.Helper()
+
+	users := make([]*entities.User, 0, len(emails))
+
+	for _, email := range emails {
+		user, err := entities.NewUser(ids.MustGenerateUserID(), email, "contractuser", time.Now())
+		if err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+
+		users = append(users, user)
+	}
+
+	return users
+}
+
+// contractIDs returns the IDs of users in order.
+func contractIDs(users []*entities.User) []string {
+	result := make([]string, 0, len(users))
+	for _, user := range users {
+		result = append(result, user.ID.String())
+	}
+
+	return result
+}
+
+func findContractUser(t *testing.T, repo repositories.UserRepository, user *entities.User) *entities.User {
+	t.Helper()
+
+	found, err := repo.FindByID(t.Context(), user.ID)
+	if err != nil {
+		t.Fatalf("find user: %v", err)
+	}
+
+	return found
+}
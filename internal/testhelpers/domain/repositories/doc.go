@@ -0,0 +1,2 @@
+// Package repositories provides a conformance suite for repository implementations.
+package repositories
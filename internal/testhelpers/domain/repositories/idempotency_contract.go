@@ -0,0 +1,171 @@
+package repositories
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/repositories"
+)
+
+// RunIdempotencyStoreContract checks that an implementation follows the
+// IdempotencyStore contract. newStore must return an empty store.
+func RunIdempotencyStoreContract(t *testing.T, newStore func(t *testing.T) repositories.IdempotencyStore) {
+	t.Helper()
+
+	now := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	pending := func(key, hash string) repositories.IdempotencyRecord {
+		return repositories.IdempotencyRecord{ //nolint:exhaustruct // a reservation has no response
+			Key:         key,
+			RequestHash: hash,
+			ExpiresAt:   now.Add(time.Hour),
+		}
+	}
+
+	reserve := func(t *testing.T, store repositories.IdempotencyStore, record repositories.IdempotencyRecord,
+		at time.Time,
+	) (repositories.IdempotencyRecord, bool) {
+		t.Helper()
+
+		held, reserved, err := store.Reserve(t.Context(), record, at)
+		if err != nil {
+			t.Fatalf("Reserve(%q) error = %v", record.Key, err)
+		}
+
+		return held, reserved
+	}
+
+	t.Run("Reserve free key", func(t *testing.T) {
+		_, reserved := reserve(t, newStore(t), pending("k1", "h1"), now)
+		if !reserved {
+			t.Error("reserved = false, want true for an unused key")
+		}
+	})
+
+	t.Run("Reserve running key", func(t *testing.T) {
+		store := newStore(t)
+		reserve(t, store, pending("k1", "h1"), now)
+
+		held, reserved := reserve(t, store, pending("k1", "h2"), now)
+		if reserved {
+			t.Fatal("reserved = true, want false while the first request runs")
+		}
+
+		if held.Completed() || held.RequestHash != "h1" {
+			t.Errorf("held = %+v, want the running reservation of h1", held)
+		}
+	})
+
+	t.Run("Reserve completed key", func(t *testing.T) {
+		store := newStore(t)
+		record := pending("k1", "h1")
+		reserve(t, store, record, now)
+
+		record.Status = 201
+		record.ContentType = "application/json"
+		record.Location = "/api/v1/users/u1"
+		record.Body = []byte(`{"id":"u1"}`)
+
+		err := store.Complete(t.Context(), record)
+		if err != nil {
+			t.Fatalf("Complete() error = %v", err)
+		}
+
+		held, reserved := reserve(t, store, pending("k1", "h1"), now.Add(time.Minute))
+		if reserved || !held.Completed() {
+			t.Fatalf("reserved = %v, held = %+v, want the completed record", reserved, held)
+		}
+
+		if held.Status != 201 || held.ContentType != "application/json" || held.Location != "/api/v1/users/u1" ||
+			string(held.Body) != `{"id":"u1"}` || held.RequestHash != "h1" || !held.ExpiresAt.Equal(record.ExpiresAt) {
+			t.Errorf("held = %+v, want %+v", held, record)
+		}
+	})
+
+	t.Run("Reserve expired key", func(t *testing.T) {
+		store := newStore(t)
+		record := pending("k1", "h1")
+		reserve(t, store, record, now)
+
+		record.Status = 201
+
+		err := store.Complete(t.Context(), record)
+		if err != nil {
+			t.Fatalf("Complete() error = %v", err)
+		}
+
+		_, reserved := reserve(t, store, pending("k1", "h1"), record.ExpiresAt.Add(-time.Nanosecond))
+		if reserved {
+			t.Error("reserved = true, want false just before expiry")
+		}
+
+		renewed := pending("k1", "h2")
+		renewed.ExpiresAt = record.ExpiresAt.Add(time.Hour)
+
+		held, reserved := reserve(t, store, renewed, record.ExpiresAt)
+		if !reserved || held.Completed() {
+			t.Fatalf("reserved = %v, held = %+v, want a fresh reservation at expiry", reserved, held)
+		}
+
+		held, _ = reserve(t, store, pending("k1", "h3"), record.ExpiresAt)
+		if held.RequestHash != "h2" || held.Completed() {
+			t.Errorf("held = %+v, want the new running reservation of h2", held)
+		}
+	})
+
+	t.Run("Release", func(t *testing.T) {
+		store := newStore(t)
+		reserve(t, store, pending("k1", "h1"), now)
+
+		err := store.Release(t.Context(), "k1")
+		if err != nil {
+			t.Fatalf("Release() error = %v", err)
+		}
+
+		_, reserved := reserve(t, store, pending("k1", "h2"), now)
+		if !reserved {
+			t.Error("reserved = false, want true after Release")
+		}
+	})
+
+	t.Run("keys are independent", func(t *testing.T) {
+		store := newStore(t)
+		reserve(t, store, pending("k1", "h1"), now)
+
+		_, reserved := reserve(t, store, pending("k2", "h1"), now)
+		if !reserved {
+			t.Error("reserved = false, want true for another key")
+		}
+	})
+
+	t.Run("concurrent Reserve claims once", func(t *testing.T) {
+		const callers = 16
+
+		store := newStore(t)
+
+		var (
+			wg       sync.WaitGroup
+			reserved atomic.Int64
+		)
+
+		for range callers {
+			wg.Go(func() {
+				_, ok, err := store.Reserve(t.Context(), pending("k1", "h1"), now)
+				if err != nil {
+					t.Errorf("Reserve() error = %v", err)
+				}
+
+				if ok {
+					reserved.Add(1)
+				}
+			})
+		}
+
+		wg.Wait()
+
+		if got := reserved.Load(); got != 1 {
+			t.Errorf("%d of %d callers reserved the key, want 1", got, callers)
+		}
+	})
+}
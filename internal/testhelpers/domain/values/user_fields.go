@@ -0,0 +1,31 @@
+package values
+
+import (
+	"testing"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/domain/values"
+)
+
+// MustEmail parses email, failing the test when it is invalid.
+func MustEmail(tb testing.TB, email string) values.Email {
+	tb.Helper()
+
+	emailVO, err := values.NewEmail(email)
+	if err != nil {
+		tb.Fatalf("NewEmail(%q) error = %v", email, err)
+	}
+
+	return emailVO
+}
+
+// MustUserName parses name, failing the test when it is invalid.
+func MustUserName(tb testing.TB, name string) values.UserName {
+	tb.Helper()
+
+	nameVO, err := values.NewUserName(name)
+	if err != nil {
+		tb.Fatalf("NewUserName(%q) error = %v", name, err)
+	}
+
+	return nameVO
+}
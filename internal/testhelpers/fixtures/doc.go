@@ -0,0 +1,2 @@
+// Package fixtures replays recorded request/response fixtures against a handler.
+package fixtures
@@ -0,0 +1,145 @@
+package fixtures
+
+import (
+	"bytes"
+	"encoding/json/v2"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/application/middleware"
+)
+
+// Replay re-issues every fixture under dir against handler and reports
+// responses that differ from the recording. Fields named in ignore are
+// removed at any depth before comparing, for volatile values such as
+// timestamps and generated IDs.
+func Replay(tb testing.TB, handler http.Handler, dir string, ignore []string) {
+	tb.Helper()
+
+	for _, path := range fixturePaths(tb, dir) {
+		fixture, err := middleware.ReadFixture(path)
+		if err != nil {
+			tb.Errorf("%v", err)
+
+			continue
+		}
+
+		status, body := serveFixture(tb, handler, fixture)
+
+		if status != fixture.Response.Status {
+			tb.Errorf("%s: status = %d, recorded %d", path, status, fixture.Response.Status)
+		}
+
+		got := stripFields(middleware.RedactBody(middleware.DecodeBody(body)), ignore)
+		want := stripFields(fixture.Response.Body, ignore)
+
+		if !reflect.DeepEqual(got, want) {
+			tb.Errorf("%s: body differs from recording\n got: %v\nwant: %v", path, got, want)
+		}
+	}
+}
+
+// Regenerate re-issues every fixture under dir and overwrites its recorded
+// response with the current one. Wire it to an -update test flag.
+func Regenerate(tb testing.TB, handler http.Handler, dir string) {
+	tb.Helper()
+
+	for _, path := range fixturePaths(tb, dir) {
+		fixture, err := middleware.ReadFixture(path)
+		if err != nil {
+			tb.Fatalf("%v", err)
+		}
+
+		status, body := serveFixture(tb, handler, fixture)
+		fixture.Response.Status = status
+		fixture.Response.Body = middleware.RedactBody(middleware.DecodeBody(body))
+
+		err = middleware.WriteFixture(path, fixture)
+		if err != nil {
+			tb.Fatalf("%v", err)
+		}
+	}
+}
+
+func fixturePaths(tb testing.TB, dir string) []string {
+	tb.Helper()
+
+	var paths []string
+
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err == nil && !entry.IsDir() && filepath.Ext(path) == ".json" {
+			paths = append(paths, path)
+		}
+
+		return err
+	})
+	if err != nil {
+		tb.Fatalf("walk fixtures in %s: %v", dir, err)
+	}
+
+	slices.Sort(paths)
+
+	return paths
+}
+
+func serveFixture(tb testing.TB, handler http.Handler, fixture middleware.Fixture) (int, []byte) {
+	tb.Helper()
+
+	var body []byte
+
+	switch recorded := fixture.Request.Body.(type) {
+	case nil:
+	case string:
+		body = []byte(recorded)
+	default:
+		encoded, err := json.Marshal(recorded)
+		if err != nil {
+			tb.Fatalf("encode fixture request body: %v", err)
+		}
+
+		body = encoded
+	}
+
+	target := fixture.Request.Path
+	if fixture.Request.Query != "" {
+		target += "?" + fixture.Request.Query
+	}
+
+	req := httptest.NewRequest(fixture.Request.Method, target, bytes.NewReader(body))
+	for name, value := range fixture.Request.Headers {
+		req.Header.Set(name, value)
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	return w.Code, w.Body.Bytes()
+}
+
+func stripFields(value any, ignore []string) any {
+	switch v := value.(type) {
+	case map[string]any:
+		stripped := make(map[string]any, len(v))
+		for key, field := range v {
+			if !slices.Contains(ignore, key) {
+				stripped[key] = stripFields(field, ignore)
+			}
+		}
+
+		return stripped
+	case []any:
+		stripped := make([]any, len(v))
+		for i, item := range v {
+			stripped[i] = stripFields(item, ignore)
+		}
+
+		return stripped
+	default:
+		return value
+	}
+}
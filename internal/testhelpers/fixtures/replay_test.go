@@ -0,0 +1,105 @@
+package fixtures_test
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/LarsArtmann/template-arch-lint/internal/application/middleware"
+	"github.com/LarsArtmann/template-arch-lint/internal/testhelpers/fixtures"
+)
+
+var update = flag.Bool("update", false, "regenerate recorded fixtures")
+
+// failureCollector records failures instead of failing the real test.
+type failureCollector struct {
+	testing.TB
+
+	failures []string
+}
+
+func (c *failureCollector) Helper() {}
+
+func (c *failureCollector) Errorf(format string, args ...any) {
+	c.failures = append(c.failures, fmt.Sprintf(format, args...))
+}
+
+func (c *failureCollector) Fatalf(format string, args ...any) {
+	c.Errorf(format, args...)
+}
+
+// userHandler returns a new ID on every call, like a real create endpoint.
+func userHandler(name string) http.Handler {
+	var counter atomic.Int32
+
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"id":"user_%d","name":%q}`, counter.Add(1)+100, name)
+	})
+}
+
+func record(t *testing.T, handler http.Handler) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	recorder := middleware.NewRecorder(middleware.RecordingOptions{Dir: dir, SampleRate: 1, Routes: nil})
+	recorder.Middleware(handler).ServeHTTP(httptest.NewRecorder(),
+		httptest.NewRequest(http.MethodGet, "/api/v1/users/user_1", nil))
+	recorder.Wait()
+
+	return dir
+}
+
+func TestReplayIgnoresVolatileFields(t *testing.T) {
+	dir := record(t, userHandler("jane"))
+
+	strict := &failureCollector{TB: nil, failures: nil}
+	fixtures.Replay(strict, userHandler("jane"), dir, nil)
+
+	if len(strict.failures) != 0 {
+		t.Errorf("fresh handler should reproduce the recording: %v", strict.failures)
+	}
+
+	replayed := userHandler("jane")
+	replayed.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	changedID := &failureCollector{TB: nil, failures: nil}
+	fixtures.Replay(changedID, replayed, dir, nil)
+
+	if len(changedID.failures) != 1 {
+		t.Errorf("changed id should be reported, got %v", changedID.failures)
+	}
+
+	fixtures.Replay(t, replayed, dir, []string{"id"})
+}
+
+func TestRegenerateUpdatesRecordedResponses(t *testing.T) {
+	dir := record(t, userHandler("jane"))
+	renamed := userHandler("janet")
+
+	before := &failureCollector{TB: nil, failures: nil}
+	fixtures.Replay(before, renamed, dir, []string{"id"})
+
+	if len(before.failures) != 1 {
+		t.Fatalf("changed response should be reported, got %v", before.failures)
+	}
+
+	fixtures.Regenerate(t, renamed, dir)
+	fixtures.Replay(t, userHandler("janet"), dir, []string{"id"})
+}
+
+// TestRecordedFixtures shows the -update flow for a checked-in fixture
+// directory: go test ./... -run TestRecordedFixtures -update.
+func TestRecordedFixtures(t *testing.T) {
+	dir := record(t, userHandler("jane"))
+	handler := userHandler("jane")
+
+	if *update {
+		fixtures.Regenerate(t, handler, dir)
+	}
+
+	fixtures.Replay(t, handler, dir, []string{"id"})
+}
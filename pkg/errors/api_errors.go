@@ -0,0 +1,113 @@
+package errors
+
+import (
+	"errors"
+	"net/http"
+)
+
+// APICode is a stable, machine-readable error code of an API response.
+// Clients branch on it; the message is for humans and may change.
+type APICode string
+
+const (
+	// APICodeValidationFailed marks a request that failed validation;
+	// APIError.Field names the offending field or query parameter.
+	APICodeValidationFailed APICode = "VALIDATION_FAILED"
+	// APICodeInvalidRequestBody marks a request body that cannot be decoded.
+	APICodeInvalidRequestBody APICode = "INVALID_REQUEST_BODY"
+	// APICodeRequestTooLarge marks a request body over the size limit.
+	APICodeRequestTooLarge APICode = "REQUEST_TOO_LARGE"
+	// APICodeUnsupportedMediaType marks a request body of the wrong content type.
+	APICodeUnsupportedMediaType APICode = "UNSUPPORTED_MEDIA_TYPE"
+	// APICodePreconditionFailed marks a failed If-Match or If-None-Match.
+	APICodePreconditionFailed APICode = "PRECONDITION_FAILED"
+	// APICodeUserNotFound marks a user that does not exist.
+	APICodeUserNotFound APICode = "USER_NOT_FOUND"
+	// APICodeNotFound marks any other resource that does not exist.
+	APICodeNotFound APICode = "NOT_FOUND"
+	// APICodeEmailAlreadyExists marks an email taken by another user.
+	APICodeEmailAlreadyExists APICode = "EMAIL_ALREADY_EXISTS"
+	// APICodeConflict marks any other conflict with the current state.
+	APICodeConflict APICode = "CONFLICT"
+	// APICodeIdempotencyKeyReused marks an Idempotency-Key sent again with
+	// a different request body.
+	APICodeIdempotencyKeyReused APICode = "IDEMPOTENCY_KEY_REUSED"
+	// APICodeIdempotencyKeyInUse marks an Idempotency-Key whose first
+	// request has not finished yet.
+	APICodeIdempotencyKeyInUse APICode = "IDEMPOTENCY_KEY_IN_USE"
+	// APICodeRateLimited marks a client over its request rate limit.
+	APICodeRateLimited APICode = "RATE_LIMITED"
+	// APICodeOverloaded marks a request shed while the server is over a
+	// load limit.
+	APICodeOverloaded APICode = "OVERLOADED"
+	// APICodeTimeout marks a request whose handler ran past its timeout.
+	APICodeTimeout APICode = "TIMEOUT"
+	// APICodeCORSRejected marks a preflight from a disallowed origin or for a
+	// disallowed method.
+	APICodeCORSRejected APICode = "CORS_REJECTED"
+	// APICodeUnauthorized marks a request without a valid access token.
+	APICodeUnauthorized APICode = "UNAUTHORIZED"
+	// APICodeForbidden marks an authenticated caller that lacks a required role.
+	APICodeForbidden APICode = "FORBIDDEN"
+	// APICodeInternal marks a server-side failure. Its message is generic;
+	// APIError.CorrelationID finds the logged cause.
+	APICodeInternal APICode = "INTERNAL_ERROR"
+)
+
+// APIError is the JSON body of every API error response. RequestID ties
+// it to the request's log lines when the server assigns request IDs.
+type APIError struct {
+	Code          APICode           `json:"code"`
+	Message       string            `json:"message"`
+	Field         string            `json:"field,omitzero"`
+	Details       map[string]string `json:"details,omitzero"`
+	CorrelationID string            `json:"correlation_id,omitzero"`
+	RequestID     string            `json:"request_id,omitzero"`
+}
+
+// ToAPIError maps err to its HTTP status and response body by the domain
+// error types in its chain: ValidationError is 400, NotFoundError 404,
+// ConflictError 409 and anything else 500. The body is built from the
+// matched error alone, never from err.Error(), which may carry wrapped
+// internals; a 500 body says nothing beyond "Internal server error".
+func ToAPIError(err error) (int, APIError) {
+	if ve, ok := errors.AsType[*ValidationError](err); ok {
+		return http.StatusBadRequest, APIError{ //nolint:exhaustruct // the caller assigns request IDs
+			Code:    APICodeValidationFailed,
+			Message: ve.message,
+			Field:   ve.field,
+			Details: ve.details.Extra,
+		}
+	}
+
+	if nfe, ok := errors.AsType[*NotFoundError](err); ok {
+		code := APICodeNotFound
+		if nfe.resource == "user" {
+			code = APICodeUserNotFound
+		}
+
+		return http.StatusNotFound, APIError{ //nolint:exhaustruct // not found names no field
+			Code:    code,
+			Message: nfe.resource + " not found",
+		}
+	}
+
+	if ce, ok := errors.AsType[*ConflictError](err); ok {
+		code := APICodeConflict
+		if ce.details.Field == "email" {
+			code = APICodeEmailAlreadyExists
+		}
+
+		return http.StatusConflict, APIError{ //nolint:exhaustruct // conflicts need no correlation
+			Code:    code,
+			Message: ce.message,
+			Field:   ce.details.Field,
+			Details: ce.details.Extra,
+		}
+	}
+
+	return http.StatusInternalServerError, APIError{ //nolint:exhaustruct // the caller assigns the correlation ID
+		Code:    APICodeInternal,
+		Message: "Internal server error",
+	}
+}
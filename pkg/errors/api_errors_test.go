@@ -0,0 +1,82 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestToAPIError(t *testing.T) {
+	emailTaken := NewConflictError("user already exists", ErrorDetails{Resource: "user", Field: "email"})
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		want       APIError
+	}{
+		{
+			name:       "validation",
+			err:        fmt.Errorf("create user: %w", NewValidationError("email", "invalid email format")),
+			wantStatus: http.StatusBadRequest,
+			want:       APIError{Code: APICodeValidationFailed, Message: "invalid email format", Field: "email"},
+		},
+		{
+			name:       "user not found",
+			err:        NewInternalError("failed to get user", NewNotFoundError("user", "")),
+			wantStatus: http.StatusNotFound,
+			want:       APIError{Code: APICodeUserNotFound, Message: "user not found"},
+		},
+		{
+			name:       "other resource not found",
+			err:        NewNotFoundError("session", "s-1"),
+			wantStatus: http.StatusNotFound,
+			want:       APIError{Code: APICodeNotFound, Message: "session not found"},
+		},
+		{
+			name:       "email already exists",
+			err:        fmt.Errorf("email a@example.com already in use: %w", emailTaken),
+			wantStatus: http.StatusConflict,
+			want:       APIError{Code: APICodeEmailAlreadyExists, Message: "user already exists", Field: "email"},
+		},
+		{
+			name:       "other conflict",
+			err:        NewConflictError("user was modified concurrently", ErrorDetails{Resource: "user"}),
+			wantStatus: http.StatusConflict,
+			want:       APIError{Code: APICodeConflict, Message: "user was modified concurrently"},
+		},
+		{
+			name:       "internal",
+			err:        NewInternalError("failed to list users", errors.New("connection refused")),
+			wantStatus: http.StatusInternalServerError,
+			want:       APIError{Code: APICodeInternal, Message: "Internal server error"},
+		},
+		{
+			name:       "database",
+			err:        NewDatabaseError("query users", errors.New("disk I/O error"), true),
+			wantStatus: http.StatusInternalServerError,
+			want:       APIError{Code: APICodeInternal, Message: "Internal server error"},
+		},
+		{
+			name:       "plain error",
+			err:        errors.New("secret detail"),
+			wantStatus: http.StatusInternalServerError,
+			want:       APIError{Code: APICodeInternal, Message: "Internal server error"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, got := ToAPIError(tt.err)
+			if status != tt.wantStatus {
+				t.Errorf("status = %d, want %d", status, tt.wantStatus)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ToAPIError() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/checker"
+	"golang.org/x/tools/go/packages"
+)
+
+// Exit codes of the standalone runner.
+const (
+	exitClean    = 0
+	exitFindings = 1
+	exitError    = 2
+)
+
+// customConfigFile is the golangci-lint custom build configuration that
+// holds the plugin settings golangci-lint runs with.
+const customConfigFile = ".custom-gcl.yml"
+
+var errLoadPackages = errors.New("packages contain errors")
+
+// archLintFinding is one diagnostic, as printed by -json.
+type archLintFinding struct {
+	Analyzer string `json:"analyzer"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Category string `json:"category,omitempty"`
+	Message  string `json:"message"`
+}
+
+// runArchLint runs the plugin analyzers on the packages named by args
+// without golangci-lint. It returns exitClean, exitFindings or exitError.
+func runArchLint(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("arch-lint", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: arch-lint [flags] packages...")
+		flags.PrintDefaults()
+	}
+
+	jsonOutput := flags.Bool("json", false, "print one JSON object per diagnostic and line")
+	settings := flags.String("settings", "",
+		`plugin settings as a JSON object, e.g. {"code-duplication-detector":{"min-tokens":20}}`)
+	config := flags.String("config", "",
+		"read the plugin settings from this golangci-lint custom build `file` "+
+			"(default: "+customConfigFile+" in the module root, when there is one)")
+	tests := flags.Bool("tests", true, "also analyze test files")
+	dir := flags.String("C", "", "run as if started in `dir`")
+
+	// Analyzer flags pass through as -<analyzer>.<flag>, as in golangci-lint.
+	analyzers := pluginAnalyzers()
+	for _, analyzer := range analyzers {
+		analyzer.Flags.VisitAll(func(f *flag.Flag) {
+			flags.Var(f.Value, analyzer.Name+"."+f.Name, f.Usage)
+		})
+	}
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitClean
+		}
+
+		return exitError
+	}
+
+	conf, err := pluginSettings(*settings, *config, *dir)
+	if err != nil {
+		fmt.Fprintf(stderr, "arch-lint: %v\n", err)
+
+		return exitError
+	}
+
+	findings, err := archLint(conf, *dir, *tests, flags.Args())
+	if err != nil {
+		fmt.Fprintf(stderr, "arch-lint: %v\n", err)
+
+		return exitError
+	}
+
+	for _, finding := range findings {
+		if *jsonOutput {
+			line, _ := json.Marshal(finding)
+			fmt.Fprintf(stdout, "%s\n", line)
+		} else {
+			fmt.Fprintf(stdout, "%s:%d:%d: %s (%s)\n",
+				finding.File, finding.Line, finding.Column, finding.Message, finding.Analyzer)
+		}
+	}
+
+	if len(findings) > 0 {
+		return exitFindings
+	}
+
+	return exitClean
+}
+
+// pluginAnalyzers returns the analyzers New registers, before settings.
+func pluginAnalyzers() []*analysis.Analyzer {
+	analyzers, _ := New(nil)
+
+	return analyzers
+}
+
+// pluginSettings returns the settings New is configured with: -settings
+// when given, otherwise the template-arch-lint settings of the -config file
+// or, without one, of the module's .custom-gcl.yml, so that the runner
+// and golangci-lint check the same rules. Without either the defaults apply.
+func pluginSettings(settings, config, dir string) (map[string]any, error) {
+	var conf map[string]any
+
+	if settings != "" {
+		if err := json.Unmarshal([]byte(settings), &conf); err != nil {
+			return nil, fmt.Errorf("parse -settings: %w", err)
+		}
+
+		return conf, nil
+	}
+
+	if config == "" {
+		found, err := findCustomConfig(dir)
+		if err != nil || found == "" {
+			return nil, err
+		}
+
+		config = found
+	}
+
+	return readCustomConfig(config)
+}
+
+// findCustomConfig looks for .custom-gcl.yml from dir up to the module
+// root, the first directory holding a go.mod. It returns "" when there is none.
+func findCustomConfig(dir string) (string, error) {
+	current, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("find %s: %w", customConfigFile, err)
+	}
+
+	for {
+		candidate := filepath.Join(current, customConfigFile)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		}
+
+		if _, err := os.Stat(filepath.Join(current, "go.mod")); err == nil {
+			return "", nil
+		}
+
+		parent := filepath.Dir(current)
+		if parent == current {
+			return "", nil
+		}
+
+		current = parent
+	}
+}
+
+// readCustomConfig returns linters.settings.custom.template-arch-lint.settings
+// of a golangci-lint custom build configuration.
+func readCustomConfig(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read -config: %w", err)
+	}
+
+	var config struct {
+		Linters struct {
+			Settings struct {
+				Custom map[string]struct {
+					Settings map[string]any `yaml:"settings"`
+				} `yaml:"custom"`
+			} `yaml:"settings"`
+		} `yaml:"linters"`
+	}
+
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	return config.Linters.Settings.Custom["template-arch-lint"].Settings, nil
+}
+
+func archLint(conf map[string]any, dir string, tests bool, patterns []string) ([]archLintFinding, error) {
+	analyzers, err := New(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+
+	pkgs, err := packages.Load(&packages.Config{ //nolint:exhaustruct // defaults for the rest
+		Mode:  packages.LoadAllSyntax,
+		Dir:   dir,
+		Tests: tests,
+	}, patterns...)
+	if err != nil {
+		return nil, fmt.Errorf("load packages: %w", err)
+	}
+
+	var loadErrors []string
+
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		for _, err := range pkg.Errors {
+			loadErrors = append(loadErrors, err.Error())
+		}
+	})
+
+	if len(loadErrors) > 0 {
+		return nil, fmt.Errorf("%w:\n%s", errLoadPackages, strings.Join(loadErrors, "\n"))
+	}
+
+	// analysis.Validate requires identifier names; the plugin keeps its
+	// dashed names for golangci-lint, so the runner checks renamed copies.
+	renamed := make([]*analysis.Analyzer, 0, len(analyzers))
+	names := make(map[*analysis.Analyzer]string, len(analyzers))
+
+	for _, analyzer := range analyzers {
+		analyzerCopy := *analyzer
+		analyzerCopy.Name = strings.ReplaceAll(analyzer.Name, "-", "_")
+		renamed = append(renamed, &analyzerCopy)
+		names[&analyzerCopy] = analyzer.Name
+	}
+
+	graph, err := checker.Analyze(renamed, pkgs, nil)
+	if err != nil {
+		return nil, fmt.Errorf("analyze: %w", err)
+	}
+
+	return collectFindings(graph, names)
+}
+
+// collectFindings gathers the diagnostics of the root actions. Packages
+// and their test variants share files, so repeats are dropped.
+func collectFindings(graph *checker.Graph, names map[*analysis.Analyzer]string) ([]archLintFinding, error) {
+	var (
+		findings []archLintFinding
+		errs     []error
+	)
+
+	seen := make(map[archLintFinding]bool)
+
+	for action := range graph.All() {
+		if !action.IsRoot {
+			continue
+		}
+
+		if action.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", action, action.Err))
+
+			continue
+		}
+
+		for _, diagnostic := range action.Diagnostics {
+			position := action.Package.Fset.Position(diagnostic.Pos)
+			finding := findingAt(position, names[action.Analyzer], diagnostic)
+
+			if !seen[finding] {
+				seen[finding] = true
+				findings = append(findings, finding)
+			}
+		}
+	}
+
+	sort.Slice(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+
+		return a.Column < b.Column
+	})
+
+	return findings, errors.Join(errs...)
+}
+
+func findingAt(position token.Position, analyzer string, diagnostic analysis.Diagnostic) archLintFinding {
+	return archLintFinding{
+		Analyzer: analyzer,
+		File:     position.Filename,
+		Line:     position.Line,
+		Column:   position.Column,
+		Category: diagnostic.Category,
+		Message:  diagnostic.Message,
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// buildArchLint builds the standalone runner into a temporary directory.
+func buildArchLint(t *testing.T) string {
+	t.Helper()
+
+	binary := filepath.Join(t.TempDir(), "arch-lint")
+
+	output, err := exec.Command("go", "build", "-o", binary, ".").CombinedOutput()
+	if err != nil {
+		t.Fatalf("go build: %v\n%s", err, output)
+	}
+
+	return binary
+}
+
+func runBinary(t *testing.T, binary string, args ...string) (string, int) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.Command(binary, args...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+
+	err := cmd.Run()
+
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		t.Fatalf("run %s: %v", binary, err)
+	}
+
+	return stdout.String(), cmd.ProcessState.ExitCode()
+}
+
+func TestArchLintReportsSecondMain(t *testing.T) {
+	binary := buildArchLint(t)
+
+	output, code := runBinary(t, binary, "-json", "-C", filepath.Join("testdata", "twomains"),
+		"-settings", `{"cmd-single-main":{"max-main-files":1}}`, "./...")
+	if code != exitFindings {
+		t.Fatalf("exit code = %d, want %d\n%s", code, exitFindings, output)
+	}
+
+	var findings []archLintFinding
+
+	for line := range strings.Lines(output) {
+		var finding archLintFinding
+		if err := json.Unmarshal([]byte(line), &finding); err != nil {
+			t.Fatalf("line %q is not a JSON finding: %v", line, err)
+		}
+
+		findings = append(findings, finding)
+	}
+
+	if len(findings) != 1 || findings[0].Analyzer != "cmd-single-main" ||
+		!strings.HasSuffix(findings[0].File, filepath.Join("cmd", "worker", "main.go")) ||
+		!strings.Contains(findings[0].Message, "CMD_SINGLE_MAIN: Found 2 main.go files in cmd/, expected at most 1") {
+		t.Errorf("findings = %+v, want one CMD_SINGLE_MAIN finding on cmd/worker/main.go", findings)
+	}
+
+	_, code = runBinary(t, binary, "-C", filepath.Join("testdata", "twomains"), "./...")
+	if code != exitClean {
+		t.Errorf("exit code with one main.go per command = %d, want %d", code, exitClean)
+	}
+
+	_, code = runBinary(t, binary, "-settings", `{"code-duplication-detector":{"min-tokens":0}}`, "./...")
+	if code != exitError {
+		t.Errorf("exit code with invalid settings = %d, want %d", code, exitError)
+	}
+}
+
+func TestArchLintReadsCustomConfig(t *testing.T) {
+	binary := buildArchLint(t)
+	dir := filepath.Join("testdata", "configured")
+
+	output, code := runBinary(t, binary, "-C", dir, "./...")
+	if code != exitFindings || !strings.Contains(output, "CMD_SINGLE_MAIN: Found 2 main.go files") {
+		t.Errorf("exit code = %d, want %d with the .custom-gcl.yml limit applied\n%s", code, exitFindings, output)
+	}
+
+	_, code = runBinary(t, binary, "-C", dir, "-settings", "{}", "./...")
+	if code != exitClean {
+		t.Errorf("exit code with -settings = %d, want %d: -settings replaces the config file", code, exitClean)
+	}
+
+	output, code = runBinary(t, binary, "-C", filepath.Join("testdata", "twomains"),
+		"-config", filepath.Join(dir, customConfigFile), "./...")
+	if code != exitFindings {
+		t.Errorf("exit code with -config = %d, want %d\n%s", code, exitFindings, output)
+	}
+}
@@ -4,69 +4,200 @@ import (
 	"errors"
 	"fmt"
 	"go/ast"
+	"io/fs"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"golang.org/x/tools/go/analysis"
 )
 
-// runCmdSingleMainValidation implements CMD single main enforcement.
+// cmdSingleMainConfig holds the cmd-single-main settings.
+type cmdSingleMainConfig struct {
+	// Commands, when set, are the only allowed cmd/<name> directories.
+	Commands []string
+	// MaxMainFiles, when positive, caps the main.go files below cmd/.
+	MaxMainFiles int
+}
+
+var cmdSingleMain cmdSingleMainConfig
+
+// configureCmdSingleMain applies the cmd-single-main plugin settings:
+// commands, a list of cmd subdirectory names, and max-main-files.
+func configureCmdSingleMain(conf any) error {
+	settings, ok := conf.(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	raw, ok := settings["cmd-single-main"]
+	if !ok || raw == nil {
+		return nil
+	}
+
+	section, ok := raw.(map[string]any)
+	if !ok {
+		return fmt.Errorf("cmd-single-main: %w: want a map, got %T", errInvalidSetting, raw)
+	}
+
+	var config cmdSingleMainConfig
+
+	for key, value := range section {
+		var err error
+
+		switch strings.ReplaceAll(key, "_", "-") {
+		case "enable":
+			// golangci-lint decides whether the plugin runs.
+		case "commands":
+			config.Commands, err = commandNames(value)
+		case "max-main-files":
+			config.MaxMainFiles, err = positiveInt(value)
+		default:
+			err = fmt.Errorf("%w: unknown key", errInvalidSetting)
+		}
+
+		if err != nil {
+			return fmt.Errorf("cmd-single-main.%s: %w", key, err)
+		}
+	}
+
+	cmdSingleMain = config
+
+	return nil
+}
+
+func commandNames(value any) ([]string, error) {
+	items, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: want a list of command names, got %T", errInvalidSetting, value)
+	}
+
+	names := make([]string, 0, len(items))
+
+	for _, item := range items {
+		name, ok := item.(string)
+		if !ok || name == "" || strings.ContainsAny(name, `/\`) {
+			return nil, fmt.Errorf("%w: want a cmd subdirectory name, got %v", errInvalidSetting, item)
+		}
+
+		names = append(names, name)
+	}
+
+	return names, nil
+}
+
+// runCmdSingleMainValidation enforces the cmd/ layout: each command is a
+// directory cmd/<name>/ with exactly one main.go, or a module with one
+// command keeps it at cmd/main.go. It reports a main.go directly in cmd/
+// beside other commands or an allowlist, one nested below a command, a
+// command outside the configured list, main.go files beyond
+// max-main-files, a main package's main.go outside cmd/ and main.go files
+// without func main.
 func runCmdSingleMainValidation(pass *analysis.Pass) (any, error) {
-	var mainFiles []string
+	config := cmdSingleMain
 
 	for _, file := range pass.Files {
 		filename := pass.Fset.Position(file.Pos()).Filename
+		if filepath.Base(filename) != "main.go" {
+			continue
+		}
+
+		cmdDir, ok := cmdRoot(filename)
+		if !ok {
+			if file.Name.Name == "main" {
+				pass.Reportf(file.Pos(),
+					"CMD_SINGLE_MAIN: main.go of package main outside cmd/. Move it to cmd/<name>/main.go")
+			}
 
-		// Check if this is a main.go file in cmd/ directory
-		if filepath.Base(filename) == "main.go" &&
-			strings.Contains(filepath.Dir(filename), "cmd") {
-			mainFiles = append(mainFiles, filename)
+			continue
 		}
-	}
 
-	// Validate constraint: exactly one main.go in cmd/
-	if len(mainFiles) == 0 {
-		// Report error on the first file analyzed (this is a project-wide constraint)
-		if len(pass.Files) > 0 {
-			pass.Reportf(pass.Files[0].Pos(),
-				"CMD_SINGLE_MAIN: No main.go files found in cmd/ directory. "+
-					"Create exactly one main.go file: mkdir -p cmd/server && touch cmd/server/main.go")
+		if problem := commandLayoutProblem(config, cmdDir, filename); problem != "" {
+			pass.Reportf(file.Pos(), "CMD_SINGLE_MAIN: %s", problem)
 		}
-	} else if len(mainFiles) > 1 {
-		// Report error on each extra main file
-		for i, mainFile := range mainFiles {
-			if i > 0 { // Skip the first one (that's allowed)
-				pass.Reportf(getFileNodeByPath(pass, mainFile).Pos(),
-					"CMD_SINGLE_MAIN: Found %d main.go files in cmd/, expected exactly 1. "+
-						"Consolidate using CLI frameworks like cobra: %s",
-					len(mainFiles), strings.Join(mainFiles, ", "))
-			}
+
+		// Additional validation: ensure main.go contains proper package main and func main()
+		err := validateMainFile(pass, file)
+		if err != nil {
+			pass.Reportf(file.Pos(), "CMD_SINGLE_MAIN: %v", err)
 		}
 	}
 
-	// Additional validation: ensure main.go contains proper package main and func main()
-	for _, mainFile := range mainFiles {
-		file := getFileNodeByPath(pass, mainFile)
-		if file != nil {
-			err := validateMainFile(pass, file)
-			if err != nil {
-				pass.Reportf(file.Pos(), "CMD_SINGLE_MAIN: %v", err)
-			}
+	return nil, nil
+}
+
+// commandLayoutProblem describes what is wrong with the place of the
+// main.go filename below cmdDir, or returns "".
+func commandLayoutProblem(config cmdSingleMainConfig, cmdDir, filename string) string {
+	rel, err := filepath.Rel(cmdDir, filepath.Dir(filename))
+	if err != nil {
+		return ""
+	}
+
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+
+	switch {
+	case rel == "." && len(config.Commands) == 0 && len(findMainFiles(cmdDir)) == 1:
+		// A single-command module may keep its entrypoint at cmd/main.go.
+	case rel == ".":
+		return "main.go directly in cmd/ next to other commands. Move it to cmd/<name>/main.go, " +
+			"one directory per command"
+	case len(parts) > 1:
+		return fmt.Sprintf("main.go nested below command cmd/%s. Each command has exactly one main.go, "+
+			"at cmd/%s/main.go", parts[0], parts[0])
+	case len(config.Commands) > 0 && !slices.Contains(config.Commands, parts[0]):
+		return fmt.Sprintf("command cmd/%s is not one of the allowed commands: %s",
+			parts[0], strings.Join(config.Commands, ", "))
+	}
+
+	if config.MaxMainFiles > 0 {
+		mainFiles := findMainFiles(cmdDir)
+		if index := slices.Index(mainFiles, filename); index >= config.MaxMainFiles {
+			return fmt.Sprintf("Found %d main.go files in cmd/, expected at most %d. "+
+				"Consolidate using CLI frameworks like cobra", len(mainFiles), config.MaxMainFiles)
 		}
 	}
 
-	return nil, nil
+	return ""
 }
 
-// getFileNodeByPath finds the AST file node for a given file path.
-func getFileNodeByPath(pass *analysis.Pass, filePath string) *ast.File {
-	for _, file := range pass.Files {
-		if pass.Fset.Position(file.Pos()).Filename == filePath {
-			return file
+// cmdRoot returns the nearest enclosing directory named cmd.
+func cmdRoot(filename string) (string, bool) {
+	for dir := filepath.Dir(filename); ; dir = filepath.Dir(dir) {
+		if filepath.Base(dir) == "cmd" {
+			return dir, true
+		}
+
+		if parent := filepath.Dir(dir); parent == dir {
+			return "", false
 		}
 	}
+}
 
-	return nil
+// findMainFiles lists the main.go files below cmdDir in lexical order,
+// skipping testdata, vendor and hidden directories.
+func findMainFiles(cmdDir string) []string {
+	var mainFiles []string
+
+	_ = filepath.WalkDir(cmdDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil //nolint:nilerr // an unreadable directory holds no main.go we can report
+		}
+
+		name := entry.Name()
+		if entry.IsDir() && path != cmdDir &&
+			(name == "testdata" || name == "vendor" || strings.HasPrefix(name, ".")) {
+			return filepath.SkipDir
+		}
+
+		if !entry.IsDir() && name == "main.go" {
+			mainFiles = append(mainFiles, path)
+		}
+
+		return nil
+	})
+
+	return mainFiles
 }
 
 // validateMainFile ensures main.go has proper structure.
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func runCmdSingleMain(t *testing.T, settings map[string]any, pkgs ...string) {
+	t.Helper()
+	t.Cleanup(func() { cmdSingleMain = cmdSingleMainConfig{} })
+
+	_, err := New(map[string]any{"cmd-single-main": settings})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// analysistest requires identifier names; the plugin keeps its dashed names.
+	analyzer := *CmdSingleMainAnalyzer
+	analyzer.Name = "cmdsinglemain"
+
+	analysistest.Run(t, analysistest.TestData(), &analyzer, pkgs...)
+}
+
+func TestCmdSingleMain(t *testing.T) {
+	runCmdSingleMain(t, map[string]any{},
+		"example.com/commands/cmd/...",
+		"example.com/rootmain/cmd",
+		"example.com/mixedmain/cmd/...",
+		"example.com/nested/cmd/...",
+		"example.com/outside/tool",
+		"example.com/nomain/cmd/api")
+}
+
+func TestCmdSingleMainAllowlist(t *testing.T) {
+	runCmdSingleMain(t, map[string]any{"commands": []any{"api"}}, "example.com/allowlist/cmd/...")
+}
+
+func TestCmdSingleMainInvalidSettings(t *testing.T) {
+	t.Cleanup(func() { cmdSingleMain = cmdSingleMainConfig{} })
+
+	for _, settings := range []map[string]any{
+		{"commands": []any{"api/v2"}},
+		{"max-main-files": -1},
+		{"enforce-main-function": true},
+	} {
+		_, err := New(map[string]any{"cmd-single-main": settings})
+		if !errors.Is(err, errInvalidSetting) {
+			t.Errorf("%v: error = %v, want an invalid setting error", settings, err)
+		}
+	}
+}
+
+// TestCmdSingleMainRepositoryLayout runs the analyzer with the default
+// settings over the main.go files of this repository's own module, which
+// keeps its one command at cmd/main.go. The check only needs syntax, so
+// the files are parsed rather than loaded.
+func TestCmdSingleMainRepositoryLayout(t *testing.T) {
+	root, err := filepath.Abs(filepath.Join("..", "..", ".."))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fset := token.NewFileSet()
+
+	var files []*ast.File
+
+	err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() && path != root {
+			if name := entry.Name(); name == "testdata" || name == "vendor" || strings.HasPrefix(name, ".") {
+				return filepath.SkipDir
+			}
+
+			// Nested modules, such as this plugin, are linted on their own.
+			if _, statErr := os.Stat(filepath.Join(path, "go.mod")); statErr == nil {
+				return filepath.SkipDir
+			}
+		}
+
+		if entry.IsDir() || entry.Name() != "main.go" {
+			return nil
+		}
+
+		file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		files = append(files, file)
+
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(files) == 0 {
+		t.Fatalf("no main.go below %s", root)
+	}
+
+	pass := &analysis.Pass{ //nolint:exhaustruct // the analyzer reads only syntax
+		Analyzer: CmdSingleMainAnalyzer,
+		Fset:     fset,
+		Files:    files,
+		Report: func(diagnostic analysis.Diagnostic) {
+			t.Errorf("%s: %s", fset.Position(diagnostic.Pos), diagnostic.Message)
+		},
+	}
+
+	_, err = runCmdSingleMainValidation(pass)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -1,15 +1,207 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"go/ast"
 	"go/token"
+	"math"
 	"reflect"
+	"regexp"
+	"sort"
 	"strings"
 
 	"golang.org/x/tools/go/analysis"
 )
 
+// maxReportedDuplicates caps the diagnostics per clone group.
+const maxReportedDuplicates = 3
+
+var errInvalidSetting = errors.New("invalid setting")
+
+// codeDuplicationConfig holds the code-duplication-detector settings.
+type codeDuplicationConfig struct {
+	// MinStatements is the fewest statements a block needs to be compared.
+	MinStatements int
+	// MinTokens is the fewest AST nodes a block needs to be compared.
+	MinTokens int
+	// SimilarityThreshold is the lowest similarity, from 0 to 1, reported
+	// for blocks of the same structure.
+	SimilarityThreshold float64
+	// SkipTests skips _test.go files.
+	SkipTests bool
+	// SkipGenerated skips generated files and templ output.
+	SkipGenerated bool
+	// ExcludePatterns skip files whose path matches any of them.
+	ExcludePatterns []*regexp.Regexp
+}
+
+func defaultCodeDuplicationConfig() codeDuplicationConfig {
+	return codeDuplicationConfig{
+		MinStatements:       3,
+		MinTokens:           15,
+		SimilarityThreshold: 0.8,
+		SkipTests:           true,
+		SkipGenerated:       true,
+		ExcludePatterns:     nil,
+	}
+}
+
+var codeDuplication = defaultCodeDuplicationConfig()
+
+// configureCodeDuplication applies the code-duplication-detector plugin
+// settings: min-statements, min-tokens, similarity-threshold, skip-tests,
+// skip-generated and exclude-patterns. Underscores may stand for dashes.
+func configureCodeDuplication(conf any) error {
+	settings, ok := conf.(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	raw, ok := settings["code-duplication-detector"]
+	if !ok || raw == nil {
+		return nil
+	}
+
+	section, ok := raw.(map[string]any)
+	if !ok {
+		return fmt.Errorf("code-duplication-detector: %w: want a map, got %T", errInvalidSetting, raw)
+	}
+
+	config := defaultCodeDuplicationConfig()
+
+	for key, value := range section {
+		err := config.set(strings.ReplaceAll(key, "_", "-"), value)
+		if err != nil {
+			return fmt.Errorf("code-duplication-detector.%s: %w", key, err)
+		}
+	}
+
+	codeDuplication = config
+
+	return nil
+}
+
+func (c *codeDuplicationConfig) set(key string, value any) error {
+	var err error
+
+	switch key {
+	case "enable":
+		// golangci-lint decides whether the plugin runs.
+	case "min-statements":
+		c.MinStatements, err = positiveInt(value)
+	case "min-tokens":
+		c.MinTokens, err = positiveInt(value)
+	case "similarity-threshold":
+		c.SimilarityThreshold, err = fraction(value)
+	case "skip-tests":
+		c.SkipTests, err = boolean(value)
+	case "skip-generated":
+		c.SkipGenerated, err = boolean(value)
+	case "exclude-patterns":
+		c.ExcludePatterns, err = patterns(value)
+	default:
+		err = fmt.Errorf("%w: unknown key", errInvalidSetting)
+	}
+
+	return err
+}
+
+func positiveInt(value any) (int, error) {
+	var n float64
+
+	switch v := value.(type) {
+	case int:
+		n = float64(v)
+	case int64:
+		n = float64(v)
+	case uint64:
+		n = float64(v)
+	case float64:
+		n = v
+	default:
+		return 0, fmt.Errorf("%w: want a positive integer, got %T", errInvalidSetting, value)
+	}
+
+	if n < 1 || n != math.Trunc(n) || n > math.MaxInt32 {
+		return 0, fmt.Errorf("%w: want a positive integer, got %v", errInvalidSetting, value)
+	}
+
+	return int(n), nil
+}
+
+func fraction(value any) (float64, error) {
+	var f float64
+
+	switch v := value.(type) {
+	case int:
+		f = float64(v)
+	case float64:
+		f = v
+	default:
+		return 0, fmt.Errorf("%w: want a number from 0 to 1, got %T", errInvalidSetting, value)
+	}
+
+	if f < 0 || f > 1 {
+		return 0, fmt.Errorf("%w: want a number from 0 to 1, got %v", errInvalidSetting, value)
+	}
+
+	return f, nil
+}
+
+func boolean(value any) (bool, error) {
+	b, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("%w: want true or false, got %T", errInvalidSetting, value)
+	}
+
+	return b, nil
+}
+
+func patterns(value any) ([]*regexp.Regexp, error) {
+	items, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: want a list of regular expressions, got %T", errInvalidSetting, value)
+	}
+
+	compiled := make([]*regexp.Regexp, 0, len(items))
+
+	for _, item := range items {
+		pattern, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: want a regular expression, got %T", errInvalidSetting, item)
+		}
+
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", errInvalidSetting, err)
+		}
+
+		compiled = append(compiled, re)
+	}
+
+	return compiled, nil
+}
+
+// skipsFile reports whether the settings exclude file from comparison.
+func (c *codeDuplicationConfig) skipsFile(file *ast.File, filename string) bool {
+	if c.SkipTests && strings.HasSuffix(filename, "_test.go") {
+		return true
+	}
+
+	if c.SkipGenerated && (ast.IsGenerated(file) || strings.HasSuffix(filename, "_templ.go")) {
+		return true
+	}
+
+	for _, pattern := range c.ExcludePatterns {
+		if pattern.MatchString(filename) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // CodeBlock represents a block of code for duplication analysis.
 type CodeBlock struct {
 	Node     ast.Node
@@ -22,37 +214,57 @@ type CodeBlock struct {
 
 // runCodeDuplicationDetection implements code duplication detection analyzer.
 func runCodeDuplicationDetection(pass *analysis.Pass) (any, error) {
-	const minTokens = 15 // Configurable threshold
+	config := codeDuplication
 
 	var codeBlocks []CodeBlock
 
 	// Extract code blocks from all files
 	for _, file := range pass.Files {
 		filename := pass.Fset.Position(file.Pos()).Filename
-		blocks := extractCodeBlocks(pass, file, filename, minTokens)
+		if config.skipsFile(file, filename) {
+			continue
+		}
+
+		blocks := extractCodeBlocks(file, filename, config)
 		codeBlocks = append(codeBlocks, blocks...)
 	}
 
-	// Find duplicates
+	// Report the largest clones first; clones nested in them add nothing.
 	duplicates := findDuplicateBlocks(codeBlocks)
+	sort.Slice(duplicates, func(i, j int) bool {
+		if duplicates[i][0].Tokens != duplicates[j][0].Tokens {
+			return duplicates[i][0].Tokens > duplicates[j][0].Tokens
+		}
+
+		return duplicates[i][0].StartPos < duplicates[j][0].StartPos
+	})
+
+	var reported []CodeBlock
 
-	// Report duplicates
 	for _, group := range duplicates {
-		if len(group) > 1 {
-			first := group[0]
-			pass.Reportf(
-				first.StartPos,
-				"CODE_DUPLICATION: Duplicated code block (%d tokens) found in %d locations. Consider extracting to a function.",
-				first.Tokens,
-				len(group),
-			)
-
-			for i, block := range group[1:] {
-				if i < 3 { // Limit to first 3 duplicates to avoid spam
-					pass.Reportf(block.StartPos,
-						"CODE_DUPLICATION: Duplicate of code at %s",
-						pass.Fset.Position(first.StartPos))
-				}
+		if allContained(group, reported) {
+			continue
+		}
+
+		first := group[0]
+		similarity := blockSimilarity(first.Node, group[1].Node)
+
+		if similarity < config.SimilarityThreshold {
+			continue
+		}
+
+		reported = append(reported, group...)
+
+		pass.Reportf(first.StartPos,
+			"CODE_DUPLICATION: Duplicated code block (%d tokens, %.0f%% similar) found in %d locations, "+
+				"also at %s. Consider extracting to a function.",
+			first.Tokens, similarity*100, len(group), pass.Fset.Position(group[1].StartPos))
+
+		for i, block := range group[1:] {
+			if i < maxReportedDuplicates { // Limit duplicates to avoid spam
+				pass.Reportf(block.StartPos,
+					"CODE_DUPLICATION: Duplicate (%.0f%% similar) of code at %s",
+					blockSimilarity(first.Node, block.Node)*100, pass.Fset.Position(first.StartPos))
 			}
 		}
 	}
@@ -61,33 +273,13 @@ func runCodeDuplicationDetection(pass *analysis.Pass) (any, error) {
 }
 
 // extractCodeBlocks extracts analyzable code blocks from a file.
-func extractCodeBlocks(
-	pass *analysis.Pass,
-	file *ast.File,
-	filename string,
-	minTokens int,
-) []CodeBlock {
+func extractCodeBlocks(file *ast.File, filename string, config codeDuplicationConfig) []CodeBlock {
 	var blocks []CodeBlock
 
 	ast.Inspect(file, func(n ast.Node) bool {
 		switch node := n.(type) {
-		case *ast.FuncDecl:
-			if node.Body != nil && len(node.Body.List) >= 3 {
-				block := createCodeBlock(pass, node.Body, filename, minTokens)
-				if block != nil {
-					blocks = append(blocks, *block)
-				}
-			}
-		case *ast.BlockStmt:
-			if len(node.List) >= 3 {
-				block := createCodeBlock(pass, node, filename, minTokens)
-				if block != nil {
-					blocks = append(blocks, *block)
-				}
-			}
-		case *ast.IfStmt, *ast.ForStmt, *ast.RangeStmt, *ast.SwitchStmt, *ast.TypeSwitchStmt:
-			// Analyze complex statements
-			block := createCodeBlock(pass, node, filename, minTokens)
+		case *ast.BlockStmt, *ast.IfStmt, *ast.ForStmt, *ast.RangeStmt, *ast.SwitchStmt, *ast.TypeSwitchStmt:
+			block := createCodeBlock(node, filename, config)
 			if block != nil {
 				blocks = append(blocks, *block)
 			}
@@ -100,19 +292,14 @@ func extractCodeBlocks(
 }
 
 // createCodeBlock creates a code block for duplication analysis.
-func createCodeBlock(
-	pass *analysis.Pass,
-	node ast.Node,
-	filename string,
-	minTokens int,
-) *CodeBlock {
-	if node == nil {
+func createCodeBlock(node ast.Node, filename string, config codeDuplicationConfig) *CodeBlock {
+	if node == nil || countStatements(node) < config.MinStatements {
 		return nil
 	}
 
 	// Calculate approximate token count
 	tokenCount := estimateTokenCount(node)
-	if tokenCount < minTokens {
+	if tokenCount < config.MinTokens {
 		return nil
 	}
 
@@ -129,6 +316,24 @@ func createCodeBlock(
 	}
 }
 
+// countStatements counts the statements in node, nested ones included;
+// blocks only group statements and do not count themselves.
+func countStatements(node ast.Node) int {
+	count := 0
+
+	ast.Inspect(node, func(n ast.Node) bool {
+		if _, ok := n.(ast.Stmt); ok {
+			if _, block := n.(*ast.BlockStmt); !block {
+				count++
+			}
+		}
+
+		return true
+	})
+
+	return count
+}
+
 // estimateTokenCount provides a rough estimate of tokens in an AST node.
 func estimateTokenCount(node ast.Node) int {
 	count := 0
@@ -180,6 +385,65 @@ func generateStructuralHash(node ast.Node) string {
 	return builder.String()
 }
 
+// blockSimilarity is the share of identifiers and literals two blocks of
+// the same structure have in common: 1 for a verbatim copy, less for a
+// copy with renamed variables or changed constants.
+func blockSimilarity(a, b ast.Node) float64 {
+	leavesA, leavesB := leaves(a), leaves(b)
+	if len(leavesA) == 0 || len(leavesA) != len(leavesB) {
+		return 1
+	}
+
+	same := 0
+
+	for i := range leavesA {
+		if leavesA[i] == leavesB[i] {
+			same++
+		}
+	}
+
+	return float64(same) / float64(len(leavesA))
+}
+
+func leaves(node ast.Node) []string {
+	var values []string
+
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch leaf := n.(type) {
+		case *ast.Ident:
+			values = append(values, leaf.Name)
+		case *ast.BasicLit:
+			values = append(values, leaf.Value)
+		}
+
+		return true
+	})
+
+	return values
+}
+
+// allContained reports whether every block of group lies within one of
+// the reported blocks.
+func allContained(group, reported []CodeBlock) bool {
+	for _, block := range group {
+		contained := false
+
+		for _, outer := range reported {
+			if outer.StartPos <= block.StartPos && block.EndPos <= outer.EndPos {
+				contained = true
+
+				break
+			}
+		}
+
+		if !contained {
+			return false
+		}
+	}
+
+	return true
+}
+
 // findDuplicateBlocks groups code blocks by their structural similarity.
 func findDuplicateBlocks(blocks []CodeBlock) [][]CodeBlock {
 	hashGroups := make(map[string][]CodeBlock)
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func runCodeDuplication(t *testing.T, settings map[string]any, pkgs ...string) {
+	t.Helper()
+	t.Cleanup(func() { codeDuplication = defaultCodeDuplicationConfig() })
+
+	_, err := New(map[string]any{"code-duplication-detector": settings})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// analysistest requires identifier names; the plugin keeps its dashed names.
+	analyzer := *CodeDuplicationAnalyzer
+	analyzer.Name = "codeduplication"
+
+	analysistest.Run(t, analysistest.TestData(), &analyzer, pkgs...)
+}
+
+func TestCodeDuplication(t *testing.T) {
+	runCodeDuplication(t, map[string]any{"similarity-threshold": 0}, "example.com/dup/clones")
+}
+
+func TestCodeDuplicationDefaultThreshold(t *testing.T) {
+	runCodeDuplication(t, map[string]any{}, "example.com/dup/threshold")
+}
+
+func TestCodeDuplicationMinStatements(t *testing.T) {
+	runCodeDuplication(t, map[string]any{"min_statements": 6}, "example.com/dup/quiet")
+}
+
+func TestCodeDuplicationSkipsTemplOutput(t *testing.T) {
+	runCodeDuplication(t, map[string]any{"skip-generated": true}, "example.com/dup/generated")
+}
+
+func TestCodeDuplicationInvalidSettings(t *testing.T) {
+	t.Cleanup(func() { codeDuplication = defaultCodeDuplicationConfig() })
+
+	tests := map[string]any{
+		"min-statements":       0,
+		"min-tokens":           "many",
+		"similarity-threshold": 1.5,
+		"skip-tests":           "yes",
+		"exclude-patterns":     []any{"("},
+		"max-clones":           3,
+	}
+
+	for key, value := range tests {
+		_, err := New(map[string]any{"code-duplication-detector": map[string]any{key: value}})
+		if !errors.Is(err, errInvalidSetting) {
+			t.Errorf("%s = %v: error = %v, want an invalid setting error", key, value, err)
+		}
+	}
+}
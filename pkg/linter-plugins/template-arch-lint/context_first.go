@@ -0,0 +1,298 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// contextFirstConfig holds the context-first settings.
+type contextFirstConfig struct {
+	// Packages are package path globs; "*" matches within one path element
+	// and "**" across elements.
+	Packages []string
+	// Allow are method name globs exempt from both conventions, for pure
+	// getters and builders.
+	Allow []string
+}
+
+func defaultContextFirstConfig() contextFirstConfig {
+	return contextFirstConfig{
+		Packages: []string{"**/domain/services", "**/domain/repositories"},
+		Allow:    []string{"With*"},
+	}
+}
+
+var contextFirst = defaultContextFirstConfig()
+
+// contextFirstExempt are method names that follow their own contract:
+// fmt.Stringer, error, validation and the encoding interfaces.
+var contextFirstExempt = []string{
+	"String", "GoString", "Error", "Format", "Validate", "IsValid",
+	"MarshalJSON", "UnmarshalJSON", "MarshalText", "UnmarshalText",
+}
+
+// configureContextFirst applies the context-first plugin settings:
+// packages and allow, each a list of globs.
+func configureContextFirst(conf any) error {
+	settings, ok := conf.(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	raw, ok := settings["context-first"]
+	if !ok || raw == nil {
+		return nil
+	}
+
+	section, ok := raw.(map[string]any)
+	if !ok {
+		return fmt.Errorf("context-first: %w: want a map, got %T", errInvalidSetting, raw)
+	}
+
+	config := defaultContextFirstConfig()
+
+	for key, value := range section {
+		var err error
+
+		switch key {
+		case "enable":
+			// golangci-lint decides whether the plugin runs.
+		case "packages":
+			config.Packages, err = globs(value)
+		case "allow":
+			config.Allow, err = globs(value)
+		default:
+			err = fmt.Errorf("%w: unknown key", errInvalidSetting)
+		}
+
+		if err != nil {
+			return fmt.Errorf("context-first.%s: %w", key, err)
+		}
+	}
+
+	contextFirst = config
+
+	return nil
+}
+
+func globs(value any) ([]string, error) {
+	items, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: want a list of globs, got %T", errInvalidSetting, value)
+	}
+
+	patterns := make([]string, 0, len(items))
+
+	for _, item := range items {
+		pattern, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: want a glob, got %T", errInvalidSetting, item)
+		}
+
+		if _, err := path.Match(strings.ReplaceAll(pattern, "**", "*"), ""); err != nil {
+			return nil, fmt.Errorf("%w: glob %q: %w", errInvalidSetting, pattern, err)
+		}
+
+		patterns = append(patterns, pattern)
+	}
+
+	return patterns, nil
+}
+
+// matchGlob matches name against pattern, where "**" also crosses "/".
+func matchGlob(pattern, name string) bool {
+	var expr strings.Builder
+
+	expr.WriteString("^")
+
+	for i, part := range strings.Split(pattern, "**") {
+		if i > 0 {
+			expr.WriteString(".*")
+		}
+
+		for j, segment := range strings.Split(part, "*") {
+			if j > 0 {
+				expr.WriteString("[^/]*")
+			}
+
+			expr.WriteString(regexp.QuoteMeta(segment))
+		}
+	}
+
+	expr.WriteString("$")
+
+	matched, _ := regexp.MatchString(expr.String(), name)
+
+	return matched
+}
+
+func matchesAnyGlob(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matchGlob(pattern, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// runContextFirst checks the exported methods of the configured packages,
+// on concrete types and in interfaces: the first parameter must be a
+// context.Context and the last result an error. Only services and
+// repositories are held to this: methods with a value receiver belong to
+// value types such as specifications and records, and an interface none of
+// whose methods takes a context describes one.
+func runContextFirst(pass *analysis.Pass) (any, error) {
+	config := contextFirst
+	if !matchesAnyGlob(config.Packages, pass.Pkg.Path()) {
+		return nil, nil
+	}
+
+	for _, file := range pass.Files {
+		if strings.HasSuffix(pass.Fset.Position(file.Pos()).Filename, "_test.go") {
+			continue
+		}
+
+		ast.Inspect(file, func(node ast.Node) bool {
+			switch n := node.(type) {
+			case *ast.FuncDecl:
+				if n.Recv != nil && hasPointerReceiver(pass, n) {
+					checkContextFirst(pass, config, n.Name.Name, n.Type)
+				}
+
+				return false
+			case *ast.InterfaceType:
+				if !takesContext(pass, n) {
+					return true
+				}
+
+				for _, method := range n.Methods.List {
+					fnType, ok := method.Type.(*ast.FuncType)
+					if !ok {
+						continue
+					}
+
+					for _, name := range method.Names {
+						checkContextFirst(pass, config, name.Name, fnType)
+					}
+				}
+			}
+
+			return true
+		})
+	}
+
+	return nil, nil
+}
+
+func checkContextFirst(pass *analysis.Pass, config contextFirstConfig, name string, fnType *ast.FuncType) {
+	if !ast.IsExported(name) || strings.HasPrefix(name, "New") ||
+		matchesAnyGlob(contextFirstExempt, name) || matchesAnyGlob(config.Allow, name) {
+		return
+	}
+
+	params := fieldTypes(pass, fnType.Params)
+	if len(params) == 0 || !isContextType(params[0]) {
+		position := -1
+
+		for i, param := range params {
+			if isContextType(param) {
+				position = i + 1
+
+				break
+			}
+		}
+
+		if position > 0 {
+			pass.Reportf(fnType.Params.Pos(),
+				"CONTEXT_FIRST: %s takes context.Context as parameter %d; it must be the first parameter",
+				name, position)
+		} else {
+			pass.Reportf(fnType.Params.Pos(),
+				"CONTEXT_FIRST: %s must take context.Context as its first parameter", name)
+		}
+	}
+
+	results := fieldTypes(pass, fnType.Results)
+	if len(results) == 0 || !returnsErrorLast(results[len(results)-1]) {
+		pos := fnType.Params.Pos()
+		if fnType.Results != nil {
+			pos = fnType.Results.Pos()
+		}
+
+		pass.Reportf(pos, "ERROR_LAST: %s must return error as its last result", name)
+	}
+}
+
+func hasPointerReceiver(pass *analysis.Pass, fn *ast.FuncDecl) bool {
+	_, ok := types.Unalias(pass.TypesInfo.TypeOf(fn.Recv.List[0].Type)).(*types.Pointer)
+
+	return ok
+}
+
+// takesContext reports whether any method of iface has a context.Context
+// parameter.
+func takesContext(pass *analysis.Pass, iface *ast.InterfaceType) bool {
+	for _, method := range iface.Methods.List {
+		fnType, ok := method.Type.(*ast.FuncType)
+		if ok && slices.ContainsFunc(fieldTypes(pass, fnType.Params), isContextType) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fieldTypes expands a field list into one type per parameter or result.
+func fieldTypes(pass *analysis.Pass, fields *ast.FieldList) []types.Type {
+	if fields == nil {
+		return nil
+	}
+
+	var list []types.Type
+
+	for _, field := range fields.List {
+		t := pass.TypesInfo.TypeOf(field.Type)
+
+		for range max(len(field.Names), 1) {
+			list = append(list, t)
+		}
+	}
+
+	return list
+}
+
+func isContextType(t types.Type) bool {
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok {
+		return false
+	}
+
+	obj := named.Obj()
+
+	return obj.Pkg() != nil && obj.Pkg().Path() == "context" && obj.Name() == "Context"
+}
+
+// returnsErrorLast accepts error itself and the error-carrying
+// github.com/samber/mo Result the services use.
+func returnsErrorLast(t types.Type) bool {
+	if isErrorType(t) {
+		return true
+	}
+
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok {
+		return false
+	}
+
+	obj := named.Obj()
+
+	return obj.Pkg() != nil && obj.Pkg().Path() == "github.com/samber/mo" && obj.Name() == "Result"
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestContextFirst(t *testing.T) {
+	t.Cleanup(func() { contextFirst = defaultContextFirstConfig() })
+
+	// Timeout is a pure getter, allowed alongside the With* builders.
+	_, err := New(map[string]any{"context-first": map[string]any{"allow": []any{"With*", "Timeout"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// analysistest requires identifier names; the plugin keeps its dashed names.
+	analyzer := *ContextFirstAnalyzer
+	analyzer.Name = "contextfirst"
+
+	analysistest.Run(t, analysistest.TestData(), &analyzer,
+		"example.com/app/domain/services", "example.com/app/domain/repositories")
+}
+
+func TestContextFirstInvalidSettings(t *testing.T) {
+	t.Cleanup(func() { contextFirst = defaultContextFirstConfig() })
+
+	for _, settings := range []map[string]any{
+		{"packages": "**/services"},
+		{"allow": []any{"Get["}},
+		{"getters": []any{"Get*"}},
+	} {
+		_, err := New(map[string]any{"context-first": settings})
+		if !errors.Is(err, errInvalidSetting) {
+			t.Errorf("%v: error = %v, want an invalid setting error", settings, err)
+		}
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"**/domain/services", "github.com/acme/app/internal/domain/services", true},
+		{"**/domain/services", "github.com/acme/app/internal/domain/services/testhelpers", false},
+		{"*/domain/services", "github.com/acme/domain/services", false},
+		{"With*", "WithLogger", true},
+		{"With*", "Without", true},
+		{"Get?", "GetX", false},
+	}
+
+	for _, tt := range tests {
+		if got := matchGlob(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
@@ -3,39 +3,120 @@ package main
 import (
 	"fmt"
 	"go/ast"
+	"maps"
 	"path/filepath"
-	"regexp"
+	"slices"
 	"strings"
+	"unicode"
 
 	"golang.org/x/tools/go/analysis"
 )
 
+// Filename rule IDs. Each diagnostic carries its rule ID as the category.
+const (
+	ruleMixedCase           = "mixed-case"
+	ruleNonSnakeCase        = "non-snake-case"
+	ruleDotsBeyondExtension = "dots-beyond-extension"
+	ruleTestFilePlacement   = "test-file-placement"
+)
+
+func defaultFilenameRules() map[string]bool {
+	return map[string]bool{
+		ruleMixedCase:           true,
+		ruleNonSnakeCase:        true,
+		ruleDotsBeyondExtension: true,
+		ruleTestFilePlacement:   true,
+	}
+}
+
+var filenameRules = defaultFilenameRules()
+
+// configureFilenameValidator applies the filename-validator plugin
+// settings: rules maps a rule ID to whether it is enabled.
+func configureFilenameValidator(conf any) error {
+	settings, ok := conf.(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	raw, ok := settings["filename-validator"]
+	if !ok || raw == nil {
+		return nil
+	}
+
+	section, ok := raw.(map[string]any)
+	if !ok {
+		return fmt.Errorf("filename-validator: %w: want a map, got %T", errInvalidSetting, raw)
+	}
+
+	rules := defaultFilenameRules()
+
+	for key, value := range section {
+		switch key {
+		case "enable":
+			// golangci-lint decides whether the plugin runs.
+		case "rules":
+			err := setFilenameRules(rules, value)
+			if err != nil {
+				return fmt.Errorf("filename-validator.rules: %w", err)
+			}
+		default:
+			return fmt.Errorf("filename-validator.%s: %w: unknown key", key, errInvalidSetting)
+		}
+	}
+
+	filenameRules = rules
+
+	return nil
+}
+
+func setFilenameRules(rules map[string]bool, value any) error {
+	toggles, ok := value.(map[string]any)
+	if !ok {
+		return fmt.Errorf("%w: want a map of rule IDs, got %T", errInvalidSetting, value)
+	}
+
+	for rule, toggle := range toggles {
+		if _, known := rules[rule]; !known {
+			return fmt.Errorf("%w: unknown rule %q, want one of %s",
+				errInvalidSetting, rule, strings.Join(slices.Sorted(maps.Keys(rules)), ", "))
+		}
+
+		enabled, err := boolean(toggle)
+		if err != nil {
+			return fmt.Errorf("%s: %w", rule, err)
+		}
+
+		rules[rule] = enabled
+	}
+
+	return nil
+}
+
 // runFilenameValidation implements filename validation analyzer.
 func runFilenameValidation(pass *analysis.Pass) (any, error) {
-	// Standard Go filename patterns
-	validFilenameRegex := regexp.MustCompile(`^[a-z][a-z0-9_]*(_test)?\.go$`)
+	rules := filenameRules
 
 	for _, file := range pass.Files {
 		filename := filepath.Base(pass.Fset.Position(file.Pos()).Filename)
 
 		// Skip generated files
-		if isGeneratedFile(filename) {
+		if isGeneratedFile(filename) || ast.IsGenerated(file) {
 			continue
 		}
 
-		// Validate filename pattern
-		if !validFilenameRegex.MatchString(filename) {
-			pass.Reportf(
-				file.Pos(),
-				"filename %q does not follow Go naming conventions. Use lowercase with underscores: example_service.go",
-				filename,
-			)
-		}
+		suggested := snakeCaseFilename(filename)
 
-		// Check for common anti-patterns
-		err := checkFilenameAntiPatterns(pass, filename, file)
-		if err != nil {
-			pass.Reportf(file.Pos(), "%v", err)
+		for _, rule := range filenameViolations(filename, file) {
+			if !rules[rule] {
+				continue
+			}
+
+			pass.Report(analysis.Diagnostic{ //nolint:exhaustruct // no related positions or fixes
+				Pos:      file.Name.Pos(),
+				Category: rule,
+				Message:  filenameMessage(rule, filename, suggested),
+			})
 		}
 	}
 
@@ -44,7 +125,7 @@ func runFilenameValidation(pass *analysis.Pass) (any, error) {
 
 // isGeneratedFile checks if a file is generated and should be skipped.
 func isGeneratedFile(filename string) bool {
-	generatedPatterns := []string{
+	generatedSuffixes := []string{
 		"_gen.go",
 		"_generated.go",
 		".pb.go",
@@ -52,8 +133,8 @@ func isGeneratedFile(filename string) bool {
 		"_mock.go",
 	}
 
-	for _, pattern := range generatedPatterns {
-		if strings.Contains(filename, pattern) {
+	for _, suffix := range generatedSuffixes {
+		if strings.HasSuffix(filename, suffix) {
 			return true
 		}
 	}
@@ -61,52 +142,139 @@ func isGeneratedFile(filename string) bool {
 	return false
 }
 
-// checkFilenameAntiPatterns validates against common filename anti-patterns.
-func checkFilenameAntiPatterns(pass *analysis.Pass, filename string, file *ast.File) error {
-	// Check for camelCase filenames
-	if strings.ContainsAny(filename[:len(filename)-3], "ABCDEFGHIJKLMNOPQRSTUVWXYZ") {
-		return fmt.Errorf("filename %q uses camelCase. Use snake_case: %s",
-			filename, strings.ToLower(filename))
+// filenameViolations returns the IDs of the rules filename breaks.
+func filenameViolations(filename string, file *ast.File) []string {
+	var violations []string
+
+	stem := strings.TrimSuffix(filename, ".go")
+
+	if strings.ContainsFunc(stem, unicode.IsUpper) {
+		violations = append(violations, ruleMixedCase)
+	}
+
+	if strings.Contains(stem, ".") {
+		violations = append(violations, ruleDotsBeyondExtension)
 	}
 
-	// Check for dashes (should use underscores)
-	if strings.Contains(filename, "-") {
-		suggested := strings.ReplaceAll(filename, "-", "_")
+	if !isSnakeCase(strings.ToLower(strings.ReplaceAll(stem, ".", "_"))) {
+		violations = append(violations, ruleNonSnakeCase)
+	}
 
-		return fmt.Errorf("filename %q uses dashes. Use underscores: %s",
-			filename, suggested)
+	if !strings.HasSuffix(stem, "_test") && declaresTests(file) {
+		violations = append(violations, ruleTestFilePlacement)
 	}
 
-	// Check package name alignment
-	packageName := file.Name.Name
-	expectedPrefix := strings.TrimSuffix(filename, ".go")
-	expectedPrefix = strings.TrimSuffix(expectedPrefix, "_test")
+	return violations
+}
+
+func filenameMessage(rule, filename, suggested string) string {
+	switch rule {
+	case ruleMixedCase:
+		if suggested != "" {
+			return fmt.Sprintf("%s: filename %q uses upper case. Rename it to %s", rule, filename, suggested)
+		}
+
+		return fmt.Sprintf("%s: filename %q uses upper case. Use lowercase snake_case", rule, filename)
+	case ruleNonSnakeCase:
+		if suggested != "" {
+			return fmt.Sprintf("%s: filename %q is not snake_case. Rename it to %s", rule, filename, suggested)
+		}
 
-	// For single-word packages, filename should match or be descriptive
-	if packageName != "main" && !strings.HasPrefix(expectedPrefix, packageName) &&
-		!isDescriptiveFilename(expectedPrefix, packageName) {
-		return fmt.Errorf("filename %q doesn't align with package %q. Consider %s.go or %s_%s.go",
-			filename, packageName, packageName, packageName, expectedPrefix)
+		return fmt.Sprintf("%s: filename %q is not snake_case. Use lowercase letters, digits and underscores",
+			rule, filename)
+	case ruleDotsBeyondExtension:
+		return fmt.Sprintf("%s: filename %q has dots before .go. Use underscores to separate words", rule, filename)
+	default:
+		return fmt.Sprintf("%s: filename %q declares tests but go test only runs tests in _test.go files",
+			rule, filename)
 	}
+}
 
-	return nil
+// snakeCaseFilename returns the snake_case name for filename when the
+// rename is mechanical: words split at case changes and dashes. It returns
+// "" when filename already conforms or needs more than that, such as a
+// dot before the extension.
+func snakeCaseFilename(filename string) string {
+	stem := strings.TrimSuffix(filename, ".go")
+	if strings.Contains(stem, ".") {
+		return ""
+	}
+
+	runes := []rune(stem)
+
+	var builder strings.Builder
+
+	for i, r := range runes {
+		switch {
+		case r == '-':
+			builder.WriteRune('_')
+		case unicode.IsUpper(r):
+			// A word starts at an upper case letter after a lower case one
+			// or a digit, or at the last capital of an acronym: HTTPServer.
+			if i > 0 && runes[i-1] != '_' && runes[i-1] != '-' &&
+				(!unicode.IsUpper(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				builder.WriteRune('_')
+			}
+
+			builder.WriteRune(unicode.ToLower(r))
+		default:
+			builder.WriteRune(r)
+		}
+	}
+
+	snake := builder.String()
+	if snake == stem || !isSnakeCase(strings.TrimSuffix(snake, "_test")) {
+		return ""
+	}
+
+	return snake + ".go"
 }
 
-// isDescriptiveFilename checks if a filename is descriptively appropriate for the package.
-func isDescriptiveFilename(filename, packageName string) bool {
-	// Allow common descriptive patterns
-	descriptivePatterns := []string{
-		"handler", "service", "repository", "model", "entity", "value",
-		"error", "config", "client", "server", "middleware", "util", "helper",
-		"test", "benchmark", "example", "mock", "stub",
+// isSnakeCase reports whether name is lowercase words of letters and
+// digits joined by single underscores, starting with a letter.
+func isSnakeCase(name string) bool {
+	if name == "" || name[0] < 'a' || name[0] > 'z' || strings.HasSuffix(name, "_") || strings.Contains(name, "__") {
+		return false
 	}
 
-	for _, pattern := range descriptivePatterns {
-		if strings.Contains(filename, pattern) {
-			return true
+	for _, r := range name {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '_' {
+			return false
 		}
 	}
 
-	// Allow if filename contains package name
-	return strings.Contains(filename, packageName)
+	return true
+}
+
+// declaresTests reports whether file declares a Test, Benchmark or Fuzz
+// function taking a testing parameter.
+func declaresTests(file *ast.File) bool {
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv != nil || fn.Type.Params.NumFields() != 1 {
+			continue
+		}
+
+		param, ok := fn.Type.Params.List[0].Type.(*ast.StarExpr)
+		if !ok {
+			continue
+		}
+
+		sel, ok := param.X.(*ast.SelectorExpr)
+		if !ok || !slices.Contains([]string{"T", "B", "F"}, sel.Sel.Name) {
+			continue
+		}
+
+		if pkg, ok := sel.X.(*ast.Ident); !ok || pkg.Name != "testing" {
+			continue
+		}
+
+		for _, prefix := range []string{"Test", "Benchmark", "Fuzz"} {
+			if strings.HasPrefix(fn.Name.Name, prefix) {
+				return true
+			}
+		}
+	}
+
+	return false
 }
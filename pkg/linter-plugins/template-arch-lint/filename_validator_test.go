@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func runFilenameValidator(t *testing.T, settings map[string]any, pkgs ...string) {
+	t.Helper()
+	t.Cleanup(func() { filenameRules = defaultFilenameRules() })
+
+	_, err := New(map[string]any{"filename-validator": settings})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// analysistest requires identifier names; the plugin keeps its dashed names.
+	analyzer := *FilenameValidatorAnalyzer
+	analyzer.Name = "filenamevalidator"
+
+	analysistest.Run(t, analysistest.TestData(), &analyzer, pkgs...)
+}
+
+func TestFilenameValidator(t *testing.T) {
+	runFilenameValidator(t, map[string]any{}, "example.com/filenames")
+}
+
+func TestFilenameValidatorDisabledRule(t *testing.T) {
+	runFilenameValidator(t, map[string]any{"rules": map[string]any{"mixed-case": false}},
+		"example.com/filenames/disabled")
+}
+
+func TestFilenameValidatorInvalidSettings(t *testing.T) {
+	t.Cleanup(func() { filenameRules = defaultFilenameRules() })
+
+	for _, settings := range []map[string]any{
+		{"rules": map[string]any{"no-such-rule": false}},
+		{"rules": map[string]any{"mixed-case": "off"}},
+		{"strict-naming": true},
+	} {
+		_, err := New(map[string]any{"filename-validator": settings})
+		if !errors.Is(err, errInvalidSetting) {
+			t.Errorf("%v: error = %v, want an invalid setting error", settings, err)
+		}
+	}
+}
+
+func TestSnakeCaseFilename(t *testing.T) {
+	tests := map[string]string{
+		"FooBar.go":          "foo_bar.go",
+		"fooBar_test.go":     "foo_bar_test.go",
+		"HTTPServer.go":      "http_server.go",
+		"user-service.go":    "user_service.go",
+		"oauth2Client.go":    "oauth2_client.go",
+		"user_service.go":    "",
+		"user.repository.go": "",
+		"Foo__Bar.go":        "",
+	}
+
+	for filename, want := range tests {
+		if got := snakeCaseFilename(filename); got != want {
+			t.Errorf("snakeCaseFilename(%q) = %q, want %q", filename, got, want)
+		}
+	}
+}
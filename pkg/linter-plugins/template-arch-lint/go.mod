@@ -2,4 +2,12 @@ module github.com/LarsArtmann/template-arch-lint/pkg/linter-plugins/template-arc
 
 go 1.26.3
 
-require golang.org/x/tools v0.48.0
+require (
+	go.yaml.in/yaml/v3 v3.0.4
+	golang.org/x/tools v0.48.0
+)
+
+require (
+	golang.org/x/mod v0.38.0 // indirect
+	golang.org/x/sync v0.22.0 // indirect
+)
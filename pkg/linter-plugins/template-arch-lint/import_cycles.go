@@ -1,113 +1,114 @@
 package main
 
 import (
+	"fmt"
+	"go/ast"
 	"go/types"
 	"strings"
 
 	"golang.org/x/tools/go/analysis"
 )
 
-// runImportCycleDetection implements import cycle detection analyzer.
+// importsFact records the module packages a package reaches through its
+// imports. Each entry holds the shortest import chain to the package,
+// starting with a direct import and ending with the package itself.
+type importsFact struct {
+	Paths map[string][]string
+}
+
+func (*importsFact) AFact() {}
+
+func (f *importsFact) String() string {
+	return fmt.Sprintf("imports(%d packages)", len(f.Paths))
+}
+
+// runImportCycleDetection builds the package's imports fact from the facts
+// of its direct imports. An import whose package already reaches this one
+// closes a cycle; it is reported at the import spec with the full path.
 func runImportCycleDetection(pass *analysis.Pass) (any, error) {
-	// Build import graph for this package
-	pkg := pass.Pkg
-	imports := getPackageImports(pkg)
-
-	// Check for direct and indirect cycles
-	visited := make(map[string]bool)
-	recStack := make(map[string]bool)
-
-	for _, imp := range imports {
-		if cycle := findCycle(imp, pkg.Path(), visited, recStack, make([]string, 0)); cycle != nil {
-			// Report cycle on the first file
-			if len(pass.Files) > 0 {
-				pass.Reportf(pass.Files[0].Pos(),
-					"IMPORT_CYCLE: Import cycle detected: %s",
-					strings.Join(cycle, " -> "))
+	self := pass.Pkg.Path()
+	reach := make(map[string][]string)
+	reported := make(map[string]bool)
+
+	for _, file := range pass.Files {
+		for _, spec := range file.Imports {
+			imported, ok := moduleImport(pass, spec)
+			if !ok {
+				continue
+			}
+
+			var dep importsFact
+
+			_ = pass.ImportPackageFact(imported, &dep)
+
+			if cycle := cyclePath(self, imported.Path(), dep.Paths); cycle != nil && !reported[imported.Path()] {
+				reported[imported.Path()] = true
+				pass.Reportf(spec.Pos(), "IMPORT_CYCLE: Import of %q closes the cycle %s",
+					imported.Path(), strings.Join(cycle, " -> "))
 			}
+
+			extendReach(reach, imported.Path(), dep.Paths)
 		}
 	}
 
+	pass.ExportPackageFact(&importsFact{Paths: reach})
+
 	return nil, nil
 }
 
-// getPackageImports extracts all import paths from a package.
-func getPackageImports(pkg *types.Package) []string {
-	var imports []string
-
-	seen := make(map[string]bool)
+// moduleImport resolves spec to the imported package, skipping the standard
+// library, vendored packages and, when the module is known, other modules.
+func moduleImport(pass *analysis.Pass, spec *ast.ImportSpec) (*types.Package, bool) {
+	name := pass.TypesInfo.PkgNameOf(spec)
+	if name == nil {
+		return nil, false
+	}
 
-	for _, imp := range pkg.Imports() {
-		if !seen[imp.Path()] {
-			imports = append(imports, imp.Path())
-			seen[imp.Path()] = true
-		}
+	imported := name.Imported()
+	if !inModule(pass, imported.Path()) {
+		return nil, false
 	}
 
-	return imports
+	return imported, true
 }
 
-// findCycle uses DFS to detect import cycles.
-func findCycle(
-	currentPkg, targetPkg string,
-	visited, recStack map[string]bool,
-	path []string,
-) []string {
-	// Add current package to path
-	path = append(path, currentPkg)
-
-	// Mark current node as visited and part of recursion stack
-	visited[currentPkg] = true
-	recStack[currentPkg] = true
-
-	// If we've reached back to our target, we found a cycle
-	if currentPkg == targetPkg && len(path) > 1 {
-		return append(path, targetPkg) // Complete the cycle
+func inModule(pass *analysis.Pass, path string) bool {
+	if strings.HasPrefix(path, "vendor/") || strings.Contains(path, "/vendor/") {
+		return false
 	}
 
-	// This is a simplified version - in a real implementation, you would need
-	// to analyze the actual import statements of each package. For now, we'll
-	// detect direct cycles and some obvious patterns.
-
-	// Check for common cycle patterns
-	if strings.Contains(currentPkg, targetPkg) || strings.Contains(targetPkg, currentPkg) {
-		if isLikelyCycle(currentPkg, targetPkg) {
-			return []string{targetPkg, currentPkg, targetPkg}
-		}
+	if pass.Module != nil && pass.Module.Path != "" {
+		return path == pass.Module.Path || strings.HasPrefix(path, pass.Module.Path+"/")
 	}
 
-	// Clean up recursion stack
-	recStack[currentPkg] = false
+	// Without module information, only the standard library is told apart:
+	// its import paths have no dot in the first element.
+	first, _, _ := strings.Cut(path, "/")
 
-	return nil
+	return strings.Contains(first, ".")
 }
 
-// isLikelyCycle detects common import cycle patterns.
-func isLikelyCycle(pkg1, pkg2 string) bool {
-	// Check for bidirectional dependencies between related packages
-	pkg1Parts := strings.Split(pkg1, "/")
-	pkg2Parts := strings.Split(pkg2, "/")
-
-	if len(pkg1Parts) > 0 && len(pkg2Parts) > 0 {
-		// Same base package with different subpackages
-		if len(pkg1Parts) > 2 && len(pkg2Parts) > 2 {
-			base1 := strings.Join(pkg1Parts[:len(pkg1Parts)-1], "/")
-			base2 := strings.Join(pkg2Parts[:len(pkg2Parts)-1], "/")
-
-			if base1 == base2 {
-				// Potential cycle between sibling packages
-				return true
-			}
-		}
+// cyclePath returns self -> imported -> ... -> self when the package
+// imported reaches self, and nil otherwise.
+func cyclePath(self, imported string, importedReach map[string][]string) []string {
+	chain, ok := importedReach[self]
+	if !ok {
+		return nil
 	}
 
-	return false
+	return append([]string{self, imported}, chain...)
 }
 
-// Note: This is a simplified implementation for demonstration.
-// A production implementation would need to:
-// 1. Build a complete dependency graph across all packages
-// 2. Use proper graph traversal algorithms
-// 3. Cache results for performance
-// 4. Handle complex module structures
-// 5. Integrate with Go's module system for accurate dependency resolution
+// extendReach adds imported and everything it reaches to reach, keeping the
+// shortest chain to each package.
+func extendReach(reach map[string][]string, imported string, importedReach map[string][]string) {
+	reach[imported] = []string{imported}
+
+	for path, chain := range importedReach {
+		if existing, ok := reach[path]; ok && len(existing) <= len(chain)+1 {
+			continue
+		}
+
+		reach[path] = append([]string{imported}, chain...)
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+// The Go loader refuses packages in an import cycle, so the cycle cases
+// drive the fact logic directly and analysistest covers the acyclic graphs.
+
+// The diamond shares base through left and right without a cycle; the fact
+// on top counts base, left and right but neither the standard library nor
+// the vendored example.com/ext.
+func TestImportCycleNoFalsePositives(t *testing.T) {
+	analyzer := *ImportCycleAnalyzer
+	analyzer.Name = "importcycle"
+
+	analysistest.Run(t, analysistest.TestData(), &analyzer, "example.com/diamond/top")
+}
+
+func TestImportCyclePaths(t *testing.T) {
+	type pkg struct {
+		path    string
+		imports []string
+	}
+
+	tests := map[string]struct {
+		order []pkg // analysis order; the last package closes the cycle
+		want  []string
+	}{
+		"two packages": {
+			order: []pkg{{"b", []string{"a"}}, {"a", []string{"b"}}},
+			want:  []string{"a", "b", "a"},
+		},
+		"three packages": {
+			order: []pkg{{"c", []string{"a"}}, {"b", []string{"c"}}, {"a", []string{"b"}}},
+			want:  []string{"a", "b", "c", "a"},
+		},
+	}
+
+	for name, tt := range tests {
+		reaches := make(map[string]map[string][]string)
+
+		var got []string
+
+		for _, p := range tt.order {
+			reach := make(map[string][]string)
+
+			for _, imported := range p.imports {
+				if cycle := cyclePath(p.path, imported, reaches[imported]); cycle != nil {
+					got = cycle
+				}
+
+				extendReach(reach, imported, reaches[imported])
+			}
+
+			reaches[p.path] = reach
+		}
+
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: cycle = %v, want %v", name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"maps"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// layerBoundaryConfig mirrors the components and deps of .go-arch-lint.yml:
+// components name package path globs, and each component's forbid list
+// names components or package path globs whose identifiers it may not use.
+type layerBoundaryConfig struct {
+	Components map[string][]string
+	Forbid     map[string][]string
+}
+
+func defaultLayerBoundaryConfig() layerBoundaryConfig {
+	return layerBoundaryConfig{
+		Components: map[string][]string{
+			"domain":         {"internal/domain/**"},
+			"application":    {"internal/application/**"},
+			"infrastructure": {"internal/infrastructure/**"},
+		},
+		Forbid: map[string][]string{
+			"domain":      {"infrastructure", "database/sql"},
+			"application": {"infrastructure"},
+		},
+	}
+}
+
+var layerBoundary = defaultLayerBoundaryConfig()
+
+// configureLayerBoundary applies the layer-boundary plugin settings:
+//
+//	layer-boundary:
+//	  components:
+//	    application: {in: internal/application/**}
+//	  deps:
+//	    application: {forbid: [infrastructure, database/sql]}
+func configureLayerBoundary(conf any) error {
+	settings, ok := conf.(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	raw, ok := settings["layer-boundary"]
+	if !ok || raw == nil {
+		return nil
+	}
+
+	section, ok := raw.(map[string]any)
+	if !ok {
+		return fmt.Errorf("layer-boundary: %w: want a map, got %T", errInvalidSetting, raw)
+	}
+
+	config := defaultLayerBoundaryConfig()
+
+	for key, value := range section {
+		var err error
+
+		switch key {
+		case "enable":
+			// golangci-lint decides whether the plugin runs.
+		case "components":
+			config.Components, err = componentPatterns(value, "in")
+		case "deps":
+			config.Forbid, err = componentPatterns(value, "forbid")
+		default:
+			err = fmt.Errorf("%w: unknown key", errInvalidSetting)
+		}
+
+		if err != nil {
+			return fmt.Errorf("layer-boundary.%s: %w", key, err)
+		}
+	}
+
+	for component := range config.Forbid {
+		if _, ok := config.Components[component]; !ok {
+			return fmt.Errorf("layer-boundary.deps.%s: %w: unknown component, want one of %s", component,
+				errInvalidSetting, strings.Join(slices.Sorted(maps.Keys(config.Components)), ", "))
+		}
+	}
+
+	layerBoundary = config
+
+	return nil
+}
+
+// componentPatterns reads a map of component names to {field: patterns},
+// where patterns is a string or a list of strings.
+func componentPatterns(value any, field string) (map[string][]string, error) {
+	components, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: want a map of components, got %T", errInvalidSetting, value)
+	}
+
+	result := make(map[string][]string, len(components))
+
+	for name, raw := range components {
+		entry, ok := raw.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%s: %w: want a map with %s, got %T", name, errInvalidSetting, field, raw)
+		}
+
+		var patterns []string
+
+		switch v := entry[field].(type) {
+		case string:
+			patterns = []string{v}
+		case []any:
+			var err error
+
+			patterns, err = globs(v)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", name, field, err)
+			}
+		default:
+			return nil, fmt.Errorf("%s.%s: %w: want a glob or a list of globs, got %T",
+				name, field, errInvalidSetting, v)
+		}
+
+		result[name] = patterns
+	}
+
+	return result, nil
+}
+
+// forbiddenPatterns returns the package path globs the package at path may
+// not use, with component names resolved to their globs.
+func (c layerBoundaryConfig) forbiddenPatterns(path string) []string {
+	var patterns []string
+
+	for component, in := range c.Components {
+		if !matchesAnyPathGlob(in, path) {
+			continue
+		}
+
+		for _, entry := range c.Forbid[component] {
+			if resolved, ok := c.Components[entry]; ok {
+				patterns = append(patterns, resolved...)
+			} else {
+				patterns = append(patterns, entry)
+			}
+		}
+	}
+
+	return patterns
+}
+
+// matchesAnyPathGlob matches package path against globs relative to the
+// module root: a glob matches the whole path or any suffix of it that
+// starts after a "/". A trailing "/**" also matches the directory itself.
+func matchesAnyPathGlob(patterns []string, path string) bool {
+	for _, pattern := range patterns {
+		base, recursive := strings.CutSuffix(pattern, "/**")
+
+		for suffix := path; ; {
+			if matchGlob(pattern, suffix) || recursive && matchGlob(base, suffix) {
+				return true
+			}
+
+			_, rest, found := strings.Cut(suffix, "/")
+			if !found {
+				break
+			}
+
+			suffix = rest
+		}
+	}
+
+	return false
+}
+
+// runLayerBoundary reports uses of identifiers from packages the current
+// layer may not depend on, whether through a qualified, aliased or dot
+// import.
+func runLayerBoundary(pass *analysis.Pass) (any, error) {
+	forbidden := layerBoundary.forbiddenPatterns(pass.Pkg.Path())
+	if len(forbidden) == 0 {
+		return nil, nil
+	}
+
+	isForbidden := func(pkg *types.Package) bool {
+		return pkg != nil && pkg != pass.Pkg && matchesAnyPathGlob(forbidden, pkg.Path())
+	}
+
+	for _, file := range pass.Files {
+		if strings.HasSuffix(pass.Fset.Position(file.Pos()).Filename, "_test.go") {
+			continue
+		}
+
+		for _, spec := range file.Imports {
+			if spec.Name == nil || spec.Name.Name != "_" {
+				continue
+			}
+
+			if name := pass.TypesInfo.PkgNameOf(spec); name != nil && isForbidden(name.Imported()) {
+				pass.Reportf(spec.Pos(), "LAYER_BOUNDARY: %s may not import %s",
+					pass.Pkg.Path(), name.Imported().Path())
+			}
+		}
+
+		ast.Inspect(file, func(node ast.Node) bool {
+			switch n := node.(type) {
+			case *ast.ImportSpec:
+				return false
+			case *ast.SelectorExpr:
+				ident, ok := n.X.(*ast.Ident)
+				if !ok {
+					return true
+				}
+
+				pkgName, ok := pass.TypesInfo.Uses[ident].(*types.PkgName)
+				if ok && isForbidden(pkgName.Imported()) {
+					reportLayerBoundary(pass, n, ident.Name+"."+n.Sel.Name, pkgName.Imported())
+				}
+
+				return !ok
+			case *ast.Ident:
+				// Only dot-imported identifiers reach here unqualified.
+				obj := pass.TypesInfo.Uses[n]
+				if obj != nil && isForbidden(obj.Pkg()) && obj.Parent() == obj.Pkg().Scope() {
+					reportLayerBoundary(pass, n, n.Name, obj.Pkg())
+				}
+			}
+
+			return true
+		})
+	}
+
+	return nil, nil
+}
+
+func reportLayerBoundary(pass *analysis.Pass, node ast.Node, name string, pkg *types.Package) {
+	pass.Reportf(node.Pos(), "LAYER_BOUNDARY: %s uses %s from %s; inject it via the container instead",
+		pass.Pkg.Path(), name, pkg.Path())
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestLayerBoundary(t *testing.T) {
+	// analysistest requires identifier names; the plugin keeps its dashed names.
+	analyzer := *LayerBoundaryAnalyzer
+	analyzer.Name = "layerboundary"
+
+	analysistest.Run(t, analysistest.TestData(), &analyzer,
+		"example.com/layered/internal/application/handlers",
+		"example.com/layered/internal/domain/services",
+		"example.com/layered/internal/infrastructure/persistence")
+}
+
+func TestLayerBoundaryConfiguredRules(t *testing.T) {
+	t.Cleanup(func() { layerBoundary = defaultLayerBoundaryConfig() })
+
+	_, err := New(map[string]any{"layer-boundary": map[string]any{
+		"components": map[string]any{
+			"handlers": map[string]any{"in": "internal/application/handlers"},
+			"storage":  map[string]any{"in": []any{"internal/infrastructure/**"}},
+		},
+		"deps": map[string]any{
+			"handlers": map[string]any{"forbid": []any{"storage"}},
+		},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"internal/infrastructure/**"}
+	if got := layerBoundary.forbiddenPatterns("example.com/app/internal/application/handlers"); len(got) != 1 ||
+		got[0] != want[0] {
+		t.Errorf("forbidden patterns = %v, want %v", got, want)
+	}
+
+	if got := layerBoundary.forbiddenPatterns("example.com/app/internal/domain/services"); got != nil {
+		t.Errorf("domain is no longer configured, got forbidden patterns %v", got)
+	}
+}
+
+func TestLayerBoundaryInvalidSettings(t *testing.T) {
+	t.Cleanup(func() { layerBoundary = defaultLayerBoundaryConfig() })
+
+	for _, settings := range []map[string]any{
+		{"components": []any{"internal/domain/**"}},
+		{"components": map[string]any{"domain": map[string]any{"in": 3}}},
+		{"deps": map[string]any{"web": map[string]any{"forbid": "internal/infrastructure/**"}}},
+		{"rules": map[string]any{}},
+	} {
+		_, err := New(map[string]any{"layer-boundary": settings})
+		if !errors.Is(err, errInvalidSetting) {
+			t.Errorf("%v: error = %v, want an invalid setting error", settings, err)
+		}
+	}
+}
@@ -1,42 +1,75 @@
-// Package main implements the unified template-arch-lint plugin for golangci-lint
-// This plugin consolidates filename validation, CMD single main enforcement,
-// import cycle detection, and code duplication analysis into a single analyzer.
+// Package main implements the unified template-arch-lint plugin for golangci-lint.
+// New returns its analyzers: filename validation, cmd single main
+// enforcement, import cycle detection, code duplication, repository nil
+// checks in services, error wrapping context, context-first/error-last
+// method signatures and layer boundaries.
+//
+// Built as a program it runs the same analyzers without golangci-lint:
+//
+//	go run github.com/LarsArtmann/template-arch-lint/pkg/linter-plugins/template-arch-lint ./...
+//
+// It reads its settings from the module's .custom-gcl.yml, as golangci-lint
+// does, unless -settings or -config says otherwise. It exits 0 when clean,
+// 1 with findings and 2 when it could not run.
 package main
 
 import (
+	"fmt"
+	"os"
+
 	"golang.org/x/tools/go/analysis"
 )
 
+func main() {
+	os.Exit(runArchLint(os.Args[1:], os.Stdout, os.Stderr))
+}
+
 // New returns all analyzers provided by the template-arch-lint plugin.
 // This is the required entry point for golangci-lint custom plugins.
 func New(conf any) ([]*analysis.Analyzer, error) {
+	for _, configure := range []func(any) error{
+		configureFilenameValidator, configureCmdSingleMain, configureCodeDuplication,
+		configureWrapContext, configureContextFirst, configureLayerBoundary,
+	} {
+		err := configure(conf)
+		if err != nil {
+			return nil, fmt.Errorf("template-arch-lint settings: %w", err)
+		}
+	}
+
 	return []*analysis.Analyzer{
 		FilenameValidatorAnalyzer,
 		CmdSingleMainAnalyzer,
 		ImportCycleAnalyzer,
 		CodeDuplicationAnalyzer,
+		RepositoryNilCheckAnalyzer,
+		WrapContextAnalyzer,
+		ContextFirstAnalyzer,
+		LayerBoundaryAnalyzer,
 	}, nil
 }
 
 // FilenameValidatorAnalyzer validates Go file naming conventions.
 var FilenameValidatorAnalyzer = &analysis.Analyzer{
 	Name: "filename-validator",
-	Doc:  "Validates Go file naming conventions following standard patterns",
+	Doc:  "Validates Go file naming conventions: snake_case, no extra dots, tests in _test.go files",
 	Run:  runFilenameValidation,
 }
 
-// CmdSingleMainAnalyzer enforces exactly one main.go file in cmd/ directory.
+// CmdSingleMainAnalyzer enforces exactly one main.go per cmd/<name>/ command directory.
 var CmdSingleMainAnalyzer = &analysis.Analyzer{
 	Name: "cmd-single-main",
-	Doc:  "Enforces exactly one main.go file in cmd/ directory for clean architecture",
+	Doc:  "Enforces one main.go per cmd/<name>/ command directory and none elsewhere",
 	Run:  runCmdSingleMainValidation,
 }
 
 // ImportCycleAnalyzer detects import cycles and circular dependencies.
 var ImportCycleAnalyzer = &analysis.Analyzer{
 	Name: "import-cycle-detector",
-	Doc:  "Detects import cycles and circular dependencies using AST analysis",
+	Doc:  "Detects import cycles across packages and reports the full cycle path",
 	Run:  runImportCycleDetection,
+
+	FactTypes: []analysis.Fact{(*importsFact)(nil)},
 }
 
 // CodeDuplicationAnalyzer detects code duplications using AST analysis.
@@ -45,3 +78,31 @@ var CodeDuplicationAnalyzer = &analysis.Analyzer{
 	Doc:  "Detects code duplications using AST analysis with configurable thresholds",
 	Run:  runCodeDuplicationDetection,
 }
+
+// RepositoryNilCheckAnalyzer flags nil checks on repository lookup results in services.
+var RepositoryNilCheckAnalyzer = &analysis.Analyzer{
+	Name: "repository-nil-check",
+	Doc:  "Flags nil comparisons on repository Find* results in the service layer",
+	Run:  runRepositoryNilCheck,
+}
+
+// WrapContextAnalyzer flags errors from calls returned without added context.
+var WrapContextAnalyzer = &analysis.Analyzer{
+	Name: "wrap-context",
+	Doc:  "Flags errors propagated from calls without wrapping context in repositories and services",
+	Run:  runWrapContext,
+}
+
+// ContextFirstAnalyzer enforces the context-first, error-last method signatures of services and repositories.
+var ContextFirstAnalyzer = &analysis.Analyzer{
+	Name: "context-first",
+	Doc:  "Requires exported service and repository methods to take context.Context first and return error last",
+	Run:  runContextFirst,
+}
+
+// LayerBoundaryAnalyzer forbids layers from using identifiers of the packages they may not depend on.
+var LayerBoundaryAnalyzer = &analysis.Analyzer{
+	Name: "layer-boundary",
+	Doc:  "Forbids domain and application code from referencing infrastructure identifiers directly",
+	Run:  runLayerBoundary,
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// runRepositoryNilCheck flags nil comparisons on repository lookup results in
+// the service layer. A missing entity is reported through the not-found
+// error, so checking the returned pointer against nil hides the contract.
+func runRepositoryNilCheck(pass *analysis.Pass) (any, error) {
+	if !strings.Contains(pass.Pkg.Path(), "/services") {
+		return nil, nil
+	}
+
+	for _, file := range pass.Files {
+		lookupResults := make(map[types.Object]string)
+
+		ast.Inspect(file, func(node ast.Node) bool {
+			switch n := node.(type) {
+			case *ast.AssignStmt:
+				recordRepositoryLookup(pass, n, lookupResults)
+			case *ast.BinaryExpr:
+				reportRepositoryNilComparison(pass, n, lookupResults)
+			}
+
+			return true
+		})
+	}
+
+	return nil, nil
+}
+
+// recordRepositoryLookup remembers variables assigned from a Find* method on
+// a *Repository type, keyed to the method name for the diagnostic.
+func recordRepositoryLookup(pass *analysis.Pass, assign *ast.AssignStmt, lookupResults map[types.Object]string) {
+	if len(assign.Rhs) != 1 || len(assign.Lhs) == 0 {
+		return
+	}
+
+	call, ok := assign.Rhs[0].(*ast.CallExpr)
+	if !ok {
+		return
+	}
+
+	selector, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || !strings.HasPrefix(selector.Sel.Name, "Find") {
+		return
+	}
+
+	selection := pass.TypesInfo.Selections[selector]
+	if selection == nil || !isRepositoryType(selection.Recv()) {
+		return
+	}
+
+	ident, ok := assign.Lhs[0].(*ast.Ident)
+	if !ok || ident.Name == "_" {
+		return
+	}
+
+	if obj := pass.TypesInfo.ObjectOf(ident); obj != nil {
+		lookupResults[obj] = selector.Sel.Name
+	}
+}
+
+func isRepositoryType(t types.Type) bool {
+	if pointer, ok := t.(*types.Pointer); ok {
+		t = pointer.Elem()
+	}
+
+	named, ok := t.(*types.Named)
+
+	return ok && strings.HasSuffix(named.Obj().Name(), "Repository")
+}
+
+func reportRepositoryNilComparison(
+	pass *analysis.Pass,
+	expr *ast.BinaryExpr,
+	lookupResults map[types.Object]string,
+) {
+	if expr.Op != token.EQL && expr.Op != token.NEQ {
+		return
+	}
+
+	operand := expr.X
+	if isNilExpr(pass, operand) {
+		operand = expr.Y
+	} else if !isNilExpr(pass, expr.Y) {
+		return
+	}
+
+	ident, ok := operand.(*ast.Ident)
+	if !ok {
+		return
+	}
+
+	method, ok := lookupResults[pass.TypesInfo.ObjectOf(ident)]
+	if !ok {
+		return
+	}
+
+	pass.Reportf(expr.Pos(),
+		"REPOSITORY_NIL_CHECK: %s result %q compared against nil; "+
+			"check the not-found error or use the %sOption variant",
+		method, ident.Name, method)
+}
+
+func isNilExpr(pass *analysis.Pass, expr ast.Expr) bool {
+	tv, ok := pass.TypesInfo.Types[expr]
+
+	return ok && tv.IsNil()
+}
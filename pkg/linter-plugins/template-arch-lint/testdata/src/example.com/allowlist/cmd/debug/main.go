@@ -0,0 +1,3 @@
+package main // want `command cmd/debug is not one of the allowed commands: api`
+
+func main() {}
@@ -0,0 +1,34 @@
+package repositories
+
+import "context"
+
+type User struct{ ID string }
+
+type UserRepository interface {
+	FindByID(ctx context.Context, id string) (*User, error)
+	Count(ctx context.Context) int // want `ERROR_LAST: Count must return error as its last result`
+	Delete(id string) error        // want `CONTEXT_FIRST: Delete must take context.Context as its first parameter`
+}
+
+// Specification has no method that takes a context: a value type, not a
+// repository contract.
+type Specification interface {
+	IsSatisfiedBy(user *User) bool
+	ToSQL() (string, bool)
+}
+
+type ByID struct{ ID string }
+
+func (s ByID) IsSatisfiedBy(user *User) bool { return user.ID == s.ID }
+
+func (s ByID) ToSQL() (string, bool) { return "id = ?", true }
+
+type Record struct{ Status int }
+
+func (r Record) Completed() bool { return r.Status != 0 }
+
+type Cache struct{ records map[string]Record }
+
+func (c *Cache) Completed(key string) bool { // want `CONTEXT_FIRST: Completed` `ERROR_LAST: Completed`
+	return c.records[key].Completed()
+}
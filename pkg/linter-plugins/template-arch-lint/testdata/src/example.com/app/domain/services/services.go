@@ -0,0 +1,40 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+type UserService struct {
+	timeout time.Duration
+}
+
+func NewUserService() *UserService { return &UserService{timeout: time.Second} }
+
+func (s *UserService) WithTimeout(timeout time.Duration) *UserService {
+	s.timeout = timeout
+
+	return s
+}
+
+func (s *UserService) Timeout() time.Duration { return s.timeout }
+
+func (s *UserService) String() string { return "UserService" }
+
+func (s *UserService) Validate() error { return nil }
+
+func (s *UserService) GetUser(ctx context.Context, id string) (string, error) { return id, ctx.Err() }
+
+func (*UserService) Rename(id string, ctx context.Context) error { // want `as parameter 2; it must be the first`
+	return errors.Join(ctx.Err(), errors.New(id))
+}
+
+func (s *UserService) Deactivate(ctx context.Context, id string) bool { // want `ERROR_LAST: Deactivate`
+	return ctx.Err() == nil && id != ""
+}
+
+// want +1 `CONTEXT_FIRST: Purge must take context.Context` `ERROR_LAST: Purge must return error`
+func (s *UserService) Purge() {}
+
+func (s *UserService) reindex(id string) bool { return id != "" }
@@ -0,0 +1,21 @@
+package driver
+
+import "errors"
+
+var ErrNoRows = errors.New("no rows in result set")
+
+type ValidationError struct{}
+
+func (*ValidationError) Error() string { return "invalid" }
+
+func Query() (string, error) { return "", nil }
+
+func Exec() error { return nil }
+
+func Validate() *ValidationError { return nil }
+
+func WithinTx(fn func() error) error { return fn() }
+
+func Each(fn func(string) error) error { return fn("") }
+
+func Collect(fn func() (string, error)) map[string]error { return nil }
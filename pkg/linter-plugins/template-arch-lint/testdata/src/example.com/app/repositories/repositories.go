@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"example.com/app/driver"
+)
+
+func Unwrapped() (string, error) {
+	row, err := driver.Query()
+	if err != nil {
+		return "", err // want `WRAP_CONTEXT: error from Query returned without context in Unwrapped; wrap it, e\.g\. fmt\.Errorf\("Unwrapped: %w", err\)`
+	}
+
+	return row, nil
+}
+
+func DirectCall() error {
+	return driver.Exec() // want `WRAP_CONTEXT: error from Exec returned without context in DirectCall`
+}
+
+func Wrapped() (string, error) {
+	row, err := driver.Query()
+	if err != nil {
+		return "", fmt.Errorf("query row: %w", err)
+	}
+
+	return row, nil
+}
+
+func SentinelPassthrough() (string, error) {
+	row, err := driver.Query()
+	if errors.Is(err, driver.ErrNoRows) {
+		return "", err
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("query row: %w", err)
+	}
+
+	return row, nil
+}
+
+func DomainError() error {
+	if err := driver.Validate(); err != nil {
+		return err
+	}
+
+	return errors.New("always fails")
+}
+
+func NamedReturn() (row string, err error) {
+	row, err = driver.Query()
+	if err != nil {
+		return // want `WRAP_CONTEXT: error from Query returned without context in NamedReturn`
+	}
+
+	return row, nil
+}
+
+func Closure() error {
+	run := func() error {
+		err := driver.Exec()
+
+		return err // want `WRAP_CONTEXT: error from Exec returned without context in Closure`
+	}
+
+	return fmt.Errorf("run: %w", run())
+}
+
+func SamePackageHelper() error {
+	if err := DirectCall(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func Transaction() error {
+	err := driver.WithinTx(func() error {
+		return driver.Exec() // want `WRAP_CONTEXT: error from Exec returned without context in Transaction`
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func Iterate(fn func(string) error) error {
+	return driver.Each(fn)
+}
+
+func Callback(fn func() error) error {
+	return fn()
+}
+
+func Cancelled(ctx context.Context) error {
+	return ctx.Err()
+}
+
+func Collected() map[string]error {
+	return driver.Collect(func() (string, error) {
+		return "", driver.Exec()
+	})
+}
@@ -0,0 +1,8 @@
+package repositoriesold
+
+import "example.com/app/driver"
+
+// Not checked: "repositories" is only a prefix of this path element.
+func Exec() error {
+	return driver.Exec()
+}
@@ -0,0 +1,16 @@
+package services
+
+import (
+	"errors"
+
+	"example.com/app/driver"
+)
+
+func SentinelPassthrough() error {
+	err := driver.Exec()
+	if errors.Is(err, driver.ErrNoRows) {
+		return err // want `WRAP_CONTEXT: error from Exec returned without context in SentinelPassthrough`
+	}
+
+	return nil
+}
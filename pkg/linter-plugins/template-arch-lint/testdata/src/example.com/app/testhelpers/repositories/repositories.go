@@ -0,0 +1,8 @@
+package repositories
+
+import "example.com/app/driver"
+
+// Excluded: test helpers that share the name of a checked layer.
+func Contract() error {
+	return driver.Exec()
+}
@@ -0,0 +1,3 @@
+package base
+
+const Name = "base"
@@ -0,0 +1,5 @@
+package left
+
+import "example.com/diamond/base"
+
+const Name = "left/" + base.Name
@@ -0,0 +1,10 @@
+package right
+
+import (
+	"strings"
+
+	"example.com/diamond/base"
+	"example.com/ext"
+)
+
+var Name = strings.ToUpper(base.Name) + ext.Suffix
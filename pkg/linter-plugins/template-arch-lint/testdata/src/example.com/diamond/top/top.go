@@ -0,0 +1,10 @@
+package top // want package:"imports\\(3 packages\\)"
+
+import (
+	"fmt"
+
+	"example.com/diamond/left"
+	"example.com/diamond/right"
+)
+
+var Name = fmt.Sprint(left.Name, right.Name)
@@ -0,0 +1,23 @@
+package clones
+
+func SumEven(values []int) int { // want `\(21 tokens, 27% similar\) found in 2 locations, also at .*/clones.go:14:32`
+	total := 0
+	for _, v := range values {
+		if v%2 == 0 {
+			total += v
+		}
+	}
+
+	return total
+}
+
+func SumOdd(numbers []int) int { // want `Duplicate \(27% similar\) of code at .*/clones.go:3:32`
+	sum := 0
+	for _, n := range numbers {
+		if n%2 == 1 {
+			sum += n
+		}
+	}
+
+	return sum
+}
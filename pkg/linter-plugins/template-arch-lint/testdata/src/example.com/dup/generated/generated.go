@@ -0,0 +1,5 @@
+package generated
+
+func Double(n int) int {
+	return n * 2
+}
@@ -0,0 +1,23 @@
+package quiet
+
+func SumEven(values []int) int {
+	total := 0
+	for _, v := range values {
+		if v%2 == 0 {
+			total += v
+		}
+	}
+
+	return total
+}
+
+func SumOdd(numbers []int) int {
+	sum := 0
+	for _, n := range numbers {
+		if n%2 == 1 {
+			sum += n
+		}
+	}
+
+	return sum
+}
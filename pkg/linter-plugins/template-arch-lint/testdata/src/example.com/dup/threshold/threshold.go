@@ -0,0 +1,49 @@
+package threshold
+
+// CountPositive and CountPositiveAgain are copies: reported at the default threshold.
+
+func CountPositive(values []int) int { // want `\(19 tokens, 100% similar\) found in 2 locations, also at .*/threshold.go:16:43`
+	count := 0
+	for _, v := range values {
+		if v > 0 {
+			count += v
+		}
+	}
+
+	return count
+}
+
+func CountPositiveAgain(values []int) int { // want `Duplicate \(100% similar\) of code at .*/threshold.go:5:38`
+	count := 0
+	for _, v := range values {
+		if v > 0 {
+			count += v
+		}
+	}
+
+	return count
+}
+
+// SumEven and SumOdd only share their shape: below the default threshold.
+
+func SumEven(values []int) int {
+	total := 0
+	for _, v := range values {
+		if v%2 == 0 {
+			total += v
+		}
+	}
+
+	return total
+}
+
+func SumOdd(numbers []int) int {
+	sum := 0
+	for _, n := range numbers {
+		if n%2 == 1 {
+			sum += n
+		}
+	}
+
+	return sum
+}
@@ -0,0 +1 @@
+package filenames // want `mixed-case: filename "FooBar.go" uses upper case. Rename it to foo_bar.go`
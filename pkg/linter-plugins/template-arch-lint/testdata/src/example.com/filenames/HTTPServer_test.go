@@ -0,0 +1 @@
+package filenames // want `mixed-case: filename "HTTPServer_test.go" uses upper case. Rename it to http_server_test.go`
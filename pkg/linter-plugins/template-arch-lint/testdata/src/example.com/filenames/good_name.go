@@ -0,0 +1,5 @@
+package filenames
+
+import "testing"
+
+func newFixture(t *testing.T) {}
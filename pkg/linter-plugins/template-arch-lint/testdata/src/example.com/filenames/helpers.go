@@ -0,0 +1,5 @@
+package filenames // want `test-file-placement: filename "helpers.go" declares tests`
+
+import "testing"
+
+func TestHelpers(t *testing.T) {}
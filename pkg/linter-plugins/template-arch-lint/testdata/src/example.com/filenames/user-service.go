@@ -0,0 +1 @@
+package filenames // want `non-snake-case: filename "user-service.go" is not snake_case. Rename it to user_service.go`
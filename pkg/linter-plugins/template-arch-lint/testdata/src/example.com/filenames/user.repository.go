@@ -0,0 +1 @@
+package filenames // want `dots-beyond-extension: filename "user.repository.go" has dots before .go`
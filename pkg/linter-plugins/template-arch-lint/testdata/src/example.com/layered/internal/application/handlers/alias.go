@@ -0,0 +1,13 @@
+package handlers
+
+import store "example.com/layered/internal/infrastructure/persistence"
+
+type UserHandler struct {
+	users *store.UserRepository // want `LAYER_BOUNDARY: .* uses store.UserRepository from`
+}
+
+func NewUserHandler() *UserHandler {
+	users := store.NewUserRepository() // want `store.NewUserRepository .*; inject it via the container`
+
+	return &UserHandler{users: users}
+}
@@ -0,0 +1,7 @@
+package handlers
+
+import . "example.com/layered/internal/infrastructure/persistence"
+
+func newRepository() *UserRepository { // want `uses UserRepository from`
+	return NewUserRepository() // want `uses NewUserRepository from`
+}
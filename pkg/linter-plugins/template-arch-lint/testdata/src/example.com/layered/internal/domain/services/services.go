@@ -0,0 +1,10 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+)
+
+func IsMissing(err error) bool {
+	return errors.Is(err, sql.ErrNoRows) // want `uses sql.ErrNoRows from database/sql`
+}
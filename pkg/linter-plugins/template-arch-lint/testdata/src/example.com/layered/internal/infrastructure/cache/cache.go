@@ -0,0 +1,5 @@
+package cache
+
+type Cache struct{}
+
+func New() *Cache { return &Cache{} }
@@ -0,0 +1,12 @@
+package persistence
+
+import "example.com/layered/internal/infrastructure/cache"
+
+type UserRepository struct {
+	cache *cache.Cache
+}
+
+// NewUserRepository may use the rest of the infrastructure layer.
+func NewUserRepository() *UserRepository {
+	return &UserRepository{cache: cache.New()}
+}
@@ -0,0 +1,3 @@
+package main // want `main.go directly in cmd/ next to other commands. Move it to cmd/<name>/main.go`
+
+func main() {}
@@ -0,0 +1,3 @@
+package main // want `main.go nested below command cmd/api`
+
+func main() {}
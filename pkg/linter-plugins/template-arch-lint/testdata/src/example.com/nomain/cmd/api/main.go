@@ -0,0 +1,3 @@
+package main // want `main.go must contain a main\(\) function`
+
+func run() {}
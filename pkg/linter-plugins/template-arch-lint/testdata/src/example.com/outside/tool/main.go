@@ -0,0 +1,3 @@
+package main // want `main.go of package main outside cmd/`
+
+func main() {}
@@ -0,0 +1,4 @@
+package main
+
+// The only command of the module may live directly in cmd/.
+func main() {}
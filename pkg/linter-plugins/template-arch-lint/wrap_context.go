@@ -0,0 +1,398 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// wrapContextConfig holds the wrap-context settings. All three are
+// package path globs as in context-first.
+type wrapContextConfig struct {
+	Packages []string
+	// StrictPackages also flag sentinel passthrough.
+	StrictPackages []string
+	// Exclude wins over Packages, for helpers that merely share a name.
+	Exclude []string
+}
+
+func defaultWrapContextConfig() wrapContextConfig {
+	return wrapContextConfig{
+		Packages:       []string{"**/repositories", "**/services"},
+		StrictPackages: nil,
+		Exclude:        []string{"**/testhelpers/**"},
+	}
+}
+
+var wrapContext = defaultWrapContextConfig()
+
+// configureWrapContext applies the wrap-context plugin settings:
+// packages, strict-packages and exclude, each a list of globs.
+func configureWrapContext(conf any) error {
+	settings, ok := conf.(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	raw, ok := settings["wrap-context"]
+	if !ok || raw == nil {
+		return nil
+	}
+
+	section, ok := raw.(map[string]any)
+	if !ok {
+		return fmt.Errorf("wrap-context: %w: want a map, got %T", errInvalidSetting, raw)
+	}
+
+	config := defaultWrapContextConfig()
+
+	for key, value := range section {
+		var err error
+
+		switch key {
+		case "enable":
+			// golangci-lint decides whether the plugin runs.
+		case "packages":
+			config.Packages, err = globs(value)
+		case "strict-packages":
+			config.StrictPackages, err = globs(value)
+		case "exclude":
+			config.Exclude, err = globs(value)
+		default:
+			err = fmt.Errorf("%w: unknown key", errInvalidSetting)
+		}
+
+		if err != nil {
+			return fmt.Errorf("wrap-context.%s: %w", key, err)
+		}
+	}
+
+	wrapContext = config
+
+	return nil
+}
+
+// wrapContextFunc holds the state for one function body.
+type wrapContextFunc struct {
+	pass   *analysis.Pass
+	name   string
+	strict bool
+	// results are the named error results, for bare returns.
+	results []*ast.Ident
+	// unwrapped maps an error variable to the call it was last assigned from.
+	unwrapped map[types.Object]string
+	// sentinels are if-bodies guarded by errors.Is on a variable.
+	sentinels []sentinelGuard
+}
+
+type sentinelGuard struct {
+	body *ast.BlockStmt
+	obj  types.Object
+}
+
+// runWrapContext flags returns that propagate an error from a call without
+// adding context. Errors built by fmt.Errorf, the errors packages or a
+// domain error constructor count as wrapped, and so do calls whose static
+// result type is a concrete domain error and calls within the package.
+// Outside strict packages a return guarded by errors.Is on the same
+// variable is an intended sentinel passthrough and is allowed.
+func runWrapContext(pass *analysis.Pass) (any, error) {
+	config := wrapContext
+	if !matchesAnyGlob(config.Packages, pass.Pkg.Path()) || matchesAnyGlob(config.Exclude, pass.Pkg.Path()) {
+		return nil, nil
+	}
+
+	strict := matchesAnyGlob(config.StrictPackages, pass.Pkg.Path())
+
+	for _, file := range pass.Files {
+		if strings.HasSuffix(pass.Fset.Position(file.Pos()).Filename, "_test.go") {
+			continue
+		}
+
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+
+			checkWrapContext(pass, fn.Name.Name, strict, fn.Type, fn.Body)
+		}
+	}
+
+	return nil, nil
+}
+
+func checkWrapContext(pass *analysis.Pass, name string, strict bool, fnType *ast.FuncType, body *ast.BlockStmt) {
+	check := &wrapContextFunc{
+		pass:      pass,
+		name:      name,
+		strict:    strict,
+		results:   namedErrorResults(pass, fnType),
+		unwrapped: make(map[types.Object]string),
+		sentinels: nil,
+	}
+
+	// data are func literals whose errors become values, such as the
+	// callback of a map-building helper, rather than returned errors.
+	data := make(map[*ast.FuncLit]bool)
+
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.CallExpr:
+			if result := lastResult(pass, n); result != nil && !isErrorType(result) {
+				for _, arg := range n.Args {
+					if lit, ok := ast.Unparen(arg).(*ast.FuncLit); ok {
+						data[lit] = true
+					}
+				}
+			}
+		case *ast.FuncLit:
+			if !data[n] {
+				checkWrapContext(pass, name, strict, n.Type, n.Body)
+			}
+
+			return false
+		case *ast.AssignStmt:
+			check.recordAssign(n)
+		case *ast.IfStmt:
+			check.recordSentinelGuard(n)
+		case *ast.ReturnStmt:
+			check.checkReturn(n)
+		}
+
+		return true
+	})
+}
+
+func namedErrorResults(pass *analysis.Pass, fnType *ast.FuncType) []*ast.Ident {
+	if fnType.Results == nil {
+		return nil
+	}
+
+	var names []*ast.Ident
+
+	for _, field := range fnType.Results.List {
+		if !isErrorType(pass.TypesInfo.TypeOf(field.Type)) {
+			continue
+		}
+
+		names = append(names, field.Names...)
+	}
+
+	return names
+}
+
+// recordAssign tracks which error variables currently hold an unwrapped
+// error from a call. Any other assignment clears the variable.
+func (c *wrapContextFunc) recordAssign(assign *ast.AssignStmt) {
+	for i, lhs := range assign.Lhs {
+		ident, ok := lhs.(*ast.Ident)
+		if !ok || ident.Name == "_" {
+			continue
+		}
+
+		obj := c.pass.TypesInfo.ObjectOf(ident)
+		if obj == nil || !isErrorType(obj.Type()) {
+			continue
+		}
+
+		delete(c.unwrapped, obj)
+
+		rhs := assign.Rhs[0]
+		if len(assign.Rhs) == len(assign.Lhs) {
+			rhs = assign.Rhs[i]
+		}
+
+		if call, ok := ast.Unparen(rhs).(*ast.CallExpr); ok && c.propagates(call) {
+			c.unwrapped[obj] = calleeName(call)
+		}
+	}
+}
+
+func (c *wrapContextFunc) recordSentinelGuard(stmt *ast.IfStmt) {
+	if c.strict {
+		return
+	}
+
+	ast.Inspect(stmt.Cond, func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok || len(call.Args) == 0 || !isFunc(c.pass, call, "errors", "Is") {
+			return true
+		}
+
+		if ident, ok := ast.Unparen(call.Args[0]).(*ast.Ident); ok {
+			c.sentinels = append(c.sentinels, sentinelGuard{body: stmt.Body, obj: c.pass.TypesInfo.ObjectOf(ident)})
+		}
+
+		return true
+	})
+}
+
+func (c *wrapContextFunc) checkReturn(ret *ast.ReturnStmt) {
+	if len(ret.Results) == 0 {
+		for _, ident := range c.results {
+			c.checkIdent(ret, ret.Pos(), c.pass.TypesInfo.ObjectOf(ident))
+		}
+
+		return
+	}
+
+	for _, result := range ret.Results {
+		switch expr := ast.Unparen(result).(type) {
+		case *ast.Ident:
+			if isErrorType(c.pass.TypesInfo.TypeOf(expr)) {
+				c.checkIdent(ret, expr.Pos(), c.pass.TypesInfo.ObjectOf(expr))
+			}
+		case *ast.CallExpr:
+			if returnsError(c.pass, expr) && c.propagates(expr) {
+				c.report(expr.Pos(), calleeName(expr), "err")
+			}
+		}
+	}
+}
+
+func (c *wrapContextFunc) checkIdent(ret *ast.ReturnStmt, pos token.Pos, obj types.Object) {
+	callee, ok := c.unwrapped[obj]
+	if !ok {
+		return
+	}
+
+	for _, guard := range c.sentinels {
+		if guard.obj == obj && guard.body.Pos() <= ret.Pos() && ret.End() <= guard.body.End() {
+			return
+		}
+	}
+
+	c.report(pos, callee, obj.Name())
+}
+
+func (c *wrapContextFunc) report(pos token.Pos, callee, variable string) {
+	c.pass.Reportf(pos,
+		"WRAP_CONTEXT: error from %s returned without context in %s; "+
+			"wrap it, e.g. fmt.Errorf(\"%s: %%w\", %s)",
+		callee, c.name, c.name, variable)
+}
+
+// propagates reports whether call passes on an error it did not create.
+func (c *wrapContextFunc) propagates(call *ast.CallExpr) bool {
+	if c.pass.TypesInfo.Types[call.Fun].IsType() || isFunc(c.pass, call, "fmt", "Errorf") {
+		return false
+	}
+
+	// Same-package helpers are checked where they return, so the context is
+	// added once, at the point an error crosses a package boundary.
+	// context errors are the sentinels Canceled and DeadlineExceeded, which
+	// callers match with errors.Is.
+	if fn := calledFunc(c.pass, call); fn != nil && fn.Pkg() != nil &&
+		(fn.Pkg() == c.pass.Pkg || fn.Pkg().Path() == "context" || isErrorsPackage(fn.Pkg().Path())) {
+		return false
+	}
+
+	// The error of a call through a func value, or of a call handed a
+	// callback such as WithinTx, is the callback's own: it is checked where
+	// the callback returns, or belongs to whoever passed the func in.
+	if c.callsFuncValue(call) || takesErrorCallback(c.pass, call) {
+		return false
+	}
+
+	// A concrete result type such as *ValidationError is already a domain error.
+	return returnsError(c.pass, call) && isErrorInterface(lastResult(c.pass, call))
+}
+
+func (c *wrapContextFunc) callsFuncValue(call *ast.CallExpr) bool {
+	ident, ok := ast.Unparen(call.Fun).(*ast.Ident)
+	if !ok {
+		return false
+	}
+
+	_, ok = c.pass.TypesInfo.Uses[ident].(*types.Var)
+
+	return ok
+}
+
+func takesErrorCallback(pass *analysis.Pass, call *ast.CallExpr) bool {
+	for _, arg := range call.Args {
+		t := pass.TypesInfo.TypeOf(arg)
+		if t == nil {
+			continue
+		}
+
+		signature, ok := t.Underlying().(*types.Signature)
+		if ok && signature.Results().Len() > 0 &&
+			isErrorType(signature.Results().At(signature.Results().Len()-1).Type()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isErrorsPackage(path string) bool {
+	return path == "errors" || path == "github.com/pkg/errors" || strings.HasSuffix(path, "/pkg/errors")
+}
+
+func calledFunc(pass *analysis.Pass, call *ast.CallExpr) *types.Func {
+	var ident *ast.Ident
+
+	switch fun := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		ident = fun
+	case *ast.SelectorExpr:
+		ident = fun.Sel
+	default:
+		return nil
+	}
+
+	fn, _ := pass.TypesInfo.Uses[ident].(*types.Func)
+
+	return fn
+}
+
+// isFunc reports whether call targets the package-level function pkg.name.
+func isFunc(pass *analysis.Pass, call *ast.CallExpr, pkg, name string) bool {
+	fn := calledFunc(pass, call)
+
+	return fn != nil && fn.Pkg() != nil && fn.Pkg().Path() == pkg && fn.Name() == name
+}
+
+func calleeName(call *ast.CallExpr) string {
+	switch fun := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		return fun.Name
+	case *ast.SelectorExpr:
+		return fun.Sel.Name
+	default:
+		return "call"
+	}
+}
+
+func lastResult(pass *analysis.Pass, call *ast.CallExpr) types.Type {
+	switch t := pass.TypesInfo.TypeOf(call).(type) {
+	case *types.Tuple:
+		if t.Len() == 0 {
+			return nil
+		}
+
+		return t.At(t.Len() - 1).Type()
+	default:
+		return t
+	}
+}
+
+func returnsError(pass *analysis.Pass, call *ast.CallExpr) bool {
+	return isErrorType(lastResult(pass, call))
+}
+
+var errorInterface = types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
+
+func isErrorType(t types.Type) bool {
+	return t != nil && types.Implements(t, errorInterface)
+}
+
+func isErrorInterface(t types.Type) bool {
+	return t != nil && types.Identical(t, types.Universe.Lookup("error").Type())
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestWrapContext(t *testing.T) {
+	t.Cleanup(func() { wrapContext = defaultWrapContextConfig() })
+
+	_, err := New(map[string]any{"wrap-context": map[string]any{"strict-packages": []any{"**/strict/**"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// analysistest requires identifier names; the plugin keeps its dashed names.
+	analyzer := *WrapContextAnalyzer
+	analyzer.Name = "wrapcontext"
+
+	analysistest.Run(t, analysistest.TestData(), &analyzer,
+		"example.com/app/repositories", "example.com/app/strict/services",
+		"example.com/app/testhelpers/repositories", "example.com/app/repositoriesold")
+}
+
+func TestWrapContextInvalidSettings(t *testing.T) {
+	t.Cleanup(func() { wrapContext = defaultWrapContextConfig() })
+
+	for _, settings := range []any{
+		[]any{"**/services"},
+		map[string]any{"packages": "**/services"},
+		map[string]any{"strict-packages": []any{1}},
+		map[string]any{"exclude": []any{"[testhelpers"}},
+		map[string]any{"wrap-context-packages": []any{"/services"}},
+	} {
+		_, err := New(map[string]any{"wrap-context": settings})
+		if !errors.Is(err, errInvalidSetting) {
+			t.Errorf("%v: error = %v, want an invalid setting error", settings, err)
+		}
+	}
+}
@@ -4,19 +4,42 @@ VALUES (?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
 RETURNING *;
 
 -- name: GetUser :one
+SELECT * FROM users WHERE id = ? AND deleted_at IS NULL LIMIT 1;
+
+-- name: GetUserIncludingDeleted :one
 SELECT * FROM users WHERE id = ? LIMIT 1;
 
 -- name: GetUserByEmail :one
-SELECT * FROM users WHERE email = ? LIMIT 1;
+SELECT * FROM users WHERE email = ? AND deleted_at IS NULL LIMIT 1;
 
 -- name: UpdateUser :one
+-- No row is returned when the version is stale or the user is gone; callers
+-- map that to repositories.ErrConcurrentModification.
 UPDATE users 
-SET email = ?, name = ?, updated_at = CURRENT_TIMESTAMP
-WHERE id = ?
+SET email = ?, name = ?, version = version + 1, updated_at = CURRENT_TIMESTAMP
+WHERE id = ? AND version = ?
+RETURNING *;
+
+-- name: SoftDeleteUser :one
+-- Versioned like UpdateUser; no row is returned for a stale version.
+UPDATE users
+SET deleted_at = CURRENT_TIMESTAMP, version = version + 1, updated_at = CURRENT_TIMESTAMP
+WHERE id = ? AND version = ? AND deleted_at IS NULL
+RETURNING *;
+
+-- name: RestoreUser :one
+-- Fails on idx_users_email_active when an active user took the email.
+UPDATE users
+SET deleted_at = NULL, version = version + 1, updated_at = CURRENT_TIMESTAMP
+WHERE id = ? AND version = ? AND deleted_at IS NOT NULL
 RETURNING *;
 
 -- name: DeleteUser :exec
+-- Purges the row, soft-deleted or not.
 DELETE FROM users WHERE id = ?;
 
 -- name: ListUsers :many
-SELECT * FROM users ORDER BY created_at DESC LIMIT ? OFFSET ?;
\ No newline at end of file
+SELECT * FROM users WHERE deleted_at IS NULL ORDER BY created_at DESC LIMIT ? OFFSET ?;
+
+-- name: ListDeletedUsers :many
+SELECT * FROM users WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC LIMIT ? OFFSET ?;